@@ -0,0 +1,105 @@
+// Command scale-simulator pretends to be a weighing indicator on a TCP port, so the scale
+// integration can be exercised without hardware. Type a weight in kg and press enter to put
+// a fruit on the pan, "0" or "clear" to empty it, "quit" to exit.
+//
+//	go run ./cmd/scale-simulator -listen :4001 -protocol and
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"durich-be/pkg/scale"
+)
+
+func main() {
+	listen := flag.String("listen", ":4001", "address to listen on")
+	protocol := flag.String("protocol", scale.ProtocolAND, "output format: and, toledo or simple")
+	interval := flag.Duration("interval", 200*time.Millisecond, "time between frames")
+	auto := flag.Duration("auto", 0, "place a random 1.5-4.5 kg fruit every interval instead of reading stdin, e.g. 5s")
+	flag.Parse()
+
+	sim, err := scale.NewSimulator(*protocol, *interval)
+	if err != nil {
+		log.Fatalf("%v: %s", err, *protocol)
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("listen %s: %v", *listen, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("scale simulator (%s) listening on %s", *protocol, ln.Addr())
+
+	if *auto > 0 {
+		go autoPlace(ctx, sim, *auto)
+	} else {
+		go readCommands(sim, stop)
+	}
+
+	if err := sim.Serve(ctx, ln); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// autoPlace alternates between a fruit on the pan and an empty pan
+func autoPlace(ctx context.Context, sim *scale.Simulator, every time.Duration) {
+	ticker := time.NewTicker(every / 2)
+	defer ticker.Stop()
+
+	loaded := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if loaded {
+				sim.Clear()
+			} else {
+				kg := 1.5 + rand.Float64()*3
+				sim.Place(kg)
+				log.Printf("placed %.3f kg", kg)
+			}
+			loaded = !loaded
+		}
+	}
+}
+
+func readCommands(sim *scale.Simulator, stop context.CancelFunc) {
+	fmt.Println("enter weight in kg, 'clear' or 'quit'")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		cmd := strings.TrimSpace(scanner.Text())
+		switch cmd {
+		case "":
+			continue
+		case "clear":
+			sim.Clear()
+			continue
+		case "quit", "exit":
+			stop()
+			return
+		}
+
+		kg, err := strconv.ParseFloat(strings.ReplaceAll(cmd, ",", "."), 64)
+		if err != nil || kg < 0 {
+			fmt.Println("weight must be a non-negative number")
+			continue
+		}
+		sim.Place(kg)
+	}
+	stop()
+}
@@ -0,0 +1,19 @@
+package constants
+
+// Aggregation levels of the yield analytics, following the location hierarchy
+const (
+	YieldLevelPohon  = "pohon"
+	YieldLevelBlok   = "blok"
+	YieldLevelDivisi = "divisi"
+	YieldLevelEstate = "estate"
+)
+
+// Metrics the yield analytics can rank by
+const (
+	YieldUrutQty       = "qty"
+	YieldUrutBerat     = "berat"
+	YieldUrutRataBerat = "rata_berat"
+)
+
+// YieldGradeBelumGrading labels fruits that are not in a lot yet
+const YieldGradeBelumGrading = "BELUM_GRADING"
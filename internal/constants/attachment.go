@@ -0,0 +1,11 @@
+package constants
+
+// Entities an attachment can belong to
+const (
+	AttachmentEntityBuah     = "buah"
+	AttachmentEntityLot      = "lot"
+	AttachmentEntityShipment = "shipment"
+	AttachmentEntitySales    = "sales"
+)
+
+const AttachmentThumbnailSize = 320
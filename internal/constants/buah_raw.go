@@ -0,0 +1,7 @@
+package constants
+
+const (
+	SyncStatusCreated   = "CREATED"
+	SyncStatusDuplicate = "DUPLICATE"
+	SyncStatusRejected  = "REJECTED"
+)
@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+// A hold stays AKTIF until its holder ships the lot (DIKIRIM), releases it (DILEPAS) or its
+// time runs out (KEDALUWARSA)
+const (
+	HoldStatusAktif       = "AKTIF"
+	HoldStatusDilepas     = "DILEPAS"
+	HoldStatusKedaluwarsa = "KEDALUWARSA"
+	HoldStatusDikirim     = "DIKIRIM"
+)
+
+// Hold durations used when none is configured. A hold without berakhir_at lasts
+// HoldDefaultDurasi and no hold may last longer than HoldMaksDurasi.
+const (
+	HoldDefaultDurasi   = 24 * time.Hour
+	HoldMaksDurasi      = 72 * time.Hour
+	HoldDefaultInterval = 5 * time.Minute
+)
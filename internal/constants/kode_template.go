@@ -0,0 +1,14 @@
+package constants
+
+const (
+	KodeTipeBuah     = "BUAH"
+	KodeTipeLot      = "LOT"
+	KodeTipeShipment = "SHIPMENT"
+)
+
+const (
+	KodeResetNone    = "NONE"
+	KodeResetDaily   = "DAILY"
+	KodeResetMonthly = "MONTHLY"
+	KodeResetYearly  = "YEARLY"
+)
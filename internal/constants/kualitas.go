@@ -0,0 +1,32 @@
+package constants
+
+// Quality attribute keys, used in jenis configuration (atribut_wajib) and import columns
+const (
+	AtributTingkatKematangan = "tingkat_kematangan"
+	AtributCacat             = "cacat"
+	AtributSkorAroma         = "skor_aroma"
+	AtributMetodePanen       = "metode_panen"
+)
+
+const (
+	KematanganMentah         = "MENTAH"
+	KematanganSetengahMatang = "SETENGAH_MATANG"
+	KematanganMatang         = "MATANG"
+	KematanganLewatMatang    = "LEWAT_MATANG"
+)
+
+const (
+	CacatRetak      = "RETAK"
+	CacatLubangUlat = "LUBANG_ULAT"
+	CacatDuriRusak  = "DURI_RUSAK"
+)
+
+const (
+	MetodePanenJatuh  = "JATUH"
+	MetodePanenPotong = "POTONG"
+)
+
+const (
+	SkorAromaMinDefault = 1
+	SkorAromaMaxDefault = 5
+)
@@ -8,3 +8,49 @@ const (
 	LotStatusSold    = "SOLD"
 	LotStatusEmpty   = "EMPTY"
 )
+
+// Lot genealogy type. A split moves chosen fruits into a new lot, a merge moves
+// every fruit of several lots into a new one, a regrade moves fruits into a lot of another grade.
+const (
+	LotGenealogiSplit   = "SPLIT"
+	LotGenealogiMerge   = "MERGE"
+	LotGenealogiRegrade = "REGRADE"
+)
+
+// Stock ledger movement type. Every change of a lot's qty_sisa or berat_sisa is one movement,
+// SALDO_AWAL is the balance of lots that existed before the ledger.
+const (
+	LotMutasiSaldoAwal     = "SALDO_AWAL"
+	LotMutasiFinalisasi    = "FINALISASI"
+	LotMutasiKirim         = "KIRIM"
+	LotMutasiBatalKirim    = "BATAL_KIRIM"
+	LotMutasiTerima        = "TERIMA"
+	LotMutasiSplitKeluar   = "SPLIT_KELUAR"
+	LotMutasiSplitMasuk    = "SPLIT_MASUK"
+	LotMutasiMergeKeluar   = "MERGE_KELUAR"
+	LotMutasiMergeMasuk    = "MERGE_MASUK"
+	LotMutasiRegradeKeluar = "REGRADE_KELUAR"
+	LotMutasiRegradeMasuk  = "REGRADE_MASUK"
+	LotMutasiWriteOff      = "WRITE_OFF"
+	LotMutasiTimbangUlang  = "TIMBANG_ULANG"
+)
+
+// Document behind a ledger movement
+const (
+	LotMutasiRefPengiriman   = "PENGIRIMAN"
+	LotMutasiRefLot          = "LOT"
+	LotMutasiRefWriteOff     = "WRITE_OFF"
+	LotMutasiRefSesiGrading  = "SESI_GRADING"
+	LotMutasiRefTimbangUlang = "TIMBANG_ULANG"
+)
+
+// Age of a stored lot against the shelf life of its jenis. MENDEKATI starts the warning
+// window before expiry.
+const (
+	LotUmurSegar       = "SEGAR"
+	LotUmurMendekati   = "MENDEKATI"
+	LotUmurKedaluwarsa = "KEDALUWARSA"
+)
+
+// LotPeringatanDefaultJam is the warning window before expiry for a jenis that sets none
+const LotPeringatanDefaultJam = 24
@@ -0,0 +1,7 @@
+package constants
+
+// Harvest season status. A closed season no longer accepts changes to its harvests, lots or sales.
+const (
+	MusimStatusAktif   = "AKTIF"
+	MusimStatusDitutup = "DITUTUP"
+)
@@ -0,0 +1,7 @@
+package constants
+
+// Grading session status. Closing a session finalizes all of its lots.
+const (
+	SesiGradingStatusBuka    = "BUKA"
+	SesiGradingStatusDitutup = "DITUTUP"
+)
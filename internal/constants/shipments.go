@@ -6,3 +6,10 @@ const (
 	ShipmentStatusReceived  = "RECEIVED"
 	ShipmentStatusCompleted = "COMPLETED"
 )
+
+// Order in which lots are proposed for a shipment. FEFO takes the lots that expire first,
+// FIFO the lots finalized first, both before lots without a shelf life or date.
+const (
+	ShipmentSaranFEFO = "FEFO"
+	ShipmentSaranFIFO = "FIFO"
+)
@@ -0,0 +1,12 @@
+package constants
+
+import "time"
+
+// Where a stock-as-of answer comes from: the day's snapshot, or replaying the lot ledger
+const (
+	StokSumberSnapshot = "SNAPSHOT"
+	StokSumberReplay   = "REPLAY"
+)
+
+// StokSnapshotDefaultInterval is how often the snapshot job checks for a missing day when none is configured
+const StokSnapshotDefaultInterval = time.Hour
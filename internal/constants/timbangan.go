@@ -0,0 +1,14 @@
+package constants
+
+// Outcome of a captured scale reading
+const (
+	PembacaanStatusDiterima = "DITERIMA"
+	PembacaanStatusDitolak  = "DITOLAK"
+)
+
+// Stable-weight detection defaults for a stasiun timbang
+const (
+	TimbanganDefaultMinBerat  = 0.5
+	TimbanganDefaultToleransi = 0.02
+	TimbanganDefaultSampel    = 5
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Reason codes for writing stock off a lot. SUSUT is weight loss and only reduces berat.
+const (
+	WriteOffAlasanBusuk  = "BUSUK"
+	WriteOffAlasanRetak  = "RETAK"
+	WriteOffAlasanDicuri = "DICURI"
+	WriteOffAlasanSampel = "SAMPEL"
+	WriteOffAlasanSusut  = "SUSUT"
+)
+
+// A write-off above the approval threshold waits as MENUNGGU until an admin decides on it
+const (
+	WriteOffStatusMenunggu  = "MENUNGGU"
+	WriteOffStatusDisetujui = "DISETUJUI"
+	WriteOffStatusDitolak   = "DITOLAK"
+)
+
+// WriteOffDefaultAmbangBerat is the approval threshold in kg when none is configured
+const WriteOffDefaultAmbangBerat = 10.0
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsController(service services.AnalyticsService) AnalyticsController {
+	return AnalyticsController{service: service}
+}
+
+func (c *AnalyticsController) GetYield(ctx *gin.Context) {
+	var q requests.YieldQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.GetYield(ctx.Request.Context(), q)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Yield analytics retrieved successfully", result)
+}
+
+func (c *AnalyticsController) GetYieldRanking(ctx *gin.Context) {
+	var q requests.YieldQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.GetYieldRanking(ctx.Request.Context(), q)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Yield ranking retrieved successfully", result)
+}
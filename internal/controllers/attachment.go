@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentController struct {
+	service services.AttachmentService
+}
+
+func NewAttachmentController(service services.AttachmentService) AttachmentController {
+	return AttachmentController{service: service}
+}
+
+func (c *AttachmentController) Upload(ctx *gin.Context) {
+	var req requests.AttachmentUploadRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError("file wajib diunggah"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError("file tidak dapat dibaca"))
+		return
+	}
+	defer file.Close()
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.Upload(ctx.Request.Context(), req, fileHeader.Filename, file, userAuth.UserID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Lampiran berhasil diunggah", result)
+}
+
+func (c *AttachmentController) List(ctx *gin.Context) {
+	entityType := ctx.Query("entity_type")
+	entityID := ctx.Query("entity_id")
+	if entityType == "" || entityID == "" {
+		response.SendError(ctx, errors.ValidationError("entity_type dan entity_id wajib diisi"))
+		return
+	}
+
+	result, err := c.service.List(ctx.Request.Context(), entityType, entityID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Lampiran retrieved successfully", result)
+}
+
+func (c *AttachmentController) Download(ctx *gin.Context) {
+	c.serve(ctx, false)
+}
+
+func (c *AttachmentController) Thumbnail(ctx *gin.Context) {
+	c.serve(ctx, true)
+}
+
+func (c *AttachmentController) serve(ctx *gin.Context, thumbnail bool) {
+	attachment, file, err := c.service.Open(ctx.Request.Context(), ctx.Param("id"), thumbnail)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	contentType := attachment.ContentType
+	if thumbnail {
+		contentType = "image/jpeg"
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, attachment.FileName))
+	ctx.Header("Cache-Control", "private, max-age=86400")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
+
+func (c *AttachmentController) Delete(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id"), userAuth); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Lampiran berhasil dihapus", nil)
+}
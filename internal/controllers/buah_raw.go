@@ -58,6 +58,66 @@ func (c *BuahRawController) BulkCreate(ctx *gin.Context) {
 	})
 }
 
+func (c *BuahRawController) Sync(ctx *gin.Context) {
+	var req requests.BuahRawSyncRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	res, err := c.service.Sync(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Sinkronisasi data panen selesai", res)
+}
+
+// buahRawImportMaxSize caps the uploaded spreadsheet at 10 MB
+const buahRawImportMaxSize = 10 << 20
+
+func (c *BuahRawController) Import(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError("file wajib diunggah"))
+		return
+	}
+	if fileHeader.Size > buahRawImportMaxSize {
+		response.SendError(ctx, errors.ValidationError("ukuran file maksimal 10 MB"))
+		return
+	}
+
+	// Dry-run unless the caller explicitly commits
+	dryRun, err := strconv.ParseBool(ctx.DefaultPostForm("dry_run", ctx.DefaultQuery("dry_run", "true")))
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError("dry_run harus true atau false"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError("file tidak dapat dibaca"))
+		return
+	}
+	defer file.Close()
+
+	res, err := c.service.Import(ctx.Request.Context(), fileHeader.Filename, file, dryRun)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	switch {
+	case res.InvalidRows > 0:
+		response.SendSuccess(ctx, http.StatusOK, "Terdapat baris yang tidak valid, data tidak disimpan", res)
+	case dryRun:
+		response.SendSuccess(ctx, http.StatusOK, "Validasi import berhasil, data belum disimpan", res)
+	default:
+		response.SendSuccess(ctx, http.StatusCreated, "Berhasil mengimport data panen", res)
+	}
+}
+
 func (c *BuahRawController) GetList(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
@@ -165,6 +225,25 @@ func (c *BuahRawController) buildFilter(ctx *gin.Context) map[string]interface{}
 		b, _ := strconv.ParseBool(v)
 		filter["is_sorted"] = b
 	}
+	if v := ctx.Query("tingkat_kematangan"); v != "" {
+		filter["tingkat_kematangan"] = strings.ToUpper(v)
+	}
+	if v := ctx.Query("metode_panen"); v != "" {
+		filter["metode_panen"] = strings.ToUpper(v)
+	}
+	if v := ctx.Query("cacat"); v != "" {
+		filter["cacat"] = strings.Split(strings.ToUpper(v), ",")
+	}
+	if v := ctx.Query("tanpa_cacat"); v != "" {
+		b, _ := strconv.ParseBool(v)
+		filter["tanpa_cacat"] = b
+	}
+	if v, err := strconv.Atoi(ctx.Query("skor_aroma_min")); err == nil {
+		filter["skor_aroma_min"] = v
+	}
+	if v, err := strconv.Atoi(ctx.Query("skor_aroma_max")); err == nil {
+		filter["skor_aroma_max"] = v
+	}
 
 	return filter
 }
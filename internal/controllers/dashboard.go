@@ -21,8 +21,10 @@ func NewDashboardController(service services.DashboardService) *DashboardControl
 func (c *DashboardController) GetStokDashboard(ctx *gin.Context) {
 	dateFrom := ctx.Query("date_from")
 	dateTo := ctx.Query("date_to")
+	musimID := ctx.Query("musim_id")
+	asOf := ctx.Query("as_of")
 
-	res, err := c.service.GetStokDashboard(ctx.Request.Context(), dateFrom, dateTo)
+	res, err := c.service.GetStokDashboard(ctx.Request.Context(), dateFrom, dateTo, musimID, asOf)
 	if err != nil {
 		response.SendError(ctx, err)
 		return
@@ -34,8 +36,9 @@ func (c *DashboardController) GetStokDashboard(ctx *gin.Context) {
 func (c *DashboardController) GetSalesDashboard(ctx *gin.Context) {
 	dateFrom := ctx.Query("date_from")
 	dateTo := ctx.Query("date_to")
+	musimID := ctx.Query("musim_id")
 
-	res, err := c.service.GetSalesDashboard(ctx.Request.Context(), dateFrom, dateTo)
+	res, err := c.service.GetSalesDashboard(ctx.Request.Context(), dateFrom, dateTo, musimID)
 	if err != nil {
 		response.SendError(ctx, err)
 		return
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GradeController struct {
+	service services.GradeService
+}
+
+func NewGradeController(service services.GradeService) GradeController {
+	return GradeController{service: service}
+}
+
+func (c *GradeController) Create(ctx *gin.Context) {
+	var req requests.GradeRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Grade created successfully", result)
+}
+
+func (c *GradeController) GetList(ctx *gin.Context) {
+	result, err := c.service.GetList(ctx.Request.Context())
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Grade retrieved successfully", result)
+}
+
+func (c *GradeController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Grade retrieved successfully", result)
+}
+
+func (c *GradeController) Update(ctx *gin.Context) {
+	var req requests.GradeRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Grade updated successfully", result)
+}
+
+func (c *GradeController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Grade deleted successfully", nil)
+}
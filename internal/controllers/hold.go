@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldController struct {
+	service services.HoldService
+}
+
+func NewHoldController(service services.HoldService) HoldController {
+	return HoldController{service: service}
+}
+
+func (c *HoldController) Create(ctx *gin.Context) {
+	var req requests.HoldRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Create(ctx.Request.Context(), req, userAuth)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Hold created successfully", result)
+}
+
+func (c *HoldController) GetList(ctx *gin.Context) {
+	var q requests.HoldListQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.GetList(ctx.Request.Context(), q, userAuth)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Hold retrieved successfully", result)
+}
+
+func (c *HoldController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Hold retrieved successfully", result)
+}
+
+func (c *HoldController) Lepas(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Lepas(ctx.Request.Context(), ctx.Param("id"), userAuth)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Hold released successfully", result)
+}
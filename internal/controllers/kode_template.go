@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KodeTemplateController struct {
+	service services.KodeTemplateService
+}
+
+func NewKodeTemplateController(service services.KodeTemplateService) KodeTemplateController {
+	return KodeTemplateController{service: service}
+}
+
+func (c *KodeTemplateController) GetAll(ctx *gin.Context) {
+	companyID := ctx.Query("company_id")
+	result, err := c.service.GetAll(ctx.Request.Context(), companyID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Template kode retrieved successfully", result)
+}
+
+func (c *KodeTemplateController) Save(ctx *gin.Context) {
+	var req requests.KodeTemplateSaveRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.Save(ctx.Request.Context(), req, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Template kode saved successfully", result)
+}
+
+func (c *KodeTemplateController) Preview(ctx *gin.Context) {
+	var req requests.KodeTemplatePreviewRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Preview(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Template kode preview generated successfully", result)
+}
+
+func (c *KodeTemplateController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	if err := c.service.Delete(ctx.Request.Context(), id, userAuth.LocationID); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Template kode deleted successfully", nil)
+}
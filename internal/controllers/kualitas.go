@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KualitasController struct {
+	service services.KualitasService
+}
+
+func NewKualitasController(service services.KualitasService) KualitasController {
+	return KualitasController{service: service}
+}
+
+func (c *KualitasController) GetConfig(ctx *gin.Context) {
+	result, err := c.service.GetConfig(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Konfigurasi kualitas retrieved successfully", result)
+}
+
+func (c *KualitasController) SaveConfig(ctx *gin.Context) {
+	var req requests.JenisDurianKualitasRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.SaveConfig(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Konfigurasi kualitas saved successfully", result)
+}
+
+func (c *KualitasController) ResetConfig(ctx *gin.Context) {
+	if err := c.service.ResetConfig(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Konfigurasi kualitas reset to default", nil)
+}
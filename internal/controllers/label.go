@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/label"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelController struct {
+	service  services.LabelService
+	printers *label.PrinterProfiles
+}
+
+func NewLabelController(service services.LabelService, printers *label.PrinterProfiles) *LabelController {
+	return &LabelController{
+		service:  service,
+		printers: printers,
+	}
+}
+
+func (c *LabelController) GetPrinters(ctx *gin.Context) {
+	response.SendSuccess(ctx, http.StatusOK, "Success", c.printers.List())
+}
+
+func (c *LabelController) GetBuahLabel(ctx *gin.Context) {
+	lbl, err := c.service.GetBuahLabel(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	c.sendImage(ctx, lbl)
+}
+
+func (c *LabelController) GetLotLabel(ctx *gin.Context) {
+	lbl, err := c.service.GetLotLabel(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	c.sendImage(ctx, lbl)
+}
+
+func (c *LabelController) PrintSheet(ctx *gin.Context) {
+	var req requests.LabelSheetRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	symbology, err := label.ParseSymbology(req.Symbology)
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError(err.Error()))
+		return
+	}
+
+	format := label.FormatPDF
+	if req.Format == string(label.FormatZPL) {
+		format = label.FormatZPL
+	} else if req.Format != "" && req.Format != string(label.FormatPDF) {
+		response.SendError(ctx, errors.ValidationError("format sheet harus pdf atau zpl"))
+		return
+	}
+
+	labels, err := c.service.GetSheetLabels(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	var data []byte
+	if format == label.FormatZPL {
+		profile, profileErr := c.printers.Get(req.Printer)
+		if profileErr != nil {
+			response.SendError(ctx, errors.ValidationError(profileErr.Error()))
+			return
+		}
+		data, err = label.RenderZPL(labels, symbology, profile)
+	} else {
+		data, err = label.RenderSheetPDF(labels, symbology)
+	}
+	if err != nil {
+		response.SendError(ctx, errors.InternalError("gagal membuat label", err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="labels.%s"`, format))
+	ctx.Data(http.StatusOK, format.ContentType(), data)
+}
+
+func (c *LabelController) sendImage(ctx *gin.Context, lbl label.Label) {
+	symbology, err := label.ParseSymbology(ctx.Query("symbology"))
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError(err.Error()))
+		return
+	}
+
+	format, err := label.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		response.SendError(ctx, errors.ValidationError(err.Error()))
+		return
+	}
+
+	var data []byte
+	switch format {
+	case label.FormatSVG:
+		data, err = label.RenderSVG(lbl, symbology)
+	case label.FormatPDF:
+		data, err = label.RenderSheetPDF([]label.Label{lbl}, symbology)
+	case label.FormatZPL:
+		profile, profileErr := c.printers.Get(ctx.Query("printer"))
+		if profileErr != nil {
+			response.SendError(ctx, errors.ValidationError(profileErr.Error()))
+			return
+		}
+		data, err = label.RenderZPL([]label.Label{lbl}, symbology, profile)
+	default:
+		data, err = label.RenderPNG(lbl, symbology)
+	}
+	if err != nil {
+		response.SendError(ctx, errors.InternalError("gagal membuat label", err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, lbl.Code, format))
+	ctx.Data(http.StatusOK, format.ContentType(), data)
+}
@@ -31,9 +31,8 @@ func (c *LotController) Create(ctx *gin.Context) {
 	}
 
 	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
-	locationID := userAuth.LocationID
 
-	result, err := c.lotService.Create(ctx.Request.Context(), req, locationID)
+	result, err := c.lotService.Create(ctx.Request.Context(), req, userAuth.UserID, userAuth.LocationID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "error",
@@ -126,6 +125,36 @@ func (c *LotController) AddItems(ctx *gin.Context) {
 	})
 }
 
+func (c *LotController) ScanItems(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req requests.LotScanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.ScanItems(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Scan buah selesai diproses",
+		"data":    result,
+	})
+}
+
 func (c *LotController) RemoveItem(ctx *gin.Context) {
 	id := ctx.Param("id")
 
@@ -163,9 +192,8 @@ func (c *LotController) Finalize(ctx *gin.Context) {
 	req := requests.LotFinalizeRequest{}
 
 	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
-	locationID := userAuth.LocationID
 
-	result, err := c.lotService.Finalize(ctx.Request.Context(), id, req, locationID)
+	result, err := c.lotService.Finalize(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
@@ -180,3 +208,263 @@ func (c *LotController) Finalize(ctx *gin.Context) {
 		"data":    result,
 	})
 }
+
+func (c *LotController) Split(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req requests.LotSplitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.Split(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lot berhasil dipecah",
+		"data":    result,
+	})
+}
+
+func (c *LotController) Merge(ctx *gin.Context) {
+	var req requests.LotMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.Merge(ctx.Request.Context(), req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Lot berhasil digabung",
+		"data":    result,
+	})
+}
+
+func (c *LotController) GetGenealogi(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, err := c.lotService.GetGenealogi(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Lot tidak ditemukan",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) GetHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, err := c.lotService.GetHistory(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Lot tidak ditemukan",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) Regrade(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req requests.LotRegradeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.Regrade(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lot berhasil di-regrade",
+		"data":    result,
+	})
+}
+
+func (c *LotController) GetLedger(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, err := c.lotService.GetLedger(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Lot tidak ditemukan",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) GetRekonsiliasi(ctx *gin.Context) {
+	result, err := c.lotService.GetRekonsiliasi(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) GetRegrade(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, err := c.lotService.GetRegrade(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Lot tidak ditemukan",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) GetAging(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.GetAging(ctx.Request.Context(), ctx.Query("lokasi_id"), ctx.Query("jenis_durian_id"), userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) GetPeringatanUmur(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.GetPeringatanUmur(ctx.Request.Context(), ctx.Query("lokasi_id"), userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+func (c *LotController) TimbangUlang(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req requests.LotTimbangUlangRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.lotService.TimbangUlang(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Lot berhasil ditimbang ulang",
+		"data":    result,
+	})
+}
+
+func (c *LotController) GetTimbangUlang(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, err := c.lotService.GetTimbangUlang(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Lot tidak ditemukan",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MusimController struct {
+	service services.MusimService
+}
+
+func NewMusimController(service services.MusimService) MusimController {
+	return MusimController{service: service}
+}
+
+func (c *MusimController) Create(ctx *gin.Context) {
+	var req requests.MusimPanenCreateRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Musim panen created successfully", result)
+}
+
+func (c *MusimController) GetList(ctx *gin.Context) {
+	result, err := c.service.GetList(ctx.Request.Context(), ctx.Query("estate_id"), ctx.Query("status"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen retrieved successfully", result)
+}
+
+func (c *MusimController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen retrieved successfully", result)
+}
+
+func (c *MusimController) Update(ctx *gin.Context) {
+	var req requests.MusimPanenUpdateRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen updated successfully", result)
+}
+
+func (c *MusimController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen deleted successfully", nil)
+}
+
+func (c *MusimController) Close(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.Close(ctx.Request.Context(), ctx.Param("id"), userAuth.UserID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen closed successfully", result)
+}
+
+func (c *MusimController) Reopen(ctx *gin.Context) {
+	result, err := c.service.Reopen(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Musim panen reopened successfully", result)
+}
+
+func (c *MusimController) GetRingkasan(ctx *gin.Context) {
+	result, err := c.service.GetRingkasan(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Ringkasan musim retrieved successfully", result)
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PemanenController struct {
+	service services.PemanenService
+}
+
+func NewPemanenController(service services.PemanenService) PemanenController {
+	return PemanenController{service: service}
+}
+
+func (c *PemanenController) Create(ctx *gin.Context) {
+	var req requests.PemanenCreateRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Pemanen created successfully", result)
+}
+
+func (c *PemanenController) GetList(ctx *gin.Context) {
+	var aktif *bool
+	if v := ctx.Query("aktif"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			response.SendError(ctx, errors.ValidationError("aktif harus true atau false"))
+			return
+		}
+		aktif = &parsed
+	}
+
+	result, err := c.service.GetList(ctx.Request.Context(), ctx.Query("estate_id"), ctx.Query("divisi_id"), aktif)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Pemanen retrieved successfully", result)
+}
+
+func (c *PemanenController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Pemanen retrieved successfully", result)
+}
+
+func (c *PemanenController) Update(ctx *gin.Context) {
+	var req requests.PemanenUpdateRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Pemanen updated successfully", result)
+}
+
+func (c *PemanenController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Pemanen deleted successfully", nil)
+}
+
+func (c *PemanenController) GetUpahReport(ctx *gin.Context) {
+	startDate := ctx.Query("tanggal_mulai")
+	endDate := ctx.Query("tanggal_selesai")
+	if startDate == "" || endDate == "" {
+		response.SendError(ctx, errors.ValidationError("tanggal_mulai dan tanggal_selesai wajib diisi"))
+		return
+	}
+
+	result, err := c.service.GetUpahReport(ctx.Request.Context(), startDate, endDate, ctx.Query("estate_id"), ctx.Query("pemanen_id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Laporan upah panen retrieved successfully", result)
+}
+
+func (c *PemanenController) CreateTarif(ctx *gin.Context) {
+	var req requests.TarifPanenRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.CreateTarif(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Tarif panen created successfully", result)
+}
+
+func (c *PemanenController) GetTarifList(ctx *gin.Context) {
+	result, err := c.service.GetTarifList(ctx.Request.Context(), ctx.Query("jenis_durian_id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Tarif panen retrieved successfully", result)
+}
+
+func (c *PemanenController) UpdateTarif(ctx *gin.Context) {
+	var req requests.TarifPanenRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.UpdateTarif(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Tarif panen updated successfully", result)
+}
+
+func (c *PemanenController) DeleteTarif(ctx *gin.Context) {
+	if err := c.service.DeleteTarif(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Tarif panen deleted successfully", nil)
+}
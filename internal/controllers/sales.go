@@ -27,7 +27,9 @@ func (c *SalesController) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := c.service.Create(ctx.Request.Context(), req)
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	res, err := c.service.Create(ctx.Request.Context(), req, userAuth.UserID)
 	if err != nil {
 		response.SendError(ctx, err)
 		return
@@ -92,7 +94,7 @@ func (c *SalesController) Delete(ctx *gin.Context) {
 		userRole = string(userAuth.Role[0])
 	}
 
-	if err := c.service.Delete(ctx.Request.Context(), id, locationID, userRole); err != nil {
+	if err := c.service.Delete(ctx.Request.Context(), id, userAuth.UserID, locationID, userRole); err != nil {
 		response.SendError(ctx, err)
 		return
 	}
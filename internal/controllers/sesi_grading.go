@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SesiGradingController struct {
+	service services.SesiGradingService
+}
+
+func NewSesiGradingController(service services.SesiGradingService) SesiGradingController {
+	return SesiGradingController{service: service}
+}
+
+func (c *SesiGradingController) Open(ctx *gin.Context) {
+	var req requests.SesiGradingOpenRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Open(ctx.Request.Context(), req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Sesi grading opened successfully", result)
+}
+
+func (c *SesiGradingController) GetList(ctx *gin.Context) {
+	result, err := c.service.GetList(ctx.Request.Context(), ctx.Query("status"), ctx.Query("jenis_durian_id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Sesi grading retrieved successfully", result)
+}
+
+func (c *SesiGradingController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Sesi grading retrieved successfully", result)
+}
+
+func (c *SesiGradingController) Scan(ctx *gin.Context) {
+	var req requests.SesiGradingScanRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Scan(ctx.Request.Context(), ctx.Param("id"), req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Scan processed successfully", result)
+}
+
+func (c *SesiGradingController) Close(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.Close(ctx.Request.Context(), ctx.Param("id"), userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Sesi grading closed successfully", result)
+}
+
+func (c *SesiGradingController) GetLaporan(ctx *gin.Context) {
+	result, err := c.service.GetLaporan(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Laporan sesi grading retrieved successfully", result)
+}
@@ -92,9 +92,8 @@ func (c *ShipmentController) AddItem(ctx *gin.Context) {
 	}
 
 	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
-	locationID := userAuth.LocationID
 
-	if err := c.service.AddItem(ctx.Request.Context(), id, req, locationID); err != nil {
+	if err := c.service.AddItem(ctx.Request.Context(), id, req, userAuth.UserID, userAuth.LocationID); err != nil {
 		response.SendError(ctx, err)
 		return
 	}
@@ -110,7 +109,9 @@ func (c *ShipmentController) RemoveItem(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.RemoveItem(ctx.Request.Context(), id, req.DetailID); err != nil {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	if err := c.service.RemoveItem(ctx.Request.Context(), id, req.DetailID, userAuth.UserID); err != nil {
 		response.SendError(ctx, err)
 		return
 	}
@@ -148,7 +149,9 @@ func (c *ShipmentController) Receive(ctx *gin.Context) {
 		return
 	}
 
-	err := c.service.Receive(ctx.Request.Context(), id, req)
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	err := c.service.Receive(ctx.Request.Context(), id, req, userAuth.UserID)
 	if err != nil {
 		response.SendError(ctx, err)
 		return
@@ -159,10 +162,37 @@ func (c *ShipmentController) Receive(ctx *gin.Context) {
 
 func (c *ShipmentController) Finalize(ctx *gin.Context) {
 	id := ctx.Param("id")
-	if err := c.service.Finalize(ctx.Request.Context(), id); err != nil {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	if err := c.service.Finalize(ctx.Request.Context(), id, userAuth.UserID); err != nil {
 		response.SendError(ctx, err)
 		return
 	}
 
 	response.SendSuccess(ctx, http.StatusOK, "Shipment finalized successfully", nil)
-}
\ No newline at end of file
+}
+func (c *ShipmentController) Saran(ctx *gin.Context) {
+	var req requests.ShipmentSaranRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	if userAuth.UserID == "" {
+		response.SendError(ctx, errors.AuthError("Invalid token: missing user_id"))
+		return
+	}
+
+	res, err := c.service.Saran(ctx.Request.Context(), req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+
+	if res.Shipment != nil {
+		response.SendSuccess(ctx, http.StatusCreated, "Shipment created from suggested lots", res)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Lot suggestions retrieved successfully", res)
+}
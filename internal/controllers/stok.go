@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StokController struct {
+	service services.StokService
+}
+
+func NewStokController(service services.StokService) StokController {
+	return StokController{service: service}
+}
+
+func (c *StokController) GetAsOf(ctx *gin.Context) {
+	var q requests.StokAsOfQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.GetAsOf(ctx.Request.Context(), q, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Stok retrieved successfully", result)
+}
+
+func (c *StokController) TakeSnapshot(ctx *gin.Context) {
+	var req requests.StokSnapshotRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.TakeSnapshot(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Snapshot stok created successfully", result)
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimbanganController struct {
+	service services.TimbanganService
+}
+
+func NewTimbanganController(service services.TimbanganService) TimbanganController {
+	return TimbanganController{service: service}
+}
+
+func (c *TimbanganController) Create(ctx *gin.Context) {
+	var req requests.StasiunTimbangCreateRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Create(ctx.Request.Context(), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Stasiun timbang created successfully", result)
+}
+
+func (c *TimbanganController) GetList(ctx *gin.Context) {
+	result, err := c.service.GetList(ctx.Request.Context())
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Stasiun timbang retrieved successfully", result)
+}
+
+func (c *TimbanganController) GetByID(ctx *gin.Context) {
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Stasiun timbang retrieved successfully", result)
+}
+
+func (c *TimbanganController) Update(ctx *gin.Context) {
+	var req requests.StasiunTimbangRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	result, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), req)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Stasiun timbang updated successfully", result)
+}
+
+func (c *TimbanganController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Stasiun timbang deleted successfully", nil)
+}
+
+func (c *TimbanganController) OpenSesi(ctx *gin.Context) {
+	var req requests.StasiunTimbangSesiRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.OpenSesi(ctx.Request.Context(), ctx.Param("id"), req, userAuth.UserID, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Sesi timbang opened successfully", result)
+}
+
+func (c *TimbanganController) CloseSesi(ctx *gin.Context) {
+	result, err := c.service.CloseSesi(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Sesi timbang closed successfully", result)
+}
+
+func (c *TimbanganController) GetPembacaan(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+
+	result, err := c.service.GetPembacaan(ctx.Request.Context(), ctx.Param("id"), ctx.Query("lot_id"), limit)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Pembacaan timbang retrieved successfully", result)
+}
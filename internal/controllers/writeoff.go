@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/services"
+	"durich-be/pkg/authentication"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/http/response"
+	"durich-be/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WriteOffController struct {
+	service services.WriteOffService
+}
+
+func NewWriteOffController(service services.WriteOffService) WriteOffController {
+	return WriteOffController{service: service}
+}
+
+func (c *WriteOffController) Create(ctx *gin.Context) {
+	var req requests.WriteOffRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Create(ctx.Request.Context(), req, userAuth)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusCreated, "Write-off created successfully", result)
+}
+
+func (c *WriteOffController) GetList(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.GetList(ctx.Request.Context(), ctx.Query("status"), ctx.Query("lot_id"), userAuth)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Write-off retrieved successfully", result)
+}
+
+func (c *WriteOffController) GetByID(ctx *gin.Context) {
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+
+	result, err := c.service.GetByID(ctx.Request.Context(), ctx.Param("id"), userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Write-off retrieved successfully", result)
+}
+
+func (c *WriteOffController) Approve(ctx *gin.Context) {
+	var req requests.WriteOffKeputusanRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Approve(ctx.Request.Context(), ctx.Param("id"), req, userAuth.UserID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Write-off approved successfully", result)
+}
+
+func (c *WriteOffController) Reject(ctx *gin.Context) {
+	var req requests.WriteOffKeputusanRequest
+	if err := utils.BindData(ctx, &req); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.Reject(ctx.Request.Context(), ctx.Param("id"), req, userAuth.UserID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Write-off rejected successfully", result)
+}
+
+func (c *WriteOffController) GetLaporan(ctx *gin.Context) {
+	var q requests.WriteOffLaporanQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		response.SendError(ctx, errors.ValidationErrorToAppError(err))
+		return
+	}
+
+	userAuth := ctx.MustGet(authentication.Token).(requests.UserAuth)
+	result, err := c.service.GetLaporan(ctx.Request.Context(), q, userAuth.LocationID)
+	if err != nil {
+		response.SendError(ctx, err)
+		return
+	}
+	response.SendSuccess(ctx, http.StatusOK, "Laporan write-off retrieved successfully", result)
+}
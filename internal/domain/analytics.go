@@ -0,0 +1,16 @@
+package domain
+
+// YieldRow is the harvest of one unit (pohon, blok, divisi or estate) for one grade.
+// Grade is empty for fruits not yet sorted into a lot.
+type YieldRow struct {
+	UnitID       string  `bun:"unit_id"`
+	UnitKode     string  `bun:"unit_kode"`
+	UnitNama     string  `bun:"unit_nama"`
+	EstateKode   string  `bun:"estate_kode"`
+	DivisiKode   string  `bun:"divisi_kode"`
+	BlokKode     string  `bun:"blok_kode"`
+	Grade        string  `bun:"grade"`
+	Qty          int     `bun:"qty"`
+	QtyDitimbang int     `bun:"qty_ditimbang"`
+	Berat        float64 `bun:"berat"`
+}
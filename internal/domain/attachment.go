@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+type Attachment struct {
+	bun.BaseModel `bun:"table:tb_attachment,alias:att"`
+
+	ID           string     `bun:",pk" json:"id"`
+	EntityType   string     `bun:",notnull" json:"entity_type"`
+	EntityID     string     `bun:",notnull" json:"entity_id"`
+	FileName     string     `bun:",notnull" json:"file_name"`
+	ContentType  string     `bun:",notnull" json:"content_type"`
+	Size         int64      `bun:",notnull" json:"size"`
+	StorageKey   string     `bun:",notnull" json:"-"`
+	ThumbnailKey *string    `bun:",nullzero" json:"-"`
+	Keterangan   string     `bun:",nullzero" json:"keterangan,omitempty"`
+	UploadedBy   string     `bun:",notnull" json:"uploaded_by"`
+	CreatedAt    time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	DeletedAt    *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Uploader *User `bun:"rel:belongs-to,join:uploaded_by=id" json:"uploader,omitempty"`
+}
+
+func (m *Attachment) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
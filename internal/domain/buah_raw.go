@@ -21,6 +21,25 @@ type BuahRaw struct {
 	BlokID    *string  `bun:",nullzero" json:"blok_id,omitempty"`
 	Berat     float64  `bun:",default:0" json:"berat"`
 
+	PemanenID *string `bun:",nullzero" json:"pemanen_id,omitempty"`
+	MusimID   *string `bun:",nullzero" json:"musim_id,omitempty"`
+
+	// Quality attributes recorded at harvest or grading, allowed values depend on the jenis
+	TingkatKematangan *string  `bun:",nullzero" json:"tingkat_kematangan,omitempty"`
+	Cacat             []string `bun:",array" json:"cacat,omitempty"`
+	SkorAroma         *int     `bun:",nullzero" json:"skor_aroma,omitempty"`
+	MetodePanen       *string  `bun:",nullzero" json:"metode_panen,omitempty"`
+
+	// KondisiGrading is the grade the fruit got when its lot was finalized, regrades keep it
+	KondisiGrading *string `bun:",nullzero" json:"kondisi_grading,omitempty"`
+
+	// WriteOffID is the write-off that took the fruit out of its lot's stock
+	WriteOffID *string `bun:"writeoff_id,nullzero" json:"writeoff_id,omitempty"`
+
+	// DeviceID and RecordedAt are set when the record comes from an offline sync batch
+	DeviceID   *string    `bun:",nullzero" json:"device_id,omitempty"`
+	RecordedAt *time.Time `bun:",nullzero" json:"recorded_at,omitempty"`
+
 	CreatedAt         time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt         time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 	DeletedAt         *time.Time   `bun:"," json:"deleted_at,omitempty"`
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// Grade is a lot grade. Lots store its Kode in KondisiBuah, a lower Urutan is a better grade.
+type Grade struct {
+	bun.BaseModel `bun:"table:tb_grade,alias:grade"`
+
+	ID        string     `bun:",pk" json:"id"`
+	Kode      string     `bun:",notnull" json:"kode"`
+	Nama      string     `bun:",notnull" json:"nama"`
+	Urutan    int        `bun:",notnull" json:"urutan"`
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Aturan []GradeAturan `bun:"rel:has-many,join:id=grade_id" json:"aturan,omitempty"`
+}
+
+func (m *Grade) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// GradeAturan is what a fruit of one jenis needs to qualify for a grade. Nil and empty fields do not constrain.
+type GradeAturan struct {
+	bun.BaseModel `bun:"table:tb_grade_aturan,alias:aturan"`
+
+	ID             string   `bun:",pk" json:"id"`
+	GradeID        string   `bun:",notnull" json:"grade_id"`
+	JenisDurianID  string   `bun:",notnull" json:"jenis_durian_id"`
+	BeratMin       *float64 `bun:",nullzero" json:"berat_min,omitempty"`
+	BeratMax       *float64 `bun:",nullzero" json:"berat_max,omitempty"`
+	KematanganOpsi []string `bun:",array" json:"kematangan_opsi"`
+	CacatDilarang  []string `bun:",array" json:"cacat_dilarang"`
+	CacatMaks      *int     `bun:",nullzero" json:"cacat_maks,omitempty"`
+	SkorAromaMin   *int     `bun:",nullzero" json:"skor_aroma_min,omitempty"`
+
+	Grade *Grade `bun:"rel:belongs-to,join:grade_id=id" json:"grade,omitempty"`
+}
+
+func (m *GradeAturan) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// LotHold keeps a READY lot for a customer until BerakhirAt, so only its holder can ship it
+type LotHold struct {
+	bun.BaseModel `bun:"table:tb_lot_hold,alias:hold"`
+
+	ID                 string     `bun:",pk" json:"id"`
+	LotID              string     `bun:",notnull" json:"lot_id"`
+	LokasiID           *string    `bun:",nullzero" json:"lokasi_id,omitempty"`
+	Pelanggan          string     `bun:",notnull" json:"pelanggan"`
+	Catatan            *string    `bun:",nullzero" json:"catatan,omitempty"`
+	BerakhirAt         time.Time  `bun:",notnull" json:"berakhir_at"`
+	Status             string     `bun:",notnull" json:"status"`
+	DipegangOleh       string     `bun:",notnull" json:"dipegang_oleh"`
+	DilepasOleh        *string    `bun:",nullzero" json:"dilepas_oleh,omitempty"`
+	DilepasAt          *time.Time `bun:",nullzero" json:"dilepas_at,omitempty"`
+	PengirimanID       *string    `bun:",nullzero" json:"pengiriman_id,omitempty"`
+	PengirimanDetailID *string    `bun:",nullzero" json:"pengiriman_detail_id,omitempty"`
+	CreatedAt          time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt          time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	Lot        *StokLot          `bun:"rel:belongs-to,join:lot_id=id" json:"lot,omitempty"`
+	Lokasi     *TujuanPengiriman `bun:"rel:belongs-to,join:lokasi_id=id" json:"lokasi,omitempty"`
+	Pemegang   *User             `bun:"rel:belongs-to,join:dipegang_oleh=id" json:"pemegang,omitempty"`
+	Pengiriman *Pengiriman       `bun:"rel:belongs-to,join:pengiriman_id=id" json:"pengiriman,omitempty"`
+}
+
+func (m *LotHold) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
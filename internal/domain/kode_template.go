@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// KodeTemplate overrides the code format of one document type. CompanyID nil is the group wide default.
+type KodeTemplate struct {
+	bun.BaseModel `bun:"table:tb_kode_template,alias:kt"`
+
+	ID        string     `bun:",pk" json:"id"`
+	CompanyID *string    `bun:",nullzero" json:"company_id"`
+	Tipe      string     `bun:",notnull" json:"tipe"`
+	Template  string     `bun:",notnull" json:"template"`
+	Reset     string     `bun:",notnull" json:"reset"`
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Company *Company `bun:"rel:belongs-to,join:company_id=id" json:"company,omitempty"`
+}
+
+func (m *KodeTemplate) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
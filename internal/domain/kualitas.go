@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// JenisDurianKualitas limits the quality attributes recorded for fruits of one jenis.
+// Jenis without a row use the default options.
+type JenisDurianKualitas struct {
+	bun.BaseModel `bun:"table:tb_jenis_durian_kualitas,alias:jdk"`
+
+	JenisDurianID   string    `bun:",pk" json:"jenis_durian_id"`
+	KematanganOpsi  []string  `bun:",array" json:"kematangan_opsi"`
+	CacatOpsi       []string  `bun:",array" json:"cacat_opsi"`
+	MetodePanenOpsi []string  `bun:",array" json:"metode_panen_opsi"`
+	SkorAromaMin    int       `bun:",notnull" json:"skor_aroma_min"`
+	SkorAromaMax    int       `bun:",notnull" json:"skor_aroma_max"`
+	AtributWajib    []string  `bun:",array" json:"atribut_wajib"`
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+func (m *JenisDurianKualitas) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.UpdateQuery, *bun.InsertQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
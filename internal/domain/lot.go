@@ -22,6 +22,8 @@ type StokLot struct {
 	Status        string     `bun:",default:'DRAFT'" json:"status"`
 	PosisiID      *string    `bun:"current_location_id,nullzero" json:"posisi_id"`
 	ArrivedAt     *time.Time `bun:",nullzero" json:"arrived_at,omitempty"`
+	FinalizedAt   *time.Time `bun:",nullzero" json:"finalized_at,omitempty"`
+	MusimID       *string    `bun:",nullzero" json:"musim_id,omitempty"`
 	CreatedAt     time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt     time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 	DeletedAt     *time.Time `bun:"" json:"deleted_at,omitempty"`
@@ -29,6 +31,11 @@ type StokLot struct {
 	CurrentQty   int     `bun:",scanonly" json:"current_qty"`
 	CurrentBerat float64 `bun:",scanonly" json:"current_berat"`
 
+	// The running hold of the lot, only filled by the lot list
+	HoldOleh       *string    `bun:",scanonly" json:"hold_oleh,omitempty"`
+	HoldPelanggan  *string    `bun:",scanonly" json:"hold_pelanggan,omitempty"`
+	HoldBerakhirAt *time.Time `bun:",scanonly" json:"hold_berakhir_at,omitempty"`
+
 	Items []LotDetail `bun:"rel:has-many,join:id=lot_id" json:"items,omitempty"`
 
 	JenisDurianDetail *JenisDurian      `bun:"rel:belongs-to,join:jenis_durian_id=id" json:"jenis_durian_detail,omitempty"`
@@ -46,6 +53,160 @@ type LotDetail struct {
 	BuahRaw *BuahRaw `bun:"rel:belongs-to,join:buah_raw_id=id" json:"buah_raw,omitempty"`
 }
 
+// LotGenealogi links a lot to the lot it was split from or merged into
+type LotGenealogi struct {
+	bun.BaseModel `bun:"table:tb_lot_genealogi,alias:genealogi"`
+
+	ID          string    `bun:",pk" json:"id"`
+	Tipe        string    `bun:",notnull" json:"tipe"`
+	ParentLotID string    `bun:",notnull" json:"parent_lot_id"`
+	ChildLotID  string    `bun:",notnull" json:"child_lot_id"`
+	Qty         int       `bun:",notnull" json:"qty"`
+	Berat       float64   `bun:",notnull" json:"berat"`
+	CreatedBy   *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt   time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	ParentLot *StokLot           `bun:"rel:belongs-to,join:parent_lot_id=id" json:"parent_lot,omitempty"`
+	ChildLot  *StokLot           `bun:"rel:belongs-to,join:child_lot_id=id" json:"child_lot,omitempty"`
+	Buah      []LotGenealogiBuah `bun:"rel:has-many,join:id=genealogi_id" json:"buah,omitempty"`
+}
+
+// LotGenealogiBuah is one fruit moved by a split or merge
+type LotGenealogiBuah struct {
+	bun.BaseModel `bun:"table:tb_lot_genealogi_buah,alias:genealogi_buah"`
+
+	GenealogiID string `bun:",pk" json:"genealogi_id"`
+	BuahRawID   string `bun:",pk" json:"buah_raw_id"`
+}
+
+// LotStatusRiwayat is one lot status change. StatusDari is nil when the lot was created.
+type LotStatusRiwayat struct {
+	bun.BaseModel `bun:"table:tb_lot_status_riwayat,alias:status_riwayat"`
+
+	ID         string    `bun:",pk" json:"id"`
+	LotID      string    `bun:",notnull" json:"lot_id"`
+	StatusDari *string   `bun:",nullzero" json:"status_dari"`
+	StatusKe   string    `bun:",notnull" json:"status_ke"`
+	Alasan     *string   `bun:",nullzero" json:"alasan,omitempty"`
+	CreatedBy  *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	Creator *User `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
+}
+
+func (m *LotStatusRiwayat) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
+
+// LotRegrade records why and by whom fruits moved to another grade
+type LotRegrade struct {
+	bun.BaseModel `bun:"table:tb_lot_regrade,alias:regrade"`
+
+	ID          string    `bun:",pk" json:"id"`
+	GenealogiID string    `bun:",notnull" json:"genealogi_id"`
+	KondisiDari string    `bun:",notnull" json:"kondisi_dari"`
+	KondisiKe   string    `bun:",notnull" json:"kondisi_ke"`
+	Alasan      string    `bun:",notnull" json:"alasan"`
+	CreatedBy   *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt   time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	Genealogi *LotGenealogi `bun:"rel:belongs-to,join:genealogi_id=id" json:"genealogi,omitempty"`
+	Creator   *User         `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
+}
+
+func (m *LotRegrade) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
+
+// LotScan records a fruit that joined a lot by a kode_buah scan
+type LotScan struct {
+	bun.BaseModel `bun:"table:tb_lot_scan,alias:scan"`
+
+	ID        string    `bun:",pk" json:"id"`
+	LotID     string    `bun:",notnull" json:"lot_id"`
+	BuahRawID string    `bun:",notnull" json:"buah_raw_id"`
+	CreatedBy *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+func (m *LotScan) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
+
+// LotMutasi is one stock ledger row. QtySaldo and BeratSaldo are the running balance,
+// filled when the ledger is read.
+type LotMutasi struct {
+	bun.BaseModel `bun:"table:tb_lot_mutasi,alias:mutasi"`
+
+	ID            string    `bun:",pk" json:"id"`
+	Urutan        int64     `bun:",nullzero" json:"urutan"`
+	LotID         string    `bun:",notnull" json:"lot_id"`
+	LokasiID      *string   `bun:",nullzero" json:"lokasi_id,omitempty"`
+	Tipe          string    `bun:",notnull" json:"tipe"`
+	DeltaQty      int       `bun:",notnull" json:"delta_qty"`
+	DeltaBerat    float64   `bun:",notnull" json:"delta_berat"`
+	ReferensiTipe *string   `bun:",nullzero" json:"referensi_tipe,omitempty"`
+	ReferensiID   *string   `bun:",nullzero" json:"referensi_id,omitempty"`
+	ReferensiKode *string   `bun:",nullzero" json:"referensi_kode,omitempty"`
+	CreatedBy     *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt     time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	QtySaldo   int     `bun:",scanonly" json:"qty_saldo"`
+	BeratSaldo float64 `bun:",scanonly" json:"berat_saldo"`
+
+	Lokasi  *TujuanPengiriman `bun:"rel:belongs-to,join:lokasi_id=id" json:"lokasi,omitempty"`
+	Creator *User             `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
+}
+
+func (m *LotMutasi) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
+
+// LotRekonsiliasi is a lot whose ledger does not add up to its balance
+type LotRekonsiliasi struct {
+	LotID       string  `bun:"lot_id"`
+	Kode        string  `bun:"kode"`
+	Status      string  `bun:"status"`
+	QtySisa     int     `bun:"qty_sisa"`
+	BeratSisa   float64 `bun:"berat_sisa"`
+	LedgerQty   int     `bun:"ledger_qty"`
+	LedgerBerat float64 `bun:"ledger_berat"`
+}
+
+func (m *LotGenealogi) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
+
 func (m *StokLot) BeforeAppendModel(_ context.Context, query bun.Query) error {
 	switch query.(type) {
 	case *bun.InsertQuery:
@@ -57,3 +218,33 @@ func (m *StokLot) BeforeAppendModel(_ context.Context, query bun.Query) error {
 	}
 	return nil
 }
+
+// LotTimbangUlang is a stored lot weighed again. BeratAcuan is the share of BeratAwal of the
+// Qty fruits still in the lot, Susut the weight lost against it.
+type LotTimbangUlang struct {
+	bun.BaseModel `bun:"table:tb_lot_timbang_ulang,alias:timbang_ulang"`
+
+	ID           string    `bun:",pk" json:"id"`
+	LotID        string    `bun:",notnull" json:"lot_id"`
+	LokasiID     *string   `bun:",nullzero" json:"lokasi_id,omitempty"`
+	Qty          int       `bun:",notnull" json:"qty"`
+	BeratAcuan   float64   `bun:",notnull" json:"berat_acuan"`
+	BeratSebelum float64   `bun:",notnull" json:"berat_sebelum"`
+	Berat        float64   `bun:",notnull" json:"berat"`
+	Susut        float64   `bun:",notnull" json:"susut"`
+	Catatan      *string   `bun:",nullzero" json:"catatan,omitempty"`
+	CreatedBy    *string   `bun:",nullzero" json:"created_by,omitempty"`
+	CreatedAt    time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	Creator *User `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
+}
+
+func (m *LotTimbangUlang) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
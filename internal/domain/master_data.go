@@ -109,12 +109,14 @@ func (m *Blok) BeforeAppendModel(_ context.Context, query bun.Query) error {
 type JenisDurian struct {
 	bun.BaseModel `bun:"table:jenis_durian,alias:jenis_durian"`
 
-	ID        string     `bun:",pk" json:"id"`
-	Kode      string     `bun:"," json:"kode"`
-	NamaJenis string     `bun:",notnull" json:"nama_jenis"`
-	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
-	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
-	DeletedAt *time.Time `bun:"," json:"deleted_at,omitempty"`
+	ID                 string     `bun:",pk" json:"id"`
+	Kode               string     `bun:"," json:"kode"`
+	NamaJenis          string     `bun:",notnull" json:"nama_jenis"`
+	UmurSimpanJam      *int       `bun:"" json:"umur_simpan_jam,omitempty"`
+	BatasPeringatanJam *int       `bun:"" json:"batas_peringatan_jam,omitempty"`
+	CreatedAt          time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt          time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt          *time.Time `bun:"," json:"deleted_at,omitempty"`
 }
 
 func (m *JenisDurian) BeforeAppendModel(_ context.Context, query bun.Query) error {
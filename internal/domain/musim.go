@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// MusimPanen is a harvest season of one estate. An estate has at most one AKTIF season and
+// new harvests, lots and sales are tagged with it. TanggalSelesai is empty while the end is unknown.
+type MusimPanen struct {
+	bun.BaseModel `bun:"table:tb_musim_panen,alias:musim"`
+
+	ID             string          `bun:",pk" json:"id"`
+	Kode           string          `bun:",unique,notnull" json:"kode"`
+	Nama           string          `bun:",notnull" json:"nama"`
+	EstateID       string          `bun:",notnull" json:"estate_id"`
+	TanggalMulai   time.Time       `bun:"type:date,notnull" json:"tanggal_mulai"`
+	TanggalSelesai *time.Time      `bun:"type:date,nullzero" json:"tanggal_selesai,omitempty"`
+	Status         string          `bun:",notnull,default:'AKTIF'" json:"status"`
+	Ringkasan      *MusimRingkasan `bun:"type:jsonb,nullzero" json:"ringkasan,omitempty"`
+	DitutupAt      *time.Time      `bun:",nullzero" json:"ditutup_at,omitempty"`
+	DitutupOleh    *string         `bun:",nullzero" json:"ditutup_oleh,omitempty"`
+	CreatedAt      time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt      time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt      *time.Time      `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Estate *Estate `bun:"rel:belongs-to,join:estate_id=id" json:"estate,omitempty"`
+}
+
+func (m *MusimPanen) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// Covers reports whether date, a UTC calendar day like the date columns, falls inside the season
+func (m *MusimPanen) Covers(date time.Time) bool {
+	if date.Before(m.TanggalMulai) {
+		return false
+	}
+	return m.TanggalSelesai == nil || !date.After(*m.TanggalSelesai)
+}
+
+// MusimRingkasan is the season summary, frozen into the season row when it is closed
+type MusimRingkasan struct {
+	PanenQty       int                   `json:"panen_qty"`
+	PanenBerat     float64               `json:"panen_berat"`
+	GradingQty     int                   `json:"grading_qty"`
+	GradingBerat   float64               `json:"grading_berat"`
+	GradingYield   float64               `json:"grading_yield_persen"`
+	LotCount       int                   `json:"lot_count"`
+	Grade          []MusimRingkasanGrade `json:"grade"`
+	DikirimCount   int                   `json:"dikirim_count"`
+	DikirimQty     int                   `json:"dikirim_qty"`
+	DikirimBerat   float64               `json:"dikirim_berat"`
+	TerjualCount   int                   `json:"terjual_count"`
+	TerjualBerat   float64               `json:"terjual_berat"`
+	Pendapatan     float64               `json:"pendapatan"`
+	RataHargaPerKg float64               `json:"rata_harga_per_kg"`
+	DihitungAt     time.Time             `json:"dihitung_at"`
+}
+
+type MusimRingkasanGrade struct {
+	Grade    string  `bun:"grade" json:"grade"`
+	LotCount int     `bun:"lot_count" json:"lot_count"`
+	Qty      int     `bun:"qty" json:"qty"`
+	Berat    float64 `bun:"berat" json:"berat"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// Pemanen is a harvester (picker) working in one estate, optionally assigned to a divisi
+type Pemanen struct {
+	bun.BaseModel `bun:"table:tb_pemanen,alias:pemanen"`
+
+	ID        string     `bun:",pk" json:"id"`
+	Kode      string     `bun:",unique,notnull" json:"kode"`
+	Nama      string     `bun:",notnull" json:"nama"`
+	EstateID  string     `bun:",notnull" json:"estate_id"`
+	DivisiID  *string    `bun:",nullzero" json:"divisi_id,omitempty"`
+	Aktif     bool       `bun:",notnull,default:true" json:"aktif"`
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Estate *Estate `bun:"rel:belongs-to,join:estate_id=id" json:"estate,omitempty"`
+	Divisi *Divisi `bun:"rel:belongs-to,join:divisi_id=id" json:"divisi,omitempty"`
+}
+
+func (m *Pemanen) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// TarifPanen is the piece rate paid for fruits of a jenis. An empty grade applies to every grade
+// without its own rate, and the newest rate whose BerlakuMulai is on or before the harvest date wins.
+type TarifPanen struct {
+	bun.BaseModel `bun:"table:tb_tarif_panen,alias:tarif"`
+
+	ID            string     `bun:",pk" json:"id"`
+	JenisDurianID string     `bun:",notnull" json:"jenis_durian_id"`
+	Grade         string     `bun:",notnull,default:''" json:"grade"`
+	TarifPerBuah  float64    `bun:",notnull,default:0" json:"tarif_per_buah"`
+	TarifPerKg    float64    `bun:",notnull,default:0" json:"tarif_per_kg"`
+	BerlakuMulai  time.Time  `bun:"type:date,notnull" json:"berlaku_mulai"`
+	CreatedAt     time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt     time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt     *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	JenisDurian *JenisDurian `bun:"rel:belongs-to,join:jenis_durian_id=id" json:"jenis_durian,omitempty"`
+}
+
+func (m *TarifPanen) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// PanenPemanenSummary is harvested volume of one harvester grouped by what decides the rate
+type PanenPemanenSummary struct {
+	PemanenID     string  `bun:"pemanen_id"`
+	JenisDurianID string  `bun:"jenis_durian"`
+	Grade         string  `bun:"grade"`
+	TglPanen      string  `bun:"tgl_panen"`
+	Qty           int     `bun:"qty"`
+	Berat         float64 `bun:"berat"`
+}
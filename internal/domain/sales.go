@@ -16,6 +16,7 @@ type Penjualan struct {
 	BeratTerjual float64    `bun:",notnull" json:"berat_terjual"`
 	HargaTotal   float64    `bun:",notnull" json:"harga_total"`
 	TipeJual     string     `bun:",notnull" json:"tipe_jual"`
+	MusimID      *string    `bun:",nullzero" json:"musim_id,omitempty"`
 	CreatedAt    time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt    time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 	DeletedAt    *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// DocumentSequence is the last number handed out for one code prefix within a period.
+// Period is empty for counters that never reset.
+type DocumentSequence struct {
+	bun.BaseModel `bun:"table:tb_document_sequence,alias:doc_seq"`
+
+	Prefix    string    `bun:",pk" json:"prefix"`
+	Period    string    `bun:",pk" json:"period"`
+	LastValue int       `bun:",notnull" json:"last_value"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+func (m *DocumentSequence) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery, *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// SesiGrading is a grading session. A team sorts unsorted fruit of one jenis into one DRAFT
+// lot per grade, and closing the session finalizes all of them together.
+type SesiGrading struct {
+	bun.BaseModel `bun:"table:tb_sesi_grading,alias:sesi"`
+
+	ID             string     `bun:",pk" json:"id"`
+	JenisDurianID  string     `bun:",notnull" json:"jenis_durian_id"`
+	EstateID       *string    `bun:",nullzero" json:"estate_id,omitempty"`
+	MusimID        *string    `bun:",nullzero" json:"musim_id,omitempty"`
+	Status         string     `bun:",notnull,default:'BUKA'" json:"status"`
+	Catatan        *string    `bun:",nullzero" json:"catatan,omitempty"`
+	TerdaftarQty   int        `bun:",notnull,default:0" json:"terdaftar_qty"`
+	TerdaftarBerat float64    `bun:",notnull,default:0" json:"terdaftar_berat"`
+	DibukaOleh     *string    `bun:",nullzero" json:"dibuka_oleh,omitempty"`
+	DitutupOleh    *string    `bun:",nullzero" json:"ditutup_oleh,omitempty"`
+	DitutupAt      *time.Time `bun:",nullzero" json:"ditutup_at,omitempty"`
+	CreatedAt      time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt      time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	JenisDurian *JenisDurian     `bun:"rel:belongs-to,join:jenis_durian_id=id" json:"jenis_durian,omitempty"`
+	Lots        []SesiGradingLot `bun:"rel:has-many,join:id=sesi_id" json:"lots,omitempty"`
+}
+
+func (m *SesiGrading) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SesiGradingLot is the lot a session fills for one grade
+type SesiGradingLot struct {
+	bun.BaseModel `bun:"table:tb_sesi_grading_lot,alias:sesi_lot"`
+
+	SesiID      string `bun:",pk" json:"sesi_id"`
+	LotID       string `bun:",pk" json:"lot_id"`
+	KondisiBuah string `bun:",notnull" json:"kondisi_buah"`
+
+	Lot *StokLot `bun:"rel:belongs-to,join:lot_id=id" json:"lot,omitempty"`
+}
+
+// SesiGradingIsi is what a session lot holds
+type SesiGradingIsi struct {
+	LotID string  `bun:"lot_id"`
+	Qty   int     `bun:"qty"`
+	Berat float64 `bun:"berat"`
+}
+
+// SesiGradingGrader is the scanning throughput of one grader in a session
+type SesiGradingGrader struct {
+	GraderID  string    `bun:"grader_id"`
+	Email     string    `bun:"email"`
+	Qty       int       `bun:"qty"`
+	Berat     float64   `bun:"berat"`
+	MulaiAt   time.Time `bun:"mulai_at"`
+	SelesaiAt time.Time `bun:"selesai_at"`
+}
@@ -36,6 +36,7 @@ type PengirimanDetail struct {
 	LotSumberID  string    `bun:",notnull" json:"lot_sumber_id"`
 	QtyAmbil     int       `bun:",notnull" json:"qty_ambil"`
 	BeratAmbil   float64   `bun:",notnull" json:"berat_ambil"`
+	Parsial      bool      `bun:",notnull,default:false" json:"parsial"`
 	CreatedAt    time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 
 	Pengiriman *Pengiriman `bun:"rel:belongs-to,join:pengiriman_id=id" json:"pengiriman,omitempty"`
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// StokSnapshot is the stock held at the end of one day
+type StokSnapshot struct {
+	bun.BaseModel `bun:"table:tb_stok_snapshot,alias:snapshot"`
+
+	Tanggal   time.Time `bun:",pk,type:date" json:"tanggal"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	Items []StokSnapshotItem `bun:"rel:has-many,join:tanggal=tanggal" json:"items,omitempty"`
+}
+
+// StokSnapshotItem is the stock of one location, season, jenis, grade and lot status. Replaying
+// the ledger gives the same rows without Tanggal.
+type StokSnapshotItem struct {
+	bun.BaseModel `bun:"table:tb_stok_snapshot_item,alias:snapshot_item"`
+
+	ID            string    `bun:",pk" json:"id"`
+	Tanggal       time.Time `bun:",notnull,type:date" json:"tanggal"`
+	LokasiID      *string   `bun:",nullzero" json:"lokasi_id,omitempty"`
+	MusimID       *string   `bun:",nullzero" json:"musim_id,omitempty"`
+	JenisDurianID string    `bun:",notnull" json:"jenis_durian_id"`
+	KondisiBuah   string    `bun:",notnull" json:"kondisi_buah"`
+	Status        string    `bun:",notnull" json:"status"`
+	LotCount      int       `bun:",notnull" json:"lot_count"`
+	Qty           int       `bun:",notnull" json:"qty"`
+	Berat         float64   `bun:",notnull" json:"berat"`
+
+	LokasiNama      string `bun:",scanonly" json:"lokasi_nama"`
+	JenisDurianNama string `bun:",scanonly" json:"jenis_durian_nama"`
+}
+
+func (m *StokSnapshotItem) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	}
+	return nil
+}
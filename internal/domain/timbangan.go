@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// StasiunTimbang is a grading station with a networked scale. While a session is open
+// (LotID set) every stable weight read from the scale is added to that lot.
+type StasiunTimbang struct {
+	bun.BaseModel `bun:"table:tb_stasiun_timbang,alias:stasiun"`
+
+	ID        string  `bun:",pk" json:"id"`
+	Kode      string  `bun:",unique,notnull" json:"kode"`
+	Nama      string  `bun:",notnull" json:"nama"`
+	Alamat    string  `bun:",notnull" json:"alamat"`
+	Protokol  string  `bun:",notnull" json:"protokol"`
+	Transport string  `bun:",notnull,default:'tcp'" json:"transport"`
+	MinBerat  float64 `bun:",notnull" json:"min_berat"`
+	Toleransi float64 `bun:",notnull" json:"toleransi"`
+	Sampel    int     `bun:",notnull" json:"sampel"`
+	Aktif     bool    `bun:",notnull,default:true" json:"aktif"`
+
+	LotID       *string    `bun:",nullzero" json:"lot_id,omitempty"`
+	BlokID      *string    `bun:",nullzero" json:"blok_id,omitempty"`
+	PohonKode   *string    `bun:",nullzero" json:"pohon_kode,omitempty"`
+	PemanenID   *string    `bun:",nullzero" json:"pemanen_id,omitempty"`
+	SesiOleh    *string    `bun:",nullzero" json:"sesi_oleh,omitempty"`
+	SesiMulaiAt *time.Time `bun:",nullzero" json:"sesi_mulai_at,omitempty"`
+
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"deleted_at,omitempty"`
+
+	Lot *StokLot `bun:"rel:belongs-to,join:lot_id=id" json:"lot,omitempty"`
+}
+
+func (m *StasiunTimbang) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// PembacaanTimbang logs every captured weight, accepted or not, so manual overrides can be audited
+type PembacaanTimbang struct {
+	bun.BaseModel `bun:"table:tb_pembacaan_timbang,alias:pembacaan"`
+
+	ID        string    `bun:",pk" json:"id"`
+	StasiunID string    `bun:",notnull" json:"stasiun_id"`
+	LotID     *string   `bun:",nullzero" json:"lot_id,omitempty"`
+	Berat     float64   `bun:",notnull" json:"berat"`
+	Raw       string    `bun:",notnull,default:''" json:"raw"`
+	Status    string    `bun:",notnull" json:"status"`
+	BuahRawID *string   `bun:",nullzero" json:"buah_raw_id,omitempty"`
+	KodeBuah  *string   `bun:",nullzero" json:"kode_buah,omitempty"`
+	Pesan     *string   `bun:",nullzero" json:"pesan,omitempty"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+func (m *PembacaanTimbang) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok && m.ID == "" {
+		m.ID = ksuid.New().String()
+	}
+	return nil
+}
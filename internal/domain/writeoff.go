@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/uptrace/bun"
+)
+
+// LotWriteOff is stock removed from a READY lot because it was lost, spoiled or taken as a sample
+type LotWriteOff struct {
+	bun.BaseModel `bun:"table:tb_lot_writeoff,alias:writeoff"`
+
+	ID               string     `bun:",pk" json:"id"`
+	LotID            string     `bun:",notnull" json:"lot_id"`
+	LokasiID         *string    `bun:",nullzero" json:"lokasi_id,omitempty"`
+	JenisDurianID    string     `bun:",notnull" json:"jenis_durian_id"`
+	AlasanKode       string     `bun:",notnull" json:"alasan_kode"`
+	Qty              int        `bun:",notnull" json:"qty"`
+	Berat            float64    `bun:",notnull" json:"berat"`
+	Catatan          *string    `bun:",nullzero" json:"catatan,omitempty"`
+	Status           string     `bun:",notnull" json:"status"`
+	DiajukanOleh     *string    `bun:",nullzero" json:"diajukan_oleh,omitempty"`
+	DiputuskanOleh   *string    `bun:",nullzero" json:"diputuskan_oleh,omitempty"`
+	DiputuskanAt     *time.Time `bun:",nullzero" json:"diputuskan_at,omitempty"`
+	CatatanKeputusan *string    `bun:",nullzero" json:"catatan_keputusan,omitempty"`
+	CreatedAt        time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt        time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	Lot         *StokLot          `bun:"rel:belongs-to,join:lot_id=id" json:"lot,omitempty"`
+	Lokasi      *TujuanPengiriman `bun:"rel:belongs-to,join:lokasi_id=id" json:"lokasi,omitempty"`
+	JenisDurian *JenisDurian      `bun:"rel:belongs-to,join:jenis_durian_id=id" json:"jenis_durian,omitempty"`
+	Pengaju     *User             `bun:"rel:belongs-to,join:diajukan_oleh=id" json:"pengaju,omitempty"`
+}
+
+func (m *LotWriteOff) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		if m.ID == "" {
+			m.ID = ksuid.New().String()
+		}
+	case *bun.UpdateQuery:
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// WriteOffLaporan is the approved loss of one location, jenis and reason
+type WriteOffLaporan struct {
+	LokasiID        *string `bun:"lokasi_id"`
+	LokasiNama      string  `bun:"lokasi_nama"`
+	JenisDurianID   string  `bun:"jenis_durian_id"`
+	JenisDurianNama string  `bun:"jenis_durian_nama"`
+	AlasanKode      string  `bun:"alasan_kode"`
+	Jumlah          int     `bun:"jumlah"`
+	Qty             int     `bun:"qty"`
+	Berat           float64 `bun:"berat"`
+}
@@ -0,0 +1,21 @@
+package requests
+
+// YieldQuery selects the period either as a date range or as a season: Tahun with
+// MusimMulai/MusimSelesai in MM-DD. A season whose end is before its start runs into the next year.
+// MusimID picks a recorded harvest season instead and overrides both.
+type YieldQuery struct {
+	Level            string `form:"level" binding:"omitempty,oneof=pohon blok divisi estate"`
+	TanggalMulai     string `form:"tanggal_mulai" binding:"omitempty,datetime=2006-01-02"`
+	TanggalSelesai   string `form:"tanggal_selesai" binding:"omitempty,datetime=2006-01-02"`
+	Tahun            int    `form:"tahun" binding:"omitempty,min=2000,max=2100"`
+	MusimMulai       string `form:"musim_mulai" binding:"omitempty,datetime=01-02"`
+	MusimSelesai     string `form:"musim_selesai" binding:"omitempty,datetime=01-02"`
+	EstateID         string `form:"estate_id"`
+	DivisiID         string `form:"divisi_id"`
+	BlokID           string `form:"blok_id"`
+	JenisDurianID    string `form:"jenis_durian_id"`
+	Urut             string `form:"urut" binding:"omitempty,oneof=qty berat rata_berat"`
+	Limit            int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+	BandingTahunLalu bool   `form:"banding_tahun_lalu"`
+	MusimID          string `form:"musim_id"`
+}
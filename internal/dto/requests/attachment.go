@@ -0,0 +1,7 @@
+package requests
+
+type AttachmentUploadRequest struct {
+	EntityType string `form:"entity_type" binding:"required,oneof=buah lot shipment sales"`
+	EntityID   string `form:"entity_id" binding:"required"`
+	Keterangan string `form:"keterangan" binding:"max=255"`
+}
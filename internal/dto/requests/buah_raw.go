@@ -3,7 +3,9 @@ package requests
 type BuahRawBulkCreateItem struct {
 	JenisDurianID string  `json:"jenis_durian_id" binding:"required"`
 	PohonPanenID  *string `json:"pohon_panen_id"`
+	PemanenID     *string `json:"pemanen_id"`
 	Jumlah        int     `json:"jumlah" binding:"required,min=1"`
+	BuahKualitasRequest
 }
 
 type BuahRawBulkCreateRequest struct {
@@ -16,11 +18,30 @@ type BuahRawCreateRequest struct {
 	JenisDurianID string  `json:"jenis_durian_id" binding:"required"`
 	PohonPanenID  *string `json:"pohon_panen_id"`
 	BlokPanenID   *string `json:"blok_panen_id"`
+	PemanenID     *string `json:"pemanen_id"`
 	Berat         float64 `json:"berat"`
+	BuahKualitasRequest
 }
 
 type BuahRawUpdateRequest struct {
 	TglPanen      string  `json:"tgl_panen"`
 	PohonPanenID  *string `json:"pohon_panen_id"`
 	JenisDurianID string  `json:"jenis_durian_id"`
+	BuahKualitasRequest
+}
+
+type BuahRawSyncItem struct {
+	ClientID      string  `json:"client_id" binding:"required"`
+	JenisDurianID string  `json:"jenis_durian_id" binding:"required"`
+	PohonPanenID  *string `json:"pohon_panen_id"`
+	PemanenID     *string `json:"pemanen_id"`
+	TglPanen      string  `json:"tgl_panen"`
+	Berat         float64 `json:"berat"`
+	RecordedAt    string  `json:"recorded_at" binding:"required"`
+	BuahKualitasRequest
+}
+
+type BuahRawSyncRequest struct {
+	DeviceID string            `json:"device_id" binding:"required"`
+	Items    []BuahRawSyncItem `json:"items" binding:"required,min=1,max=500,dive"`
 }
@@ -0,0 +1,19 @@
+package requests
+
+type GradeRequest struct {
+	Kode   string               `json:"kode" binding:"required,max=10"`
+	Nama   string               `json:"nama" binding:"required"`
+	Urutan int                  `json:"urutan" binding:"min=0"`
+	Aturan []GradeAturanRequest `json:"aturan" binding:"dive"`
+}
+
+// GradeAturanRequest replaces the rule of one jenis. Omitted fields do not constrain.
+type GradeAturanRequest struct {
+	JenisDurianID  string   `json:"jenis_durian_id" binding:"required"`
+	BeratMin       *float64 `json:"berat_min" binding:"omitempty,gte=0"`
+	BeratMax       *float64 `json:"berat_max" binding:"omitempty,gt=0"`
+	KematanganOpsi []string `json:"kematangan_opsi" binding:"dive,required"`
+	CacatDilarang  []string `json:"cacat_dilarang" binding:"dive,required"`
+	CacatMaks      *int     `json:"cacat_maks" binding:"omitempty,min=0"`
+	SkorAromaMin   *int     `json:"skor_aroma_min" binding:"omitempty,min=0"`
+}
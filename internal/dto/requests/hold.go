@@ -0,0 +1,19 @@
+package requests
+
+import "time"
+
+// HoldRequest holds a READY lot for a customer. Without BerakhirAt the hold lasts the configured
+// default duration.
+type HoldRequest struct {
+	LotID      string     `json:"lot_id" binding:"required"`
+	Pelanggan  string     `json:"pelanggan" binding:"required"`
+	BerakhirAt *time.Time `json:"berakhir_at"`
+	Catatan    string     `json:"catatan"`
+}
+
+// HoldListQuery lists holds, Milik only the caller's own
+type HoldListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=AKTIF DILEPAS KEDALUWARSA DIKIRIM"`
+	LotID  string `form:"lot_id"`
+	Milik  bool   `form:"milik"`
+}
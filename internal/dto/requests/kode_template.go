@@ -0,0 +1,14 @@
+package requests
+
+type KodeTemplateSaveRequest struct {
+	CompanyID *string `json:"company_id"`
+	Tipe      string  `json:"tipe" binding:"required,oneof=BUAH LOT SHIPMENT"`
+	Template  string  `json:"template" binding:"required,max=100"`
+	Reset     string  `json:"reset" binding:"required,oneof=NONE DAILY MONTHLY YEARLY"`
+}
+
+type KodeTemplatePreviewRequest struct {
+	Tipe     string `json:"tipe" binding:"required,oneof=BUAH LOT SHIPMENT"`
+	Template string `json:"template" binding:"required,max=100"`
+	Reset    string `json:"reset" binding:"required,oneof=NONE DAILY MONTHLY YEARLY"`
+}
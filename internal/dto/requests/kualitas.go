@@ -0,0 +1,18 @@
+package requests
+
+// BuahKualitasRequest is embedded in harvest and grading requests. Nil fields are left unchanged on update.
+type BuahKualitasRequest struct {
+	TingkatKematangan *string  `json:"tingkat_kematangan"`
+	Cacat             []string `json:"cacat"`
+	SkorAroma         *int     `json:"skor_aroma"`
+	MetodePanen       *string  `json:"metode_panen"`
+}
+
+type JenisDurianKualitasRequest struct {
+	KematanganOpsi  []string `json:"kematangan_opsi" binding:"required,min=1,dive,required"`
+	CacatOpsi       []string `json:"cacat_opsi" binding:"required,dive,required"`
+	MetodePanenOpsi []string `json:"metode_panen_opsi" binding:"required,min=1,dive,required"`
+	SkorAromaMin    int      `json:"skor_aroma_min" binding:"min=0"`
+	SkorAromaMax    int      `json:"skor_aroma_max" binding:"required,gtefield=SkorAromaMin"`
+	AtributWajib    []string `json:"atribut_wajib" binding:"dive,oneof=tingkat_kematangan cacat skor_aroma metode_panen"`
+}
@@ -0,0 +1,9 @@
+package requests
+
+type LabelSheetRequest struct {
+	BuahRawIDs []string `json:"buah_raw_ids" binding:"omitempty,max=240"`
+	LotID      string   `json:"lot_id"`
+	Symbology  string   `json:"symbology"`
+	Format     string   `json:"format"`
+	Printer    string   `json:"printer"`
+}
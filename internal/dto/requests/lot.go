@@ -3,12 +3,20 @@ package requests
 type LotCreateRequest struct {
 	JenisDurianID string `json:"jenis_durian_id" binding:"required"`
 	KondisiBuah   string `json:"kondisi_buah" binding:"required"`
+	EstateID      string `json:"estate_id"`
 }
 
 type LotAddItemsRequest struct {
 	PohonKode string  `json:"pohon_kode" binding:"required"` // Kode pohon
 	BlokID    string  `json:"blok_id" binding:"required"`    // UUID blok
 	Berat     float64 `json:"berat" binding:"required,gt=0"`
+	PemanenID *string `json:"pemanen_id"`
+	BuahKualitasRequest
+}
+
+// LotScanRequest adds fruits already registered in the field to a DRAFT lot by kode_buah
+type LotScanRequest struct {
+	KodeBuah []string `json:"kode_buah" binding:"required,min=1,max=500,dive,required"`
 }
 
 type LotRemoveItemRequest struct {
@@ -17,3 +25,26 @@ type LotRemoveItemRequest struct {
 
 type LotFinalizeRequest struct {
 }
+
+type LotSplitRequest struct {
+	BuahRawIDs []string `json:"buah_raw_ids" binding:"required,min=1,dive,required"`
+}
+
+type LotMergeRequest struct {
+	LotIDs []string `json:"lot_ids" binding:"required,min=2,dive,required"`
+}
+
+// LotRegradeRequest regrades the whole lot unless BuahRawIDs is given. The fruits go to a
+// new lot of the new grade, or to TargetLotID when it already holds that grade.
+type LotRegradeRequest struct {
+	KondisiBuah string   `json:"kondisi_buah" binding:"required"`
+	Alasan      string   `json:"alasan" binding:"required"`
+	BuahRawIDs  []string `json:"buah_raw_ids" binding:"omitempty,dive,required"`
+	TargetLotID string   `json:"target_lot_id"`
+}
+
+// LotTimbangUlangRequest is the weight of a READY lot weighed again, it becomes the lot's berat_sisa
+type LotTimbangUlangRequest struct {
+	Berat   float64 `json:"berat" binding:"required,gt=0"`
+	Catatan string  `json:"catatan"`
+}
@@ -42,13 +42,19 @@ type BlokUpdateRequest struct {
 	DivisiID string `json:"divisi_id" binding:"required"`
 }
 
+// JenisDurianCreateRequest sets the shelf life in hours of a stored lot and the warning window
+// before it expires. Without UmurSimpanJam lots of the jenis never expire.
 type JenisDurianCreateRequest struct {
-	Kode      string `json:"kode" binding:"required"`
-	NamaJenis string `json:"nama_jenis" binding:"required"`
+	Kode               string `json:"kode" binding:"required"`
+	NamaJenis          string `json:"nama_jenis" binding:"required"`
+	UmurSimpanJam      *int   `json:"umur_simpan_jam" binding:"omitempty,min=1"`
+	BatasPeringatanJam *int   `json:"batas_peringatan_jam" binding:"omitempty,min=0"`
 }
 
 type JenisDurianUpdateRequest struct {
-	NamaJenis string `json:"nama_jenis" binding:"required"`
+	NamaJenis          string `json:"nama_jenis" binding:"required"`
+	UmurSimpanJam      *int   `json:"umur_simpan_jam" binding:"omitempty,min=1"`
+	BatasPeringatanJam *int   `json:"batas_peringatan_jam" binding:"omitempty,min=0"`
 }
 
 type PohonCreateRequest struct {
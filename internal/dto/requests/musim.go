@@ -0,0 +1,15 @@
+package requests
+
+type MusimPanenCreateRequest struct {
+	Kode           string  `json:"kode" binding:"required,max=30"`
+	Nama           string  `json:"nama" binding:"required"`
+	EstateID       string  `json:"estate_id" binding:"required"`
+	TanggalMulai   string  `json:"tanggal_mulai" binding:"required,datetime=2006-01-02"`
+	TanggalSelesai *string `json:"tanggal_selesai" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type MusimPanenUpdateRequest struct {
+	Nama           string  `json:"nama" binding:"required"`
+	TanggalMulai   string  `json:"tanggal_mulai" binding:"required,datetime=2006-01-02"`
+	TanggalSelesai *string `json:"tanggal_selesai" binding:"omitempty,datetime=2006-01-02"`
+}
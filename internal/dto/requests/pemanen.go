@@ -0,0 +1,23 @@
+package requests
+
+type PemanenCreateRequest struct {
+	Kode     string  `json:"kode" binding:"required,max=20"`
+	Nama     string  `json:"nama" binding:"required"`
+	EstateID string  `json:"estate_id" binding:"required"`
+	DivisiID *string `json:"divisi_id"`
+}
+
+type PemanenUpdateRequest struct {
+	Nama     string  `json:"nama" binding:"required"`
+	EstateID string  `json:"estate_id" binding:"required"`
+	DivisiID *string `json:"divisi_id"`
+	Aktif    *bool   `json:"aktif"`
+}
+
+type TarifPanenRequest struct {
+	JenisDurianID string  `json:"jenis_durian_id" binding:"required"`
+	Grade         string  `json:"grade"`
+	TarifPerBuah  float64 `json:"tarif_per_buah" binding:"min=0"`
+	TarifPerKg    float64 `json:"tarif_per_kg" binding:"min=0"`
+	BerlakuMulai  string  `json:"berlaku_mulai" binding:"required,datetime=2006-01-02"`
+}
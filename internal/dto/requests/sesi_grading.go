@@ -0,0 +1,16 @@
+package requests
+
+// SesiGradingOpenRequest opens one DRAFT lot per grade. Without Grade the session uses the
+// grades that have a rule for the jenis, or every grade when the jenis has none.
+type SesiGradingOpenRequest struct {
+	JenisDurianID string   `json:"jenis_durian_id" binding:"required"`
+	EstateID      string   `json:"estate_id"`
+	Grade         []string `json:"grade"`
+	Catatan       string   `json:"catatan"`
+}
+
+// SesiGradingScanRequest routes scanned fruits to the session lot of KondisiBuah
+type SesiGradingScanRequest struct {
+	KondisiBuah string   `json:"kondisi_buah" binding:"required"`
+	KodeBuah    []string `json:"kode_buah" binding:"required,min=1,max=500,dive,required"`
+}
@@ -5,12 +5,19 @@ import (
 )
 
 type ShipmentCreateRequest struct {
-	TujuanID string    `json:"tujuan_id" binding:"required"`
-	TglKirim time.Time `json:"tgl_kirim"`
+	TujuanID  string    `json:"tujuan_id" binding:"required"`
+	TglKirim  time.Time `json:"tgl_kirim"`
+	CompanyID string    `json:"company_id"`
 }
 
+// ShipmentAddItemRequest takes the whole remaining lot unless QtyAmbil or KodeBuah is given.
+// BeratAmbil defaults to the lot's average weight times QtyAmbil. KodeBuah splits those
+// fruits into their own lot, which is then taken whole.
 type ShipmentAddItemRequest struct {
-	LotID string `json:"lot_id" binding:"required"`
+	LotID      string   `json:"lot_id" binding:"required"`
+	QtyAmbil   *int     `json:"qty_ambil" binding:"omitempty,min=1"`
+	BeratAmbil *float64 `json:"berat_ambil" binding:"omitempty,gt=0"`
+	KodeBuah   []string `json:"kode_buah" binding:"omitempty,dive,required"`
 }
 
 type ShipmentRemoveItemRequest struct {
@@ -30,3 +37,23 @@ type ShipmentReceiveRequest struct {
 		QtyDiterima   *int    `json:"qty_diterima"`
 	} `json:"details" binding:"required,dive"`
 }
+
+// ShipmentSaranRequest asks for the lots to fill a shipment. Urutan defaults to FEFO. Buat
+// also creates the DRAFT shipment with the proposed lots.
+type ShipmentSaranRequest struct {
+	TujuanID  string                     `json:"tujuan_id" binding:"required"`
+	Urutan    string                     `json:"urutan" binding:"omitempty,oneof=FEFO FIFO"`
+	Items     []ShipmentSaranItemRequest `json:"items" binding:"required,min=1,dive"`
+	Buat      bool                       `json:"buat"`
+	TglKirim  time.Time                  `json:"tgl_kirim"`
+	CompanyID string                     `json:"company_id"`
+}
+
+// ShipmentSaranItemRequest is the need of one jenis and grade. Qty is filled when given,
+// otherwise Berat.
+type ShipmentSaranItemRequest struct {
+	JenisDurianID string  `json:"jenis_durian_id" binding:"required"`
+	KondisiBuah   string  `json:"kondisi_buah" binding:"required"`
+	Qty           int     `json:"qty" binding:"min=0"`
+	Berat         float64 `json:"berat" binding:"min=0"`
+}
@@ -0,0 +1,15 @@
+package requests
+
+// StokAsOfQuery asks for the stock held at the end of Tanggal
+type StokAsOfQuery struct {
+	Tanggal       string `form:"tanggal" binding:"required,datetime=2006-01-02"`
+	LokasiID      string `form:"lokasi_id"`
+	MusimID       string `form:"musim_id"`
+	JenisDurianID string `form:"jenis_durian_id"`
+	KondisiBuah   string `form:"kondisi_buah"`
+	Status        string `form:"status" binding:"omitempty,oneof=READY BOOKED SHIPPED SOLD EMPTY"`
+}
+
+type StokSnapshotRequest struct {
+	Tanggal string `json:"tanggal" binding:"required,datetime=2006-01-02"`
+}
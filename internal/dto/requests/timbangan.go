@@ -0,0 +1,26 @@
+package requests
+
+type StasiunTimbangRequest struct {
+	Nama      string   `json:"nama" binding:"required"`
+	Alamat    string   `json:"alamat" binding:"required,hostname_port"`
+	Protokol  string   `json:"protokol" binding:"required,oneof=and toledo simple"`
+	Transport string   `json:"transport" binding:"omitempty,oneof=tcp telnet"`
+	MinBerat  *float64 `json:"min_berat" binding:"omitempty,gt=0"`
+	Toleransi *float64 `json:"toleransi" binding:"omitempty,gte=0"`
+	Sampel    *int     `json:"sampel" binding:"omitempty,min=1,max=50"`
+	Aktif     *bool    `json:"aktif"`
+}
+
+type StasiunTimbangCreateRequest struct {
+	Kode string `json:"kode" binding:"required,max=20"`
+	StasiunTimbangRequest
+}
+
+// StasiunTimbangSesiRequest binds a station to the lot being graded. Every captured
+// weight becomes a fruit from PohonKode in BlokID, credited to PemanenID when set.
+type StasiunTimbangSesiRequest struct {
+	LotID     string  `json:"lot_id" binding:"required"`
+	BlokID    string  `json:"blok_id" binding:"required"`
+	PohonKode string  `json:"pohon_kode" binding:"required"`
+	PemanenID *string `json:"pemanen_id"`
+}
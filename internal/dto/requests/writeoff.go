@@ -0,0 +1,23 @@
+package requests
+
+// WriteOffRequest removes stock from a READY lot. Qty equal to the lot's remaining qty writes
+// off the whole lot, a partial write-off without Berat prorates it. SUSUT takes Berat only.
+type WriteOffRequest struct {
+	LotID      string  `json:"lot_id" binding:"required"`
+	AlasanKode string  `json:"alasan_kode" binding:"required,oneof=BUSUK RETAK DICURI SAMPEL SUSUT"`
+	Qty        int     `json:"qty" binding:"min=0"`
+	Berat      float64 `json:"berat" binding:"min=0"`
+	Catatan    string  `json:"catatan"`
+}
+
+type WriteOffKeputusanRequest struct {
+	Catatan string `json:"catatan"`
+}
+
+type WriteOffLaporanQuery struct {
+	DateFrom      string `form:"date_from" binding:"omitempty,datetime=2006-01-02"`
+	DateTo        string `form:"date_to" binding:"omitempty,datetime=2006-01-02"`
+	LokasiID      string `form:"lokasi_id"`
+	JenisDurianID string `form:"jenis_durian_id"`
+	AlasanKode    string `form:"alasan_kode" binding:"omitempty,oneof=BUSUK RETAK DICURI SAMPEL SUSUT"`
+}
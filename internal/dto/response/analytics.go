@@ -0,0 +1,58 @@
+package response
+
+type YieldPeriode struct {
+	Mulai   string `json:"mulai"`
+	Selesai string `json:"selesai"`
+}
+
+type YieldGrade struct {
+	Grade  string  `json:"grade"`
+	Qty    int     `json:"qty"`
+	Persen float64 `json:"persen"`
+}
+
+// YieldMetrik averages weight over weighed fruits only, fruits recorded without berat are counted in Qty
+type YieldMetrik struct {
+	Qty          int          `json:"qty"`
+	QtyDitimbang int          `json:"qty_ditimbang"`
+	TotalBerat   float64      `json:"total_berat"`
+	RataBerat    float64      `json:"rata_berat"`
+	Grade        []YieldGrade `json:"grade"`
+}
+
+type YieldBanding struct {
+	YieldMetrik
+	SelisihQtyPersen   *float64 `json:"selisih_qty_persen"`
+	SelisihBeratPersen *float64 `json:"selisih_berat_persen"`
+}
+
+type YieldItem struct {
+	Peringkat  int    `json:"peringkat"`
+	ID         string `json:"id"`
+	Kode       string `json:"kode"`
+	Nama       string `json:"nama"`
+	EstateKode string `json:"estate_kode,omitempty"`
+	DivisiKode string `json:"divisi_kode,omitempty"`
+	BlokKode   string `json:"blok_kode,omitempty"`
+	YieldMetrik
+	Banding *YieldBanding `json:"banding,omitempty"`
+}
+
+type YieldResponse struct {
+	Level          string        `json:"level"`
+	Urut           string        `json:"urut"`
+	Periode        YieldPeriode  `json:"periode"`
+	PeriodeBanding *YieldPeriode `json:"periode_banding,omitempty"`
+	Total          YieldMetrik   `json:"total"`
+	TotalBanding   *YieldBanding `json:"total_banding,omitempty"`
+	Items          []YieldItem   `json:"items"`
+}
+
+type YieldRankingResponse struct {
+	Level          string        `json:"level"`
+	Urut           string        `json:"urut"`
+	Periode        YieldPeriode  `json:"periode"`
+	PeriodeBanding *YieldPeriode `json:"periode_banding,omitempty"`
+	Terbaik        []YieldItem   `json:"terbaik"`
+	Terburuk       []YieldItem   `json:"terburuk"`
+}
@@ -0,0 +1,18 @@
+package response
+
+import "time"
+
+type AttachmentResponse struct {
+	ID            string    `json:"id"`
+	EntityType    string    `json:"entity_type"`
+	EntityID      string    `json:"entity_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	Keterangan    string    `json:"keterangan,omitempty"`
+	UploadedBy    string    `json:"uploaded_by"`
+	UploaderEmail string    `json:"uploader_email,omitempty"`
+	URL           string    `json:"url"`
+	ThumbnailURL  string    `json:"thumbnail_url,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -24,9 +24,51 @@ type BuahRawResponse struct {
 	KodePohon   string            `json:"kode_pohon"` // Added field
 	LotKode     *string           `json:"kode_lot"`   // Added field
 	TglPanen    string            `json:"tgl_panen"`
+	Berat       float64           `json:"berat"`
+	Kualitas    BuahKualitas      `json:"kualitas"`
+	PemanenID   *string           `json:"pemanen_id"`
 	CreatedAt   string            `json:"created_at"`
 }
 
+type BuahKualitas struct {
+	TingkatKematangan *string  `json:"tingkat_kematangan"`
+	Cacat             []string `json:"cacat"`
+	SkorAroma         *int     `json:"skor_aroma"`
+	MetodePanen       *string  `json:"metode_panen"`
+}
+
+type BuahRawSyncItemResult struct {
+	ClientID string `json:"client_id"`
+	Status   string `json:"status"`
+	KodeBuah string `json:"kode_buah,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type BuahRawSyncResponse struct {
+	DeviceID       string                  `json:"device_id"`
+	SyncedAt       string                  `json:"synced_at"`
+	TotalCreated   int                     `json:"total_created"`
+	TotalDuplicate int                     `json:"total_duplicate"`
+	TotalRejected  int                     `json:"total_rejected"`
+	Items          []BuahRawSyncItemResult `json:"items"`
+}
+
+type BuahRawImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type BuahRawImportResponse struct {
+	DryRun        bool                    `json:"dry_run"`
+	TotalRows     int                     `json:"total_rows"`
+	ValidRows     int                     `json:"valid_rows"`
+	InvalidRows   int                     `json:"invalid_rows"`
+	TotalInserted int                     `json:"total_inserted"`
+	Errors        []BuahRawImportRowError `json:"errors"`
+	Items         []BuahRawResponse       `json:"items,omitempty"`
+}
+
 type PaginationMeta struct {
 	Page      int `json:"page"`
 	Limit     int `json:"limit"`
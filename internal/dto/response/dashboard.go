@@ -5,6 +5,8 @@ type DashboardStokResponse struct {
 	StokByJenis []StokByJenis         `json:"stok_by_jenis"`
 	Throughput  ThroughputSummary     `json:"throughput"`
 	Trend7Hari  []ThroughputTrendItem `json:"trend_7_hari"`
+	AsOf        string                `json:"as_of,omitempty"`
+	Sumber      string                `json:"sumber,omitempty"`
 }
 
 type StokSummary struct {
@@ -0,0 +1,19 @@
+package response
+
+type GradeResponse struct {
+	ID     string                `json:"id"`
+	Kode   string                `json:"kode"`
+	Nama   string                `json:"nama"`
+	Urutan int                   `json:"urutan"`
+	Aturan []GradeAturanResponse `json:"aturan"`
+}
+
+type GradeAturanResponse struct {
+	JenisDurianID  string   `json:"jenis_durian_id"`
+	BeratMin       *float64 `json:"berat_min,omitempty"`
+	BeratMax       *float64 `json:"berat_max,omitempty"`
+	KematanganOpsi []string `json:"kematangan_opsi"`
+	CacatDilarang  []string `json:"cacat_dilarang"`
+	CacatMaks      *int     `json:"cacat_maks,omitempty"`
+	SkorAromaMin   *int     `json:"skor_aroma_min,omitempty"`
+}
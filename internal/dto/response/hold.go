@@ -0,0 +1,26 @@
+package response
+
+import "time"
+
+type HoldResponse struct {
+	ID              string     `json:"id"`
+	LotID           string     `json:"lot_id"`
+	LotKode         string     `json:"lot_kode"`
+	JenisDurianNama string     `json:"jenis_durian_nama"`
+	KondisiBuah     string     `json:"kondisi_buah"`
+	QtySisa         int        `json:"qty_sisa"`
+	BeratSisa       float64    `json:"berat_sisa"`
+	LokasiID        *string    `json:"lokasi_id,omitempty"`
+	LokasiNama      string     `json:"lokasi_nama,omitempty"`
+	Pelanggan       string     `json:"pelanggan"`
+	Catatan         *string    `json:"catatan,omitempty"`
+	BerakhirAt      time.Time  `json:"berakhir_at"`
+	Status          string     `json:"status"`
+	DipegangOleh    string     `json:"dipegang_oleh"`
+	Email           string     `json:"email,omitempty"`
+	DilepasOleh     *string    `json:"dilepas_oleh,omitempty"`
+	DilepasAt       *time.Time `json:"dilepas_at,omitempty"`
+	PengirimanID    *string    `json:"pengiriman_id,omitempty"`
+	PengirimanKode  string     `json:"pengiriman_kode,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package response
+
+import "time"
+
+type KodeTemplateResponse struct {
+	ID          string    `json:"id"`
+	CompanyID   *string   `json:"company_id"`
+	CompanyNama string    `json:"company_nama,omitempty"`
+	Tipe        string    `json:"tipe"`
+	Template    string    `json:"template"`
+	Reset       string    `json:"reset"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type KodeTemplatePreviewResponse struct {
+	Tipe     string `json:"tipe"`
+	Template string `json:"template"`
+	Reset    string `json:"reset"`
+	Contoh   string `json:"contoh"`
+}
@@ -0,0 +1,12 @@
+package response
+
+type JenisDurianKualitasResponse struct {
+	JenisDurianID   string   `json:"jenis_durian_id"`
+	IsDefault       bool     `json:"is_default"`
+	KematanganOpsi  []string `json:"kematangan_opsi"`
+	CacatOpsi       []string `json:"cacat_opsi"`
+	MetodePanenOpsi []string `json:"metode_panen_opsi"`
+	SkorAromaMin    int      `json:"skor_aroma_min"`
+	SkorAromaMax    int      `json:"skor_aroma_max"`
+	AtributWajib    []string `json:"atribut_wajib"`
+}
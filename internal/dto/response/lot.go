@@ -15,7 +15,17 @@ type LotResponse struct {
 	CurrentQty      int       `json:"current_qty"`
 	CurrentBerat    float64   `json:"current_berat"`
 	Status          string    `json:"status"`
+	MusimID         *string   `json:"musim_id,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
+
+	MulaiSimpanAt *time.Time `json:"mulai_simpan_at,omitempty"`
+	UmurJam       *float64   `json:"umur_jam,omitempty"`
+	KedaluwarsaAt *time.Time `json:"kedaluwarsa_at,omitempty"`
+	StatusUmur    string     `json:"status_umur,omitempty"`
+
+	HoldOleh       *string    `json:"hold_oleh,omitempty"`
+	HoldPelanggan  *string    `json:"hold_pelanggan,omitempty"`
+	HoldBerakhirAt *time.Time `json:"hold_berakhir_at,omitempty"`
 }
 
 type LotDetailResponse struct {
@@ -32,8 +42,34 @@ type LotItemResponse struct {
 	JenisDurian string  `json:"jenis_durian"` // Format: "KODE - Nama Jenis"
 }
 
+// LotAddItemsResponse carries the grade suggested for the fruit, when a grade rule matches it,
+// and whether the lot has that grade
 type LotAddItemsResponse struct {
-	CurrentQty int `json:"current_qty"`
+	BuahRawID   string  `json:"buah_raw_id"`
+	KodeBuah    string  `json:"kode_buah"`
+	CurrentQty  int     `json:"current_qty"`
+	SaranGrade  *string `json:"saran_grade,omitempty"`
+	GradeSesuai *bool   `json:"grade_sesuai,omitempty"`
+}
+
+// LotScanResponse lists the scanned fruits that joined the lot and the ones that were refused with the reason
+type LotScanResponse struct {
+	Diterima   []LotScanItem    `json:"diterima"`
+	Ditolak    []LotScanDitolak `json:"ditolak"`
+	CurrentQty int              `json:"current_qty"`
+}
+
+type LotScanItem struct {
+	BuahRawID   string  `json:"buah_raw_id"`
+	KodeBuah    string  `json:"kode_buah"`
+	Berat       float64 `json:"berat"`
+	SaranGrade  *string `json:"saran_grade,omitempty"`
+	GradeSesuai *bool   `json:"grade_sesuai,omitempty"`
+}
+
+type LotScanDitolak struct {
+	KodeBuah string `json:"kode_buah"`
+	Alasan   string `json:"alasan"`
 }
 
 type LotFinalizeResponse struct {
@@ -42,3 +78,164 @@ type LotFinalizeResponse struct {
 	BeratTotal float64 `json:"berat_total"`
 	Status     string  `json:"status"`
 }
+
+type LotSplitResponse struct {
+	Asal LotResponse `json:"asal"`
+	Baru LotResponse `json:"baru"`
+}
+
+type LotMergeResponse struct {
+	Asal []LotResponse `json:"asal"`
+	Baru LotResponse   `json:"baru"`
+}
+
+type LotGenealogiItem struct {
+	ID        string    `json:"id"`
+	Tipe      string    `json:"tipe"`
+	LotID     string    `json:"lot_id"`
+	LotKode   string    `json:"lot_kode"`
+	Qty       int       `json:"qty"`
+	Berat     float64   `json:"berat"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LotRegradeResponse shows the original lot and the lot that received the regraded fruits
+type LotRegradeResponse struct {
+	Asal        LotResponse `json:"asal"`
+	Tujuan      LotResponse `json:"tujuan"`
+	KondisiDari string      `json:"kondisi_dari"`
+	KondisiKe   string      `json:"kondisi_ke"`
+	Qty         int         `json:"qty"`
+	Berat       float64     `json:"berat"`
+	Alasan      string      `json:"alasan"`
+}
+
+type LotRegradeItem struct {
+	ID            string    `json:"id"`
+	LotAsalID     string    `json:"lot_asal_id"`
+	LotAsalKode   string    `json:"lot_asal_kode"`
+	LotTujuanID   string    `json:"lot_tujuan_id"`
+	LotTujuanKode string    `json:"lot_tujuan_kode"`
+	KondisiDari   string    `json:"kondisi_dari"`
+	KondisiKe     string    `json:"kondisi_ke"`
+	Qty           int       `json:"qty"`
+	Berat         float64   `json:"berat"`
+	Alasan        string    `json:"alasan"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LotStatusRiwayatResponse is one lot status change, StatusDari is nil for the status the lot was created with
+type LotStatusRiwayatResponse struct {
+	ID         string    `json:"id"`
+	StatusDari *string   `json:"status_dari"`
+	StatusKe   string    `json:"status_ke"`
+	Alasan     *string   `json:"alasan,omitempty"`
+	CreatedBy  *string   `json:"created_by,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LotGenealogiResponse lists the lots a lot came from and the lots that came from it
+type LotGenealogiResponse struct {
+	LotID   string             `json:"lot_id"`
+	Asal    []LotGenealogiItem `json:"asal"`
+	Turunan []LotGenealogiItem `json:"turunan"`
+}
+
+// LotLedgerResponse is the stock ledger of a lot. Cocok reports whether the movements add up
+// to the lot's current balance.
+type LotLedgerResponse struct {
+	LotID       string              `json:"lot_id"`
+	Kode        string              `json:"kode"`
+	QtySisa     int                 `json:"qty_sisa"`
+	BeratSisa   float64             `json:"berat_sisa"`
+	LedgerQty   int                 `json:"ledger_qty"`
+	LedgerBerat float64             `json:"ledger_berat"`
+	Cocok       bool                `json:"cocok"`
+	Mutasi      []LotMutasiResponse `json:"mutasi"`
+}
+
+type LotMutasiResponse struct {
+	ID            string    `json:"id"`
+	Tipe          string    `json:"tipe"`
+	LokasiID      *string   `json:"lokasi_id,omitempty"`
+	LokasiNama    string    `json:"lokasi_nama,omitempty"`
+	DeltaQty      int       `json:"delta_qty"`
+	DeltaBerat    float64   `json:"delta_berat"`
+	QtySaldo      int       `json:"qty_saldo"`
+	BeratSaldo    float64   `json:"berat_saldo"`
+	ReferensiTipe *string   `json:"referensi_tipe,omitempty"`
+	ReferensiID   *string   `json:"referensi_id,omitempty"`
+	ReferensiKode *string   `json:"referensi_kode,omitempty"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LotRekonsiliasiItem is a lot whose ledger does not add up to its balance
+type LotRekonsiliasiItem struct {
+	LotID       string  `json:"lot_id"`
+	Kode        string  `json:"kode"`
+	Status      string  `json:"status"`
+	QtySisa     int     `json:"qty_sisa"`
+	BeratSisa   float64 `json:"berat_sisa"`
+	LedgerQty   int     `json:"ledger_qty"`
+	LedgerBerat float64 `json:"ledger_berat"`
+}
+
+type LotAgingResponse struct {
+	Lokasi []LotAgingLokasi `json:"lokasi"`
+}
+
+// LotAgingLokasi is the stored stock of one location, grouped by how long it has been stored
+type LotAgingLokasi struct {
+	LokasiID    *string            `json:"lokasi_id,omitempty"`
+	LokasiNama  string             `json:"lokasi_nama,omitempty"`
+	LotCount    int                `json:"lot_count"`
+	Qty         int                `json:"qty"`
+	Berat       float64            `json:"berat"`
+	Mendekati   int                `json:"mendekati"`
+	Kedaluwarsa int                `json:"kedaluwarsa"`
+	Kelompok    []LotAgingKelompok `json:"kelompok"`
+}
+
+type LotAgingKelompok struct {
+	Label    string  `json:"label"`
+	LotCount int     `json:"lot_count"`
+	Qty      int     `json:"qty"`
+	Berat    float64 `json:"berat"`
+}
+
+type LotPeringatanItem struct {
+	LotID           string     `json:"lot_id"`
+	Kode            string     `json:"kode"`
+	LokasiID        *string    `json:"lokasi_id,omitempty"`
+	LokasiNama      string     `json:"lokasi_nama,omitempty"`
+	JenisDurianNama string     `json:"jenis_durian_nama"`
+	KondisiBuah     string     `json:"kondisi_buah"`
+	Status          string     `json:"status"`
+	QtySisa         int        `json:"qty_sisa"`
+	BeratSisa       float64    `json:"berat_sisa"`
+	MulaiSimpanAt   *time.Time `json:"mulai_simpan_at,omitempty"`
+	KedaluwarsaAt   *time.Time `json:"kedaluwarsa_at,omitempty"`
+	SisaJam         float64    `json:"sisa_jam"`
+	StatusUmur      string     `json:"status_umur"`
+}
+
+type LotTimbangUlangResponse struct {
+	ID           string    `json:"id"`
+	LotID        string    `json:"lot_id"`
+	LokasiID     *string   `json:"lokasi_id,omitempty"`
+	Qty          int       `json:"qty"`
+	BeratAcuan   float64   `json:"berat_acuan"`
+	BeratSebelum float64   `json:"berat_sebelum"`
+	Berat        float64   `json:"berat"`
+	Susut        float64   `json:"susut"`
+	SusutPersen  float64   `json:"susut_persen"`
+	Catatan      *string   `json:"catatan,omitempty"`
+	CreatedBy    *string   `json:"created_by,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
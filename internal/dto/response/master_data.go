@@ -42,11 +42,13 @@ type BlokResponse struct {
 }
 
 type JenisDurianResponse struct {
-	ID        string    `json:"id"`
-	Kode      string    `json:"kode"`
-	NamaJenis string    `json:"nama_jenis"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 string    `json:"id"`
+	Kode               string    `json:"kode"`
+	NamaJenis          string    `json:"nama_jenis"`
+	UmurSimpanJam      *int      `json:"umur_simpan_jam,omitempty"`
+	BatasPeringatanJam *int      `json:"batas_peringatan_jam,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type PohonResponse struct {
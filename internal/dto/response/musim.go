@@ -0,0 +1,21 @@
+package response
+
+import (
+	"durich-be/internal/domain"
+	"time"
+)
+
+type MusimPanenResponse struct {
+	ID             string                 `json:"id"`
+	Kode           string                 `json:"kode"`
+	Nama           string                 `json:"nama"`
+	EstateID       string                 `json:"estate_id"`
+	EstateNama     string                 `json:"estate_nama,omitempty"`
+	TanggalMulai   string                 `json:"tanggal_mulai"`
+	TanggalSelesai *string                `json:"tanggal_selesai"`
+	Status         string                 `json:"status"`
+	DitutupAt      *time.Time             `json:"ditutup_at,omitempty"`
+	DitutupOleh    *string                `json:"ditutup_oleh,omitempty"`
+	Ringkasan      *domain.MusimRingkasan `json:"ringkasan,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package response
+
+import "time"
+
+type PemanenResponse struct {
+	ID         string    `json:"id"`
+	Kode       string    `json:"kode"`
+	Nama       string    `json:"nama"`
+	EstateID   string    `json:"estate_id"`
+	EstateNama string    `json:"estate_nama,omitempty"`
+	DivisiID   *string   `json:"divisi_id"`
+	DivisiNama string    `json:"divisi_nama,omitempty"`
+	Aktif      bool      `json:"aktif"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type TarifPanenResponse struct {
+	ID              string    `json:"id"`
+	JenisDurianID   string    `json:"jenis_durian_id"`
+	JenisDurianNama string    `json:"jenis_durian_nama,omitempty"`
+	Grade           string    `json:"grade"`
+	TarifPerBuah    float64   `json:"tarif_per_buah"`
+	TarifPerKg      float64   `json:"tarif_per_kg"`
+	BerlakuMulai    string    `json:"berlaku_mulai"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type UpahPanenLine struct {
+	JenisDurianID   string  `json:"jenis_durian_id"`
+	JenisDurianNama string  `json:"jenis_durian_nama"`
+	Grade           string  `json:"grade"`
+	TarifID         *string `json:"tarif_id"`
+	TarifPerBuah    float64 `json:"tarif_per_buah"`
+	TarifPerKg      float64 `json:"tarif_per_kg"`
+	Qty             int     `json:"qty"`
+	Berat           float64 `json:"berat"`
+	Upah            float64 `json:"upah"`
+}
+
+type UpahPanenPemanen struct {
+	PemanenID  string          `json:"pemanen_id"`
+	Kode       string          `json:"kode"`
+	Nama       string          `json:"nama"`
+	Lines      []UpahPanenLine `json:"lines"`
+	TotalQty   int             `json:"total_qty"`
+	TotalBerat float64         `json:"total_berat"`
+	TotalUpah  float64         `json:"total_upah"`
+}
+
+type UpahPanenResponse struct {
+	TanggalMulai   string             `json:"tanggal_mulai"`
+	TanggalSelesai string             `json:"tanggal_selesai"`
+	Pemanen        []UpahPanenPemanen `json:"pemanen"`
+	TotalQty       int                `json:"total_qty"`
+	TotalBerat     float64            `json:"total_berat"`
+	TotalUpah      float64            `json:"total_upah"`
+	QtyTanpaTarif  int                `json:"qty_tanpa_tarif"`
+}
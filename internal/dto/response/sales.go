@@ -12,6 +12,7 @@ type SalesResponse struct {
 	BeratTerjual float64   `json:"berat_terjual"`
 	HargaTotal   float64   `json:"harga_total"`
 	TipeJual     string    `json:"tipe_jual"`
+	MusimID      *string   `json:"musim_id,omitempty"`
 }
 
 type SalesDetailResponse struct {
@@ -42,5 +43,6 @@ func NewSalesResponse(s *domain.Penjualan) SalesResponse {
 		BeratTerjual: s.BeratTerjual,
 		HargaTotal:   s.HargaTotal,
 		TipeJual:     s.TipeJual,
+		MusimID:      s.MusimID,
 	}
 }
@@ -0,0 +1,70 @@
+package response
+
+import "time"
+
+type SesiGradingResponse struct {
+	ID              string                   `json:"id"`
+	JenisDurianID   string                   `json:"jenis_durian_id"`
+	JenisDurianNama string                   `json:"jenis_durian_nama"`
+	EstateID        *string                  `json:"estate_id,omitempty"`
+	MusimID         *string                  `json:"musim_id,omitempty"`
+	Status          string                   `json:"status"`
+	Catatan         *string                  `json:"catatan,omitempty"`
+	Lots            []SesiGradingLotResponse `json:"lots"`
+	DibukaOleh      *string                  `json:"dibuka_oleh,omitempty"`
+	DitutupOleh     *string                  `json:"ditutup_oleh,omitempty"`
+	DitutupAt       *time.Time               `json:"ditutup_at,omitempty"`
+	CreatedAt       time.Time                `json:"created_at"`
+}
+
+type SesiGradingLotResponse struct {
+	LotID       string  `json:"lot_id"`
+	Kode        string  `json:"kode"`
+	KondisiBuah string  `json:"kondisi_buah"`
+	Status      string  `json:"status"`
+	Qty         int     `json:"qty"`
+	Berat       float64 `json:"berat"`
+}
+
+type SesiGradingScanResponse struct {
+	LotID       string `json:"lot_id"`
+	LotKode     string `json:"lot_kode"`
+	KondisiBuah string `json:"kondisi_buah"`
+	LotScanResponse
+}
+
+// SesiGradingLaporan is the session report. Yield compares the graded fruit with the
+// registered fruit of the jenis the session drew from, frozen when the session closed.
+type SesiGradingLaporan struct {
+	SesiID           string                  `json:"sesi_id"`
+	Status           string                  `json:"status"`
+	Distribusi       []SesiGradingDistribusi `json:"distribusi"`
+	Grader           []SesiGradingGraderItem `json:"grader"`
+	TergradingQty    int                     `json:"tergrading_qty"`
+	TergradingBerat  float64                 `json:"tergrading_berat"`
+	TerdaftarQty     int                     `json:"terdaftar_qty"`
+	TerdaftarBerat   float64                 `json:"terdaftar_berat"`
+	YieldPersen      float64                 `json:"yield_persen"`
+	YieldBeratPersen float64                 `json:"yield_berat_persen"`
+	DihitungAt       time.Time               `json:"dihitung_at"`
+}
+
+type SesiGradingDistribusi struct {
+	KondisiBuah string  `json:"kondisi_buah"`
+	LotID       string  `json:"lot_id"`
+	LotKode     string  `json:"lot_kode"`
+	Qty         int     `json:"qty"`
+	Berat       float64 `json:"berat"`
+	PersenQty   float64 `json:"persen_qty"`
+	PersenBerat float64 `json:"persen_berat"`
+}
+
+type SesiGradingGraderItem struct {
+	GraderID  string    `json:"grader_id"`
+	Email     string    `json:"email,omitempty"`
+	Qty       int       `json:"qty"`
+	Berat     float64   `json:"berat"`
+	MulaiAt   time.Time `json:"mulai_at"`
+	SelesaiAt time.Time `json:"selesai_at"`
+	PerJam    float64   `json:"per_jam"`
+}
@@ -25,6 +25,7 @@ type ShipmentItemResponse struct {
 	Grade       string  `json:"grade"`
 	QtyAmbil    int     `json:"qty_ambil"`
 	BeratAmbil  float64 `json:"berat_ambil"`
+	Parsial     bool    `json:"parsial"`
 }
 
 type ShipmentDetailResponse struct {
@@ -58,3 +59,34 @@ func NewShipmentResponse(p *domain.Pengiriman) ShipmentResponse {
 		CreatedAt:  p.CreatedAt,
 	}
 }
+
+type ShipmentSaranResponse struct {
+	Urutan   string                  `json:"urutan"`
+	Lengkap  bool                    `json:"lengkap"`
+	Items    []ShipmentSaranItem     `json:"items"`
+	Shipment *ShipmentDetailResponse `json:"shipment,omitempty"`
+}
+
+// ShipmentSaranItem is what the proposed lots cover of one requested jenis and grade
+type ShipmentSaranItem struct {
+	JenisDurianID   string             `json:"jenis_durian_id"`
+	JenisDurianNama string             `json:"jenis_durian_nama"`
+	KondisiBuah     string             `json:"kondisi_buah"`
+	QtyDiminta      int                `json:"qty_diminta"`
+	BeratDiminta    float64            `json:"berat_diminta"`
+	Qty             int                `json:"qty"`
+	Berat           float64            `json:"berat"`
+	Lengkap         bool               `json:"lengkap"`
+	Lots            []ShipmentSaranLot `json:"lots"`
+}
+
+type ShipmentSaranLot struct {
+	LotID         string     `json:"lot_id"`
+	Kode          string     `json:"kode"`
+	QtyAmbil      int        `json:"qty_ambil"`
+	BeratAmbil    float64    `json:"berat_ambil"`
+	Parsial       bool       `json:"parsial"`
+	MulaiSimpanAt *time.Time `json:"mulai_simpan_at,omitempty"`
+	KedaluwarsaAt *time.Time `json:"kedaluwarsa_at,omitempty"`
+	StatusUmur    string     `json:"status_umur,omitempty"`
+}
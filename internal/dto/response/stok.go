@@ -0,0 +1,33 @@
+package response
+
+import "time"
+
+type StokAsOfResponse struct {
+	Tanggal    string           `json:"tanggal"`
+	Sumber     string           `json:"sumber"`
+	Items      []StokPosisiItem `json:"items"`
+	TotalLot   int              `json:"total_lot"`
+	TotalQty   int              `json:"total_qty"`
+	TotalBerat float64          `json:"total_berat"`
+}
+
+type StokPosisiItem struct {
+	LokasiID        *string `json:"lokasi_id,omitempty"`
+	LokasiNama      string  `json:"lokasi_nama,omitempty"`
+	MusimID         *string `json:"musim_id,omitempty"`
+	JenisDurianID   string  `json:"jenis_durian_id"`
+	JenisDurianNama string  `json:"jenis_durian_nama"`
+	KondisiBuah     string  `json:"kondisi_buah"`
+	Status          string  `json:"status"`
+	LotCount        int     `json:"lot_count"`
+	Qty             int     `json:"qty"`
+	Berat           float64 `json:"berat"`
+}
+
+type StokSnapshotResponse struct {
+	Tanggal    string    `json:"tanggal"`
+	JumlahItem int       `json:"jumlah_item"`
+	TotalQty   int       `json:"total_qty"`
+	TotalBerat float64   `json:"total_berat"`
+	CreatedAt  time.Time `json:"created_at"`
+}
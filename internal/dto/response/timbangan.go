@@ -0,0 +1,49 @@
+package response
+
+import "time"
+
+type StasiunTimbangResponse struct {
+	ID        string               `json:"id"`
+	Kode      string               `json:"kode"`
+	Nama      string               `json:"nama"`
+	Alamat    string               `json:"alamat"`
+	Protokol  string               `json:"protokol"`
+	Transport string               `json:"transport"`
+	MinBerat  float64              `json:"min_berat"`
+	Toleransi float64              `json:"toleransi"`
+	Sampel    int                  `json:"sampel"`
+	Aktif     bool                 `json:"aktif"`
+	Sesi      *StasiunTimbangSesi  `json:"sesi"`
+	Status    StasiunTimbangStatus `json:"status"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+type StasiunTimbangSesi struct {
+	LotID     string     `json:"lot_id"`
+	LotKode   string     `json:"lot_kode"`
+	BlokID    *string    `json:"blok_id"`
+	PohonKode *string    `json:"pohon_kode"`
+	PemanenID *string    `json:"pemanen_id"`
+	MulaiAt   *time.Time `json:"mulai_at"`
+}
+
+// StasiunTimbangStatus is the live view of the scale connection, only known to the instance running the reader
+type StasiunTimbangStatus struct {
+	Terhubung     bool       `json:"terhubung"`
+	BeratTerakhir *float64   `json:"berat_terakhir"`
+	Bergerak      bool       `json:"bergerak"`
+	DibacaAt      *time.Time `json:"dibaca_at"`
+	Error         string     `json:"error,omitempty"`
+}
+
+type PembacaanTimbangResponse struct {
+	ID        string    `json:"id"`
+	LotID     *string   `json:"lot_id"`
+	Berat     float64   `json:"berat"`
+	Raw       string    `json:"raw"`
+	Status    string    `json:"status"`
+	BuahRawID *string   `json:"buah_raw_id"`
+	KodeBuah  *string   `json:"kode_buah"`
+	Pesan     *string   `json:"pesan"`
+	CreatedAt time.Time `json:"created_at"`
+}
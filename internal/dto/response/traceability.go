@@ -3,8 +3,20 @@ package response
 import "time"
 
 type TraceLotResponse struct {
-	LotInfo LotTraceInfo     `json:"lot_info"`
-	Fruits  []FruitTraceInfo `json:"fruits"`
+	LotInfo LotTraceInfo        `json:"lot_info"`
+	Fruits  []FruitTraceInfo    `json:"fruits"`
+	Asal    []LotGenealogiTrace `json:"asal"`
+	Turunan []LotGenealogiTrace `json:"turunan"`
+}
+
+// LotGenealogiTrace is a split or merge that fed the traced lot (asal) or took fruit from it (turunan)
+type LotGenealogiTrace struct {
+	LotID     string    `json:"lot_id"`
+	Kode      string    `json:"kode"`
+	Tipe      string    `json:"tipe"`
+	Qty       int       `json:"qty"`
+	Berat     float64   `json:"berat"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type LotTraceInfo struct {
@@ -44,19 +56,24 @@ type FruitDetailInfo struct {
 	JenisDurian string          `json:"jenis_durian"`
 	TglPanen    string          `json:"tgl_panen"`
 	LokasiPanen LokasiTraceInfo `json:"lokasi_panen"`
+	Berat       float64         `json:"berat"`
+	Kualitas    BuahKualitas    `json:"kualitas"`
 }
 
 type FruitJourney struct {
-	Lot      *LotJourneyInfo      `json:"lot,omitempty"`
-	Shipment *ShipmentJourneyInfo `json:"shipment,omitempty"`
-	Sales    *SalesJourneyInfo    `json:"sales,omitempty"`
+	Lot        *LotJourneyInfo      `json:"lot,omitempty"`
+	RiwayatLot []LotJourneyInfo     `json:"riwayat_lot,omitempty"`
+	Shipment   *ShipmentJourneyInfo `json:"shipment,omitempty"`
+	Sales      *SalesJourneyInfo    `json:"sales,omitempty"`
 }
 
 type LotJourneyInfo struct {
 	ID          string    `json:"id"`
+	Kode        string    `json:"kode"`
 	KondisiBuah string    `json:"kondisi_buah"`
 	JoinedAt    time.Time `json:"joined_at"`
 	Status      string    `json:"status"`
+	Melalui     string    `json:"melalui,omitempty"`
 }
 
 type ShipmentJourneyInfo struct {
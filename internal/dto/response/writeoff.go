@@ -0,0 +1,41 @@
+package response
+
+import "time"
+
+type WriteOffResponse struct {
+	ID               string     `json:"id"`
+	LotID            string     `json:"lot_id"`
+	LotKode          string     `json:"lot_kode"`
+	LokasiID         *string    `json:"lokasi_id,omitempty"`
+	LokasiNama       string     `json:"lokasi_nama,omitempty"`
+	JenisDurianID    string     `json:"jenis_durian_id"`
+	JenisDurianNama  string     `json:"jenis_durian_nama"`
+	AlasanKode       string     `json:"alasan_kode"`
+	Qty              int        `json:"qty"`
+	Berat            float64    `json:"berat"`
+	Catatan          *string    `json:"catatan,omitempty"`
+	Status           string     `json:"status"`
+	DiajukanOleh     *string    `json:"diajukan_oleh,omitempty"`
+	Email            string     `json:"email,omitempty"`
+	DiputuskanOleh   *string    `json:"diputuskan_oleh,omitempty"`
+	DiputuskanAt     *time.Time `json:"diputuskan_at,omitempty"`
+	CatatanKeputusan *string    `json:"catatan_keputusan,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+type WriteOffLaporanResponse struct {
+	Items      []WriteOffLaporanItem `json:"items"`
+	TotalQty   int                   `json:"total_qty"`
+	TotalBerat float64               `json:"total_berat"`
+}
+
+type WriteOffLaporanItem struct {
+	LokasiID        *string `json:"lokasi_id,omitempty"`
+	LokasiNama      string  `json:"lokasi_nama,omitempty"`
+	JenisDurianID   string  `json:"jenis_durian_id"`
+	JenisDurianNama string  `json:"jenis_durian_nama"`
+	AlasanKode      string  `json:"alasan_kode"`
+	Jumlah          int     `json:"jumlah"`
+	Qty             int     `json:"qty"`
+	Berat           float64 `json:"berat"`
+}
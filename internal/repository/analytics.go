@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+)
+
+// YieldFilter scopes a yield query. StartDate and EndDate are inclusive YYYY-MM-DD harvest dates.
+type YieldFilter struct {
+	Level         string
+	StartDate     string
+	EndDate       string
+	EstateID      string
+	DivisiID      string
+	BlokID        string
+	JenisDurianID string
+	MusimID       string
+}
+
+type AnalyticsRepository interface {
+	GetYield(ctx context.Context, filter YieldFilter) ([]domain.YieldRow, error)
+}
+
+type analyticsRepository struct {
+	db *database.Database
+}
+
+func NewAnalyticsRepository(db *database.Database) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// yieldUnits selects the unit identity for each level, and the real columns to group it by
+var yieldUnits = map[string]struct {
+	columns []string
+	group   string
+}{
+	constants.YieldLevelPohon: {
+		columns: []string{
+			"pohon.id AS unit_id", "pohon.kode AS unit_kode", "pohon.nama AS unit_nama",
+			"estate.kode AS estate_kode", "divisi.kode AS divisi_kode", "blok.kode AS blok_kode",
+		},
+		group: "pohon.id, estate.kode, divisi.kode, blok.kode",
+	},
+	constants.YieldLevelBlok: {
+		columns: []string{
+			"blok.id AS unit_id", "blok.kode AS unit_kode", "blok.nama_blok AS unit_nama",
+			"estate.kode AS estate_kode", "divisi.kode AS divisi_kode", "'' AS blok_kode",
+		},
+		group: "blok.id, estate.kode, divisi.kode",
+	},
+	constants.YieldLevelDivisi: {
+		columns: []string{
+			"divisi.id AS unit_id", "divisi.kode AS unit_kode", "divisi.nama AS unit_nama",
+			"estate.kode AS estate_kode", "'' AS divisi_kode", "'' AS blok_kode",
+		},
+		group: "divisi.id, estate.kode",
+	},
+	constants.YieldLevelEstate: {
+		columns: []string{
+			"estate.id AS unit_id", "estate.kode AS unit_kode", "estate.nama AS unit_nama",
+			"'' AS estate_kode", "'' AS divisi_kode", "'' AS blok_kode",
+		},
+		group: "estate.id",
+	},
+}
+
+// GetYield walks the same pohon -> blok -> divisi -> estate joins as GetPohonWithFullHierarchy and
+// left joins the period's fruits, so units without any harvest are returned with zero qty.
+func (r *analyticsRepository) GetYield(ctx context.Context, filter YieldFilter) ([]domain.YieldRow, error) {
+	var rows []domain.YieldRow
+
+	query := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("pohon").
+		Join("JOIN blok ON blok.id = pohon.blok_id AND blok.deleted_at IS NULL").
+		Join("JOIN divisi ON divisi.id = blok.divisi_id AND divisi.deleted_at IS NULL").
+		Join("JOIN estate ON estate.id = divisi.estate_id AND estate.deleted_at IS NULL").
+		Join("LEFT JOIN tb_buah_raw AS br").
+		JoinOn("br.pohon_panen = pohon.id").
+		JoinOn("br.deleted_at IS NULL").
+		JoinOn("br.tgl_panen BETWEEN ? AND ?", filter.StartDate, filter.EndDate)
+	if filter.JenisDurianID != "" {
+		query.JoinOn("br.jenis_durian = ?", filter.JenisDurianID)
+	}
+	if filter.MusimID != "" {
+		query.JoinOn("br.musim_id = ?", filter.MusimID)
+	}
+	query.Join("LEFT JOIN tb_stok_lot AS stok_lot ON stok_lot.id = br.lot_id")
+
+	unit := yieldUnits[filter.Level]
+	for _, col := range unit.columns {
+		query.ColumnExpr(col)
+	}
+	query.ColumnExpr("COALESCE(stok_lot.kondisi_buah, '') AS grade").
+		ColumnExpr("COUNT(br.id) AS qty").
+		ColumnExpr("COUNT(br.id) FILTER (WHERE br.berat > 0) AS qty_ditimbang").
+		ColumnExpr("COALESCE(SUM(br.berat), 0) AS berat").
+		Where("pohon.deleted_at IS NULL").
+		GroupExpr(unit.group).
+		GroupExpr("stok_lot.kondisi_buah")
+
+	if filter.EstateID != "" {
+		query.Where("estate.id = ?", filter.EstateID)
+	}
+	if filter.DivisiID != "" {
+		query.Where("divisi.id = ?", filter.DivisiID)
+	}
+	if filter.BlokID != "" {
+		query.Where("blok.id = ?", filter.BlokID)
+	}
+
+	err := query.Scan(ctx, &rows)
+	return rows, err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"fmt"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id string) (*domain.Attachment, error)
+	GetByEntity(ctx context.Context, entityType, entityID string) ([]domain.Attachment, error)
+	Delete(ctx context.Context, id string) error
+	EntityExists(ctx context.Context, entityType, entityID string) (bool, error)
+}
+
+type attachmentRepository struct {
+	db *database.Database
+}
+
+func NewAttachmentRepository(db *database.Database) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// attachmentEntityTables maps an entity type to the table holding it
+var attachmentEntityTables = map[string]string{
+	constants.AttachmentEntityBuah:     "tb_buah_raw",
+	constants.AttachmentEntityLot:      "tb_stok_lot",
+	constants.AttachmentEntityShipment: "tb_pengiriman",
+	constants.AttachmentEntitySales:    "tb_penjualan",
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(attachment).Exec(ctx)
+	return err
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	attachment := new(domain.Attachment)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(attachment).
+		Relation("Uploader").
+		Where("att.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return attachment, err
+}
+
+func (r *attachmentRepository) GetByEntity(ctx context.Context, entityType, entityID string) ([]domain.Attachment, error) {
+	var list []domain.Attachment
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Uploader").
+		Where("att.entity_type = ?", entityType).
+		Where("att.entity_id = ?", entityID).
+		Order("att.created_at DESC").
+		Scan(ctx)
+	return list, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().
+		Model((*domain.Attachment)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *attachmentRepository) EntityExists(ctx context.Context, entityType, entityID string) (bool, error) {
+	table, ok := attachmentEntityTables[entityType]
+	if !ok {
+		return false, fmt.Errorf("entity_type tidak dikenal: %s", entityType)
+	}
+
+	return r.db.InitQuery(ctx).NewSelect().
+		TableExpr(table).
+		Where("id = ?", entityID).
+		Where("deleted_at IS NULL").
+		Exists(ctx)
+}
@@ -5,44 +5,73 @@ import (
 	"durich-be/internal/domain"
 	"durich-be/pkg/database"
 	"fmt"
+	"strings"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
 type BuahRawRepository interface {
-	BulkCreate(ctx context.Context, data []domain.BuahRaw) error
-	Create(ctx context.Context, data *domain.BuahRaw) error
+	BulkCreate(ctx context.Context, data []domain.BuahRaw, specs []SequenceSpec) error
+	Create(ctx context.Context, data *domain.BuahRaw, spec SequenceSpec) error
 	GetLastKodeByJenis(ctx context.Context, kodeJenis string) (string, error)
 	GetJenisDurianByID(ctx context.Context, id string) (domain.JenisDurian, error)
 	GetJenisDurianByIDs(ctx context.Context, ids []string) (map[string]domain.JenisDurian, error)
+	GetJenisDurianByKodes(ctx context.Context, kodes []string) (map[string]domain.JenisDurian, error)
 	GetBlokFullDetail(ctx context.Context, blokID string) (domain.Blok, domain.Divisi, domain.Estate, domain.Company, error)
 	GetList(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]domain.BuahRaw, int, error)
 	GetUnsorted(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]domain.BuahRaw, int, error)
 	GetByID(ctx context.Context, id string) (domain.BuahRaw, error)
+	GetByIDs(ctx context.Context, ids []string) ([]domain.BuahRaw, error)
+	GetByIDsUnscoped(ctx context.Context, ids []string) ([]domain.BuahRaw, error)
 	Update(ctx context.Context, data *domain.BuahRaw) error
 	Delete(ctx context.Context, id string) error
 	GetLotDetails(ctx context.Context, lotID string) ([]domain.BuahRaw, error)
-	GetNextSequenceWithLock(ctx context.Context, prefix string, tglPanen string) (int, error)
 	GetPohonWithFullHierarchy(ctx context.Context, pohonID string) (*domain.Pohon, error)
+	GetPohonByKodesWithHierarchy(ctx context.Context, keys []PohonKey) (map[PohonKey]*domain.Pohon, error)
+}
+
+// PohonKey names a tree by its upper-cased block code and its own code, tree codes repeat
+// across blocks
+type PohonKey struct {
+	BlokKode  string
+	PohonKode string
 }
 
 type buahRawRepository struct {
-	db *database.Database
+	db           *database.Database
+	sequenceRepo SequenceRepository
 }
 
-func NewBuahRawRepository(db *database.Database) BuahRawRepository {
-	return &buahRawRepository{db: db}
+func NewBuahRawRepository(db *database.Database, sequenceRepo SequenceRepository) BuahRawRepository {
+	return &buahRawRepository{
+		db:           db,
+		sequenceRepo: sequenceRepo,
+	}
 }
 
-func (r *buahRawRepository) BulkCreate(ctx context.Context, data []domain.BuahRaw) error {
+// BulkCreate assigns kode_buah to data[i] from specs[i] and inserts everything in one transaction
+func (r *buahRawRepository) BulkCreate(ctx context.Context, data []domain.BuahRaw, specs []SequenceSpec) error {
 	const batchSize = 1000
 
+	if len(data) != len(specs) {
+		return fmt.Errorf("jumlah data dan sequence tidak sama")
+	}
+
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, specs)
+	if err != nil {
+		return err
+	}
+	for i := range data {
+		data[i].KodeBuah = kodes[i]
+	}
+
 	for i := 0; i < len(data); i += batchSize {
 		end := i + batchSize
 		if end > len(data) {
@@ -147,6 +176,25 @@ func (r *buahRawRepository) applyFilters(q *bun.SelectQuery, filter map[string]i
 			}
 		}
 	}
+	if val, ok := filter["tingkat_kematangan"].(string); ok && val != "" {
+		q = q.Where("buah_raw.tingkat_kematangan = ?", val)
+	}
+	if val, ok := filter["metode_panen"].(string); ok && val != "" {
+		q = q.Where("buah_raw.metode_panen = ?", val)
+	}
+	if val, ok := filter["cacat"].([]string); ok && len(val) > 0 {
+		// Any of the given defects
+		q = q.Where("buah_raw.cacat && ?", pgdialect.Array(val))
+	}
+	if val, ok := filter["tanpa_cacat"].(bool); ok && val {
+		q = q.Where("cardinality(buah_raw.cacat) = 0")
+	}
+	if val, ok := filter["skor_aroma_min"].(int); ok {
+		q = q.Where("buah_raw.skor_aroma >= ?", val)
+	}
+	if val, ok := filter["skor_aroma_max"].(int); ok {
+		q = q.Where("buah_raw.skor_aroma <= ?", val)
+	}
 	if val, ok := filter["blok_panen_id"].(string); ok && val != "" {
 		q = q.Where("pohon.blok_id = ?", val)
 	}
@@ -175,6 +223,41 @@ func (r *buahRawRepository) GetByID(ctx context.Context, id string) (domain.Buah
 	return data, err
 }
 
+func (r *buahRawRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.BuahRaw, error) {
+	var list []domain.BuahRaw
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	err := r.db.InitQuery(ctx).NewSelect().Model(&list).
+		Relation("JenisDurianDetail").
+		Relation("PohonPanenDetail").
+		Relation("PohonPanenDetail.Blok").
+		Relation("PohonPanenDetail.Blok.Divisi").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
+		Relation("Lot").
+		Where("buah_raw.id IN (?)", bun.In(ids)).
+		Where("buah_raw.deleted_at IS NULL").
+		Order("buah_raw.kode_buah ASC").
+		Scan(ctx)
+	return list, err
+}
+
+// GetByIDsUnscoped also returns soft-deleted rows so that replayed sync items are never re-inserted
+func (r *buahRawRepository) GetByIDsUnscoped(ctx context.Context, ids []string) ([]domain.BuahRaw, error) {
+	var list []domain.BuahRaw
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Where("buah_raw.id IN (?)", bun.In(ids)).
+		Scan(ctx)
+	return list, err
+}
+
 func (r *buahRawRepository) Update(ctx context.Context, data *domain.BuahRaw) error {
 	_, err := r.db.InitQuery(ctx).NewUpdate().Model(data).WherePK().Exec(ctx)
 	return err
@@ -189,9 +272,25 @@ func (r *buahRawRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (r *buahRawRepository) Create(ctx context.Context, data *domain.BuahRaw) error {
-	_, err := r.db.InitQuery(ctx).NewInsert().Model(data).Exec(ctx)
-	return err
+func (r *buahRawRepository) Create(ctx context.Context, data *domain.BuahRaw, spec SequenceSpec) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return err
+	}
+	data.KodeBuah = kodes[0]
+
+	_, err = tx.NewInsert().Model(data).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *buahRawRepository) GetLastKodeByJenis(ctx context.Context, kodeJenis string) (string, error) {
@@ -210,39 +309,6 @@ func (r *buahRawRepository) GetLastKodeByJenis(ctx context.Context, kodeJenis st
 	return buah.KodeBuah, nil
 }
 
-func (r *buahRawRepository) GetNextSequenceWithLock(ctx context.Context, prefix string, tglPanen string) (int, error) {
-	tx, err := r.db.InitQuery(ctx).Begin()
-	if err != nil {
-		return 0, err
-	}
-	defer tx.Rollback()
-
-	var buah domain.BuahRaw
-	err = tx.NewSelect().
-		Model(&buah).
-		Column("kode_buah").
-		Where("kode_buah LIKE ?", fmt.Sprintf("%s-F%%", prefix)).
-		Order("kode_buah DESC").
-		Limit(1).
-		For("UPDATE").
-		Scan(ctx)
-
-	sequence := 1
-	if err == nil && buah.KodeBuah != "" {
-		var lastSeq int
-		_, err = fmt.Sscanf(buah.KodeBuah, prefix+"-F%d", &lastSeq)
-		if err == nil {
-			sequence = lastSeq + 1
-		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, err
-	}
-
-	return sequence, nil
-}
-
 func (r *buahRawRepository) GetJenisDurianByID(ctx context.Context, id string) (domain.JenisDurian, error) {
 	var jenis domain.JenisDurian
 	err := r.db.InitQuery(ctx).NewSelect().
@@ -270,6 +336,26 @@ func (r *buahRawRepository) GetJenisDurianByIDs(ctx context.Context, ids []strin
 	return result, nil
 }
 
+// GetJenisDurianByKodes is keyed by upper-cased kode
+func (r *buahRawRepository) GetJenisDurianByKodes(ctx context.Context, kodes []string) (map[string]domain.JenisDurian, error) {
+	var jenisList []domain.JenisDurian
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&jenisList).
+		Where("UPPER(kode) IN (?)", bun.In(kodes)).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]domain.JenisDurian, len(jenisList))
+	for _, j := range jenisList {
+		result[strings.ToUpper(j.Kode)] = j
+	}
+	return result, nil
+}
+
 func (r *buahRawRepository) GetBlokFullDetail(ctx context.Context, blokID string) (domain.Blok, domain.Divisi, domain.Estate, domain.Company, error) {
 	var blok domain.Blok
 	var divisi domain.Divisi
@@ -320,6 +406,7 @@ func (r *buahRawRepository) GetLotDetails(ctx context.Context, lotID string) ([]
 		Relation("PohonPanenDetail.Blok.Divisi.Estate").
 		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
 		Where("buah_raw.lot_id = ?", lotID).
+		Where("buah_raw.writeoff_id IS NULL").
 		Where("buah_raw.deleted_at IS NULL").
 		Scan(ctx)
 
@@ -343,3 +430,44 @@ func (r *buahRawRepository) GetPohonWithFullHierarchy(ctx context.Context, pohon
 
 	return &pohon, nil
 }
+
+// GetPohonByKodesWithHierarchy finds the trees of keys. A key matching more than one tree maps
+// to nil, it cannot tell them apart.
+func (r *buahRawRepository) GetPohonByKodesWithHierarchy(ctx context.Context, keys []PohonKey) (map[PohonKey]*domain.Pohon, error) {
+	bloks := make([]string, 0, len(keys))
+	pohons := make([]string, 0, len(keys))
+	for _, key := range keys {
+		bloks = append(bloks, key.BlokKode)
+		pohons = append(pohons, key.PohonKode)
+	}
+
+	var pohonList []domain.Pohon
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&pohonList).
+		Relation("Blok").
+		Relation("Blok.Divisi").
+		Relation("Blok.Divisi.Estate").
+		Relation("Blok.Divisi.Estate.Company").
+		Where("UPPER(pohon.kode) IN (?)", bun.In(pohons)).
+		Where("UPPER(blok.kode) IN (?)", bun.In(bloks)).
+		Where("pohon.deleted_at IS NULL").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[PohonKey]*domain.Pohon, len(pohonList))
+	for i := range pohonList {
+		key := PohonKey{
+			BlokKode:  strings.ToUpper(pohonList[i].Blok.Kode),
+			PohonKode: strings.ToUpper(pohonList[i].Kode),
+		}
+		if _, found := result[key]; found {
+			result[key] = nil
+			continue
+		}
+		result[key] = &pohonList[i]
+	}
+	return result, nil
+}
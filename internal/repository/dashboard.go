@@ -4,13 +4,19 @@ import (
 	"context"
 	"durich-be/internal/dto/response"
 	"durich-be/pkg/database"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/uptrace/bun"
 )
 
 type DashboardRepository interface {
-	GetStokDashboard(ctx context.Context, dateFrom, dateTo time.Time) (*response.DashboardStokResponse, error)
-	GetSalesDashboard(ctx context.Context, dateFrom, dateTo time.Time) (*response.DashboardSalesResponse, error)
+	GetStokDashboard(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (*response.DashboardStokResponse, error)
+	GetStokSummaryAsOf(ctx context.Context, before time.Time, musimID string) (response.StokSummary, error)
+	GetThroughput(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (response.ThroughputSummary, []response.ThroughputTrendItem, error)
+	GetSalesDashboard(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (*response.DashboardSalesResponse, error)
 	GetWarehouseData(ctx context.Context, locationID string) (*response.WarehouseDataResponse, error)
 }
 
@@ -22,7 +28,17 @@ func NewDashboardRepository(db *database.Database) DashboardRepository {
 	return &dashboardRepository{db: db}
 }
 
-func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, dateTo time.Time) (*response.DashboardStokResponse, error) {
+// filterMusim limits a query to one harvest season through the given musim_id column, if set
+func filterMusim(column, musimID string) func(*bun.SelectQuery) *bun.SelectQuery {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		if musimID == "" {
+			return q
+		}
+		return q.Where(column+" = ?", musimID)
+	}
+}
+
+func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (*response.DashboardStokResponse, error) {
 	var (
 		summary     response.StokSummary
 		stokByJenis []response.StokByJenis
@@ -36,7 +52,7 @@ func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, da
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		s, err := r.getStokSummary(ctx)
+		s, err := r.getStokSummary(ctx, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -47,7 +63,7 @@ func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, da
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		j, err := r.getStokByJenis(ctx)
+		j, err := r.getStokByJenis(ctx, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -58,7 +74,7 @@ func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, da
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		t, err := r.getThroughputSummary(ctx, dateFrom, dateTo)
+		t, err := r.getThroughputSummary(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -69,7 +85,7 @@ func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, da
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		tr, err := r.getThroughputTrend(ctx, dateFrom, dateTo)
+		tr, err := r.getThroughputTrend(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -94,7 +110,68 @@ func (r *dashboardRepository) GetStokDashboard(ctx context.Context, dateFrom, da
 	}, nil
 }
 
-func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.StokSummary, error) {
+// GetStokSummaryAsOf counts the fruits and empty lots as they were before a moment. The lot
+// counts of the stock itself come from the stock as of that moment. A fruit counts as sorted
+// once it sat in its current lot, which misses fruits that were in a lot and released later.
+func (r *dashboardRepository) GetStokSummaryAsOf(ctx context.Context, before time.Time, musimID string) (response.StokSummary, error) {
+	var summary response.StokSummary
+
+	count, err := r.db.NewSelect().
+		TableExpr("tb_buah_raw AS br").
+		Where("br.deleted_at IS NULL").
+		Where("br.created_at < ?", before).
+		Apply(filterMusim("br.musim_id", musimID)).
+		Count(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.TotalBuahMentah = count
+
+	count, err = r.db.NewSelect().
+		TableExpr("tb_buah_raw AS br").
+		Join("LEFT JOIN tb_stok_lot AS sl ON sl.id = br.lot_id").
+		Where("br.deleted_at IS NULL").
+		Where("br.created_at < ?", before).
+		Where("(br.lot_id IS NULL OR sl.created_at >= ? OR EXISTS (SELECT 1 FROM tb_lot_scan AS ls WHERE ls.buah_raw_id = br.id AND ls.lot_id = br.lot_id AND ls.created_at >= ?))", before, before).
+		Apply(filterMusim("br.musim_id", musimID)).
+		Count(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.BuahBelumDisortir = count
+
+	status := r.db.NewSelect().
+		TableExpr("tb_lot_status_riwayat AS rw").
+		ColumnExpr("rw.status_ke").
+		Where("rw.lot_id = sl.id").
+		Where("rw.created_at < ?", before).
+		OrderExpr("rw.created_at DESC").
+		Limit(1)
+	count, err = r.db.NewSelect().
+		TableExpr("tb_stok_lot AS sl").
+		Where("sl.deleted_at IS NULL").
+		Where("(?) = ?", status, "EMPTY").
+		Apply(filterMusim("sl.musim_id", musimID)).
+		Count(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.LotEmpty = count
+
+	return summary, nil
+}
+
+// GetThroughput returns the throughput part of the stok dashboard
+func (r *dashboardRepository) GetThroughput(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (response.ThroughputSummary, []response.ThroughputTrendItem, error) {
+	throughput, err := r.getThroughputSummary(ctx, dateFrom, dateTo, musimID)
+	if err != nil {
+		return throughput, nil, err
+	}
+	trend, err := r.getThroughputTrend(ctx, dateFrom, dateTo, musimID)
+	return throughput, trend, err
+}
+
+func (r *dashboardRepository) getStokSummary(ctx context.Context, musimID string) (response.StokSummary, error) {
 	var summary response.StokSummary
 
 	count, err := r.db.NewSelect().
@@ -102,6 +179,7 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 		ColumnExpr("COUNT(*) as total").
 		Table("tb_buah_raw").
 		Where("deleted_at IS NULL").
+		Apply(filterMusim("musim_id", musimID)).
 		Count(ctx)
 	if err != nil {
 		return summary, err
@@ -112,6 +190,7 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 		ColumnExpr("COUNT(*) as total").
 		Table("tb_buah_raw").
 		Where("deleted_at IS NULL AND is_sorted = ?", false).
+		Apply(filterMusim("musim_id", musimID)).
 		Count(ctx)
 	if err != nil {
 		return summary, err
@@ -122,6 +201,7 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 		ColumnExpr("COUNT(*) as total").
 		Table("tb_stok_lot").
 		Where("deleted_at IS NULL AND status != ?", "EMPTY").
+		Apply(filterMusim("musim_id", musimID)).
 		Count(ctx)
 	if err != nil {
 		return summary, err
@@ -132,6 +212,7 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 		ColumnExpr("COUNT(*) as total").
 		Table("tb_stok_lot").
 		Where("deleted_at IS NULL AND status = ?", "READY").
+		Apply(filterMusim("musim_id", musimID)).
 		Count(ctx)
 	if err != nil {
 		return summary, err
@@ -142,6 +223,7 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 		ColumnExpr("COUNT(*) as total").
 		Table("tb_stok_lot").
 		Where("deleted_at IS NULL AND status = ?", "EMPTY").
+		Apply(filterMusim("musim_id", musimID)).
 		Count(ctx)
 	if err != nil {
 		return summary, err
@@ -151,9 +233,10 @@ func (r *dashboardRepository) getStokSummary(ctx context.Context) (response.Stok
 	return summary, nil
 }
 
-func (r *dashboardRepository) getStokByJenis(ctx context.Context) ([]response.StokByJenis, error) {
+func (r *dashboardRepository) getStokByJenis(ctx context.Context, musimID string) ([]response.StokByJenis, error) {
 	type queryResult struct {
 		JenisDurian string  `bun:"nama_jenis"`
+		KondisiBuah string  `bun:"kondisi_buah"`
 		TotalQty    int     `bun:"total_qty"`
 		TotalBerat  float64 `bun:"total_berat"`
 		LotCount    int     `bun:"lot_count"`
@@ -161,37 +244,56 @@ func (r *dashboardRepository) getStokByJenis(ctx context.Context) ([]response.St
 
 	var results []queryResult
 
+	// Grouped per grade too, so regraded lots show up in the grade distribution
 	err := r.db.NewSelect().
 		ColumnExpr("jd.nama_jenis").
+		ColumnExpr("sl.kondisi_buah").
 		ColumnExpr("SUM(sl.qty_sisa) as total_qty").
 		ColumnExpr("SUM(sl.berat_sisa) as total_berat").
 		ColumnExpr("COUNT(sl.id) as lot_count").
 		TableExpr("tb_stok_lot AS sl").
-		Join("LEFT JOIN jenis_durian AS jd ON sl.jenis_durian = jd.id").
+		Join("LEFT JOIN jenis_durian AS jd ON sl.jenis_durian_id = jd.id").
 		Where("sl.deleted_at IS NULL").
 		Where("sl.status != ?", "EMPTY").
-		Group("jd.nama_jenis").
-		Order("total_qty DESC").
+		Apply(filterMusim("sl.musim_id", musimID)).
+		Group("jd.nama_jenis", "sl.kondisi_buah").
 		Scan(ctx, &results)
 	if err != nil {
 		return nil, err
 	}
 
 	stokByJenis := make([]response.StokByJenis, 0, len(results))
+	index := make(map[string]int)
 	for _, r := range results {
-		stokByJenis = append(stokByJenis, response.StokByJenis{
-			JenisDurian:          r.JenisDurian,
-			TotalQty:             r.TotalQty,
-			TotalBerat:           r.TotalBerat,
-			LotCount:             r.LotCount,
-			AvgGradeDistribution: map[string]string{},
-		})
+		i, ok := index[r.JenisDurian]
+		if !ok {
+			i = len(stokByJenis)
+			index[r.JenisDurian] = i
+			stokByJenis = append(stokByJenis, response.StokByJenis{
+				JenisDurian:          r.JenisDurian,
+				AvgGradeDistribution: map[string]string{},
+			})
+		}
+		stokByJenis[i].TotalQty += r.TotalQty
+		stokByJenis[i].TotalBerat += r.TotalBerat
+		stokByJenis[i].LotCount += r.LotCount
+	}
+
+	for _, r := range results {
+		s := &stokByJenis[index[r.JenisDurian]]
+		if s.TotalQty > 0 {
+			s.AvgGradeDistribution[r.KondisiBuah] = fmt.Sprintf("%.1f%%", float64(r.TotalQty)*100/float64(s.TotalQty))
+		}
 	}
 
+	sort.SliceStable(stokByJenis, func(i, j int) bool {
+		return stokByJenis[i].TotalQty > stokByJenis[j].TotalQty
+	})
+
 	return stokByJenis, nil
 }
 
-func (r *dashboardRepository) getThroughputSummary(ctx context.Context, dateFrom, dateTo time.Time) (response.ThroughputSummary, error) {
+func (r *dashboardRepository) getThroughputSummary(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (response.ThroughputSummary, error) {
 	days := int(dateTo.Sub(dateFrom).Hours()/24) + 1
 	if days <= 0 {
 		days = 1
@@ -209,20 +311,26 @@ func (r *dashboardRepository) getThroughputSummary(ctx context.Context, dateFrom
 		ColumnExpr("COUNT(br.id)").
 		TableExpr("tb_buah_raw AS br").
 		Where("br.created_at BETWEEN ? AND ?", dateFrom, dateTo).
-		Where("br.deleted_at IS NULL")
+		Where("br.deleted_at IS NULL").
+		Apply(filterMusim("br.musim_id", musimID))
 
 	subquery2 := r.db.NewSelect().
 		ColumnExpr("COUNT(sl.id)").
 		TableExpr("tb_stok_lot AS sl").
 		Where("sl.updated_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("sl.status = ?", "READY").
-		Where("sl.deleted_at IS NULL")
+		Where("sl.deleted_at IS NULL").
+		Apply(filterMusim("sl.musim_id", musimID))
 
 	subquery3 := r.db.NewSelect().
 		ColumnExpr("COUNT(p.id)").
 		TableExpr("tb_pengiriman AS p").
 		Where("p.created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("p.deleted_at IS NULL")
+	if musimID != "" {
+		// A shipment belongs to the season of the lots it carries
+		subquery3 = subquery3.Where("EXISTS (SELECT 1 FROM tb_pengiriman_detail AS pd JOIN tb_stok_lot AS sl ON sl.id = pd.lot_sumber_id WHERE pd.pengiriman_id = p.id AND sl.musim_id = ?)", musimID)
+	}
 
 	err := r.db.NewSelect().
 		ColumnExpr("(?) as buah_masuk", subquery1).
@@ -241,7 +349,7 @@ func (r *dashboardRepository) getThroughputSummary(ctx context.Context, dateFrom
 	}, nil
 }
 
-func (r *dashboardRepository) getThroughputTrend(ctx context.Context, dateFrom, dateTo time.Time) ([]response.ThroughputTrendItem, error) {
+func (r *dashboardRepository) getThroughputTrend(ctx context.Context, dateFrom, dateTo time.Time, musimID string) ([]response.ThroughputTrendItem, error) {
 	type queryResult struct {
 		Tanggal      string `bun:"tanggal"`
 		BuahMasuk    int    `bun:"buah_masuk"`
@@ -259,6 +367,7 @@ func (r *dashboardRepository) getThroughputTrend(ctx context.Context, dateFrom,
 		Table("tb_buah_raw").
 		Where("created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("deleted_at IS NULL").
+		Apply(filterMusim("musim_id", musimID)).
 		GroupExpr("TO_CHAR(created_at, 'YYYY-MM-DD')").
 		Order("tanggal DESC").
 		Limit(7).
@@ -281,7 +390,7 @@ func (r *dashboardRepository) getThroughputTrend(ctx context.Context, dateFrom,
 	return trends, nil
 }
 
-func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, dateTo time.Time) (*response.DashboardSalesResponse, error) {
+func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (*response.DashboardSalesResponse, error) {
 	var (
 		summary       response.SalesSummary
 		breakdownJens []response.SalesBreakdownJenis
@@ -296,7 +405,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		s, err := r.getSalesSummary(ctx, dateFrom, dateTo)
+		s, err := r.getSalesSummary(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -307,7 +416,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		j, err := r.getSalesBreakdownJenis(ctx, dateFrom, dateTo)
+		j, err := r.getSalesBreakdownJenis(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -318,7 +427,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		t, err := r.getSalesBreakdownTipe(ctx, dateFrom, dateTo)
+		t, err := r.getSalesBreakdownTipe(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -329,7 +438,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		h, err := r.getSalesTrendHarga(ctx, dateFrom, dateTo)
+		h, err := r.getSalesTrendHarga(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -340,7 +449,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		b, err := r.getSalesTopBuyers(ctx, dateFrom, dateTo)
+		b, err := r.getSalesTopBuyers(ctx, dateFrom, dateTo, musimID)
 		if err != nil {
 			errC <- err
 			return
@@ -366,7 +475,7 @@ func (r *dashboardRepository) GetSalesDashboard(ctx context.Context, dateFrom, d
 	}, nil
 }
 
-func (r *dashboardRepository) getSalesSummary(ctx context.Context, dateFrom, dateTo time.Time) (response.SalesSummary, error) {
+func (r *dashboardRepository) getSalesSummary(ctx context.Context, dateFrom, dateTo time.Time, musimID string) (response.SalesSummary, error) {
 	type queryResult struct {
 		TotalOmzet        float64 `bun:"total_omzet"`
 		TotalTransaksi    int     `bun:"total_transaksi"`
@@ -382,6 +491,7 @@ func (r *dashboardRepository) getSalesSummary(ctx context.Context, dateFrom, dat
 		Table("tb_penjualan").
 		Where("created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("deleted_at IS NULL").
+		Apply(filterMusim("musim_id", musimID)).
 		Scan(ctx, &result)
 	if err != nil {
 		return response.SalesSummary{}, err
@@ -401,7 +511,7 @@ func (r *dashboardRepository) getSalesSummary(ctx context.Context, dateFrom, dat
 	}, nil
 }
 
-func (r *dashboardRepository) getSalesBreakdownJenis(ctx context.Context, dateFrom, dateTo time.Time) ([]response.SalesBreakdownJenis, error) {
+func (r *dashboardRepository) getSalesBreakdownJenis(ctx context.Context, dateFrom, dateTo time.Time, musimID string) ([]response.SalesBreakdownJenis, error) {
 	type queryResult struct {
 		JenisDurian    string  `bun:"jenis_durian"`
 		Omzet          float64 `bun:"omzet"`
@@ -423,6 +533,7 @@ func (r *dashboardRepository) getSalesBreakdownJenis(ctx context.Context, dateFr
 		Join("LEFT JOIN jenis_durian AS jd ON sl.jenis_durian = jd.id").
 		Where("p.created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("p.deleted_at IS NULL").
+		Apply(filterMusim("p.musim_id", musimID)).
 		Group("jd.nama_jenis").
 		Order("omzet DESC").
 		Scan(ctx, &results)
@@ -450,7 +561,7 @@ func (r *dashboardRepository) getSalesBreakdownJenis(ctx context.Context, dateFr
 	return breakdown, nil
 }
 
-func (r *dashboardRepository) getSalesBreakdownTipe(ctx context.Context, dateFrom, dateTo time.Time) ([]response.SalesBreakdownTipe, error) {
+func (r *dashboardRepository) getSalesBreakdownTipe(ctx context.Context, dateFrom, dateTo time.Time, musimID string) ([]response.SalesBreakdownTipe, error) {
 	type queryResult struct {
 		TipeJual       string  `bun:"tipe_jual"`
 		Omzet          float64 `bun:"omzet"`
@@ -466,6 +577,7 @@ func (r *dashboardRepository) getSalesBreakdownTipe(ctx context.Context, dateFro
 		Table("tb_penjualan").
 		Where("created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("deleted_at IS NULL").
+		Apply(filterMusim("musim_id", musimID)).
 		Group("tipe_jual").
 		Order("omzet DESC").
 		Scan(ctx, &results)
@@ -491,11 +603,11 @@ func (r *dashboardRepository) getSalesBreakdownTipe(ctx context.Context, dateFro
 	return breakdown, nil
 }
 
-func (r *dashboardRepository) getSalesTrendHarga(ctx context.Context, dateFrom, dateTo time.Time) ([]response.SalesTrendHarga, error) {
+func (r *dashboardRepository) getSalesTrendHarga(ctx context.Context, dateFrom, dateTo time.Time, musimID string) ([]response.SalesTrendHarga, error) {
 	return []response.SalesTrendHarga{}, nil
 }
 
-func (r *dashboardRepository) getSalesTopBuyers(ctx context.Context, dateFrom, dateTo time.Time) ([]response.SalesTopBuyer, error) {
+func (r *dashboardRepository) getSalesTopBuyers(ctx context.Context, dateFrom, dateTo time.Time, musimID string) ([]response.SalesTopBuyer, error) {
 	type queryResult struct {
 		Tujuan         string  `bun:"tujuan"`
 		TotalPembelian float64 `bun:"total_pembelian"`
@@ -512,6 +624,7 @@ func (r *dashboardRepository) getSalesTopBuyers(ctx context.Context, dateFrom, d
 		Join("LEFT JOIN tb_pengiriman AS pr ON p.pengiriman_id = pr.id").
 		Where("p.created_at BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("p.deleted_at IS NULL").
+		Apply(filterMusim("p.musim_id", musimID)).
 		Group("pr.tujuan").
 		Order("total_pembelian DESC").
 		Limit(10).
@@ -0,0 +1,17 @@
+package repository
+
+import "fmt"
+
+// ValidationError is a business rule broken inside a repository transaction, as opposed to a
+// database failure. Services report it as a bad request.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func validationError(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+
+	"github.com/uptrace/bun"
+)
+
+type GradeRepository interface {
+	Create(ctx context.Context, grade *domain.Grade) error
+	Update(ctx context.Context, grade *domain.Grade) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*domain.Grade, error)
+	GetByKode(ctx context.Context, kode string) (*domain.Grade, error)
+	GetList(ctx context.Context) ([]domain.Grade, error)
+	GetAturanByJenis(ctx context.Context, jenisID string) ([]domain.GradeAturan, error)
+}
+
+type gradeRepository struct {
+	db *database.Database
+}
+
+func NewGradeRepository(db *database.Database) GradeRepository {
+	return &gradeRepository{db: db}
+}
+
+func (r *gradeRepository) Create(ctx context.Context, grade *domain.Grade) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NewInsert().Model(grade).Exec(ctx); err != nil {
+		return err
+	}
+	if err := insertGradeAturan(ctx, tx, grade); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update writes the grade and replaces all of its rules
+func (r *gradeRepository) Update(ctx context.Context, grade *domain.Grade) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NewUpdate().
+		Model(grade).
+		Column("kode", "nama", "urutan", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NewDelete().
+		Model((*domain.GradeAturan)(nil)).
+		Where("grade_id = ?", grade.ID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if err := insertGradeAturan(ctx, tx, grade); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *gradeRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().Model((*domain.Grade)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *gradeRepository) GetByID(ctx context.Context, id string) (*domain.Grade, error) {
+	grade := new(domain.Grade)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(grade).
+		Relation("Aturan").
+		Where("grade.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return grade, err
+}
+
+func (r *gradeRepository) GetByKode(ctx context.Context, kode string) (*domain.Grade, error) {
+	grade := new(domain.Grade)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(grade).
+		Where("grade.kode = ?", kode).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return grade, err
+}
+
+func (r *gradeRepository) GetList(ctx context.Context) ([]domain.Grade, error) {
+	var list []domain.Grade
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Aturan").
+		Order("grade.urutan", "grade.kode").
+		Scan(ctx)
+	return list, err
+}
+
+// GetAturanByJenis returns the rules of a jenis, best grade first
+func (r *gradeRepository) GetAturanByJenis(ctx context.Context, jenisID string) ([]domain.GradeAturan, error) {
+	var list []domain.GradeAturan
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Grade").
+		Where("aturan.jenis_durian_id = ?", jenisID).
+		Where("grade.deleted_at IS NULL").
+		Order("grade.urutan", "grade.kode").
+		Scan(ctx)
+	return list, err
+}
+
+func insertGradeAturan(ctx context.Context, tx bun.Tx, grade *domain.Grade) error {
+	if len(grade.Aturan) == 0 {
+		return nil
+	}
+	for i := range grade.Aturan {
+		grade.Aturan[i].GradeID = grade.ID
+	}
+	_, err := tx.NewInsert().Model(&grade.Aturan).Exec(ctx)
+	return err
+}
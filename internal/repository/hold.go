@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold *domain.LotHold) error
+	Lepas(ctx context.Context, hold *domain.LotHold) error
+	Expire(ctx context.Context, now time.Time) (int64, error)
+	GetByID(ctx context.Context, id string) (*domain.LotHold, error)
+	GetList(ctx context.Context, status, lotID, lokasiID, pemegangID string) ([]domain.LotHold, error)
+}
+
+type holdRepository struct {
+	db *database.Database
+}
+
+func NewHoldRepository(db *database.Database) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+// Create holds a READY lot that has stock left and no running hold. A hold whose time ran out
+// but was not expired by the job yet is expired here first.
+func (r *holdRepository) Create(ctx context.Context, hold *domain.LotHold) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lot := new(domain.StokLot)
+	err = tx.NewSelect().
+		Model(lot).
+		Where("id = ?", hold.LotID).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return errors.New("lot tidak ditemukan")
+	}
+	if lot.Status != constants.LotStatusReady || lot.QtySisa == 0 {
+		return fmt.Errorf("lot %s tidak berstatus READY", lot.Kode)
+	}
+
+	now := time.Now()
+	if _, err := expireHolds(ctx, tx, now, lot.ID); err != nil {
+		return err
+	}
+
+	current, err := runningHold(ctx, tx, lot.ID, now)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return fmt.Errorf("lot %s sudah di-hold untuk %s sampai %s", lot.Kode, current.Pelanggan, current.BerakhirAt.Format("2006-01-02 15:04"))
+	}
+
+	hold.LokasiID = lot.PosisiID
+	hold.Status = constants.HoldStatusAktif
+	_, err = tx.NewInsert().Model(hold).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Lepas releases a hold that is still AKTIF
+func (r *holdRepository) Lepas(ctx context.Context, hold *domain.LotHold) error {
+	res, err := r.db.InitQuery(ctx).NewUpdate().
+		Model(hold).
+		Column("status", "dilepas_oleh", "dilepas_at", "updated_at").
+		WherePK().
+		Where("status = ?", constants.HoldStatusAktif).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("hold sudah tidak aktif")
+	}
+	return nil
+}
+
+// Expire marks every AKTIF hold whose time ran out at now KEDALUWARSA
+func (r *holdRepository) Expire(ctx context.Context, now time.Time) (int64, error) {
+	return expireHolds(ctx, r.db.InitQuery(ctx), now, "")
+}
+
+func (r *holdRepository) GetByID(ctx context.Context, id string) (*domain.LotHold, error) {
+	hold := new(domain.LotHold)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(hold).
+		Relation("Lot").
+		Relation("Lot.JenisDurianDetail").
+		Relation("Lokasi").
+		Relation("Pemegang").
+		Relation("Pengiriman").
+		Where("hold.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return hold, err
+}
+
+func (r *holdRepository) GetList(ctx context.Context, status, lotID, lokasiID, pemegangID string) ([]domain.LotHold, error) {
+	var list []domain.LotHold
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Lot").
+		Relation("Lot.JenisDurianDetail").
+		Relation("Lokasi").
+		Relation("Pemegang").
+		Relation("Pengiriman").
+		Order("hold.created_at DESC")
+	if status != "" {
+		query.Where("hold.status = ?", status)
+	}
+	if lotID != "" {
+		query.Where("hold.lot_id = ?", lotID)
+	}
+	if lokasiID != "" {
+		query.Where("hold.lokasi_id = ?", lokasiID)
+	}
+	if pemegangID != "" {
+		query.Where("hold.dipegang_oleh = ?", pemegangID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+// expireHolds expires the AKTIF holds that ran out at now, of one lot or of every lot when lotID
+// is empty
+func expireHolds(ctx context.Context, db bun.IDB, now time.Time, lotID string) (int64, error) {
+	query := db.NewUpdate().
+		Model((*domain.LotHold)(nil)).
+		Set("status = ?", constants.HoldStatusKedaluwarsa).
+		Set("updated_at = ?", now).
+		Where("status = ?", constants.HoldStatusAktif).
+		Where("berakhir_at <= ?", now)
+	if lotID != "" {
+		query.Where("lot_id = ?", lotID)
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// runningHold returns the AKTIF hold of a lot that has not run out at now, nil when there is none
+func runningHold(ctx context.Context, tx bun.Tx, lotID string, now time.Time) (*domain.LotHold, error) {
+	hold := new(domain.LotHold)
+	err := tx.NewSelect().
+		Model(hold).
+		Where("lot_id = ?", lotID).
+		Where("status = ?", constants.HoldStatusAktif).
+		Where("berakhir_at > ?", now).
+		For("UPDATE").
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// checkHold is called with lot locked, before stock of the lot is changed by userID. A lot held
+// by someone else is rejected, the user's own running hold is returned, nil when there is none.
+func checkHold(ctx context.Context, tx bun.Tx, lot *domain.StokLot, userID string) (*domain.LotHold, error) {
+	hold, err := runningHold(ctx, tx, lot.ID, time.Now())
+	if err != nil || hold == nil {
+		return nil, err
+	}
+	if hold.DipegangOleh != userID {
+		return nil, validationError("lot %s sedang di-hold untuk %s sampai %s", lot.Kode, hold.Pelanggan, hold.BerakhirAt.Format("2006-01-02 15:04"))
+	}
+	return hold, nil
+}
+
+// releaseHold releases the holder's own hold of a lot that was emptied
+func releaseHold(ctx context.Context, tx bun.Tx, hold *domain.LotHold, userID string) error {
+	if hold == nil {
+		return nil
+	}
+	now := time.Now()
+	hold.Status = constants.HoldStatusDilepas
+	hold.DilepasOleh = &userID
+	hold.DilepasAt = &now
+	_, err := tx.NewUpdate().
+		Model(hold).
+		Column("status", "dilepas_oleh", "dilepas_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// takeHold is called once detail is inserted. A lot held by someone else cannot be shipped.
+// When the holder takes the whole lot its hold becomes the shipment line and turns DIKIRIM,
+// after a partial take the hold stays AKTIF on the rest.
+func takeHold(ctx context.Context, tx bun.Tx, lot *domain.StokLot, detail *domain.PengirimanDetail, userID string) error {
+	hold, err := checkHold(ctx, tx, lot, userID)
+	if err != nil || hold == nil || detail.Parsial {
+		return err
+	}
+
+	hold.Status = constants.HoldStatusDikirim
+	hold.PengirimanID = &detail.PengirimanID
+	hold.PengirimanDetailID = &detail.ID
+	_, err = tx.NewUpdate().
+		Model(hold).
+		Column("status", "pengiriman_id", "pengiriman_detail_id", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// restoreHold gives a hold back when its shipment line is removed, while it still has time and
+// the lot was not held again meanwhile. Otherwise the hold is released by userID.
+func restoreHold(ctx context.Context, tx bun.Tx, detailID, userID string) error {
+	now := time.Now()
+	_, err := tx.NewUpdate().
+		Model((*domain.LotHold)(nil)).
+		Set("status = ?", constants.HoldStatusAktif).
+		Set("pengiriman_id = NULL").
+		Set("pengiriman_detail_id = NULL").
+		Set("updated_at = ?", now).
+		Where("pengiriman_detail_id = ?", detailID).
+		Where("status = ?", constants.HoldStatusDikirim).
+		Where("berakhir_at > ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM tb_lot_hold AS h2 WHERE h2.lot_id = hold.lot_id AND h2.status = ?)", constants.HoldStatusAktif).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*domain.LotHold)(nil)).
+		Set("status = ?", constants.HoldStatusDilepas).
+		Set("dilepas_oleh = ?", userID).
+		Set("dilepas_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("pengiriman_detail_id = ?", detailID).
+		Where("status = ?", constants.HoldStatusDikirim).
+		Exec(ctx)
+	return err
+}
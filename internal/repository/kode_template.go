@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+)
+
+type KodeTemplateRepository interface {
+	Create(ctx context.Context, tpl *domain.KodeTemplate) error
+	Update(ctx context.Context, tpl *domain.KodeTemplate) error
+	Delete(ctx context.Context, id string) error
+	GetAll(ctx context.Context, companyID string) ([]domain.KodeTemplate, error)
+	GetByID(ctx context.Context, id string) (*domain.KodeTemplate, error)
+	GetByCompanyAndTipe(ctx context.Context, companyID *string, tipe string) (*domain.KodeTemplate, error)
+	GetEffective(ctx context.Context, tipe, companyID string) (*domain.KodeTemplate, error)
+}
+
+type kodeTemplateRepository struct {
+	db *database.Database
+}
+
+func NewKodeTemplateRepository(db *database.Database) KodeTemplateRepository {
+	return &kodeTemplateRepository{db: db}
+}
+
+func (r *kodeTemplateRepository) Create(ctx context.Context, tpl *domain.KodeTemplate) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(tpl).Exec(ctx)
+	return err
+}
+
+func (r *kodeTemplateRepository) Update(ctx context.Context, tpl *domain.KodeTemplate) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().Model(tpl).WherePK().Exec(ctx)
+	return err
+}
+
+func (r *kodeTemplateRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().
+		Model((*domain.KodeTemplate)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *kodeTemplateRepository) GetAll(ctx context.Context, companyID string) ([]domain.KodeTemplate, error) {
+	var list []domain.KodeTemplate
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Company").
+		Order("kt.tipe ASC", "kt.company_id ASC")
+
+	if companyID != "" {
+		query = query.Where("kt.company_id = ?", companyID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+func (r *kodeTemplateRepository) GetByID(ctx context.Context, id string) (*domain.KodeTemplate, error) {
+	tpl := new(domain.KodeTemplate)
+	err := r.db.InitQuery(ctx).NewSelect().Model(tpl).Relation("Company").Where("kt.id = ?", id).Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tpl, err
+}
+
+func (r *kodeTemplateRepository) GetByCompanyAndTipe(ctx context.Context, companyID *string, tipe string) (*domain.KodeTemplate, error) {
+	tpl := new(domain.KodeTemplate)
+	query := r.db.InitQuery(ctx).NewSelect().Model(tpl).Where("kt.tipe = ?", tipe)
+	if companyID == nil {
+		query = query.Where("kt.company_id IS NULL")
+	} else {
+		query = query.Where("kt.company_id = ?", *companyID)
+	}
+
+	err := query.Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tpl, err
+}
+
+// GetEffective returns the company's own template, falling back to the group default. Nil means none is configured.
+func (r *kodeTemplateRepository) GetEffective(ctx context.Context, tipe, companyID string) (*domain.KodeTemplate, error) {
+	tpl := new(domain.KodeTemplate)
+	query := r.db.InitQuery(ctx).NewSelect().Model(tpl).Where("kt.tipe = ?", tipe)
+	if companyID != "" {
+		query = query.Where("(kt.company_id = ? OR kt.company_id IS NULL)", companyID)
+	} else {
+		query = query.Where("kt.company_id IS NULL")
+	}
+
+	err := query.OrderExpr("kt.company_id IS NULL ASC").Limit(1).Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tpl, err
+}
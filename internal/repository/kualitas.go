@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+
+	"github.com/uptrace/bun"
+)
+
+type KualitasRepository interface {
+	GetByJenisID(ctx context.Context, jenisID string) (*domain.JenisDurianKualitas, error)
+	GetByJenisIDs(ctx context.Context, jenisIDs []string) (map[string]domain.JenisDurianKualitas, error)
+	Save(ctx context.Context, cfg *domain.JenisDurianKualitas) error
+	Delete(ctx context.Context, jenisID string) error
+}
+
+type kualitasRepository struct {
+	db *database.Database
+}
+
+func NewKualitasRepository(db *database.Database) KualitasRepository {
+	return &kualitasRepository{db: db}
+}
+
+func (r *kualitasRepository) GetByJenisID(ctx context.Context, jenisID string) (*domain.JenisDurianKualitas, error) {
+	cfg := new(domain.JenisDurianKualitas)
+	err := r.db.InitQuery(ctx).NewSelect().Model(cfg).Where("jdk.jenis_durian_id = ?", jenisID).Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return cfg, err
+}
+
+func (r *kualitasRepository) GetByJenisIDs(ctx context.Context, jenisIDs []string) (map[string]domain.JenisDurianKualitas, error) {
+	var list []domain.JenisDurianKualitas
+	err := r.db.InitQuery(ctx).NewSelect().Model(&list).Where("jdk.jenis_durian_id IN (?)", bun.In(jenisIDs)).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]domain.JenisDurianKualitas, len(list))
+	for _, cfg := range list {
+		result[cfg.JenisDurianID] = cfg
+	}
+	return result, nil
+}
+
+func (r *kualitasRepository) Save(ctx context.Context, cfg *domain.JenisDurianKualitas) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().
+		Model(cfg).
+		On("CONFLICT (jenis_durian_id) DO UPDATE").
+		Set("kematangan_opsi = EXCLUDED.kematangan_opsi").
+		Set("cacat_opsi = EXCLUDED.cacat_opsi").
+		Set("metode_panen_opsi = EXCLUDED.metode_panen_opsi").
+		Set("skor_aroma_min = EXCLUDED.skor_aroma_min").
+		Set("skor_aroma_max = EXCLUDED.skor_aroma_max").
+		Set("atribut_wajib = EXCLUDED.atribut_wajib").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("created_at").
+		Exec(ctx)
+	return err
+}
+
+func (r *kualitasRepository) Delete(ctx context.Context, jenisID string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().
+		Model((*domain.JenisDurianKualitas)(nil)).
+		Where("jenis_durian_id = ?", jenisID).
+		Exec(ctx)
+	return err
+}
@@ -2,37 +2,80 @@ package repository
 
 import (
 	"context"
+	"durich-be/internal/constants"
 	"durich-be/internal/domain"
 	"durich-be/pkg/database"
-	"fmt"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/uptrace/bun"
 )
 
 type LotRepository interface {
-	Create(ctx context.Context, lot *domain.StokLot) error
+	Create(ctx context.Context, lot *domain.StokLot, spec SequenceSpec, userID string) error
 	GetByID(ctx context.Context, id string) (*domain.StokLot, error)
 	GetList(ctx context.Context, status, jenisDurianID, kondisi, locationID, scope, createdAt string) ([]domain.StokLot, error)
 	Update(ctx context.Context, lot *domain.StokLot) error
-	AddBuah(ctx context.Context, buah *domain.BuahRaw) error
+	Finalize(ctx context.Context, lot *domain.StokLot, userID string) error
+	AddBuah(ctx context.Context, buah *domain.BuahRaw, spec SequenceSpec) error
 	RemoveItem(ctx context.Context, lotID, buahRawID string) error
 	GetItemCount(ctx context.Context, lotID string) (int, error)
 	GetBuahRawByID(ctx context.Context, id string) (*domain.BuahRaw, error)
-	GetNextLotKode(ctx context.Context) (string, error)
-	GetNextLotSequence(ctx context.Context, dateStr, jenisKode, grade string) (string, error)
+	GetBuahByKodes(ctx context.Context, lotID string, kodes []string) ([]domain.BuahRaw, error)
+	GetBuahRawByKodes(ctx context.Context, kodes []string) ([]domain.BuahRaw, error)
+	AssignBuah(ctx context.Context, lotID string, buahRawIDs []string, musimID *string, userID string) ([]string, error)
 	GetPohonByKode(ctx context.Context, kode string, blokID string) (*domain.Pohon, error)
 	GetTotalWeight(ctx context.Context, lotID string) (float64, error)
+	Split(ctx context.Context, parentID string, child *domain.StokLot, spec SequenceSpec, buahRawIDs []string, userID string) (*domain.LotGenealogi, error)
+	Merge(ctx context.Context, lotIDs []string, child *domain.StokLot, spec SequenceSpec, userID string) ([]domain.LotGenealogi, error)
+	GetGenealogi(ctx context.Context, lotID string) ([]domain.LotGenealogi, error)
+	GetStatusRiwayat(ctx context.Context, lotID string) ([]domain.LotStatusRiwayat, error)
+	Regrade(ctx context.Context, lotID string, target *domain.StokLot, spec SequenceSpec, buahRawIDs []string, regrade *domain.LotRegrade) (*domain.LotGenealogi, error)
+	GetRegrade(ctx context.Context, lotID string) ([]domain.LotRegrade, error)
+	GetMutasi(ctx context.Context, lotID string) ([]domain.LotMutasi, error)
+	GetRekonsiliasi(ctx context.Context) ([]domain.LotRekonsiliasi, error)
+	GetTersimpan(ctx context.Context, locationID, jenisDurianID string) ([]domain.StokLot, error)
+	GetSiapKirim(ctx context.Context, locationID, jenisDurianID, kondisi, userID string) ([]domain.StokLot, error)
+	TimbangUlang(ctx context.Context, tu *domain.LotTimbangUlang, userID string) error
+	GetTimbangUlang(ctx context.Context, lotID string) ([]domain.LotTimbangUlang, error)
 }
 
 type lotRepository struct {
-	db *database.Database
+	db           *database.Database
+	sequenceRepo SequenceRepository
 }
 
-func NewLotRepository(db *database.Database) LotRepository {
-	return &lotRepository{db: db}
+func NewLotRepository(db *database.Database, sequenceRepo SequenceRepository) LotRepository {
+	return &lotRepository{
+		db:           db,
+		sequenceRepo: sequenceRepo,
+	}
 }
 
-func (r *lotRepository) Create(ctx context.Context, lot *domain.StokLot) error {
-	_, err := r.db.InitQuery(ctx).NewInsert().Model(lot).Exec(ctx)
-	return err
+func (r *lotRepository) Create(ctx context.Context, lot *domain.StokLot, spec SequenceSpec, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return err
+	}
+	lot.Kode = kodes[0]
+
+	_, err = tx.NewInsert().Model(lot).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := recordLotCreated(ctx, tx, lot, userID, "lot dibuat"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *lotRepository) GetByID(ctx context.Context, id string) (*domain.StokLot, error) {
@@ -61,6 +104,8 @@ func (r *lotRepository) GetList(ctx context.Context, status, jenisDurianID, kond
 		ColumnExpr("stok_lot.*").
 		ColumnExpr("(SELECT COUNT(*) FROM tb_buah_raw WHERE lot_id = stok_lot.id) AS current_qty").
 		ColumnExpr("(SELECT COALESCE(SUM(berat), 0) FROM tb_buah_raw WHERE lot_id = stok_lot.id) AS current_berat").
+		ColumnExpr("hold.dipegang_oleh AS hold_oleh, hold.pelanggan AS hold_pelanggan, hold.berakhir_at AS hold_berakhir_at").
+		Join("LEFT JOIN tb_lot_hold AS hold ON hold.lot_id = stok_lot.id AND hold.status = ? AND hold.berakhir_at > NOW()", constants.HoldStatusAktif).
 		Where("stok_lot.deleted_at IS NULL")
 
 	if locationID != "" {
@@ -92,19 +137,104 @@ func (r *lotRepository) GetList(ctx context.Context, status, jenisDurianID, kond
 	return lots, nil
 }
 
+// Update writes every column except status, which only changes through the state machine
 func (r *lotRepository) Update(ctx context.Context, lot *domain.StokLot) error {
 	_, err := r.db.InitQuery(ctx).NewUpdate().
 		Model(lot).
+		ExcludeColumn("status").
 		WherePK().
 		Exec(ctx)
 	return err
 }
 
-func (r *lotRepository) AddBuah(ctx context.Context, buah *domain.BuahRaw) error {
-	_, err := r.db.InitQuery(ctx).NewInsert().Model(buah).Exec(ctx)
+// Finalize writes the totals of a DRAFT lot and makes it READY
+func (r *lotRepository) Finalize(ctx context.Context, lot *domain.StokLot, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := new(domain.StokLot)
+	err = tx.NewSelect().Model(current).Where("id = ?", lot.ID).For("UPDATE").Scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Lots of an open grading session are finalized together when it closes
+	inSesi, err := tx.NewSelect().
+		TableExpr("tb_sesi_grading_lot AS sl").
+		Join("JOIN tb_sesi_grading AS sesi ON sesi.id = sl.sesi_id").
+		Where("sl.lot_id = ?", lot.ID).
+		Where("sesi.status = ?", constants.SesiGradingStatusBuka).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if inSesi {
+		return validationError("lot %s termasuk sesi grading yang masih dibuka, tutup sesinya untuk memfinalisasi", current.Kode)
+	}
+
+	lot.Status = current.Status
+	if err := setLotStatus(ctx, tx, lot, constants.LotStatusReady, userID, "finalisasi lot"); err != nil {
+		return err
+	}
+	if err := recordGrading(ctx, tx, lot); err != nil {
+		return err
+	}
+	if err := recordMutasi(ctx, tx, lot, constants.LotMutasiFinalisasi, lot.QtySisa-current.QtySisa, lot.BeratSisa-current.BeratSisa, mutasiRef{}, userID); err != nil {
+		return err
+	}
+
+	finalizedAt := time.Now()
+	lot.FinalizedAt = &finalizedAt
+	_, err = tx.NewUpdate().
+		Model(lot).
+		Column("berat_awal", "qty_awal", "berat_sisa", "qty_sisa", "status", "finalized_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// recordGrading stamps the grade of a lot being finalized on its fruits that were not graded
+// before, so a later regrade does not change it
+func recordGrading(ctx context.Context, tx bun.Tx, lot *domain.StokLot) error {
+	_, err := tx.NewUpdate().
+		Model((*domain.BuahRaw)(nil)).
+		Set("kondisi_grading = ?", lot.KondisiBuah).
+		Set("updated_at = NOW()").
+		Where("lot_id = ?", lot.ID).
+		Where("kondisi_grading IS NULL").
+		Where("deleted_at IS NULL").
+		Exec(ctx)
 	return err
 }
 
+func (r *lotRepository) AddBuah(ctx context.Context, buah *domain.BuahRaw, spec SequenceSpec) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return err
+	}
+	buah.KodeBuah = kodes[0]
+
+	_, err = tx.NewInsert().Model(buah).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *lotRepository) GetPohonByKode(ctx context.Context, kode string, blokID string) (*domain.Pohon, error) {
 	pohon := new(domain.Pohon)
 	query := r.db.InitQuery(ctx).NewSelect().
@@ -123,8 +253,29 @@ func (r *lotRepository) GetPohonByKode(ctx context.Context, kode string, blokID
 	return pohon, nil
 }
 
+// RemoveItem deletes a fruit created in the lot. A fruit that was scanned in is released
+// back to unsorted instead, its harvest record stays.
 func (r *lotRepository) RemoveItem(ctx context.Context, lotID, buahRawID string) error {
-	_, err := r.db.InitQuery(ctx).NewDelete().
+	scanned, err := r.db.InitQuery(ctx).NewSelect().
+		Model((*domain.LotScan)(nil)).
+		Where("lot_id = ?", lotID).
+		Where("buah_raw_id = ?", buahRawID).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if scanned {
+		_, err = r.db.InitQuery(ctx).NewUpdate().
+			Model((*domain.BuahRaw)(nil)).
+			Set("lot_id = NULL").
+			Set("updated_at = NOW()").
+			Where("id = ?", buahRawID).
+			Where("lot_id = ?", lotID).
+			Exec(ctx)
+		return err
+	}
+
+	_, err = r.db.InitQuery(ctx).NewDelete().
 		Model((*domain.BuahRaw)(nil)).
 		Where("id = ?", buahRawID).
 		Where("lot_id = ?", lotID).
@@ -164,77 +315,687 @@ func (r *lotRepository) GetBuahRawByID(ctx context.Context, id string) (*domain.
 	return buah, nil
 }
 
-func (r *lotRepository) GetNextLotSequence(ctx context.Context, dateStr, jenisKode, grade string) (string, error) {
+// GetBuahByKodes returns the fruits of lotID whose kode_buah is in kodes
+func (r *lotRepository) GetBuahByKodes(ctx context.Context, lotID string, kodes []string) ([]domain.BuahRaw, error) {
+	var buah []domain.BuahRaw
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&buah).
+		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
+		Where("buah_raw.lot_id = ?", lotID).
+		Where("buah_raw.kode_buah IN (?)", bun.In(kodes)).
+		Where("buah_raw.writeoff_id IS NULL").
+		Where("buah_raw.deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buah, nil
+}
+
+// GetBuahRawByKodes finds fruits by kode_buah wherever they are, with the lot they are in
+func (r *lotRepository) GetBuahRawByKodes(ctx context.Context, kodes []string) ([]domain.BuahRaw, error) {
+	var buah []domain.BuahRaw
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&buah).
+		Relation("Lot").
+		Where("buah_raw.kode_buah IN (?)", bun.In(kodes)).
+		Where("buah_raw.deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buah, nil
+}
+
+// AssignBuah links unsorted fruits to a DRAFT lot and returns the ids it linked. Fruits that
+// joined another lot in the meantime are skipped, so a fruit never ends up in two lots.
+// A lot without a season takes musimID.
+func (r *lotRepository) AssignBuah(ctx context.Context, lotID string, buahRawIDs []string, musimID *string, userID string) ([]string, error) {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	// Pattern: LOT-KODE-GRADE-DDMMYY-%
-	prefix := fmt.Sprintf("LOT-%s-%s-%s", jenisKode, grade, dateStr)
-
-	var lot domain.StokLot
+	lot := new(domain.StokLot)
 	err = tx.NewSelect().
-		Model(&lot).
-		Column("kode").
-		Where("kode LIKE ?", prefix+"-%").
-		Order("kode DESC").
-		Limit(1).
+		Model(lot).
+		Where("id = ?", lotID).
+		Where("status = ?", constants.LotStatusDraft).
+		Where("deleted_at IS NULL").
 		For("UPDATE").
 		Scan(ctx)
+	if err != nil {
+		return nil, errors.New("lot tidak ditemukan atau tidak berstatus DRAFT")
+	}
+
+	var assigned []string
+	_, err = tx.NewUpdate().
+		Model((*domain.BuahRaw)(nil)).
+		Set("lot_id = ?", lotID).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", bun.In(buahRawIDs)).
+		Where("lot_id IS NULL").
+		Where("deleted_at IS NULL").
+		Returning("id").
+		Exec(ctx, &assigned)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assigned) == 0 {
+		return assigned, tx.Commit()
+	}
+
+	scans := make([]domain.LotScan, 0, len(assigned))
+	for _, id := range assigned {
+		scan := domain.LotScan{LotID: lotID, BuahRawID: id}
+		if userID != "" {
+			scan.CreatedBy = &userID
+		}
+		scans = append(scans, scan)
+	}
+	if _, err := tx.NewInsert().Model(&scans).Exec(ctx); err != nil {
+		return nil, err
+	}
 
-	nextSeq := 1
-	if err == nil && lot.Kode != "" {
-		var seq int
-		_, err = fmt.Sscanf(lot.Kode, prefix+"-%d", &seq)
-		if err == nil {
-			nextSeq = seq + 1
+	if lot.MusimID == nil && musimID != nil {
+		lot.MusimID = musimID
+		_, err = tx.NewUpdate().Model(lot).Column("musim_id", "updated_at").WherePK().Exec(ctx)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	newKode := fmt.Sprintf("%s-%02d", prefix, nextSeq)
-	return newKode, tx.Commit()
+	return assigned, tx.Commit()
+}
+
+// Split moves the given fruits of a READY lot into child, a new READY lot. The fruits are
+// counted inside the transaction and take their share of the parent's weight, the parent
+// keeps the rest.
+func (r *lotRepository) Split(ctx context.Context, parentID string, child *domain.StokLot, spec SequenceSpec, buahRawIDs []string, userID string) (*domain.LotGenealogi, error) {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	gen, err := splitLot(ctx, tx, r.sequenceRepo, parentID, child, spec, buahRawIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	return gen, tx.Commit()
+}
+
+// splitLot does Split inside tx, so a caller can act on child in the same transaction
+func splitLot(ctx context.Context, tx bun.Tx, sequenceRepo SequenceRepository, parentID string, child *domain.StokLot, spec SequenceSpec, buahRawIDs []string, userID string) (*domain.LotGenealogi, error) {
+	parent := new(domain.StokLot)
+	err := tx.NewSelect().
+		Model(parent).
+		Where("id = ?", parentID).
+		Where("status = ?", constants.LotStatusReady).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, validationError("lot tidak ditemukan atau tidak berstatus READY")
+	}
+	if _, err := checkHold(ctx, tx, parent, userID); err != nil {
+		return nil, err
+	}
+
+	var qty int
+	var berat float64
+	err = tx.NewSelect().
+		Model((*domain.BuahRaw)(nil)).
+		ColumnExpr("COUNT(*)").
+		ColumnExpr("COALESCE(SUM(berat), 0)").
+		Where("id IN (?)", bun.In(buahRawIDs)).
+		Where("lot_id = ?", parentID).
+		Where("writeoff_id IS NULL").
+		Where("deleted_at IS NULL").
+		Scan(ctx, &qty, &berat)
+	if err != nil {
+		return nil, err
+	}
+	if qty != len(buahRawIDs) {
+		return nil, validationError("%d buah bukan anggota lot %s", len(buahRawIDs)-qty, parent.Kode)
+	}
+	if qty >= parent.QtySisa {
+		return nil, validationError("lot asal harus menyisakan minimal 1 buah")
+	}
+	totalQty, totalBerat, err := lotBuahTotal(ctx, tx, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	berat = prorataBerat(parent.BeratSisa, berat, totalBerat, qty, totalQty)
+
+	kodes, err := reserveKodes(ctx, tx, sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return nil, err
+	}
+	child.Kode = kodes[0]
+	child.QtyAwal, child.QtySisa = qty, qty
+	child.BeratAwal, child.BeratSisa = berat, berat
+
+	if _, err = tx.NewInsert().Model(child).Exec(ctx); err != nil {
+		return nil, err
+	}
+	if err := recordLotCreated(ctx, tx, child, userID, "hasil pecah lot "+parent.Kode); err != nil {
+		return nil, err
+	}
+	if err := recordMutasi(ctx, tx, child, constants.LotMutasiSplitMasuk, qty, berat, lotRef(parent), userID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*domain.BuahRaw)(nil)).
+		Set("lot_id = ?", child.ID).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", bun.In(buahRawIDs)).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parent.QtySisa -= qty
+	parent.BeratSisa -= berat
+	if err := recordMutasi(ctx, tx, parent, constants.LotMutasiSplitKeluar, -qty, -berat, lotRef(child), userID); err != nil {
+		return nil, err
+	}
+	_, err = tx.NewUpdate().
+		Model(parent).
+		Column("qty_sisa", "berat_sisa", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &domain.LotGenealogi{
+		Tipe:        constants.LotGenealogiSplit,
+		ParentLotID: parent.ID,
+		ChildLotID:  child.ID,
+		Qty:         qty,
+		Berat:       berat,
+		CreatedBy:   &userID,
+	}
+	if err := insertGenealogi(ctx, tx, gen, buahRawIDs); err != nil {
+		return nil, err
+	}
+
+	return gen, nil
+}
+
+// lotBuahTotal counts and weighs the fruits still in a lot
+func lotBuahTotal(ctx context.Context, tx bun.Tx, lotID string) (int, float64, error) {
+	var qty int
+	var berat float64
+	err := tx.NewSelect().
+		Model((*domain.BuahRaw)(nil)).
+		ColumnExpr("COUNT(*)").
+		ColumnExpr("COALESCE(SUM(berat), 0)").
+		Where("lot_id = ?", lotID).
+		Where("writeoff_id IS NULL").
+		Where("deleted_at IS NULL").
+		Scan(ctx, &qty, &berat)
+	return qty, berat, err
+}
+
+// prorataBerat is the part of beratSisa that moves with qty fruits weighing berat, out of
+// totalQty fruits weighing totalBerat left in the lot. The fruits were weighed at harvest and
+// the lot may have been re-weighed since, so only their share counts. Without weighed fruits
+// the share goes by count. The lot always keeps some weight.
+func prorataBerat(beratSisa, berat, totalBerat float64, qty, totalQty int) float64 {
+	var share float64
+	switch {
+	case totalBerat > 0:
+		share = berat / totalBerat
+	case totalQty > 0:
+		share = float64(qty) / float64(totalQty)
+	}
+
+	moved := math.Round(beratSisa*share*100) / 100
+	if maks := math.Round((beratSisa-0.01)*100) / 100; moved > maks {
+		moved = maks
+	}
+	if moved < 0 {
+		moved = 0
+	}
+	return moved
 }
 
-func (r *lotRepository) GetNextLotKode(ctx context.Context) (string, error) {
+// Merge empties the given READY lots into child, a new READY lot. The lots must share
+// jenis, grade, location and season with child.
+func (r *lotRepository) Merge(ctx context.Context, lotIDs []string, child *domain.StokLot, spec SequenceSpec, userID string) ([]domain.LotGenealogi, error) {
+	seen := make(map[string]bool, len(lotIDs))
+	for _, id := range lotIDs {
+		if seen[id] {
+			return nil, validationError("lot %s disebut lebih dari sekali", id)
+		}
+		seen[id] = true
+	}
+
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	var lot domain.StokLot
+	// Locked in id order so concurrent merges cannot deadlock
+	var sources []domain.StokLot
 	err = tx.NewSelect().
-		Model(&lot).
-		Column("kode").
-		Where("kode LIKE ?", "LOT-%").
-		Order("kode DESC").
-		Limit(1).
+		Model(&sources).
+		Where("id IN (?)", bun.In(lotIDs)).
+		Where("deleted_at IS NULL").
+		Order("id").
 		For("UPDATE").
 		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) != len(lotIDs) {
+		for _, lot := range sources {
+			delete(seen, lot.ID)
+		}
+		for _, id := range lotIDs {
+			if seen[id] {
+				return nil, validationError("lot %s tidak ditemukan", id)
+			}
+		}
+	}
 
-	nextSeq := 1
-	if err == nil && lot.Kode != "" {
-		// Extract number from LOT-001
-		var seq int
-		_, err = fmt.Sscanf(lot.Kode, "LOT-%d", &seq)
-		if err == nil {
-			nextSeq = seq + 1
+	for _, lot := range sources {
+		switch {
+		case lot.Status != constants.LotStatusReady:
+			return nil, validationError("lot %s tidak berstatus READY", lot.Kode)
+		case lot.JenisDurianID != child.JenisDurianID || lot.KondisiBuah != child.KondisiBuah:
+			return nil, validationError("lot %s berbeda jenis atau grade", lot.Kode)
+		case !sameStringPtr(lot.PosisiID, child.PosisiID):
+			return nil, validationError("lot %s berada di lokasi lain", lot.Kode)
+		case !sameStringPtr(lot.MusimID, child.MusimID):
+			return nil, validationError("lot %s berasal dari musim panen lain", lot.Kode)
+		}
+		child.QtySisa += lot.QtySisa
+		child.BeratSisa += lot.BeratSisa
+		// The merged lot is as old as its oldest fruits
+		if lot.FinalizedAt != nil && (child.FinalizedAt == nil || lot.FinalizedAt.Before(*child.FinalizedAt)) {
+			child.FinalizedAt = lot.FinalizedAt
 		}
 	}
+	child.QtyAwal, child.BeratAwal = child.QtySisa, child.BeratSisa
 
-	newKode := fmt.Sprintf("LOT-%03d", nextSeq)
+	holds := make([]*domain.LotHold, len(sources))
+	for i := range sources {
+		if err := checkAlokasiParsial(ctx, tx, &sources[i]); err != nil {
+			return nil, err
+		}
+		// Every fruit moves, so they must be what the lot still counts
+		qty, _, err := lotBuahTotal(ctx, tx, sources[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if qty != sources[i].QtySisa {
+			return nil, validationError("lot %s berisi %d buah, tidak sama dengan sisa qty %d", sources[i].Kode, qty, sources[i].QtySisa)
+		}
+		if holds[i], err = checkHold(ctx, tx, &sources[i], userID); err != nil {
+			return nil, err
+		}
+	}
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return nil, err
+	}
+	child.Kode = kodes[0]
 
-	var existing domain.StokLot
+	if _, err = tx.NewInsert().Model(child).Exec(ctx); err != nil {
+		return nil, err
+	}
+	if err := recordLotCreated(ctx, tx, child, userID, "hasil gabung lot"); err != nil {
+		return nil, err
+	}
+
+	gens := make([]domain.LotGenealogi, 0, len(sources))
+	for i := range sources {
+		lot := &sources[i]
+
+		var buahIDs []string
+		err = tx.NewSelect().
+			Model((*domain.BuahRaw)(nil)).
+			Column("id").
+			Where("lot_id = ?", lot.ID).
+			Where("writeoff_id IS NULL").
+			Where("deleted_at IS NULL").
+			Scan(ctx, &buahIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(buahIDs) > 0 {
+			_, err = tx.NewUpdate().
+				Model((*domain.BuahRaw)(nil)).
+				Set("lot_id = ?", child.ID).
+				Set("updated_at = NOW()").
+				Where("id IN (?)", bun.In(buahIDs)).
+				Exec(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		gen := domain.LotGenealogi{
+			Tipe:        constants.LotGenealogiMerge,
+			ParentLotID: lot.ID,
+			ChildLotID:  child.ID,
+			Qty:         lot.QtySisa,
+			Berat:       lot.BeratSisa,
+			CreatedBy:   &userID,
+		}
+		if err := insertGenealogi(ctx, tx, &gen, buahIDs); err != nil {
+			return nil, err
+		}
+		gens = append(gens, gen)
+
+		if err := recordMutasi(ctx, tx, child, constants.LotMutasiMergeMasuk, lot.QtySisa, lot.BeratSisa, lotRef(lot), userID); err != nil {
+			return nil, err
+		}
+		if err := recordMutasi(ctx, tx, lot, constants.LotMutasiMergeKeluar, -lot.QtySisa, -lot.BeratSisa, lotRef(child), userID); err != nil {
+			return nil, err
+		}
+		lot.QtySisa = 0
+		lot.BeratSisa = 0
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusEmpty, userID, "digabung ke lot "+child.Kode); err != nil {
+			return nil, err
+		}
+		if err := releaseHold(ctx, tx, holds[i], userID); err != nil {
+			return nil, err
+		}
+		_, err = tx.NewUpdate().
+			Model(lot).
+			Column("qty_sisa", "berat_sisa", "status", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return gens, tx.Commit()
+}
+
+func (r *lotRepository) GetGenealogi(ctx context.Context, lotID string) ([]domain.LotGenealogi, error) {
+	var list []domain.LotGenealogi
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("ParentLot").
+		Relation("ChildLot").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("genealogi.parent_lot_id = ?", lotID).WhereOr("genealogi.child_lot_id = ?", lotID)
+		}).
+		Order("genealogi.created_at").
+		Scan(ctx)
+	return list, err
+}
+
+// Regrade moves fruits of a READY lot into target, a lot of regrade.KondisiKe. Without
+// buahRawIDs every fruit moves and the lot ends EMPTY. A target with an ID must be an
+// existing READY lot of the same jenis, location and season, otherwise it is inserted with spec.
+func (r *lotRepository) Regrade(ctx context.Context, lotID string, target *domain.StokLot, spec SequenceSpec, buahRawIDs []string, regrade *domain.LotRegrade) (*domain.LotGenealogi, error) {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locked in id order so concurrent regrades cannot deadlock
+	ids := []string{lotID}
+	if target.ID != "" {
+		ids = append(ids, target.ID)
+	}
+	var locked []domain.StokLot
 	err = tx.NewSelect().
-		Model(&existing).
-		Where("kode = ?", newKode).
+		Model(&locked).
+		Where("id IN (?)", bun.In(ids)).
+		Where("deleted_at IS NULL").
+		Order("id").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) != len(ids) {
+		return nil, validationError("lot tidak ditemukan")
+	}
+
+	var parent *domain.StokLot
+	for i := range locked {
+		if locked[i].ID == lotID {
+			parent = &locked[i]
+		} else {
+			*target = locked[i]
+		}
+	}
+	if parent.Status != constants.LotStatusReady {
+		return nil, validationError("hanya lot dengan status READY yang bisa di-regrade")
+	}
+	if parent.KondisiBuah == regrade.KondisiKe {
+		return nil, validationError("lot %s sudah berkondisi %s", parent.Kode, regrade.KondisiKe)
+	}
+	if target.ID != "" {
+		switch {
+		case target.Status != constants.LotStatusReady:
+			return nil, validationError("lot tujuan %s tidak berstatus READY", target.Kode)
+		case target.JenisDurianID != parent.JenisDurianID || target.KondisiBuah != regrade.KondisiKe:
+			return nil, validationError("lot tujuan %s harus berjenis sama dan berkondisi %s", target.Kode, regrade.KondisiKe)
+		case !sameStringPtr(target.PosisiID, parent.PosisiID):
+			return nil, validationError("lot tujuan %s berada di lokasi lain", target.Kode)
+		case !sameStringPtr(target.MusimID, parent.MusimID):
+			return nil, validationError("lot tujuan %s berasal dari musim panen lain", target.Kode)
+		}
+	}
+
+	// A lot partly allocated to a shipment holds more fruits than its remaining quantity
+	if err := checkAlokasiParsial(ctx, tx, parent); err != nil {
+		return nil, err
+	}
+	hold, err := checkHold(ctx, tx, parent, stringValue(regrade.CreatedBy))
+	if err != nil {
+		return nil, err
+	}
+	if target.ID != "" {
+		if _, err := checkHold(ctx, tx, target, stringValue(regrade.CreatedBy)); err != nil {
+			return nil, err
+		}
+	}
+
+	var fruits []domain.BuahRaw
+	query := tx.NewSelect().
+		Model(&fruits).
+		Column("id", "berat").
+		Where("lot_id = ?", parent.ID).
+		Where("writeoff_id IS NULL").
+		Where("deleted_at IS NULL")
+	if len(buahRawIDs) > 0 {
+		query = query.Where("id IN (?)", bun.In(buahRawIDs))
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+	if len(buahRawIDs) > 0 && len(fruits) != len(buahRawIDs) {
+		return nil, validationError("%d buah bukan anggota lot %s", len(buahRawIDs)-len(fruits), parent.Kode)
+	}
+	if len(fruits) == 0 {
+		return nil, validationError("lot %s tidak memiliki buah", parent.Kode)
+	}
+
+	qty := len(fruits)
+	switch {
+	case len(buahRawIDs) == 0 && qty != parent.QtySisa:
+		return nil, validationError("lot %s berisi %d buah, tidak sama dengan sisa qty %d", parent.Kode, qty, parent.QtySisa)
+	case qty > parent.QtySisa:
+		return nil, validationError("buah yang dipilih melebihi sisa qty lot %s (%d)", parent.Kode, parent.QtySisa)
+	}
+
+	fruitIDs := make([]string, 0, qty)
+	berat := 0.0
+	for _, f := range fruits {
+		fruitIDs = append(fruitIDs, f.ID)
+		berat += f.Berat
+	}
+	whole := qty == parent.QtySisa
+	if whole {
+		berat = parent.BeratSisa
+	} else {
+		totalQty, totalBerat, err := lotBuahTotal(ctx, tx, parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		berat = prorataBerat(parent.BeratSisa, berat, totalBerat, qty, totalQty)
+	}
+
+	if target.ID == "" {
+		kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+		if err != nil {
+			return nil, err
+		}
+		target.Kode = kodes[0]
+		target.QtyAwal, target.QtySisa = qty, qty
+		target.BeratAwal, target.BeratSisa = berat, berat
+
+		if _, err = tx.NewInsert().Model(target).Exec(ctx); err != nil {
+			return nil, err
+		}
+		if err := recordLotCreated(ctx, tx, target, stringValue(regrade.CreatedBy), "regrade dari lot "+parent.Kode); err != nil {
+			return nil, err
+		}
+	} else {
+		target.QtyAwal += qty
+		target.QtySisa += qty
+		target.BeratAwal += berat
+		target.BeratSisa += berat
+		_, err = tx.NewUpdate().
+			Model(target).
+			Column("qty_awal", "qty_sisa", "berat_awal", "berat_sisa", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordMutasi(ctx, tx, target, constants.LotMutasiRegradeMasuk, qty, berat, lotRef(parent), stringValue(regrade.CreatedBy)); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*domain.BuahRaw)(nil)).
+		Set("lot_id = ?", target.ID).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", bun.In(fruitIDs)).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parent.QtySisa -= qty
+	parent.BeratSisa -= berat
+	if err := recordMutasi(ctx, tx, parent, constants.LotMutasiRegradeKeluar, -qty, -berat, lotRef(target), stringValue(regrade.CreatedBy)); err != nil {
+		return nil, err
+	}
+	if whole {
+		parent.BeratSisa = 0
+		if err := setLotStatus(ctx, tx, parent, constants.LotStatusEmpty, stringValue(regrade.CreatedBy), "regrade ke "+regrade.KondisiKe+": "+regrade.Alasan); err != nil {
+			return nil, err
+		}
+		if err := releaseHold(ctx, tx, hold, stringValue(regrade.CreatedBy)); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.NewUpdate().
+		Model(parent).
+		Column("qty_sisa", "berat_sisa", "status", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &domain.LotGenealogi{
+		Tipe:        constants.LotGenealogiRegrade,
+		ParentLotID: parent.ID,
+		ChildLotID:  target.ID,
+		Qty:         qty,
+		Berat:       berat,
+		CreatedBy:   regrade.CreatedBy,
+	}
+	if err := insertGenealogi(ctx, tx, gen, fruitIDs); err != nil {
+		return nil, err
+	}
+
+	regrade.GenealogiID = gen.ID
+	regrade.KondisiDari = parent.KondisiBuah
+	if _, err := tx.NewInsert().Model(regrade).Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return gen, tx.Commit()
+}
+
+// GetRegrade lists the regrades a lot took part in, as source or target
+func (r *lotRepository) GetRegrade(ctx context.Context, lotID string) ([]domain.LotRegrade, error) {
+	var list []domain.LotRegrade
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Genealogi").
+		Relation("Genealogi.ParentLot").
+		Relation("Genealogi.ChildLot").
+		Relation("Creator").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("genealogi.parent_lot_id = ?", lotID).WhereOr("genealogi.child_lot_id = ?", lotID)
+		}).
+		Order("regrade.created_at").
+		Scan(ctx)
+	return list, err
+}
+
+func (r *lotRepository) GetStatusRiwayat(ctx context.Context, lotID string) ([]domain.LotStatusRiwayat, error) {
+	var list []domain.LotStatusRiwayat
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Creator").
+		Where("status_riwayat.lot_id = ?", lotID).
+		Order("status_riwayat.created_at").
 		Scan(ctx)
-	if err == nil {
-		nextSeq++
-		newKode = fmt.Sprintf("LOT-%03d", nextSeq)
+	return list, err
+}
+
+func insertGenealogi(ctx context.Context, tx bun.IDB, gen *domain.LotGenealogi, buahRawIDs []string) error {
+	if _, err := tx.NewInsert().Model(gen).Exec(ctx); err != nil {
+		return err
 	}
+	if len(buahRawIDs) == 0 {
+		return nil
+	}
+
+	rows := make([]domain.LotGenealogiBuah, 0, len(buahRawIDs))
+	for _, id := range buahRawIDs {
+		rows = append(rows, domain.LotGenealogiBuah{GenealogiID: gen.ID, BuahRawID: id})
+	}
+	_, err := tx.NewInsert().Model(&rows).Exec(ctx)
+	return err
+}
 
-	return newKode, tx.Commit()
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sameStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"math"
+
+	"github.com/uptrace/bun"
+)
+
+// mutasiRef is the document behind a ledger movement
+type mutasiRef struct {
+	Tipe string
+	ID   string
+	Kode string
+}
+
+// recordMutasi appends a change of the lot balance to the stock ledger, at the lot's current
+// location. Every write of qty_sisa/berat_sisa must go through it. A change of nothing is skipped.
+func recordMutasi(ctx context.Context, tx bun.IDB, lot *domain.StokLot, tipe string, deltaQty int, deltaBerat float64, ref mutasiRef, userID string) error {
+	deltaBerat = math.Round(deltaBerat*100) / 100
+	if deltaQty == 0 && deltaBerat == 0 {
+		return nil
+	}
+
+	mutasi := &domain.LotMutasi{
+		LotID:      lot.ID,
+		LokasiID:   lot.PosisiID,
+		Tipe:       tipe,
+		DeltaQty:   deltaQty,
+		DeltaBerat: deltaBerat,
+	}
+	if ref.Tipe != "" {
+		mutasi.ReferensiTipe = &ref.Tipe
+	}
+	if ref.ID != "" {
+		mutasi.ReferensiID = &ref.ID
+	}
+	if ref.Kode != "" {
+		mutasi.ReferensiKode = &ref.Kode
+	}
+	if userID != "" {
+		mutasi.CreatedBy = &userID
+	}
+	_, err := tx.NewInsert().Model(mutasi).Exec(ctx)
+	return err
+}
+
+// GetMutasi returns the ledger of a lot, oldest first, with the running balance
+func (r *lotRepository) GetMutasi(ctx context.Context, lotID string) ([]domain.LotMutasi, error) {
+	var list []domain.LotMutasi
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Lokasi").
+		Relation("Creator").
+		ColumnExpr("mutasi.*").
+		ColumnExpr("SUM(mutasi.delta_qty) OVER (ORDER BY mutasi.urutan) AS qty_saldo").
+		ColumnExpr("SUM(mutasi.delta_berat) OVER (ORDER BY mutasi.urutan) AS berat_saldo").
+		Where("mutasi.lot_id = ?", lotID).
+		Order("mutasi.urutan").
+		Scan(ctx)
+	return list, err
+}
+
+// GetRekonsiliasi returns the lots whose ledger does not add up to qty_sisa/berat_sisa
+func (r *lotRepository) GetRekonsiliasi(ctx context.Context) ([]domain.LotRekonsiliasi, error) {
+	var list []domain.LotRekonsiliasi
+	err := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_stok_lot AS sl").
+		Join("LEFT JOIN (?) AS m ON m.lot_id = sl.id", r.db.InitQuery(ctx).NewSelect().
+			TableExpr("tb_lot_mutasi").
+			ColumnExpr("lot_id").
+			ColumnExpr("SUM(delta_qty) AS qty").
+			ColumnExpr("SUM(delta_berat) AS berat").
+			GroupExpr("lot_id")).
+		ColumnExpr("sl.id AS lot_id, sl.kode, sl.status, sl.qty_sisa, sl.berat_sisa").
+		ColumnExpr("COALESCE(m.qty, 0) AS ledger_qty").
+		ColumnExpr("COALESCE(m.berat, 0) AS ledger_berat").
+		Where("sl.deleted_at IS NULL").
+		Where("(sl.qty_sisa <> COALESCE(m.qty, 0) OR ABS(sl.berat_sisa - COALESCE(m.berat, 0)) >= 0.01)").
+		OrderExpr("sl.kode").
+		Scan(ctx, &list)
+	return list, err
+}
+
+func lotRef(lot *domain.StokLot) mutasiRef {
+	return mutasiRef{Tipe: constants.LotMutasiRefLot, ID: lot.ID, Kode: lot.Kode}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+
+	"github.com/uptrace/bun"
+)
+
+// lotTransitions is the lot state machine, keyed by the current status. A shipped lot
+// becomes READY again when an internal shipment is received, a SOLD lot goes back to
+// SHIPPED when its sale is deleted.
+var lotTransitions = map[string][]string{
+	constants.LotStatusDraft:   {constants.LotStatusReady},
+	constants.LotStatusReady:   {constants.LotStatusBooked, constants.LotStatusEmpty},
+	constants.LotStatusBooked:  {constants.LotStatusReady, constants.LotStatusShipped},
+	constants.LotStatusShipped: {constants.LotStatusReady, constants.LotStatusSold},
+	constants.LotStatusSold:    {constants.LotStatusShipped},
+	constants.LotStatusEmpty:   {},
+}
+
+func lotTransitionAllowed(from, to string) bool {
+	for _, s := range lotTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// setLotStatus moves lot to status and records the change. The caller must hold the
+// lot row lock and still has to write the status column. Keeping the same status is a no-op.
+func setLotStatus(ctx context.Context, tx bun.IDB, lot *domain.StokLot, status, userID, alasan string) error {
+	if lot.Status == status {
+		return nil
+	}
+	if !lotTransitionAllowed(lot.Status, status) {
+		return validationError("status lot %s tidak bisa berubah dari %s ke %s", lot.Kode, lot.Status, status)
+	}
+
+	from := lot.Status
+	lot.Status = status
+	return insertLotRiwayat(ctx, tx, lot, &from, userID, alasan)
+}
+
+// recordLotCreated records the status a new lot starts with
+func recordLotCreated(ctx context.Context, tx bun.IDB, lot *domain.StokLot, userID, alasan string) error {
+	return insertLotRiwayat(ctx, tx, lot, nil, userID, alasan)
+}
+
+func insertLotRiwayat(ctx context.Context, tx bun.IDB, lot *domain.StokLot, from *string, userID, alasan string) error {
+	riwayat := &domain.LotStatusRiwayat{
+		LotID:      lot.ID,
+		StatusDari: from,
+		StatusKe:   lot.Status,
+	}
+	if alasan != "" {
+		riwayat.Alasan = &alasan
+	}
+	if userID != "" {
+		riwayat.CreatedBy = &userID
+	}
+	_, err := tx.NewInsert().Model(riwayat).Exec(ctx)
+	return err
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"testing"
+)
+
+func TestLotTransitionAllowed(t *testing.T) {
+	statuses := []string{
+		constants.LotStatusDraft,
+		constants.LotStatusReady,
+		constants.LotStatusBooked,
+		constants.LotStatusShipped,
+		constants.LotStatusSold,
+		constants.LotStatusEmpty,
+	}
+
+	allowed := map[[2]string]bool{
+		{constants.LotStatusDraft, constants.LotStatusReady}:    true,
+		{constants.LotStatusReady, constants.LotStatusBooked}:   true,
+		{constants.LotStatusReady, constants.LotStatusEmpty}:    true,
+		{constants.LotStatusBooked, constants.LotStatusReady}:   true,
+		{constants.LotStatusBooked, constants.LotStatusShipped}: true,
+		{constants.LotStatusShipped, constants.LotStatusReady}:  true,
+		{constants.LotStatusShipped, constants.LotStatusSold}:   true,
+		{constants.LotStatusSold, constants.LotStatusShipped}:   true,
+	}
+
+	for _, from := range statuses {
+		if _, ok := lotTransitions[from]; !ok {
+			t.Errorf("status %s has no entry in lotTransitions", from)
+		}
+		for _, to := range statuses {
+			want := allowed[[2]string{from, to}]
+			if got := lotTransitionAllowed(from, to); got != want {
+				t.Errorf("lotTransitionAllowed(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if lotTransitionAllowed("UNKNOWN", constants.LotStatusReady) {
+		t.Error("an unknown status must not move anywhere")
+	}
+}
+
+func TestSetLotStatusWithoutWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"same status is a no-op", constants.LotStatusReady, constants.LotStatusReady, false},
+		{"draft cannot ship", constants.LotStatusDraft, constants.LotStatusShipped, true},
+		{"empty is final", constants.LotStatusEmpty, constants.LotStatusReady, true},
+		{"sold cannot be emptied", constants.LotStatusSold, constants.LotStatusEmpty, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lot := &domain.StokLot{Kode: "LOT-1", Status: tt.from}
+			// Neither case reaches the database
+			err := setLotStatus(context.Background(), nil, lot, tt.to, "", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setLotStatus error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if lot.Status != tt.from {
+				t.Errorf("lot status changed to %s", lot.Status)
+			}
+		})
+	}
+}
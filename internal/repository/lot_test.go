@@ -0,0 +1,32 @@
+package repository
+
+import "testing"
+
+func TestProrataBerat(t *testing.T) {
+	tests := []struct {
+		name       string
+		beratSisa  float64
+		berat      float64
+		totalBerat float64
+		qty        int
+		totalQty   int
+		want       float64
+	}{
+		{name: "share of the weighed fruits", beratSisa: 20, berat: 5, totalBerat: 25, qty: 2, totalQty: 10, want: 4},
+		{name: "share by count without weights", beratSisa: 20, qty: 2, totalQty: 10, want: 4},
+		{name: "rounded to two places", beratSisa: 10, qty: 1, totalQty: 3, want: 3.33},
+		{name: "shrunk lot keeps the proportion", beratSisa: 18, berat: 10, totalBerat: 20, qty: 5, totalQty: 10, want: 9},
+		{name: "leaves the parent some weight", beratSisa: 10, berat: 9.999, totalBerat: 10, qty: 9, totalQty: 10, want: 9.99},
+		{name: "nothing to share", beratSisa: 10, want: 0},
+		{name: "never negative", beratSisa: 0, berat: 1, totalBerat: 2, qty: 1, totalQty: 2, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := prorataBerat(tt.beratSisa, tt.berat, tt.totalBerat, tt.qty, tt.totalQty)
+			if got != tt.want {
+				t.Errorf("prorataBerat = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/uptrace/bun"
+)
+
+// GetTersimpan returns the lots held in storage, READY or BOOKED with fruits left, oldest first
+func (r *lotRepository) GetTersimpan(ctx context.Context, locationID, jenisDurianID string) ([]domain.StokLot, error) {
+	var lots []domain.StokLot
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&lots).
+		Relation("JenisDurianDetail").
+		Relation("Posisi").
+		Where("stok_lot.deleted_at IS NULL").
+		Where("stok_lot.status IN (?)", bun.In([]string{constants.LotStatusReady, constants.LotStatusBooked})).
+		Where("stok_lot.qty_sisa > 0")
+	if locationID != "" {
+		query.Where("stok_lot.current_location_id = ?", locationID)
+	}
+	if jenisDurianID != "" {
+		query.Where("stok_lot.jenis_durian_id = ?", jenisDurianID)
+	}
+
+	err := query.
+		OrderExpr("COALESCE(stok_lot.arrived_at, stok_lot.finalized_at)").
+		Scan(ctx)
+	return lots, err
+}
+
+// GetSiapKirim returns the READY lots of a jenis and grade userID can ship from locationID,
+// leaving out lots held by someone else. The central warehouse, an empty locationID, only
+// ships its own lots.
+func (r *lotRepository) GetSiapKirim(ctx context.Context, locationID, jenisDurianID, kondisi, userID string) ([]domain.StokLot, error) {
+	var lots []domain.StokLot
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&lots).
+		Relation("JenisDurianDetail").
+		Where("stok_lot.deleted_at IS NULL").
+		Where("stok_lot.status = ?", constants.LotStatusReady).
+		Where("stok_lot.qty_sisa > 0").
+		Where("stok_lot.jenis_durian_id = ?", jenisDurianID).
+		Where("stok_lot.kondisi_buah = ?", kondisi).
+		Where("NOT EXISTS (SELECT 1 FROM tb_lot_hold AS hold WHERE hold.lot_id = stok_lot.id AND hold.status = ? AND hold.berakhir_at > NOW() AND hold.dipegang_oleh <> ?)", constants.HoldStatusAktif, userID)
+	if locationID == "" {
+		query.Where("stok_lot.current_location_id IS NULL")
+	} else {
+		query.Where("stok_lot.current_location_id = ?", locationID)
+	}
+
+	err := query.Order("stok_lot.kode").Scan(ctx)
+	return lots, err
+}
+
+// TimbangUlang records a new weight of a READY lot and makes it the lot's berat_sisa. The
+// weight lost, or gained, goes to the ledger.
+func (r *lotRepository) TimbangUlang(ctx context.Context, tu *domain.LotTimbangUlang, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lot := new(domain.StokLot)
+	err = tx.NewSelect().
+		Model(lot).
+		Where("id = ?", tu.LotID).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return err
+	}
+	if lot.Status != constants.LotStatusReady {
+		return fmt.Errorf("lot %s tidak berstatus READY", lot.Kode)
+	}
+	if lot.QtySisa == 0 {
+		return errors.New("lot sudah kosong")
+	}
+
+	tu.LokasiID = lot.PosisiID
+	tu.Qty = lot.QtySisa
+	tu.BeratSebelum = lot.BeratSisa
+	tu.BeratAcuan = lot.BeratSisa
+	if lot.QtyAwal > 0 {
+		tu.BeratAcuan = math.Round(lot.BeratAwal*float64(lot.QtySisa)/float64(lot.QtyAwal)*100) / 100
+	}
+	tu.Susut = math.Round((tu.BeratAcuan-tu.Berat)*100) / 100
+	if userID != "" {
+		tu.CreatedBy = &userID
+	}
+	if _, err := tx.NewInsert().Model(tu).Exec(ctx); err != nil {
+		return err
+	}
+
+	ref := mutasiRef{Tipe: constants.LotMutasiRefTimbangUlang, ID: tu.ID}
+	if err := recordMutasi(ctx, tx, lot, constants.LotMutasiTimbangUlang, 0, tu.Berat-lot.BeratSisa, ref, userID); err != nil {
+		return err
+	}
+
+	lot.BeratSisa = tu.Berat
+	_, err = tx.NewUpdate().
+		Model(lot).
+		Column("berat_sisa", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *lotRepository) GetTimbangUlang(ctx context.Context, lotID string) ([]domain.LotTimbangUlang, error) {
+	var list []domain.LotTimbangUlang
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Creator").
+		Where("timbang_ulang.lot_id = ?", lotID).
+		Order("timbang_ulang.created_at DESC").
+		Scan(ctx)
+	return list, err
+}
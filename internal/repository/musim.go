@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"time"
+)
+
+type MusimRepository interface {
+	Create(ctx context.Context, musim *domain.MusimPanen) error
+	Update(ctx context.Context, musim *domain.MusimPanen) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*domain.MusimPanen, error)
+	GetList(ctx context.Context, estateID, status string) ([]domain.MusimPanen, error)
+	GetByEstate(ctx context.Context, estateID string) ([]domain.MusimPanen, error)
+	GetAktifByEstate(ctx context.Context, estateID string) (*domain.MusimPanen, error)
+	IsUsed(ctx context.Context, id string) (bool, error)
+	GetRingkasan(ctx context.Context, id string) (*domain.MusimRingkasan, error)
+}
+
+type musimRepository struct {
+	db *database.Database
+}
+
+func NewMusimRepository(db *database.Database) MusimRepository {
+	return &musimRepository{db: db}
+}
+
+func (r *musimRepository) Create(ctx context.Context, musim *domain.MusimPanen) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(musim).Exec(ctx)
+	return err
+}
+
+func (r *musimRepository) Update(ctx context.Context, musim *domain.MusimPanen) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().Model(musim).WherePK().ExcludeColumn("created_at").Exec(ctx)
+	return err
+}
+
+func (r *musimRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().Model((*domain.MusimPanen)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *musimRepository) GetByID(ctx context.Context, id string) (*domain.MusimPanen, error) {
+	musim := new(domain.MusimPanen)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(musim).
+		Relation("Estate").
+		Where("musim.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return musim, err
+}
+
+func (r *musimRepository) GetList(ctx context.Context, estateID, status string) ([]domain.MusimPanen, error) {
+	var list []domain.MusimPanen
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Estate").
+		Order("musim.tanggal_mulai DESC")
+	if estateID != "" {
+		query.Where("musim.estate_id = ?", estateID)
+	}
+	if status != "" {
+		query.Where("musim.status = ?", status)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+func (r *musimRepository) GetByEstate(ctx context.Context, estateID string) ([]domain.MusimPanen, error) {
+	var list []domain.MusimPanen
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Where("musim.estate_id = ?", estateID).
+		Order("musim.tanggal_mulai DESC").
+		Scan(ctx)
+	return list, err
+}
+
+func (r *musimRepository) GetAktifByEstate(ctx context.Context, estateID string) (*domain.MusimPanen, error) {
+	musim := new(domain.MusimPanen)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(musim).
+		Where("musim.estate_id = ?", estateID).
+		Where("musim.status = ?", constants.MusimStatusAktif).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return musim, err
+}
+
+// IsUsed reports whether any harvest, lot or sale is tagged with the season
+func (r *musimRepository) IsUsed(ctx context.Context, id string) (bool, error) {
+	for _, table := range []string{"tb_buah_raw", "tb_stok_lot", "tb_penjualan"} {
+		exists, err := r.db.InitQuery(ctx).NewSelect().
+			TableExpr(table).
+			Where("musim_id = ?", id).
+			Where("deleted_at IS NULL").
+			Exists(ctx)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+func (r *musimRepository) GetRingkasan(ctx context.Context, id string) (*domain.MusimRingkasan, error) {
+	ringkasan := &domain.MusimRingkasan{DihitungAt: time.Now()}
+	db := r.db.InitQuery(ctx)
+
+	err := db.NewSelect().
+		TableExpr("tb_buah_raw AS br").
+		ColumnExpr("COUNT(*) AS panen_qty").
+		ColumnExpr("COALESCE(SUM(br.berat), 0) AS panen_berat").
+		ColumnExpr("COUNT(*) FILTER (WHERE br.lot_id IS NOT NULL) AS grading_qty").
+		ColumnExpr("COALESCE(SUM(br.berat) FILTER (WHERE br.lot_id IS NOT NULL), 0) AS grading_berat").
+		Where("br.musim_id = ?", id).
+		Where("br.deleted_at IS NULL").
+		Scan(ctx, &ringkasan.PanenQty, &ringkasan.PanenBerat, &ringkasan.GradingQty, &ringkasan.GradingBerat)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.NewSelect().
+		TableExpr("tb_stok_lot AS sl").
+		Join("LEFT JOIN tb_buah_raw AS br ON br.lot_id = sl.id AND br.deleted_at IS NULL").
+		ColumnExpr("sl.kondisi_buah AS grade").
+		ColumnExpr("COUNT(DISTINCT sl.id) AS lot_count").
+		ColumnExpr("COUNT(br.id) AS qty").
+		ColumnExpr("COALESCE(SUM(br.berat), 0) AS berat").
+		Where("sl.musim_id = ?", id).
+		Where("sl.deleted_at IS NULL").
+		GroupExpr("sl.kondisi_buah").
+		OrderExpr("sl.kondisi_buah").
+		Scan(ctx, &ringkasan.Grade)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range ringkasan.Grade {
+		ringkasan.LotCount += g.LotCount
+	}
+
+	err = db.NewSelect().
+		TableExpr("tb_pengiriman_detail AS pd").
+		Join("JOIN tb_pengiriman AS p ON p.id = pd.pengiriman_id AND p.deleted_at IS NULL").
+		Join("JOIN tb_stok_lot AS sl ON sl.id = pd.lot_sumber_id").
+		ColumnExpr("COUNT(DISTINCT p.id)").
+		ColumnExpr("COALESCE(SUM(pd.qty_ambil), 0)").
+		ColumnExpr("COALESCE(SUM(pd.berat_ambil), 0)").
+		Where("sl.musim_id = ?", id).
+		Scan(ctx, &ringkasan.DikirimCount, &ringkasan.DikirimQty, &ringkasan.DikirimBerat)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.NewSelect().
+		TableExpr("tb_penjualan AS penjualan").
+		ColumnExpr("COUNT(*)").
+		ColumnExpr("COALESCE(SUM(penjualan.berat_terjual), 0)").
+		ColumnExpr("COALESCE(SUM(penjualan.harga_total), 0)").
+		Where("penjualan.musim_id = ?", id).
+		Where("penjualan.deleted_at IS NULL").
+		Scan(ctx, &ringkasan.TerjualCount, &ringkasan.TerjualBerat, &ringkasan.Pendapatan)
+	if err != nil {
+		return nil, err
+	}
+
+	if ringkasan.PanenQty > 0 {
+		ringkasan.GradingYield = float64(ringkasan.GradingQty) * 100 / float64(ringkasan.PanenQty)
+	}
+	if ringkasan.TerjualBerat > 0 {
+		ringkasan.RataHargaPerKg = ringkasan.Pendapatan / ringkasan.TerjualBerat
+	}
+	if ringkasan.Grade == nil {
+		ringkasan.Grade = []domain.MusimRingkasanGrade{}
+	}
+
+	return ringkasan, nil
+}
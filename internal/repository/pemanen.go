@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+
+	"github.com/uptrace/bun"
+)
+
+type PemanenRepository interface {
+	Create(ctx context.Context, pemanen *domain.Pemanen) error
+	Update(ctx context.Context, pemanen *domain.Pemanen) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*domain.Pemanen, error)
+	GetByIDs(ctx context.Context, ids []string) (map[string]domain.Pemanen, error)
+	GetList(ctx context.Context, estateID, divisiID string, aktif *bool) ([]domain.Pemanen, error)
+
+	CreateTarif(ctx context.Context, tarif *domain.TarifPanen) error
+	UpdateTarif(ctx context.Context, tarif *domain.TarifPanen) error
+	DeleteTarif(ctx context.Context, id string) error
+	GetTarifByID(ctx context.Context, id string) (*domain.TarifPanen, error)
+	GetTarifList(ctx context.Context, jenisDurianID string) ([]domain.TarifPanen, error)
+
+	GetPanenSummary(ctx context.Context, startDate, endDate, estateID, pemanenID string) ([]domain.PanenPemanenSummary, error)
+}
+
+type pemanenRepository struct {
+	db *database.Database
+}
+
+func NewPemanenRepository(db *database.Database) PemanenRepository {
+	return &pemanenRepository{db: db}
+}
+
+func (r *pemanenRepository) Create(ctx context.Context, pemanen *domain.Pemanen) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(pemanen).Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) Update(ctx context.Context, pemanen *domain.Pemanen) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().Model(pemanen).WherePK().ExcludeColumn("created_at").Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().Model((*domain.Pemanen)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) GetByID(ctx context.Context, id string) (*domain.Pemanen, error) {
+	pemanen := new(domain.Pemanen)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(pemanen).
+		Relation("Estate").
+		Relation("Divisi").
+		Where("pemanen.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pemanen, err
+}
+
+func (r *pemanenRepository) GetByIDs(ctx context.Context, ids []string) (map[string]domain.Pemanen, error) {
+	var list []domain.Pemanen
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Where("pemanen.id IN (?)", bun.In(ids)).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]domain.Pemanen, len(list))
+	for _, p := range list {
+		result[p.ID] = p
+	}
+	return result, nil
+}
+
+func (r *pemanenRepository) GetList(ctx context.Context, estateID, divisiID string, aktif *bool) ([]domain.Pemanen, error) {
+	var list []domain.Pemanen
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Estate").
+		Relation("Divisi").
+		Order("pemanen.nama ASC")
+
+	if estateID != "" {
+		query = query.Where("pemanen.estate_id = ?", estateID)
+	}
+	if divisiID != "" {
+		query = query.Where("pemanen.divisi_id = ?", divisiID)
+	}
+	if aktif != nil {
+		query = query.Where("pemanen.aktif = ?", *aktif)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+func (r *pemanenRepository) CreateTarif(ctx context.Context, tarif *domain.TarifPanen) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(tarif).Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) UpdateTarif(ctx context.Context, tarif *domain.TarifPanen) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().Model(tarif).WherePK().ExcludeColumn("created_at").Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) DeleteTarif(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().Model((*domain.TarifPanen)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *pemanenRepository) GetTarifByID(ctx context.Context, id string) (*domain.TarifPanen, error) {
+	tarif := new(domain.TarifPanen)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(tarif).
+		Relation("JenisDurian").
+		Where("tarif.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tarif, err
+}
+
+func (r *pemanenRepository) GetTarifList(ctx context.Context, jenisDurianID string) ([]domain.TarifPanen, error) {
+	var list []domain.TarifPanen
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("JenisDurian").
+		Order("tarif.jenis_durian_id ASC", "tarif.grade ASC", "tarif.berlaku_mulai DESC")
+
+	if jenisDurianID != "" {
+		query = query.Where("tarif.jenis_durian_id = ?", jenisDurianID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+// GetPanenSummary groups attributed fruits per harvester, jenis, grade and day. Grade is the
+// kondisi the fruit got when its lot was finalized, empty until then. Later regrades do not
+// change it.
+func (r *pemanenRepository) GetPanenSummary(ctx context.Context, startDate, endDate, estateID, pemanenID string) ([]domain.PanenPemanenSummary, error) {
+	var rows []domain.PanenPemanenSummary
+	query := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_buah_raw AS buah_raw").
+		Join("JOIN tb_pemanen AS pemanen ON pemanen.id = buah_raw.pemanen_id").
+		ColumnExpr("buah_raw.pemanen_id").
+		ColumnExpr("buah_raw.jenis_durian").
+		ColumnExpr("COALESCE(buah_raw.kondisi_grading, '') AS grade").
+		ColumnExpr("to_char(buah_raw.tgl_panen, 'YYYY-MM-DD') AS tgl_panen").
+		ColumnExpr("COUNT(*) AS qty").
+		ColumnExpr("COALESCE(SUM(buah_raw.berat), 0) AS berat").
+		Where("buah_raw.deleted_at IS NULL").
+		Where("buah_raw.tgl_panen BETWEEN ? AND ?", startDate, endDate).
+		GroupExpr("1, 2, 3, 4").
+		OrderExpr("1, 4")
+
+	if estateID != "" {
+		query = query.Where("pemanen.estate_id = ?", estateID)
+	}
+	if pemanenID != "" {
+		query = query.Where("buah_raw.pemanen_id = ?", pemanenID)
+	}
+
+	err := query.Scan(ctx, &rows)
+	return rows, err
+}
@@ -10,11 +10,11 @@ import (
 )
 
 type SalesRepository interface {
-	Create(ctx context.Context, sales *domain.Penjualan) error
+	Create(ctx context.Context, sales *domain.Penjualan, userID string) error
 	GetList(ctx context.Context, startDate, endDate, tipeJual, locationID string) ([]domain.Penjualan, error)
 	GetByID(ctx context.Context, id string) (*domain.Penjualan, error)
 	Update(ctx context.Context, sales *domain.Penjualan) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id, userID string) error
 	GetPengirimanByID(ctx context.Context, id string) (*domain.Pengiriman, error)
 	UpdatePengirimanStatus(ctx context.Context, id, status string) error
 	CheckSalesExistByShipmentID(ctx context.Context, shipmentID string) (bool, error)
@@ -28,7 +28,7 @@ func NewSalesRepository(db *database.Database) SalesRepository {
 	return &salesRepository{db: db}
 }
 
-func (r *salesRepository) Create(ctx context.Context, sales *domain.Penjualan) error {
+func (r *salesRepository) Create(ctx context.Context, sales *domain.Penjualan, userID string) error {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
@@ -51,29 +51,11 @@ func (r *salesRepository) Create(ctx context.Context, sales *domain.Penjualan) e
 		return err
 	}
 
-	// 3. Get Lot IDs from Shipment Details
-	var detailIDs []string
-	err = tx.NewSelect().
-		Model((*domain.PengirimanDetail)(nil)).
-		Column("lot_sumber_id").
-		Where("pengiriman_id = ?", sales.PengirimanID).
-		Scan(ctx, &detailIDs)
-	if err != nil {
+	// 3. Update Lot Status -> SOLD
+	if err := setShipmentLotStatus(ctx, tx, sales.PengirimanID, constants.LotStatusShipped, constants.LotStatusSold, userID, "terjual"); err != nil {
 		return err
 	}
 
-	// 4. Update Lot Status -> SOLD
-	if len(detailIDs) > 0 {
-		_, err = tx.NewUpdate().
-			Model((*domain.StokLot)(nil)).
-			Set("status = ?", constants.LotStatusSold).
-			Where("id IN (?)", bun.In(detailIDs)).
-			Exec(ctx)
-		if err != nil {
-			return err
-		}
-	}
-
 	return tx.Commit()
 }
 
@@ -135,7 +117,7 @@ func (r *salesRepository) Update(ctx context.Context, sales *domain.Penjualan) e
 	return err
 }
 
-func (r *salesRepository) Delete(ctx context.Context, id string) error {
+func (r *salesRepository) Delete(ctx context.Context, id, userID string) error {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
@@ -169,14 +151,54 @@ func (r *salesRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Restore Lot Status -> SHIPPED
+	if err := setShipmentLotStatus(ctx, tx, sales.PengirimanID, constants.LotStatusSold, constants.LotStatusShipped, userID, "penjualan dibatalkan"); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// setShipmentLotStatus moves the lots a shipment took whole from one status to another.
+// Partially taken lots stay with their origin and are left alone. Every lot must be in from.
+func setShipmentLotStatus(ctx context.Context, tx bun.Tx, shipmentID, from, to, userID, alasan string) error {
+	var lots []domain.StokLot
+	err := tx.NewSelect().
+		Model(&lots).
+		Where("id IN (?)", tx.NewSelect().
+			Model((*domain.PengirimanDetail)(nil)).
+			Column("lot_sumber_id").
+			Where("pengiriman_id = ?", shipmentID).
+			Where("parsial = false")).
+		Order("id").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range lots {
+		lot := &lots[i]
+		if lot.Status != from {
+			return validationError("lot %s berstatus %s, bukan %s", lot.Kode, lot.Status, from)
+		}
+		if err := setLotStatus(ctx, tx, lot, to, userID, alasan); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model(lot).Column("status", "updated_at").WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *salesRepository) GetPengirimanByID(ctx context.Context, id string) (*domain.Pengiriman, error) {
 	shipment := new(domain.Pengiriman)
 	err := r.db.InitQuery(ctx).NewSelect().
 		Model(shipment).
 		Relation("Details").
+		Relation("Details.Lot").
 		Where("p.id = ?", id).
 		Scan(ctx)
 	if err != nil {
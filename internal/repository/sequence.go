@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"fmt"
+	"sort"
+
+	"github.com/uptrace/bun"
+)
+
+// SequenceSpec tells a repository which counter a new document draws its number from
+// and how that number is rendered into the document code.
+type SequenceSpec struct {
+	Prefix string
+	Period string
+	Format func(seq int) string
+}
+
+type SequenceRepository interface {
+	Reserve(ctx context.Context, idb bun.IDB, prefix, period string, n int) (int, error)
+}
+
+type sequenceRepository struct {
+	db *database.Database
+}
+
+func NewSequenceRepository(db *database.Database) SequenceRepository {
+	return &sequenceRepository{db: db}
+}
+
+// Reserve allocates n consecutive numbers and returns the first one.
+// It must run on the caller's transaction: the counter row stays locked until that transaction ends,
+// so concurrent callers queue up behind it and a rollback hands the numbers back.
+func (r *sequenceRepository) Reserve(ctx context.Context, idb bun.IDB, prefix, period string, n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("jumlah sequence harus lebih dari 0")
+	}
+
+	seq := &domain.DocumentSequence{
+		Prefix:    prefix,
+		Period:    period,
+		LastValue: n,
+	}
+
+	_, err := idb.NewInsert().
+		Model(seq).
+		On("CONFLICT (prefix, period) DO UPDATE").
+		Set("last_value = doc_seq.last_value + EXCLUDED.last_value").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("last_value").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return seq.LastValue - n + 1, nil
+}
+
+// reserveKodes draws one code per spec, grouping specs that share a counter into a single reservation
+func reserveKodes(ctx context.Context, idb bun.IDB, sequenceRepo SequenceRepository, specs []SequenceSpec) ([]string, error) {
+	type counter struct {
+		prefix, period string
+	}
+
+	counts := make(map[counter]int)
+	order := make([]counter, 0)
+	for _, spec := range specs {
+		key := counter{spec.Prefix, spec.Period}
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	// Lock counters in a fixed order so two bulk requests can never deadlock on each other
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].prefix != order[j].prefix {
+			return order[i].prefix < order[j].prefix
+		}
+		return order[i].period < order[j].period
+	})
+
+	next := make(map[counter]int, len(order))
+	for _, key := range order {
+		first, err := sequenceRepo.Reserve(ctx, idb, key.prefix, key.period, counts[key])
+		if err != nil {
+			return nil, err
+		}
+		next[key] = first
+	}
+
+	kodes := make([]string, len(specs))
+	for i, spec := range specs {
+		key := counter{spec.Prefix, spec.Period}
+		kodes[i] = spec.Format(next[key])
+		next[key]++
+	}
+	return kodes, nil
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type SesiGradingRepository interface {
+	Create(ctx context.Context, sesi *domain.SesiGrading, specs []SequenceSpec, userID string) error
+	GetByID(ctx context.Context, id string) (*domain.SesiGrading, error)
+	GetList(ctx context.Context, status, jenisDurianID string) ([]domain.SesiGrading, error)
+	Close(ctx context.Context, sesi *domain.SesiGrading, userID string) error
+	GetIsi(ctx context.Context, id string) ([]domain.SesiGradingIsi, error)
+	GetGrader(ctx context.Context, id string) ([]domain.SesiGradingGrader, error)
+	GetBelumGrading(ctx context.Context, jenisDurianID string, musimID *string) (int, float64, error)
+}
+
+type sesiGradingRepository struct {
+	db           *database.Database
+	sequenceRepo SequenceRepository
+}
+
+func NewSesiGradingRepository(db *database.Database, sequenceRepo SequenceRepository) SesiGradingRepository {
+	return &sesiGradingRepository{
+		db:           db,
+		sequenceRepo: sequenceRepo,
+	}
+}
+
+// Create inserts the session together with its new DRAFT lots, sesi.Lots[i].Lot coded by specs[i]
+func (r *sesiGradingRepository) Create(ctx context.Context, sesi *domain.SesiGrading, specs []SequenceSpec, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, specs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.NewInsert().Model(sesi).Exec(ctx); err != nil {
+		return err
+	}
+	for i := range sesi.Lots {
+		lot := sesi.Lots[i].Lot
+		lot.Kode = kodes[i]
+		if _, err := tx.NewInsert().Model(lot).Exec(ctx); err != nil {
+			return err
+		}
+		if err := recordLotCreated(ctx, tx, lot, userID, "lot dibuat untuk sesi grading"); err != nil {
+			return err
+		}
+		sesi.Lots[i].SesiID = sesi.ID
+		sesi.Lots[i].LotID = lot.ID
+	}
+	if _, err := tx.NewInsert().Model(&sesi.Lots).Exec(ctx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sesiGradingRepository) GetByID(ctx context.Context, id string) (*domain.SesiGrading, error) {
+	sesi := new(domain.SesiGrading)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(sesi).
+		Relation("JenisDurian").
+		Relation("Lots", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("sesi_lot.kondisi_buah")
+		}).
+		Relation("Lots.Lot").
+		Where("sesi.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sesi, err
+}
+
+func (r *sesiGradingRepository) GetList(ctx context.Context, status, jenisDurianID string) ([]domain.SesiGrading, error) {
+	var list []domain.SesiGrading
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("JenisDurian").
+		Relation("Lots", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("sesi_lot.kondisi_buah")
+		}).
+		Relation("Lots.Lot").
+		Order("sesi.created_at DESC")
+	if status != "" {
+		query.Where("sesi.status = ?", status)
+	}
+	if jenisDurianID != "" {
+		query.Where("sesi.jenis_durian_id = ?", jenisDurianID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+// Close finalizes every lot of the session in one transaction. Lots left without fruit are
+// discarded. The registered fruit the session drew from, what it graded plus what is still
+// unsorted, is frozen into the session.
+func (r *sesiGradingRepository) Close(ctx context.Context, sesi *domain.SesiGrading, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := new(domain.SesiGrading)
+	err = tx.NewSelect().Model(current).Where("id = ?", sesi.ID).For("UPDATE").Scan(ctx)
+	if err != nil {
+		return err
+	}
+	if current.Status != constants.SesiGradingStatusBuka {
+		return errors.New("sesi grading sudah ditutup")
+	}
+
+	var lots []domain.StokLot
+	err = tx.NewSelect().
+		Model(&lots).
+		Where("id IN (?)", tx.NewSelect().
+			Model((*domain.SesiGradingLot)(nil)).
+			Column("lot_id").
+			Where("sesi_id = ?", sesi.ID)).
+		Where("deleted_at IS NULL").
+		Order("id").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	gradedQty, gradedBerat := 0, 0.0
+	for i := range lots {
+		lot := &lots[i]
+		if lot.Status != constants.LotStatusDraft {
+			return fmt.Errorf("lot %s sudah tidak berstatus DRAFT", lot.Kode)
+		}
+
+		var qty int
+		var berat float64
+		err = tx.NewSelect().
+			Model((*domain.BuahRaw)(nil)).
+			ColumnExpr("COUNT(*)").
+			ColumnExpr("COALESCE(SUM(berat), 0)").
+			Where("lot_id = ?", lot.ID).
+			Where("deleted_at IS NULL").
+			Scan(ctx, &qty, &berat)
+		if err != nil {
+			return err
+		}
+
+		if qty == 0 {
+			_, err = tx.NewUpdate().
+				Model(lot).
+				Set("deleted_at = NOW()").
+				Set("updated_at = NOW()").
+				WherePK().
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusReady, userID, "penutupan sesi grading"); err != nil {
+			return err
+		}
+		if err := recordGrading(ctx, tx, lot); err != nil {
+			return err
+		}
+		ref := mutasiRef{Tipe: constants.LotMutasiRefSesiGrading, ID: sesi.ID}
+		if err := recordMutasi(ctx, tx, lot, constants.LotMutasiFinalisasi, qty-lot.QtySisa, berat-lot.BeratSisa, ref, userID); err != nil {
+			return err
+		}
+		lot.QtyAwal, lot.QtySisa = qty, qty
+		lot.BeratAwal, lot.BeratSisa = berat, berat
+		lot.FinalizedAt = &now
+		_, err = tx.NewUpdate().
+			Model(lot).
+			Column("berat_awal", "qty_awal", "berat_sisa", "qty_sisa", "status", "finalized_at", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		gradedQty += qty
+		gradedBerat += berat
+	}
+
+	sisaQty, sisaBerat, err := belumGrading(ctx, tx, sesi.JenisDurianID, sesi.MusimID)
+	if err != nil {
+		return err
+	}
+
+	sesi.Status = constants.SesiGradingStatusDitutup
+	sesi.TerdaftarQty = gradedQty + sisaQty
+	sesi.TerdaftarBerat = gradedBerat + sisaBerat
+	sesi.DitutupOleh = &userID
+	sesi.DitutupAt = &now
+	_, err = tx.NewUpdate().
+		Model(sesi).
+		Column("status", "terdaftar_qty", "terdaftar_berat", "ditutup_oleh", "ditutup_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetIsi returns the fruit currently in each lot of the session
+func (r *sesiGradingRepository) GetIsi(ctx context.Context, id string) ([]domain.SesiGradingIsi, error) {
+	var isi []domain.SesiGradingIsi
+	err := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_sesi_grading_lot AS sl").
+		Join("JOIN tb_buah_raw AS br ON br.lot_id = sl.lot_id AND br.deleted_at IS NULL").
+		ColumnExpr("sl.lot_id AS lot_id").
+		ColumnExpr("COUNT(*) AS qty").
+		ColumnExpr("COALESCE(SUM(br.berat), 0) AS berat").
+		Where("sl.sesi_id = ?", id).
+		GroupExpr("sl.lot_id").
+		Scan(ctx, &isi)
+	return isi, err
+}
+
+// GetGrader counts the fruits each user scanned into the session lots. A fruit that left
+// the lot it was scanned into, released or moved to another lot, no longer counts.
+func (r *sesiGradingRepository) GetGrader(ctx context.Context, id string) ([]domain.SesiGradingGrader, error) {
+	var list []domain.SesiGradingGrader
+	err := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_lot_scan AS scan").
+		Join("JOIN tb_sesi_grading_lot AS sl ON sl.lot_id = scan.lot_id").
+		Join("JOIN tb_buah_raw AS br ON br.id = scan.buah_raw_id AND br.lot_id = scan.lot_id AND br.deleted_at IS NULL").
+		Join("LEFT JOIN users AS u ON u.id = scan.created_by").
+		ColumnExpr("COALESCE(scan.created_by, '') AS grader_id").
+		ColumnExpr("COALESCE(u.email, '') AS email").
+		ColumnExpr("COUNT(DISTINCT scan.buah_raw_id) AS qty").
+		ColumnExpr("COALESCE(SUM(br.berat), 0) AS berat").
+		ColumnExpr("MIN(scan.created_at) AS mulai_at").
+		ColumnExpr("MAX(scan.created_at) AS selesai_at").
+		Where("sl.sesi_id = ?", id).
+		GroupExpr("scan.created_by, u.email").
+		OrderExpr("qty DESC").
+		Scan(ctx, &list)
+	return list, err
+}
+
+// GetBelumGrading returns the registered fruit of the jenis, and of the season when given, that is not in a lot yet
+func (r *sesiGradingRepository) GetBelumGrading(ctx context.Context, jenisDurianID string, musimID *string) (int, float64, error) {
+	return belumGrading(ctx, r.db.InitQuery(ctx), jenisDurianID, musimID)
+}
+
+func belumGrading(ctx context.Context, db bun.IDB, jenisDurianID string, musimID *string) (int, float64, error) {
+	var qty int
+	var berat float64
+	query := db.NewSelect().
+		Model((*domain.BuahRaw)(nil)).
+		ColumnExpr("COUNT(*)").
+		ColumnExpr("COALESCE(SUM(berat), 0)").
+		Where("jenis_durian = ?", jenisDurianID).
+		Where("lot_id IS NULL").
+		Where("deleted_at IS NULL")
+	if musimID != nil {
+		query.Where("musim_id = ?", *musimID)
+	}
+	err := query.Scan(ctx, &qty, &berat)
+	return qty, berat, err
+}
@@ -6,41 +6,104 @@ import (
 	"durich-be/internal/domain"
 	"durich-be/pkg/database"
 	"errors"
-	"fmt"
+	"math"
 	"time"
 
 	"github.com/uptrace/bun"
 )
 
+// ShipmentReceiveItem is what arrived of one lot. A partial detail arrives as a new lot coded by
+// Spec, taking QtyAmbil fruits of the source lot with it.
 type ShipmentReceiveItem struct {
-	Berat float64
-	Qty   int
+	Berat    float64
+	Qty      int
+	QtyAmbil int
+	Parsial  bool
+	Spec     SequenceSpec
+}
+
+// ShipmentSplit splits the fruits BuahRawIDs off the lot ParentID into Child, coded by Spec,
+// so the shipment can take Child whole
+type ShipmentSplit struct {
+	ParentID   string
+	Child      *domain.StokLot
+	Spec       SequenceSpec
+	BuahRawIDs []string
 }
 
 type ShipmentRepository interface {
-	Create(ctx context.Context, shipment *domain.Pengiriman) error
+	Create(ctx context.Context, shipment *domain.Pengiriman, spec SequenceSpec) error
 	GetByID(ctx context.Context, id string) (*domain.Pengiriman, error)
 	GetList(ctx context.Context, tujuan, status, locationID, listType, tujuanType string, page, limit int) ([]domain.Pengiriman, int64, error)
-	AddItem(ctx context.Context, detail *domain.PengirimanDetail, locationID string) error
-	RemoveItem(ctx context.Context, shipmentID, detailID string) error
+	CreateWithItems(ctx context.Context, shipment *domain.Pengiriman, spec SequenceSpec, details []domain.PengirimanDetail, userID, locationID string) error
+	AddItem(ctx context.Context, detail *domain.PengirimanDetail, split *ShipmentSplit, userID, locationID string) error
+	RemoveItem(ctx context.Context, shipmentID, detailID, userID string) error
 	UpdateStatus(ctx context.Context, id, status, notes, userID string) error
-	Finalize(ctx context.Context, id string) error
+	Finalize(ctx context.Context, id, userID string) error
 	GetDetailByID(ctx context.Context, id string) (*domain.PengirimanDetail, error)
-	GetNextShipmentKode(ctx context.Context) (string, error)
-	Receive(ctx context.Context, id string, updates map[string]ShipmentReceiveItem, tujuanID string, receivedDate time.Time) error
+	Receive(ctx context.Context, id string, updates map[string]ShipmentReceiveItem, tujuanID string, receivedDate time.Time, userID string) error
 }
 
 type shipmentRepository struct {
-	db *database.Database
+	db           *database.Database
+	sequenceRepo SequenceRepository
 }
 
-func NewShipmentRepository(db *database.Database) ShipmentRepository {
-	return &shipmentRepository{db: db}
+func NewShipmentRepository(db *database.Database, sequenceRepo SequenceRepository) ShipmentRepository {
+	return &shipmentRepository{
+		db:           db,
+		sequenceRepo: sequenceRepo,
+	}
 }
 
-func (r *shipmentRepository) Create(ctx context.Context, shipment *domain.Pengiriman) error {
-	_, err := r.db.InitQuery(ctx).NewInsert().Model(shipment).Exec(ctx)
-	return err
+func (r *shipmentRepository) Create(ctx context.Context, shipment *domain.Pengiriman, spec SequenceSpec) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return err
+	}
+	shipment.Kode = kodes[0]
+
+	_, err = tx.NewInsert().Model(shipment).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateWithItems creates a DRAFT shipment holding details, all of them or nothing
+func (r *shipmentRepository) CreateWithItems(ctx context.Context, shipment *domain.Pengiriman, spec SequenceSpec, details []domain.PengirimanDetail, userID, locationID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, []SequenceSpec{spec})
+	if err != nil {
+		return err
+	}
+	shipment.Kode = kodes[0]
+
+	_, err = tx.NewInsert().Model(shipment).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range details {
+		details[i].PengirimanID = shipment.ID
+		if err := addDetail(ctx, tx, &details[i], shipment.Kode, userID, locationID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *shipmentRepository) GetByID(ctx context.Context, id string) (*domain.Pengiriman, error) {
@@ -121,7 +184,9 @@ func (r *shipmentRepository) GetList(ctx context.Context, tujuan, status, locati
 	return shipments, int64(total), nil
 }
 
-func (r *shipmentRepository) AddItem(ctx context.Context, detail *domain.PengirimanDetail, locationID string) error {
+// AddItem takes a lot into a DRAFT shipment. With split the fruits are first split off into
+// their own lot in the same transaction and that lot is taken.
+func (r *shipmentRepository) AddItem(ctx context.Context, detail *domain.PengirimanDetail, split *ShipmentSplit, userID, locationID string) error {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
@@ -129,27 +194,43 @@ func (r *shipmentRepository) AddItem(ctx context.Context, detail *domain.Pengiri
 	defer tx.Rollback()
 
 	// Check Shipment Status
-	var shipmentStatus string
+	var shipmentStatus, shipmentKode string
 	var shipmentCreatorLocation *string
 	err = tx.NewSelect().
 		Model((*domain.Pengiriman)(nil)).
-		Column("status").
+		Column("status", "kode").
 		ColumnExpr("creator.current_location_id").
 		Join("JOIN users AS creator ON creator.id = p.created_by").
 		Where("p.id = ?", detail.PengirimanID).
 		Where("p.deleted_at IS NULL").
-		Scan(ctx, &shipmentStatus, &shipmentCreatorLocation)
+		Scan(ctx, &shipmentStatus, &shipmentKode, &shipmentCreatorLocation)
 	
 	if err != nil {
-		return errors.New("shipment not found")
+		return validationError("shipment not found")
 	}
 	if shipmentStatus != constants.ShipmentStatusDraft {
-		return errors.New("shipment must be DRAFT to add items")
+		return validationError("shipment must be DRAFT to add items")
+	}
+
+	if split != nil {
+		if _, err := splitLot(ctx, tx, r.sequenceRepo, split.ParentID, split.Child, split.Spec, split.BuahRawIDs, userID); err != nil {
+			return err
+		}
+		detail.LotSumberID = split.Child.ID
 	}
 
 	// Validate Access: User can only modify shipments they have access to
 	// (Though Controller/Service usually handles this, double check here is safe)
-	
+	if err := addDetail(ctx, tx, detail, shipmentKode, userID, locationID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addDetail takes a READY lot of the user's location into a DRAFT shipment, whole or in part.
+// A lot held by another user cannot be taken.
+func addDetail(ctx context.Context, tx bun.Tx, detail *domain.PengirimanDetail, shipmentKode, userID, locationID string) error {
 	// Fetch Lot & Validate Location
 	lot := new(domain.StokLot)
 	query := tx.NewSelect().
@@ -167,50 +248,104 @@ func (r *shipmentRepository) AddItem(ctx context.Context, detail *domain.Pengiri
 		query = query.Where("current_location_id = ?", locationID)
 	}
 
-	err = query.Scan(ctx)
+	err := query.Scan(ctx)
+	if err != nil {
+		return validationError("lot not found, not in READY status, or belongs to another location")
+	}
+
+	exists, err := tx.NewSelect().
+		Model((*domain.PengirimanDetail)(nil)).
+		Where("pengiriman_id = ?", detail.PengirimanID).
+		Where("lot_sumber_id = ?", lot.ID).
+		Exists(ctx)
 	if err != nil {
-		return errors.New("lot not found, not in READY status, or belongs to another location")
+		return err
+	}
+	if exists {
+		return validationError("lot is already part of this shipment")
 	}
 
-	detail.QtyAmbil = lot.QtySisa
-	detail.BeratAmbil = lot.BeratSisa
+	// Zero QtyAmbil takes the whole remaining lot
+	if detail.QtyAmbil == 0 || detail.QtyAmbil == lot.QtySisa {
+		detail.QtyAmbil = lot.QtySisa
+		detail.BeratAmbil = lot.BeratSisa
+	} else {
+		if detail.QtyAmbil > lot.QtySisa {
+			return validationError("qty_ambil exceeds remaining lot quantity (%d)", lot.QtySisa)
+		}
+		if detail.BeratAmbil == 0 {
+			detail.BeratAmbil = math.Round(lot.BeratSisa*float64(detail.QtyAmbil)/float64(lot.QtySisa)*100) / 100
+		}
+		if detail.BeratAmbil >= lot.BeratSisa {
+			return validationError("berat_ambil must be less than remaining lot weight (%.2f) when taking part of the lot", lot.BeratSisa)
+		}
+		detail.Parsial = true
+	}
 
 	_, err = tx.NewInsert().Model(detail).Exec(ctx)
 	if err != nil {
 		return err
 	}
+	if err := takeHold(ctx, tx, lot, detail, userID); err != nil {
+		return err
+	}
 
-	lot.Status = constants.LotStatusBooked
-	// Set sisa to 0 to prevent usage
-	lot.QtySisa = 0
-	lot.BeratSisa = 0
+	// The rest of a partially taken lot stays READY
+	shipmentRef := mutasiRef{Tipe: constants.LotMutasiRefPengiriman, ID: detail.PengirimanID, Kode: shipmentKode}
+	if err := recordMutasi(ctx, tx, lot, constants.LotMutasiKirim, -detail.QtyAmbil, -detail.BeratAmbil, shipmentRef, userID); err != nil {
+		return err
+	}
+	lot.QtySisa -= detail.QtyAmbil
+	lot.BeratSisa -= detail.BeratAmbil
+	if lot.QtySisa == 0 {
+		lot.BeratSisa = 0
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusBooked, userID, "dialokasikan ke pengiriman "+shipmentKode); err != nil {
+			return err
+		}
+	}
 
 	_, err = tx.NewUpdate().
 		Model(lot).
 		Column("status", "qty_sisa", "berat_sisa").
 		WherePK().
 		Exec(ctx)
+	return err
+}
+
+// checkAlokasiParsial rejects emptying a lot while a shipment that has not arrived takes part
+// of it, that shipment could then neither leave nor give the part back
+func checkAlokasiParsial(ctx context.Context, tx bun.Tx, lot *domain.StokLot) error {
+	exists, err := tx.NewSelect().
+		Model((*domain.PengirimanDetail)(nil)).
+		Join("JOIN tb_pengiriman AS p ON p.id = pd.pengiriman_id").
+		Where("pd.lot_sumber_id = ?", lot.ID).
+		Where("pd.parsial = TRUE").
+		Where("p.status IN (?)", bun.In([]string{constants.ShipmentStatusDraft, constants.ShipmentStatusSending})).
+		Where("p.deleted_at IS NULL").
+		Exists(ctx)
 	if err != nil {
 		return err
 	}
-
-	return tx.Commit()
+	if exists {
+		return validationError("lot %s sedang dialokasikan sebagian ke pengiriman", lot.Kode)
+	}
+	return nil
 }
 
-func (r *shipmentRepository) RemoveItem(ctx context.Context, shipmentID, detailID string) error {
+func (r *shipmentRepository) RemoveItem(ctx context.Context, shipmentID, detailID, userID string) error {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	var shipmentStatus string
+	var shipmentStatus, shipmentKode string
 	err = tx.NewSelect().
 		Model((*domain.Pengiriman)(nil)).
-		Column("status").
+		Column("status", "kode").
 		Where("id = ?", shipmentID).
 		Where("deleted_at IS NULL").
-		Scan(ctx, &shipmentStatus)
+		Scan(ctx, &shipmentStatus, &shipmentKode)
 	if err != nil {
 		return errors.New("shipment not found")
 	}
@@ -234,10 +369,24 @@ func (r *shipmentRepository) RemoveItem(ctx context.Context, shipmentID, detailI
 		return err
 	}
 
-	// Restore original quantity and weight
-	lot.QtySisa = detail.QtyAmbil
-	lot.BeratSisa = detail.BeratAmbil
-	lot.Status = constants.LotStatusReady
+	// A part goes back to a lot that stayed READY. Once another shipment took the rest the
+	// lot left with it and the part cannot be given back.
+	if detail.Parsial && lot.Status != constants.LotStatusReady {
+		return validationError("lot %s sudah dialokasikan penuh ke pengiriman lain", lot.Kode)
+	}
+
+	// Give back exactly what this detail took
+	shipmentRef := mutasiRef{Tipe: constants.LotMutasiRefPengiriman, ID: shipmentID, Kode: shipmentKode}
+	if err := recordMutasi(ctx, tx, lot, constants.LotMutasiBatalKirim, detail.QtyAmbil, detail.BeratAmbil, shipmentRef, userID); err != nil {
+		return err
+	}
+	lot.QtySisa += detail.QtyAmbil
+	lot.BeratSisa += detail.BeratAmbil
+	if !detail.Parsial {
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusReady, userID, "dikeluarkan dari pengiriman "+shipmentKode); err != nil {
+			return err
+		}
+	}
 
 	_, err = tx.NewUpdate().
 		Model(lot).
@@ -248,6 +397,10 @@ func (r *shipmentRepository) RemoveItem(ctx context.Context, shipmentID, detailI
 		return err
 	}
 
+	if err := restoreHold(ctx, tx, detail.ID, userID); err != nil {
+		return err
+	}
+
 	_, err = tx.NewDelete().Model(detail).WherePK().Exec(ctx)
 	if err != nil {
 		return err
@@ -265,13 +418,23 @@ func (r *shipmentRepository) UpdateStatus(ctx context.Context, id, status, notes
 	return err
 }
 
-func (r *shipmentRepository) Finalize(ctx context.Context, id string) error {
+func (r *shipmentRepository) Finalize(ctx context.Context, id, userID string) error {
 	tx, err := r.db.InitQuery(ctx).Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	var shipmentKode string
+	err = tx.NewSelect().
+		Model((*domain.Pengiriman)(nil)).
+		Column("kode").
+		Where("id = ?", id).
+		Scan(ctx, &shipmentKode)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.NewUpdate().
 		Model((*domain.Pengiriman)(nil)).
 		Set("status = ?", constants.ShipmentStatusSending).
@@ -287,26 +450,21 @@ func (r *shipmentRepository) Finalize(ctx context.Context, id string) error {
 		return err
 	}
 
-	// Check for empty lots
-	var emptyLotIDs []string
+	// Fully allocated lots leave, partially taken ones stay READY
 	for _, d := range details {
+		if d.Parsial {
+			continue
+		}
 		lot := new(domain.StokLot)
-		err = tx.NewSelect().Model(lot).Where("id = ?", d.LotSumberID).Scan(ctx)
+		err = tx.NewSelect().Model(lot).Where("id = ?", d.LotSumberID).For("UPDATE").Scan(ctx)
 		if err != nil {
 			return err
 		}
 
-		if lot.QtySisa <= 0 {
-			emptyLotIDs = append(emptyLotIDs, d.LotSumberID)
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusShipped, userID, "pengiriman "+shipmentKode+" dikirim"); err != nil {
+			return err
 		}
-	}
-
-	if len(emptyLotIDs) > 0 {
-		_, err = tx.NewUpdate().
-			Model((*domain.StokLot)(nil)).
-			Set("status = ?", constants.LotStatusShipped).
-			Where("id IN (?)", bun.In(emptyLotIDs)).
-			Exec(ctx)
+		_, err = tx.NewUpdate().Model(lot).Column("status", "updated_at").WherePK().Exec(ctx)
 		if err != nil {
 			return err
 		}
@@ -328,37 +486,24 @@ func (r *shipmentRepository) GetDetailByID(ctx context.Context, id string) (*dom
 	return detail, nil
 }
 
-func (r *shipmentRepository) GetNextShipmentKode(ctx context.Context) (string, error) {
-	dateStr := time.Now().Format("060102") // YYMMDD
-	prefix := fmt.Sprintf("SHP-%s", dateStr)
+func (r *shipmentRepository) Receive(ctx context.Context, id string, updates map[string]ShipmentReceiveItem, tujuanID string, receivedDate time.Time, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	var lastCode string
-	err := r.db.InitQuery(ctx).NewSelect().
+	var shipmentKode string
+	err = tx.NewSelect().
 		Model((*domain.Pengiriman)(nil)).
 		Column("kode").
-		Where("kode LIKE ?", prefix+"-%").
-		Order("kode DESC").
-		Limit(1).
-		Scan(ctx, &lastCode)
-
-	seq := 1
-	if err == nil && lastCode != "" {
-		var lastSeq int
-		_, err := fmt.Sscanf(lastCode, prefix+"-%d", &lastSeq)
-		if err == nil {
-			seq = lastSeq + 1
-		}
-	}
-
-	return fmt.Sprintf("%s-%03d", prefix, seq), nil
-}
-
-func (r *shipmentRepository) Receive(ctx context.Context, id string, updates map[string]ShipmentReceiveItem, tujuanID string, receivedDate time.Time) error {
-	tx, err := r.db.InitQuery(ctx).Begin()
+		Where("id = ?", id).
+		Scan(ctx, &shipmentKode)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	alasan := "diterima dari pengiriman " + shipmentKode
+	shipmentRef := mutasiRef{Tipe: constants.LotMutasiRefPengiriman, ID: id, Kode: shipmentKode}
 
 	// Update Shipment Status and ReceivedAt
 	_, err = tx.NewUpdate().
@@ -371,16 +516,124 @@ func (r *shipmentRepository) Receive(ctx context.Context, id string, updates map
 		return err
 	}
 
-	// Update Lots
+	// Partial details arrive as new lots split off their source
+	var specs []SequenceSpec
+	var parsialIDs []string
 	for lotID, item := range updates {
+		if item.Parsial {
+			specs = append(specs, item.Spec)
+			parsialIDs = append(parsialIDs, lotID)
+		}
+	}
+	if len(specs) > 0 {
+		kodes, err := reserveKodes(ctx, tx, r.sequenceRepo, specs)
+		if err != nil {
+			return err
+		}
+
+		for i, lotID := range parsialIDs {
+			item := updates[lotID]
+			source := new(domain.StokLot)
+			if err := tx.NewSelect().Model(source).Where("id = ?", lotID).For("UPDATE").Scan(ctx); err != nil {
+				return err
+			}
+
+			// The detail names no fruits, any of the source's fruits stand for the part taken
+			var buahIDs []string
+			err = tx.NewSelect().
+				Model((*domain.BuahRaw)(nil)).
+				Column("id").
+				Where("lot_id = ?", source.ID).
+				Where("writeoff_id IS NULL").
+				Where("deleted_at IS NULL").
+				Order("kode_buah").
+				Limit(item.QtyAmbil).
+				For("UPDATE").
+				Scan(ctx, &buahIDs)
+			if err != nil {
+				return err
+			}
+			if len(buahIDs) < item.QtyAmbil {
+				return validationError("lot %s hanya memiliki %d buah untuk %d buah yang dikirim", source.Kode, len(buahIDs), item.QtyAmbil)
+			}
+
+			arrivedAt := receivedDate
+			child := &domain.StokLot{
+				Kode:          kodes[i],
+				JenisDurianID: source.JenisDurianID,
+				KondisiBuah:   source.KondisiBuah,
+				BeratAwal:     item.Berat,
+				QtyAwal:       item.Qty,
+				BeratSisa:     item.Berat,
+				QtySisa:       item.Qty,
+				Status:        constants.LotStatusReady,
+				PosisiID:      &tujuanID,
+				ArrivedAt:     &arrivedAt,
+				FinalizedAt:   source.FinalizedAt,
+				MusimID:       source.MusimID,
+			}
+			if _, err := tx.NewInsert().Model(child).Exec(ctx); err != nil {
+				return err
+			}
+			if err := recordLotCreated(ctx, tx, child, userID, alasan); err != nil {
+				return err
+			}
+			if err := recordMutasi(ctx, tx, child, constants.LotMutasiTerima, item.Qty, item.Berat, shipmentRef, userID); err != nil {
+				return err
+			}
+			if len(buahIDs) > 0 {
+				_, err = tx.NewUpdate().
+					Model((*domain.BuahRaw)(nil)).
+					Set("lot_id = ?", child.ID).
+					Set("updated_at = NOW()").
+					Where("id IN (?)", bun.In(buahIDs)).
+					Exec(ctx)
+				if err != nil {
+					return err
+				}
+			}
+
+			gen := &domain.LotGenealogi{
+				Tipe:        constants.LotGenealogiSplit,
+				ParentLotID: source.ID,
+				ChildLotID:  child.ID,
+				Qty:         item.Qty,
+				Berat:       item.Berat,
+			}
+			if userID != "" {
+				gen.CreatedBy = &userID
+			}
+			if err := insertGenealogi(ctx, tx, gen, buahIDs); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Whole lots move to the destination
+	for lotID, item := range updates {
+		if item.Parsial {
+			continue
+		}
+		lot := new(domain.StokLot)
+		if err := tx.NewSelect().Model(lot).Where("id = ?", lotID).For("UPDATE").Scan(ctx); err != nil {
+			return err
+		}
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusReady, userID, alasan); err != nil {
+			return err
+		}
+
+		arrivedAt := receivedDate
+		lot.PosisiID = &tujuanID
+		if err := recordMutasi(ctx, tx, lot, constants.LotMutasiTerima, item.Qty-lot.QtySisa, item.Berat-lot.BeratSisa, shipmentRef, userID); err != nil {
+			return err
+		}
+		lot.BeratSisa = item.Berat
+		lot.QtySisa = item.Qty
+		lot.ArrivedAt = &arrivedAt
 		_, err := tx.NewUpdate().
-			Model((*domain.StokLot)(nil)).
-			Set("current_location_id = ?", tujuanID).
-			Set("berat_sisa = ?", item.Berat).
-			Set("qty_sisa = ?", item.Qty).
-			Set("status = ?", constants.LotStatusReady).
-			Set("arrived_at = ?", receivedDate).
-			Where("id = ?", lotID).
+			Model(lot).
+			Column("current_location_id", "berat_sisa", "qty_sisa", "status", "arrived_at", "updated_at").
+			WherePK().
 			Exec(ctx)
 		if err != nil {
 			return err
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"time"
+)
+
+type StokRepository interface {
+	GetSnapshot(ctx context.Context, tanggal time.Time) (*domain.StokSnapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot *domain.StokSnapshot) error
+	GetPosisi(ctx context.Context, before time.Time) ([]domain.StokSnapshotItem, error)
+	GetAwalLedger(ctx context.Context) (*time.Time, error)
+}
+
+type stokRepository struct {
+	db *database.Database
+}
+
+func NewStokRepository(db *database.Database) StokRepository {
+	return &stokRepository{db: db}
+}
+
+// GetSnapshot returns the snapshot of a day with its items, nil when the day has none
+func (r *stokRepository) GetSnapshot(ctx context.Context, tanggal time.Time) (*domain.StokSnapshot, error) {
+	day := tanggal.Format("2006-01-02")
+
+	snapshot := new(domain.StokSnapshot)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(snapshot).
+		Where("snapshot.tanggal = ?", day).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.InitQuery(ctx).NewSelect().
+		Model(&snapshot.Items).
+		ColumnExpr("snapshot_item.*").
+		ColumnExpr("COALESCE(tp.nama, '') AS lokasi_nama").
+		ColumnExpr("COALESCE(jd.nama_jenis, '') AS jenis_durian_nama").
+		Join("LEFT JOIN tb_tujuan_pengiriman AS tp ON tp.id = snapshot_item.lokasi_id").
+		Join("LEFT JOIN jenis_durian AS jd ON jd.id = snapshot_item.jenis_durian_id").
+		Where("snapshot_item.tanggal = ?", day).
+		OrderExpr("lokasi_nama, jenis_durian_nama, snapshot_item.kondisi_buah, snapshot_item.status").
+		Scan(ctx)
+	return snapshot, err
+}
+
+// SaveSnapshot writes the snapshot of a day, replacing the one taken before
+func (r *stokRepository) SaveSnapshot(ctx context.Context, snapshot *domain.StokSnapshot) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	day := snapshot.Tanggal.Format("2006-01-02")
+	_, err = tx.NewDelete().
+		Model((*domain.StokSnapshot)(nil)).
+		Where("tanggal = ?", day).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NewInsert().
+		Model(snapshot).
+		Value("tanggal", "?", day).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshot.Items) > 0 {
+		for i := range snapshot.Items {
+			snapshot.Items[i].Tanggal = snapshot.Tanggal
+		}
+		_, err = tx.NewInsert().
+			Model(&snapshot.Items).
+			Value("tanggal", "?", day).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPosisi replays the stock ledger up to before. A lot counts at every location it still
+// holds stock at, with the status it had then. Empty balances are left out.
+func (r *stokRepository) GetPosisi(ctx context.Context, before time.Time) ([]domain.StokSnapshotItem, error) {
+	saldo := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_lot_mutasi").
+		ColumnExpr("lot_id, lokasi_id").
+		ColumnExpr("SUM(delta_qty) AS qty").
+		ColumnExpr("SUM(delta_berat) AS berat").
+		Where("created_at < ?", before).
+		GroupExpr("lot_id, lokasi_id").
+		Having("SUM(delta_qty) <> 0 OR SUM(delta_berat) <> 0")
+
+	status := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_lot_status_riwayat AS rw").
+		ColumnExpr("rw.status_ke").
+		Where("rw.lot_id = sl.id").
+		Where("rw.created_at < ?", before).
+		OrderExpr("rw.created_at DESC").
+		Limit(1)
+
+	var list []domain.StokSnapshotItem
+	err := r.db.InitQuery(ctx).NewSelect().
+		With("saldo", saldo).
+		TableExpr("saldo AS s").
+		Join("JOIN tb_stok_lot AS sl ON sl.id = s.lot_id").
+		Join("LEFT JOIN LATERAL (?) AS st ON TRUE", status).
+		Join("LEFT JOIN tb_tujuan_pengiriman AS tp ON tp.id = s.lokasi_id").
+		Join("LEFT JOIN jenis_durian AS jd ON jd.id = sl.jenis_durian_id").
+		ColumnExpr("s.lokasi_id").
+		ColumnExpr("COALESCE(tp.nama, '') AS lokasi_nama").
+		ColumnExpr("sl.musim_id").
+		ColumnExpr("sl.jenis_durian_id").
+		ColumnExpr("COALESCE(jd.nama_jenis, '') AS jenis_durian_nama").
+		ColumnExpr("sl.kondisi_buah").
+		ColumnExpr("COALESCE(st.status_ke, sl.status) AS status").
+		ColumnExpr("COUNT(DISTINCT s.lot_id) AS lot_count").
+		ColumnExpr("SUM(s.qty) AS qty").
+		ColumnExpr("SUM(s.berat) AS berat").
+		GroupExpr("s.lokasi_id, tp.nama, sl.musim_id, sl.jenis_durian_id, jd.nama_jenis, sl.kondisi_buah, COALESCE(st.status_ke, sl.status)").
+		OrderExpr("lokasi_nama, jenis_durian_nama, sl.kondisi_buah, status").
+		Scan(ctx, &list)
+	return list, err
+}
+
+// GetAwalLedger returns when the stock ledger starts, nil while it is empty. Lots that existed
+// before it were given their balance then, so the ledger says nothing about earlier stock.
+func (r *stokRepository) GetAwalLedger(ctx context.Context) (*time.Time, error) {
+	var awal sql.NullTime
+	err := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_lot_mutasi").
+		ColumnExpr("MIN(created_at)").
+		Scan(ctx, &awal)
+	if err != nil || !awal.Valid {
+		return nil, err
+	}
+	return &awal.Time, nil
+}
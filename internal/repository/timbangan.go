@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+)
+
+type TimbanganRepository interface {
+	Create(ctx context.Context, stasiun *domain.StasiunTimbang) error
+	Update(ctx context.Context, stasiun *domain.StasiunTimbang) error
+	UpdateSesi(ctx context.Context, stasiun *domain.StasiunTimbang) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*domain.StasiunTimbang, error)
+	GetList(ctx context.Context, aktifOnly bool) ([]domain.StasiunTimbang, error)
+
+	CreatePembacaan(ctx context.Context, pembacaan *domain.PembacaanTimbang) error
+	GetPembacaanList(ctx context.Context, stasiunID, lotID string, limit int) ([]domain.PembacaanTimbang, error)
+}
+
+type timbanganRepository struct {
+	db *database.Database
+}
+
+func NewTimbanganRepository(db *database.Database) TimbanganRepository {
+	return &timbanganRepository{db: db}
+}
+
+func (r *timbanganRepository) Create(ctx context.Context, stasiun *domain.StasiunTimbang) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(stasiun).Exec(ctx)
+	return err
+}
+
+// Update writes the station settings only, the session columns belong to UpdateSesi
+func (r *timbanganRepository) Update(ctx context.Context, stasiun *domain.StasiunTimbang) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().
+		Model(stasiun).
+		Column("nama", "alamat", "protokol", "transport", "min_berat", "toleransi", "sampel", "aktif", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+func (r *timbanganRepository) UpdateSesi(ctx context.Context, stasiun *domain.StasiunTimbang) error {
+	_, err := r.db.InitQuery(ctx).NewUpdate().
+		Model(stasiun).
+		Column("lot_id", "blok_id", "pohon_kode", "pemanen_id", "sesi_oleh", "sesi_mulai_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+func (r *timbanganRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.InitQuery(ctx).NewDelete().Model((*domain.StasiunTimbang)(nil)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *timbanganRepository) GetByID(ctx context.Context, id string) (*domain.StasiunTimbang, error) {
+	stasiun := new(domain.StasiunTimbang)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(stasiun).
+		Relation("Lot").
+		Where("stasiun.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return stasiun, err
+}
+
+func (r *timbanganRepository) GetList(ctx context.Context, aktifOnly bool) ([]domain.StasiunTimbang, error) {
+	var list []domain.StasiunTimbang
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Lot").
+		Order("stasiun.kode ASC")
+	if aktifOnly {
+		query.Where("stasiun.aktif = ?", true)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+func (r *timbanganRepository) CreatePembacaan(ctx context.Context, pembacaan *domain.PembacaanTimbang) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(pembacaan).Exec(ctx)
+	return err
+}
+
+func (r *timbanganRepository) GetPembacaanList(ctx context.Context, stasiunID, lotID string, limit int) ([]domain.PembacaanTimbang, error) {
+	var list []domain.PembacaanTimbang
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Where("pembacaan.stasiun_id = ?", stasiunID).
+		Order("pembacaan.created_at DESC").
+		Limit(limit)
+	if lotID != "" {
+		query.Where("pembacaan.lot_id = ?", lotID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
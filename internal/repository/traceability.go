@@ -40,31 +40,46 @@ func (r *traceabilityRepository) TraceLot(ctx context.Context, lotID string) (*r
 	var fruits []domain.BuahRaw
 	err = r.db.InitQuery(ctx).NewSelect().
 		Model(&fruits).
-		Join("INNER JOIN tb_lot_detail ON tb_lot_detail.buah_raw_id = buah_raw.id").
-		Relation("BlokPanenDetail").
-		Relation("BlokPanenDetail.Divisi").
-		Relation("BlokPanenDetail.Divisi.Estate").
-		Relation("BlokPanenDetail.Divisi.Estate.Company").
 		Relation("PohonPanenDetail").
-		Where("tb_lot_detail.lot_id = ?", lotID).
+		Relation("PohonPanenDetail.Blok").
+		Relation("PohonPanenDetail.Blok.Divisi").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
+		Where("buah_raw.lot_id = ?", lotID).
 		Where("buah_raw.deleted_at IS NULL").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var lotDetails []domain.LotDetail
+	var genealogi []domain.LotGenealogi
 	err = r.db.InitQuery(ctx).NewSelect().
-		Model(&lotDetails).
-		Where("lot_id = ?", lotID).
+		Model(&genealogi).
+		Relation("ParentLot").
+		Relation("ChildLot").
+		Relation("Buah").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("genealogi.parent_lot_id = ?", lotID).WhereOr("genealogi.child_lot_id = ?", lotID)
+		}).
+		Order("genealogi.created_at").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	lotDetailMap := make(map[string]time.Time, len(lotDetails))
-	for _, detail := range lotDetails {
-		lotDetailMap[detail.BuahRawID] = detail.CreatedAt
+	// Fruits that came in through a split or merge joined the lot at that moment
+	joinedAt := make(map[string]time.Time)
+	asal := make([]response.LotGenealogiTrace, 0)
+	turunan := make([]response.LotGenealogiTrace, 0)
+	for _, g := range genealogi {
+		if g.ChildLotID == lotID {
+			for _, b := range g.Buah {
+				joinedAt[b.BuahRawID] = g.CreatedAt
+			}
+			asal = append(asal, toGenealogiTrace(g, g.ParentLot))
+		} else {
+			turunan = append(turunan, toGenealogiTrace(g, g.ChildLot))
+		}
 	}
 
 	jenisDurian := ""
@@ -85,8 +100,10 @@ func (r *traceabilityRepository) TraceLot(ctx context.Context, lotID string) (*r
 	fruitInfos := make([]response.FruitTraceInfo, 0, len(fruits))
 	for _, fruit := range fruits {
 		addedAt := lot.CreatedAt
-		if detailTime, exists := lotDetailMap[fruit.ID]; exists {
-			addedAt = detailTime
+		if t, ok := joinedAt[fruit.ID]; ok {
+			addedAt = t
+		} else if fruit.CreatedAt.After(addedAt) {
+			addedAt = fruit.CreatedAt
 		}
 
 		lokasi := r.buildLokasiInfo(fruit)
@@ -108,6 +125,8 @@ func (r *traceabilityRepository) TraceLot(ctx context.Context, lotID string) (*r
 	return &response.TraceLotResponse{
 		LotInfo: lotInfo,
 		Fruits:  fruitInfos,
+		Asal:    asal,
+		Turunan: turunan,
 	}, nil
 }
 
@@ -116,11 +135,11 @@ func (r *traceabilityRepository) TraceFruit(ctx context.Context, fruitID string)
 	err := r.db.InitQuery(ctx).NewSelect().
 		Model(fruit).
 		Relation("JenisDurianDetail").
-		Relation("BlokPanenDetail").
-		Relation("BlokPanenDetail.Divisi").
-		Relation("BlokPanenDetail.Divisi.Estate").
-		Relation("BlokPanenDetail.Divisi.Estate.Company").
 		Relation("PohonPanenDetail").
+		Relation("PohonPanenDetail.Blok").
+		Relation("PohonPanenDetail.Blok.Divisi").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
 		Where("buah_raw.id = ?", fruitID).
 		Where("buah_raw.deleted_at IS NULL").
 		Scan(ctx)
@@ -146,9 +165,16 @@ func (r *traceabilityRepository) TraceFruit(ctx context.Context, fruitID string)
 		JenisDurian: jenisDurian,
 		TglPanen:    fruit.TglPanen,
 		LokasiPanen: lokasi,
+		Berat:       fruit.Berat,
+		Kualitas: response.BuahKualitas{
+			TingkatKematangan: fruit.TingkatKematangan,
+			Cacat:             fruit.Cacat,
+			SkorAroma:         fruit.SkorAroma,
+			MetodePanen:       fruit.MetodePanen,
+		},
 	}
 
-	journey := r.buildFruitJourney(ctx, fruitID)
+	journey := r.buildFruitJourney(ctx, fruit)
 
 	return &response.TraceFruitResponse{
 		FruitInfo: fruitInfo,
@@ -198,13 +224,12 @@ func (r *traceabilityRepository) TraceShipment(ctx context.Context, shipmentID s
 	var fruits []domain.BuahRaw
 	err = r.db.InitQuery(ctx).NewSelect().
 		Model(&fruits).
-		Join("INNER JOIN tb_lot_detail ON tb_lot_detail.buah_raw_id = buah_raw.id").
-		Relation("BlokPanenDetail").
-		Relation("BlokPanenDetail.Divisi").
-		Relation("BlokPanenDetail.Divisi.Estate").
-		Relation("BlokPanenDetail.Divisi.Estate.Company").
 		Relation("PohonPanenDetail").
-		Where("tb_lot_detail.lot_id IN (?)", bun.In(lotIDs)).
+		Relation("PohonPanenDetail.Blok").
+		Relation("PohonPanenDetail.Blok.Divisi").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate").
+		Relation("PohonPanenDetail.Blok.Divisi.Estate.Company").
+		Where("buah_raw.lot_id IN (?)", bun.In(lotIDs)).
 		Where("buah_raw.deleted_at IS NULL").
 		Scan(ctx)
 	if err != nil {
@@ -279,38 +304,75 @@ func (r *traceabilityRepository) buildLokasiString(fruit domain.BuahRaw) string
 		fruit.PohonPanenDetail.Blok.Kode)
 }
 
-func (r *traceabilityRepository) buildFruitJourney(ctx context.Context, fruitID string) response.FruitJourney {
+// buildFruitJourney follows the fruit from the lot it was graded into, through every split
+// or merge, to its current lot and the latest shipment and sale of any lot on that path
+func (r *traceabilityRepository) buildFruitJourney(ctx context.Context, fruit *domain.BuahRaw) response.FruitJourney {
 	journey := response.FruitJourney{}
 
-	var lotDetail domain.LotDetail
+	if fruit.LotID == nil {
+		return journey
+	}
+
+	var moves []domain.LotGenealogi
 	err := r.db.InitQuery(ctx).NewSelect().
-		Model(&lotDetail).
-		Relation("Lot").
-		Where("buah_raw_id = ?", fruitID).
+		Model(&moves).
+		Relation("ParentLot").
+		Relation("ChildLot").
+		Join("JOIN tb_lot_genealogi_buah AS gb ON gb.genealogi_id = genealogi.id").
+		Where("gb.buah_raw_id = ?", fruit.ID).
+		Order("genealogi.created_at").
 		Scan(ctx)
-
-	if err != nil || lotDetail.LotID == "" {
+	if err != nil {
 		return journey
 	}
 
-	lot := lotDetail.Lot
-	if lot == nil {
-		return journey
+	var riwayat []response.LotJourneyInfo
+	if len(moves) > 0 && moves[0].ParentLot != nil {
+		first := moves[0].ParentLot
+		riwayat = append(riwayat, toLotJourney(first, first.CreatedAt))
+	}
+	for _, m := range moves {
+		if m.ChildLot != nil {
+			info := toLotJourney(m.ChildLot, m.CreatedAt)
+			info.Melalui = m.Tipe
+			riwayat = append(riwayat, info)
+		}
 	}
 
-	journey.Lot = &response.LotJourneyInfo{
-		ID:          lot.ID,
-		KondisiBuah: lot.KondisiBuah,
-		JoinedAt:    lotDetail.CreatedAt,
-		Status:      lot.Status,
+	if len(riwayat) == 0 {
+		lot := new(domain.StokLot)
+		err = r.db.InitQuery(ctx).NewSelect().
+			Model(lot).
+			Where("stok_lot.id = ?", *fruit.LotID).
+			Scan(ctx)
+		if err != nil {
+			return journey
+		}
+		joined := lot.CreatedAt
+		if fruit.CreatedAt.After(joined) {
+			joined = fruit.CreatedAt
+		}
+		riwayat = append(riwayat, toLotJourney(lot, joined))
+	}
+
+	current := riwayat[len(riwayat)-1]
+	journey.Lot = &current
+	if len(riwayat) > 1 {
+		journey.RiwayatLot = riwayat
+	}
+
+	lotIDs := make([]string, 0, len(riwayat))
+	for _, l := range riwayat {
+		lotIDs = append(lotIDs, l.ID)
 	}
 
 	var pengirimanDetail domain.PengirimanDetail
 	err = r.db.InitQuery(ctx).NewSelect().
 		Model(&pengirimanDetail).
 		Relation("Pengiriman").
-		Join("INNER JOIN tb_pengiriman p ON p.id = pd.pengiriman_id").
-		Where("pd.lot_sumber_id = ?", lot.ID).
+		Where("pd.lot_sumber_id IN (?)", bun.In(lotIDs)).
+		Order("pengiriman.tgl_kirim DESC").
+		Limit(1).
 		Scan(ctx)
 
 	if err != nil || pengirimanDetail.ID == "" {
@@ -347,3 +409,27 @@ func (r *traceabilityRepository) buildFruitJourney(ctx context.Context, fruitID
 
 	return journey
 }
+
+func toLotJourney(lot *domain.StokLot, joinedAt time.Time) response.LotJourneyInfo {
+	return response.LotJourneyInfo{
+		ID:          lot.ID,
+		Kode:        lot.Kode,
+		KondisiBuah: lot.KondisiBuah,
+		JoinedAt:    joinedAt,
+		Status:      lot.Status,
+	}
+}
+
+func toGenealogiTrace(g domain.LotGenealogi, lot *domain.StokLot) response.LotGenealogiTrace {
+	trace := response.LotGenealogiTrace{
+		Tipe:      g.Tipe,
+		Qty:       g.Qty,
+		Berat:     g.Berat,
+		CreatedAt: g.CreatedAt,
+	}
+	if lot != nil {
+		trace.LotID = lot.ID
+		trace.Kode = lot.Kode
+	}
+	return trace
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/pkg/database"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type WriteOffRepository interface {
+	Create(ctx context.Context, wo *domain.LotWriteOff) error
+	Apply(ctx context.Context, wo *domain.LotWriteOff, userID string) error
+	Reject(ctx context.Context, wo *domain.LotWriteOff) error
+	GetByID(ctx context.Context, id string) (*domain.LotWriteOff, error)
+	GetList(ctx context.Context, status, lotID, lokasiID string) ([]domain.LotWriteOff, error)
+	GetLaporan(ctx context.Context, dateFrom, dateTo *time.Time, lokasiID, jenisDurianID, alasanKode string) ([]domain.WriteOffLaporan, error)
+}
+
+type writeOffRepository struct {
+	db *database.Database
+}
+
+func NewWriteOffRepository(db *database.Database) WriteOffRepository {
+	return &writeOffRepository{db: db}
+}
+
+// Create records a write-off waiting for approval, the lot is not touched yet
+func (r *writeOffRepository) Create(ctx context.Context, wo *domain.LotWriteOff) error {
+	_, err := r.db.InitQuery(ctx).NewInsert().Model(wo).Exec(ctx)
+	return err
+}
+
+// Apply takes the write-off out of the lot and approves it. A new write-off is inserted, a
+// waiting one must still be MENUNGGU. Writing off the last fruit empties the lot.
+func (r *writeOffRepository) Apply(ctx context.Context, wo *domain.LotWriteOff, userID string) error {
+	tx, err := r.db.InitQuery(ctx).Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	pending := wo.ID != ""
+	if pending {
+		current := new(domain.LotWriteOff)
+		err = tx.NewSelect().Model(current).Where("id = ?", wo.ID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if current.Status != constants.WriteOffStatusMenunggu {
+			return errors.New("write-off sudah diputuskan")
+		}
+	}
+
+	lot := new(domain.StokLot)
+	err = tx.NewSelect().
+		Model(lot).
+		Where("id = ?", wo.LotID).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return errors.New("lot tidak ditemukan")
+	}
+	if lot.Status != constants.LotStatusReady {
+		return fmt.Errorf("lot %s tidak berstatus READY", lot.Kode)
+	}
+	if err := checkAlokasiParsial(ctx, tx, lot); err != nil {
+		return err
+	}
+	hold, err := checkHold(ctx, tx, lot, userID)
+	if err != nil {
+		return err
+	}
+
+	if wo.Berat, err = beratWriteOff(wo.Qty, wo.Berat, lot); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	wo.Status = constants.WriteOffStatusDisetujui
+	wo.DiputuskanOleh = &userID
+	wo.DiputuskanAt = &now
+	if pending {
+		_, err = tx.NewUpdate().
+			Model(wo).
+			Column("berat", "status", "diputuskan_oleh", "diputuskan_at", "catatan_keputusan", "updated_at").
+			WherePK().
+			Exec(ctx)
+	} else {
+		_, err = tx.NewInsert().Model(wo).Exec(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := lepasBuahWriteOff(ctx, tx, lot, wo); err != nil {
+		return err
+	}
+
+	ref := mutasiRef{Tipe: constants.LotMutasiRefWriteOff, ID: wo.ID, Kode: wo.AlasanKode}
+	if err := recordMutasi(ctx, tx, lot, constants.LotMutasiWriteOff, -wo.Qty, -wo.Berat, ref, userID); err != nil {
+		return err
+	}
+	lot.QtySisa -= wo.Qty
+	lot.BeratSisa = math.Round((lot.BeratSisa-wo.Berat)*100) / 100
+	if lot.QtySisa == 0 {
+		lot.BeratSisa = 0
+		if err := setLotStatus(ctx, tx, lot, constants.LotStatusEmpty, userID, "write-off "+wo.AlasanKode); err != nil {
+			return err
+		}
+		if err := releaseHold(ctx, tx, hold, userID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NewUpdate().
+		Model(lot).
+		Column("status", "qty_sisa", "berat_sisa", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lepasBuahWriteOff marks wo.Qty fruits of lot written off, so the lot holds as many fruits as
+// it counts. A write-off names no fruits, any of the lot's fruits stand for them.
+func lepasBuahWriteOff(ctx context.Context, tx bun.Tx, lot *domain.StokLot, wo *domain.LotWriteOff) error {
+	if wo.Qty == 0 {
+		return nil
+	}
+
+	var buahIDs []string
+	err := tx.NewSelect().
+		Model((*domain.BuahRaw)(nil)).
+		Column("id").
+		Where("lot_id = ?", lot.ID).
+		Where("writeoff_id IS NULL").
+		Where("deleted_at IS NULL").
+		Order("kode_buah").
+		Limit(wo.Qty).
+		For("UPDATE").
+		Scan(ctx, &buahIDs)
+	if err != nil {
+		return err
+	}
+	if len(buahIDs) < wo.Qty {
+		return validationError("lot %s hanya memiliki %d buah untuk %d buah yang di-write-off", lot.Kode, len(buahIDs), wo.Qty)
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*domain.BuahRaw)(nil)).
+		Set("writeoff_id = ?", wo.ID).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", bun.In(buahIDs)).
+		Exec(ctx)
+	return err
+}
+
+// beratWriteOff is the weight qty fruits take off lot. The whole rest takes all its weight, a
+// part without a weight given takes its share by count. A part must leave the lot some weight.
+func beratWriteOff(qty int, berat float64, lot *domain.StokLot) (float64, error) {
+	switch {
+	case qty > lot.QtySisa:
+		return 0, fmt.Errorf("qty melebihi sisa lot (%d)", lot.QtySisa)
+	case qty == lot.QtySisa:
+		return lot.BeratSisa, nil
+	case berat == 0:
+		berat = math.Round(lot.BeratSisa*float64(qty)/float64(lot.QtySisa)*100) / 100
+	}
+	if berat >= lot.BeratSisa {
+		return 0, fmt.Errorf("berat harus kurang dari sisa berat lot (%.2f) bila sebagian lot dihapus", lot.BeratSisa)
+	}
+	return berat, nil
+}
+
+func (r *writeOffRepository) Reject(ctx context.Context, wo *domain.LotWriteOff) error {
+	res, err := r.db.InitQuery(ctx).NewUpdate().
+		Model(wo).
+		Column("status", "diputuskan_oleh", "diputuskan_at", "catatan_keputusan", "updated_at").
+		WherePK().
+		Where("status = ?", constants.WriteOffStatusMenunggu).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("write-off sudah diputuskan")
+	}
+	return nil
+}
+
+func (r *writeOffRepository) GetByID(ctx context.Context, id string) (*domain.LotWriteOff, error) {
+	wo := new(domain.LotWriteOff)
+	err := r.db.InitQuery(ctx).NewSelect().
+		Model(wo).
+		Relation("Lot").
+		Relation("Lokasi").
+		Relation("JenisDurian").
+		Relation("Pengaju").
+		Where("writeoff.id = ?", id).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return wo, err
+}
+
+func (r *writeOffRepository) GetList(ctx context.Context, status, lotID, lokasiID string) ([]domain.LotWriteOff, error) {
+	var list []domain.LotWriteOff
+	query := r.db.InitQuery(ctx).NewSelect().
+		Model(&list).
+		Relation("Lot").
+		Relation("Lokasi").
+		Relation("JenisDurian").
+		Relation("Pengaju").
+		Order("writeoff.created_at DESC")
+	if status != "" {
+		query.Where("writeoff.status = ?", status)
+	}
+	if lotID != "" {
+		query.Where("writeoff.lot_id = ?", lotID)
+	}
+	if lokasiID != "" {
+		query.Where("writeoff.lokasi_id = ?", lokasiID)
+	}
+
+	err := query.Scan(ctx)
+	return list, err
+}
+
+// GetLaporan sums the approved write-offs by location, jenis and reason, by the day they were approved
+func (r *writeOffRepository) GetLaporan(ctx context.Context, dateFrom, dateTo *time.Time, lokasiID, jenisDurianID, alasanKode string) ([]domain.WriteOffLaporan, error) {
+	var list []domain.WriteOffLaporan
+	query := r.db.InitQuery(ctx).NewSelect().
+		TableExpr("tb_lot_writeoff AS wo").
+		Join("LEFT JOIN tb_tujuan_pengiriman AS tp ON tp.id = wo.lokasi_id").
+		Join("JOIN jenis_durian AS jd ON jd.id = wo.jenis_durian_id").
+		ColumnExpr("wo.lokasi_id").
+		ColumnExpr("COALESCE(tp.nama, '') AS lokasi_nama").
+		ColumnExpr("wo.jenis_durian_id").
+		ColumnExpr("jd.nama_jenis AS jenis_durian_nama").
+		ColumnExpr("wo.alasan_kode").
+		ColumnExpr("COUNT(*) AS jumlah").
+		ColumnExpr("COALESCE(SUM(wo.qty), 0) AS qty").
+		ColumnExpr("COALESCE(SUM(wo.berat), 0) AS berat").
+		Where("wo.status = ?", constants.WriteOffStatusDisetujui).
+		GroupExpr("wo.lokasi_id, tp.nama, wo.jenis_durian_id, jd.nama_jenis, wo.alasan_kode").
+		OrderExpr("berat DESC")
+	if dateFrom != nil {
+		query.Where("wo.diputuskan_at >= ?", *dateFrom)
+	}
+	if dateTo != nil {
+		query.Where("wo.diputuskan_at < ?", dateTo.AddDate(0, 0, 1))
+	}
+	if lokasiID != "" {
+		query.Where("wo.lokasi_id = ?", lokasiID)
+	}
+	if jenisDurianID != "" {
+		query.Where("wo.jenis_durian_id = ?", jenisDurianID)
+	}
+	if alasanKode != "" {
+		query.Where("wo.alasan_kode = ?", alasanKode)
+	}
+
+	err := query.Scan(ctx, &list)
+	return list, err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"durich-be/internal/domain"
+	"testing"
+)
+
+func TestBeratWriteOff(t *testing.T) {
+	lot := &domain.StokLot{QtySisa: 3, BeratSisa: 10}
+
+	tests := []struct {
+		name    string
+		qty     int
+		berat   float64
+		want    float64
+		wantErr bool
+	}{
+		{name: "whole rest takes all weight", qty: 3, berat: 0, want: 10},
+		{name: "whole rest ignores a given weight", qty: 3, berat: 4, want: 10},
+		{name: "part prorated by count", qty: 1, want: 3.33},
+		{name: "prorated share is rounded", qty: 2, want: 6.67},
+		{name: "part with its own weight", qty: 1, berat: 4.2, want: 4.2},
+		{name: "shrinkage without fruits", qty: 0, berat: 0.5, want: 0.5},
+		{name: "more fruits than left", qty: 4, wantErr: true},
+		{name: "part may not take all weight", qty: 1, berat: 10, wantErr: true},
+		{name: "shrinkage may not take all weight", qty: 0, berat: 12, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := beratWriteOff(tt.qty, tt.berat, lot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("beratWriteOff error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("beratWriteOff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAnalytics(router *gin.RouterGroup, ctl controllers.AnalyticsController) {
+	group := router.Group("/analytics")
+	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse))
+	{
+		group.GET("/yield", ctl.GetYield)
+		group.GET("/yield/ranking", ctl.GetYieldRanking)
+	}
+}
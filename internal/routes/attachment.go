@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAttachment(router *gin.RouterGroup, ctl controllers.AttachmentController) {
+	group := router.Group("/attachments")
+	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse, domain.RoleSales))
+	{
+		group.POST("", ctl.Upload)
+		group.GET("", ctl.List)
+		group.GET("/:id/file", ctl.Download)
+		group.GET("/:id/thumbnail", ctl.Thumbnail)
+		group.DELETE("/:id", ctl.Delete)
+	}
+}
@@ -14,6 +14,8 @@ func RegisterBuahRaw(router *gin.RouterGroup, ctl controllers.BuahRawController)
 	{
 		group.POST("", ctl.Create)
 		group.POST("/bulk", ctl.BulkCreate)
+		group.POST("/sync", ctl.Sync)
+		group.POST("/import", ctl.Import)
 		group.GET("", ctl.GetList)
 		group.GET("/:id", ctl.GetDetail)
 		group.PUT("/:id", ctl.Update)
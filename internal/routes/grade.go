@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGrade(router *gin.RouterGroup, ctl controllers.GradeController) {
+	group := router.Group("/grades")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse, domain.RoleSales), ctl.GetList)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse, domain.RoleSales), ctl.GetByID)
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin), ctl.Create)
+		group.PUT("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Update)
+		group.DELETE("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Delete)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterHold(router *gin.RouterGroup, ctl controllers.HoldController) {
+	group := router.Group("/lot-hold")
+	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse, domain.RoleSales))
+	{
+		group.POST("", ctl.Create)
+		group.GET("", ctl.GetList)
+		group.GET("/:id", ctl.GetByID)
+		group.POST("/:id/lepas", ctl.Lepas)
+	}
+}
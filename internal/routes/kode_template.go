@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterKodeTemplate(router *gin.RouterGroup, ctl controllers.KodeTemplateController) {
+	group := router.Group("/kode-templates")
+	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin))
+	{
+		group.GET("", ctl.GetAll)
+		group.PUT("", ctl.Save)
+		group.POST("/preview", ctl.Preview)
+		group.DELETE("/:id", ctl.Delete)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterKualitas(router *gin.RouterGroup, ctl controllers.KualitasController) {
+	group := router.Group("/jenis-durian/:id/kualitas")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse, domain.RoleSales), ctl.GetConfig)
+		group.PUT("", middlewares.RoleHandler(domain.RoleAdmin), ctl.SaveConfig)
+		group.DELETE("", middlewares.RoleHandler(domain.RoleAdmin), ctl.ResetConfig)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterLabel(router *gin.RouterGroup, ctl *controllers.LabelController) {
+	group := router.Group("/labels")
+	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse))
+	{
+		group.GET("/printers", ctl.GetPrinters)
+		group.GET("/buah/:id", ctl.GetBuahLabel)
+		group.GET("/lots/:id", ctl.GetLotLabel)
+		group.POST("/sheet", ctl.PrintSheet)
+	}
+}
@@ -16,7 +16,20 @@ func RegisterLotRoutes(r *gin.RouterGroup, lotController *controllers.LotControl
 		lots.GET("", lotController.GetList)
 		lots.GET("/:id", lotController.GetDetail)
 		lots.POST("/:id/items", lotController.AddItems)
+		lots.POST("/:id/items/scan", lotController.ScanItems)
 		lots.DELETE("/:id/items", lotController.RemoveItem)
 		lots.POST("/:id/finalize", lotController.Finalize)
+		lots.POST("/:id/split", lotController.Split)
+		lots.POST("/merge", lotController.Merge)
+		lots.GET("/:id/genealogi", lotController.GetGenealogi)
+		lots.GET("/:id/history", lotController.GetHistory)
+		lots.POST("/:id/regrade", lotController.Regrade)
+		lots.GET("/:id/regrade", lotController.GetRegrade)
+		lots.GET("/:id/ledger", lotController.GetLedger)
+		lots.GET("/ledger/rekonsiliasi", lotController.GetRekonsiliasi)
+		lots.GET("/aging", lotController.GetAging)
+		lots.GET("/peringatan-umur", lotController.GetPeringatanUmur)
+		lots.POST("/:id/timbang-ulang", lotController.TimbangUlang)
+		lots.GET("/:id/timbang-ulang", lotController.GetTimbangUlang)
 	}
 }
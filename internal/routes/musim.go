@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterMusim(router *gin.RouterGroup, ctl controllers.MusimController) {
+	group := router.Group("/musim-panen")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin), ctl.Create)
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetList)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetByID)
+		group.PUT("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Update)
+		group.DELETE("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Delete)
+		group.POST("/:id/tutup", middlewares.RoleHandler(domain.RoleAdmin), ctl.Close)
+		group.POST("/:id/buka", middlewares.RoleHandler(domain.RoleAdmin), ctl.Reopen)
+		group.GET("/:id/ringkasan", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetRingkasan)
+	}
+}
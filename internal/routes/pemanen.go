@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPemanen(router *gin.RouterGroup, ctl controllers.PemanenController) {
+	group := router.Group("/pemanen")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin), ctl.Create)
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetList)
+		group.GET("/upah", middlewares.RoleHandler(domain.RoleAdmin), ctl.GetUpahReport)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetByID)
+		group.PUT("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Update)
+		group.DELETE("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Delete)
+	}
+
+	tarif := router.Group("/tarif-panen")
+	tarif.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin))
+	{
+		tarif.POST("", ctl.CreateTarif)
+		tarif.GET("", ctl.GetTarifList)
+		tarif.PUT("/:id", ctl.UpdateTarif)
+		tarif.DELETE("/:id", ctl.DeleteTarif)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSesiGrading(router *gin.RouterGroup, ctl controllers.SesiGradingController) {
+	group := router.Group("/sesi-grading")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.Open)
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetList)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetByID)
+		group.POST("/:id/scan", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.Scan)
+		group.POST("/:id/tutup", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.Close)
+		group.GET("/:id/laporan", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetLaporan)
+	}
+}
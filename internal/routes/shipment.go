@@ -13,6 +13,7 @@ func RegisterShipment(router *gin.RouterGroup, ctl *controllers.ShipmentControll
 	group.Use(middlewares.TokenAuthMiddleware(), middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse))
 	{
 		group.POST("", ctl.Create)
+		group.POST("/saran", ctl.Saran)
 		group.GET("", ctl.GetList)
 		group.GET("/:id", ctl.GetByID)
 		group.POST("/:id/items", ctl.AddItem)
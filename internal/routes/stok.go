@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterStok(router *gin.RouterGroup, ctl controllers.StokController) {
+	group := router.Group("/stok")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.GET("/as-of", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetAsOf)
+		group.POST("/snapshot", middlewares.RoleHandler(domain.RoleAdmin), ctl.TakeSnapshot)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTimbangan(router *gin.RouterGroup, ctl controllers.TimbanganController) {
+	group := router.Group("/stasiun-timbang")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin), ctl.Create)
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetList)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetByID)
+		group.PUT("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Update)
+		group.DELETE("/:id", middlewares.RoleHandler(domain.RoleAdmin), ctl.Delete)
+		group.PUT("/:id/sesi", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.OpenSesi)
+		group.DELETE("/:id/sesi", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.CloseSesi)
+		group.GET("/:id/pembacaan", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetPembacaan)
+	}
+}
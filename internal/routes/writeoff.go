@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"durich-be/internal/controllers"
+	"durich-be/internal/domain"
+	"durich-be/pkg/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWriteOff(router *gin.RouterGroup, ctl controllers.WriteOffController) {
+	group := router.Group("/write-off")
+	group.Use(middlewares.TokenAuthMiddleware())
+	{
+		group.POST("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.Create)
+		group.GET("", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetList)
+		group.GET("/laporan", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetLaporan)
+		group.GET("/:id", middlewares.RoleHandler(domain.RoleAdmin, domain.RoleWarehouse), ctl.GetByID)
+		group.POST("/:id/setujui", middlewares.RoleHandler(domain.RoleAdmin), ctl.Approve)
+		group.POST("/:id/tolak", middlewares.RoleHandler(domain.RoleAdmin), ctl.Reject)
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const yieldRankingDefaultLimit = 10
+
+type AnalyticsService interface {
+	GetYield(ctx context.Context, q requests.YieldQuery) (*response.YieldResponse, error)
+	GetYieldRanking(ctx context.Context, q requests.YieldQuery) (*response.YieldRankingResponse, error)
+}
+
+type analyticsService struct {
+	repo      repository.AnalyticsRepository
+	musimRepo repository.MusimRepository
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, musimRepo repository.MusimRepository) AnalyticsService {
+	return &analyticsService{
+		repo:      repo,
+		musimRepo: musimRepo,
+	}
+}
+
+// yieldResult is a sorted yield query, with the previous year's figures attached when requested
+type yieldResult struct {
+	level   string
+	urut    string
+	periode response.YieldPeriode
+	banding *response.YieldPeriode
+	total   response.YieldMetrik
+	prev    *response.YieldMetrik
+	items   []response.YieldItem
+}
+
+func (s *analyticsService) GetYield(ctx context.Context, q requests.YieldQuery) (*response.YieldResponse, error) {
+	res, err := s.yield(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	items := res.items
+	if q.Limit > 0 && len(items) > q.Limit {
+		items = items[:q.Limit]
+	}
+
+	resp := &response.YieldResponse{
+		Level:          res.level,
+		Urut:           res.urut,
+		Periode:        res.periode,
+		PeriodeBanding: res.banding,
+		Total:          res.total,
+		Items:          items,
+	}
+	if res.prev != nil {
+		resp.TotalBanding = compareYield(res.total, *res.prev)
+	}
+	return resp, nil
+}
+
+func (s *analyticsService) GetYieldRanking(ctx context.Context, q requests.YieldQuery) (*response.YieldRankingResponse, error) {
+	res, err := s.yield(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	n := q.Limit
+	if n <= 0 {
+		n = yieldRankingDefaultLimit
+	}
+	if n > len(res.items) {
+		n = len(res.items)
+	}
+
+	terburuk := make([]response.YieldItem, 0, n)
+	for i := len(res.items) - 1; i >= len(res.items)-n; i-- {
+		terburuk = append(terburuk, res.items[i])
+	}
+
+	return &response.YieldRankingResponse{
+		Level:          res.level,
+		Urut:           res.urut,
+		Periode:        res.periode,
+		PeriodeBanding: res.banding,
+		Terbaik:        res.items[:n],
+		Terburuk:       terburuk,
+	}, nil
+}
+
+func (s *analyticsService) yield(ctx context.Context, q requests.YieldQuery) (*yieldResult, error) {
+	start, end, err := resolveYieldPeriode(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.MusimID != "" {
+		musim, err := s.musimRepo.GetByID(ctx, q.MusimID)
+		if err != nil {
+			return nil, err
+		}
+		if musim == nil {
+			return nil, errors.NotFoundError("musim panen tidak ditemukan")
+		}
+		start, end = musim.TanggalMulai, time.Now()
+		if musim.TanggalSelesai != nil {
+			end = *musim.TanggalSelesai
+		}
+		q.EstateID = musim.EstateID
+	}
+
+	res := &yieldResult{
+		level:   q.Level,
+		urut:    q.Urut,
+		periode: response.YieldPeriode{Mulai: start.Format("2006-01-02"), Selesai: end.Format("2006-01-02")},
+	}
+	if res.level == "" {
+		res.level = constants.YieldLevelPohon
+	}
+	if res.urut == "" {
+		res.urut = constants.YieldUrutQty
+	}
+
+	filter := repository.YieldFilter{
+		Level:         res.level,
+		StartDate:     res.periode.Mulai,
+		EndDate:       res.periode.Selesai,
+		EstateID:      q.EstateID,
+		DivisiID:      q.DivisiID,
+		BlokID:        q.BlokID,
+		JenisDurianID: q.JenisDurianID,
+		MusimID:       q.MusimID,
+	}
+
+	rows, err := s.repo.GetYield(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	res.items, res.total = aggregateYield(rows)
+
+	if q.BandingTahunLalu {
+		prevStart, prevEnd := start.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0)
+		res.banding = &response.YieldPeriode{Mulai: prevStart.Format("2006-01-02"), Selesai: prevEnd.Format("2006-01-02")}
+
+		// Last year's fruit belongs to another season, so it is matched by date only
+		filter.StartDate, filter.EndDate = res.banding.Mulai, res.banding.Selesai
+		filter.MusimID = ""
+		prevRows, err := s.repo.GetYield(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		prevItems, prevTotal := aggregateYield(prevRows)
+		prevMap := make(map[string]response.YieldMetrik, len(prevItems))
+		for _, item := range prevItems {
+			prevMap[item.ID] = item.YieldMetrik
+		}
+		for i := range res.items {
+			res.items[i].Banding = compareYield(res.items[i].YieldMetrik, prevMap[res.items[i].ID])
+		}
+		res.prev = &prevTotal
+	}
+
+	sortYieldItems(res.items, res.urut)
+	return res, nil
+}
+
+// resolveYieldPeriode defaults to the current year to date when no period is given
+func resolveYieldPeriode(q requests.YieldQuery) (time.Time, time.Time, error) {
+	if q.Tahun != 0 {
+		mulai, selesai := q.MusimMulai, q.MusimSelesai
+		if mulai == "" {
+			mulai = "01-01"
+		}
+		if selesai == "" {
+			selesai = "12-31"
+		}
+
+		m1, _ := time.Parse("01-02", mulai)
+		m2, _ := time.Parse("01-02", selesai)
+		start := time.Date(q.Tahun, m1.Month(), m1.Day(), 0, 0, 0, 0, time.Local)
+		end := time.Date(q.Tahun, m2.Month(), m2.Day(), 0, 0, 0, 0, time.Local)
+		if end.Before(start) {
+			end = end.AddDate(1, 0, 0)
+		}
+		return start, end, nil
+	}
+
+	if (q.TanggalMulai == "") != (q.TanggalSelesai == "") {
+		return time.Time{}, time.Time{}, errors.ValidationError("tanggal_mulai dan tanggal_selesai harus diisi bersamaan")
+	}
+
+	if q.TanggalMulai == "" {
+		now := time.Now()
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.Local), now, nil
+	}
+
+	start, _ := time.ParseInLocation("2006-01-02", q.TanggalMulai, time.Local)
+	end, _ := time.ParseInLocation("2006-01-02", q.TanggalSelesai, time.Local)
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.ValidationError("tanggal_selesai tidak boleh sebelum tanggal_mulai")
+	}
+	return start, end, nil
+}
+
+// aggregateYield folds the per-grade rows into one item per unit plus the grand total
+func aggregateYield(rows []domain.YieldRow) ([]response.YieldItem, response.YieldMetrik) {
+	var (
+		items      []response.YieldItem
+		index      = make(map[string]int)
+		total      response.YieldMetrik
+		totalGrade = make(map[string]int)
+		unitGrade  = make(map[string]map[string]int)
+	)
+
+	for _, row := range rows {
+		i, ok := index[row.UnitID]
+		if !ok {
+			i = len(items)
+			index[row.UnitID] = i
+			items = append(items, response.YieldItem{
+				ID:         row.UnitID,
+				Kode:       row.UnitKode,
+				Nama:       row.UnitNama,
+				EstateKode: row.EstateKode,
+				DivisiKode: row.DivisiKode,
+				BlokKode:   row.BlokKode,
+			})
+			unitGrade[row.UnitID] = make(map[string]int)
+		}
+
+		if row.Qty == 0 {
+			continue
+		}
+
+		grade := row.Grade
+		if grade == "" {
+			grade = constants.YieldGradeBelumGrading
+		}
+
+		items[i].Qty += row.Qty
+		items[i].QtyDitimbang += row.QtyDitimbang
+		items[i].TotalBerat += row.Berat
+		unitGrade[row.UnitID][grade] += row.Qty
+
+		total.Qty += row.Qty
+		total.QtyDitimbang += row.QtyDitimbang
+		total.TotalBerat += row.Berat
+		totalGrade[grade] += row.Qty
+	}
+
+	for i := range items {
+		finishYieldMetrik(&items[i].YieldMetrik, unitGrade[items[i].ID])
+	}
+	finishYieldMetrik(&total, totalGrade)
+
+	return items, total
+}
+
+func finishYieldMetrik(m *response.YieldMetrik, grades map[string]int) {
+	m.TotalBerat = roundPlaces(m.TotalBerat, 3)
+	if m.QtyDitimbang > 0 {
+		m.RataBerat = roundPlaces(m.TotalBerat/float64(m.QtyDitimbang), 3)
+	}
+
+	m.Grade = make([]response.YieldGrade, 0, len(grades))
+	for grade, qty := range grades {
+		m.Grade = append(m.Grade, response.YieldGrade{
+			Grade:  grade,
+			Qty:    qty,
+			Persen: roundPlaces(float64(qty)*100/float64(m.Qty), 2),
+		})
+	}
+	sort.Slice(m.Grade, func(i, j int) bool {
+		if m.Grade[i].Qty != m.Grade[j].Qty {
+			return m.Grade[i].Qty > m.Grade[j].Qty
+		}
+		return m.Grade[i].Grade < m.Grade[j].Grade
+	})
+}
+
+func compareYield(cur, prev response.YieldMetrik) *response.YieldBanding {
+	banding := &response.YieldBanding{YieldMetrik: prev}
+	if prev.Grade == nil {
+		banding.Grade = []response.YieldGrade{}
+	}
+	if prev.Qty > 0 {
+		v := roundPlaces(float64(cur.Qty-prev.Qty)*100/float64(prev.Qty), 2)
+		banding.SelisihQtyPersen = &v
+	}
+	if prev.TotalBerat > 0 {
+		v := roundPlaces((cur.TotalBerat-prev.TotalBerat)*100/prev.TotalBerat, 2)
+		banding.SelisihBeratPersen = &v
+	}
+	return banding
+}
+
+// sortYieldItems orders best first by the chosen metric and numbers the ranks
+func sortYieldItems(items []response.YieldItem, urut string) {
+	metric := func(item response.YieldItem) float64 {
+		switch urut {
+		case constants.YieldUrutBerat:
+			return item.TotalBerat
+		case constants.YieldUrutRataBerat:
+			return item.RataBerat
+		}
+		return float64(item.Qty)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := metric(items[i]), metric(items[j])
+		if a != b {
+			return a > b
+		}
+		return items[i].Kode < items[j].Kode
+	})
+	for i := range items {
+		items[i].Peringkat = i + 1
+	}
+}
+
+func roundPlaces(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
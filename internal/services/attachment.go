@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+type AttachmentService interface {
+	Upload(ctx context.Context, req requests.AttachmentUploadRequest, fileName string, file io.Reader, userID string) (*response.AttachmentResponse, error)
+	List(ctx context.Context, entityType, entityID string) ([]response.AttachmentResponse, error)
+	Open(ctx context.Context, id string, thumbnail bool) (*domain.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, id string, user requests.UserAuth) error
+}
+
+type attachmentService struct {
+	repo    repository.AttachmentRepository
+	storage storage.Storage
+	maxSize int64
+}
+
+func NewAttachmentService(repo repository.AttachmentRepository, store storage.Storage, maxSize int64) AttachmentService {
+	return &attachmentService{
+		repo:    repo,
+		storage: store,
+		maxSize: maxSize,
+	}
+}
+
+// attachmentExtensions lists the accepted image types, detected from content rather than the file name
+var attachmentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+func (s *attachmentService) Upload(ctx context.Context, req requests.AttachmentUploadRequest, fileName string, file io.Reader, userID string) (*response.AttachmentResponse, error) {
+	exists, err := s.repo.EntityExists(ctx, req.EntityType, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NotFoundError(fmt.Sprintf("%s dengan id %s tidak ditemukan", req.EntityType, req.EntityID))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, errors.ValidationError("file tidak dapat dibaca")
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.ValidationError(fmt.Sprintf("ukuran file maksimal %d MB", s.maxSize>>20))
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := attachmentExtensions[contentType]
+	if !ok {
+		return nil, errors.ValidationError("hanya gambar JPEG, PNG atau WebP yang dapat diunggah")
+	}
+
+	thumb, err := storage.Thumbnail(data, constants.AttachmentThumbnailSize)
+	if err != nil {
+		return nil, errors.ValidationError("gambar rusak atau tidak dapat dibaca")
+	}
+
+	id := ksuid.New().String()
+	dir := path.Join("attachments", req.EntityType, req.EntityID)
+	key := path.Join(dir, id+ext)
+	thumbKey := path.Join(dir, id+"_thumb.jpg")
+
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, errors.InternalError("gagal menyimpan file", err)
+	}
+	if err := s.storage.Put(ctx, thumbKey, bytes.NewReader(thumb)); err != nil {
+		s.storage.Delete(ctx, key)
+		return nil, errors.InternalError("gagal menyimpan thumbnail", err)
+	}
+
+	attachment := &domain.Attachment{
+		ID:           id,
+		EntityType:   req.EntityType,
+		EntityID:     req.EntityID,
+		FileName:     path.Base(fileName),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		StorageKey:   key,
+		ThumbnailKey: &thumbKey,
+		Keterangan:   req.Keterangan,
+		UploadedBy:   userID,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.storage.Delete(ctx, key)
+		s.storage.Delete(ctx, thumbKey)
+		return nil, err
+	}
+
+	resp := toAttachmentResponse(*attachment)
+	return &resp, nil
+}
+
+func (s *attachmentService) List(ctx context.Context, entityType, entityID string) ([]response.AttachmentResponse, error) {
+	list, err := s.repo.GetByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.AttachmentResponse, 0, len(list))
+	for _, item := range list {
+		result = append(result, toAttachmentResponse(item))
+	}
+	return result, nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, id string, thumbnail bool) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment == nil {
+		return nil, nil, errors.NotFoundError("lampiran tidak ditemukan")
+	}
+
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			return nil, nil, errors.NotFoundError("thumbnail tidak tersedia")
+		}
+		key = *attachment.ThumbnailKey
+	}
+
+	file, err := s.storage.Open(ctx, key)
+	if err == storage.ErrNotFound {
+		return nil, nil, errors.NotFoundError("file lampiran tidak ditemukan")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// Delete only hides the attachment. Files are kept because they may be needed as evidence in a dispute.
+func (s *attachmentService) Delete(ctx context.Context, id string, user requests.UserAuth) error {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return errors.NotFoundError("lampiran tidak ditemukan")
+	}
+
+	isAdmin := false
+	for _, role := range user.Role {
+		if role == domain.RoleAdmin {
+			isAdmin = true
+		}
+	}
+	if attachment.UploadedBy != user.UserID && !isAdmin {
+		return errors.ForbiddenError("hanya pengunggah atau admin yang dapat menghapus lampiran")
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func toAttachmentResponse(attachment domain.Attachment) response.AttachmentResponse {
+	resp := response.AttachmentResponse{
+		ID:          attachment.ID,
+		EntityType:  attachment.EntityType,
+		EntityID:    attachment.EntityID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Keterangan:  attachment.Keterangan,
+		UploadedBy:  attachment.UploadedBy,
+		URL:         fmt.Sprintf("/v1/attachments/%s/file", attachment.ID),
+		CreatedAt:   attachment.CreatedAt,
+	}
+	if attachment.ThumbnailKey != nil {
+		resp.ThumbnailURL = fmt.Sprintf("/v1/attachments/%s/thumbnail", attachment.ID)
+	}
+	if attachment.Uploader != nil {
+		resp.UploaderEmail = attachment.Uploader.Email
+	}
+	return resp
+}
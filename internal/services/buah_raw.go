@@ -2,11 +2,16 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"durich-be/internal/constants"
 	"durich-be/internal/domain"
 	"durich-be/internal/dto/requests"
 	"durich-be/internal/dto/response"
 	"durich-be/internal/repository"
+	"durich-be/pkg/database"
+	"durich-be/pkg/errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -16,6 +21,8 @@ import (
 type BuahRawService interface {
 	Create(ctx context.Context, req requests.BuahRawCreateRequest) (response.BuahRawResponse, error)
 	BulkCreate(ctx context.Context, req requests.BuahRawBulkCreateRequest) ([]response.BuahRawResponse, error)
+	Sync(ctx context.Context, req requests.BuahRawSyncRequest) (response.BuahRawSyncResponse, error)
+	Import(ctx context.Context, filename string, file io.Reader, dryRun bool) (response.BuahRawImportResponse, error)
 	GetList(ctx context.Context, filter map[string]interface{}, limit, page int) (response.PaginationResponse, error)
 	GetUnsorted(ctx context.Context, filter map[string]interface{}, limit, page int) (response.PaginationResponse, error)
 	GetDetail(ctx context.Context, id string) (response.BuahRawResponse, error)
@@ -26,13 +33,26 @@ type BuahRawService interface {
 
 type buahRawService struct {
 	repo       repository.BuahRawRepository
+	pemanen    repository.PemanenRepository
+	kode       *kodeGenerator
+	kualitas   *kualitasChecker
+	musim      *musimChecker
 	jenisCache sync.Map
-	mu         sync.Mutex
 }
 
-func NewBuahRawService(repo repository.BuahRawRepository) BuahRawService {
+func NewBuahRawService(
+	repo repository.BuahRawRepository,
+	kodeTemplateRepo repository.KodeTemplateRepository,
+	kualitasRepo repository.KualitasRepository,
+	pemanenRepo repository.PemanenRepository,
+	musimRepo repository.MusimRepository,
+) BuahRawService {
 	return &buahRawService{
-		repo: repo,
+		repo:     repo,
+		pemanen:  pemanenRepo,
+		kode:     newKodeGenerator(kodeTemplateRepo),
+		kualitas: newKualitasChecker(kualitasRepo),
+		musim:    newMusimChecker(musimRepo),
 	}
 }
 
@@ -42,60 +62,196 @@ func (s *buahRawService) Create(ctx context.Context, req requests.BuahRawCreateR
 		tglPanen = time.Now().Format("2006-01-02")
 	}
 
+	buah, err := s.createBuah(ctx, domain.BuahRaw{
+		JenisDurian: req.JenisDurianID,
+		PohonPanen:  req.PohonPanenID,
+		TglPanen:    tglPanen,
+		Berat:       req.Berat,
+		PemanenID:   emptyToNil(req.PemanenID),
+	}, req.BuahKualitasRequest)
+	if err != nil {
+		return response.BuahRawResponse{}, err
+	}
+
+	return s.mapToResponse(buah), nil
+}
+
+// createBuah generates kode_buah from the company's template and inserts a single record.
+// A non-empty buah.ID is kept as is, which lets offline sync use client generated IDs.
+func (s *buahRawService) createBuah(ctx context.Context, buah domain.BuahRaw, kualitas requests.BuahKualitasRequest) (domain.BuahRaw, error) {
 	// Default pohon ID logic
-	pohonPanenID := req.PohonPanenID
 	defaultPohonID := "6SRlQ8zX9vJ2mN5P6Q7R8S9T001"
-	if pohonPanenID == nil || *pohonPanenID == "" {
-		pohonPanenID = &defaultPohonID
+	if buah.PohonPanen == nil || *buah.PohonPanen == "" {
+		buah.PohonPanen = &defaultPohonID
 	}
 
 	// Get pohon with full hierarchy (Company -> Estate -> Divisi -> Blok -> Pohon)
-	pohon, err := s.repo.GetPohonWithFullHierarchy(ctx, *pohonPanenID)
+	pohon, err := s.repo.GetPohonWithFullHierarchy(ctx, *buah.PohonPanen)
 	if err != nil {
-		return response.BuahRawResponse{}, fmt.Errorf("pohon tidak ditemukan: %v", err)
+		return buah, fmt.Errorf("pohon tidak ditemukan: %v", err)
 	}
 
-	// Build prefix from location hierarchy
-	prefix := s.buildLocationPrefix(pohon)
-	if prefix == "" {
-		return response.BuahRawResponse{}, fmt.Errorf("gagal membuat prefix lokasi: data hierarki tidak lengkap")
+	jenisDurian, err := s.getJenisDurianCached(ctx, buah.JenisDurian)
+	if err != nil {
+		return buah, fmt.Errorf("jenis durian tidak ditemukan: %v", err)
 	}
 
-	// Get next sequence for this location prefix
-	sequence, err := s.repo.GetNextSequenceWithLock(ctx, prefix, tglPanen)
-	if err != nil {
-		return response.BuahRawResponse{}, fmt.Errorf("gagal generate sequence: %v", err)
+	if err := s.kualitas.apply(ctx, kualitas, &buah); err != nil {
+		return buah, errors.ValidationError(err.Error())
 	}
 
-	newKodeBuah := fmt.Sprintf("%s-F%05d", prefix, sequence)
-	newID := ksuid.New().String()
-	now := time.Now()
+	if buah.PemanenID != nil {
+		pemanen, err := s.pemanen.GetByID(ctx, *buah.PemanenID)
+		if err != nil {
+			return buah, err
+		}
+		if err := checkPemanen(pemanen, pohon); err != nil {
+			return buah, errors.ValidationError(err.Error())
+		}
+	}
+
+	if buah.MusimID, err = s.musim.forHarvest(ctx, nil, pohonEstateID(pohon), buah.TglPanen); err != nil {
+		return buah, err
+	}
+
+	// Code values come from the location hierarchy
+	values, companyID, ok := buahKodeValues(pohon, jenisDurian.Kode, buah.TglPanen)
+	if !ok {
+		return buah, fmt.Errorf("gagal membuat prefix lokasi: data hierarki tidak lengkap")
+	}
 
-	jenisDurian, err := s.getJenisDurianCached(ctx, req.JenisDurianID)
+	spec, err := s.kode.spec(ctx, constants.KodeTipeBuah, companyID, values)
 	if err != nil {
-		return response.BuahRawResponse{}, fmt.Errorf("jenis durian tidak ditemukan: %v", err)
+		return buah, err
 	}
 
-	buah := domain.BuahRaw{
-		ID:          newID,
-		KodeBuah:    newKodeBuah,
-		JenisDurian: req.JenisDurianID,
-		PohonPanen:  pohonPanenID,
-		TglPanen:    tglPanen,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	if buah.ID == "" {
+		buah.ID = ksuid.New().String()
 	}
+	now := time.Now()
+	buah.CreatedAt = now
+	buah.UpdatedAt = now
 
-	err = s.repo.Create(ctx, &buah)
+	// kode_buah is allocated inside the insert transaction
+	err = s.repo.Create(ctx, &buah, spec)
 	if err != nil {
-		return response.BuahRawResponse{}, err
+		return buah, err
 	}
 
 	// Manually attach relations to return full response without re-querying
 	buah.JenisDurianDetail = &jenisDurian
 	buah.PohonPanenDetail = pohon
 
-	return s.mapToResponse(buah), nil
+	return buah, nil
+}
+
+// Sync applies harvest records captured offline. Items are keyed by their client generated KSUID,
+// so replaying the same batch returns the kode_buah assigned on the first run instead of inserting again.
+func (s *buahRawService) Sync(ctx context.Context, req requests.BuahRawSyncRequest) (response.BuahRawSyncResponse, error) {
+	ids := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		ids = append(ids, item.ClientID)
+	}
+
+	existing, err := s.repo.GetByIDsUnscoped(ctx, ids)
+	if err != nil {
+		return response.BuahRawSyncResponse{}, fmt.Errorf("gagal memeriksa data sinkronisasi: %v", err)
+	}
+
+	existingMap := make(map[string]domain.BuahRaw, len(existing))
+	for _, b := range existing {
+		existingMap[b.ID] = b
+	}
+
+	result := response.BuahRawSyncResponse{
+		DeviceID: req.DeviceID,
+		Items:    make([]response.BuahRawSyncItemResult, 0, len(req.Items)),
+	}
+
+	for _, item := range req.Items {
+		res := s.syncItem(ctx, req.DeviceID, item, existingMap)
+
+		switch res.Status {
+		case constants.SyncStatusCreated:
+			result.TotalCreated++
+		case constants.SyncStatusDuplicate:
+			result.TotalDuplicate++
+		default:
+			result.TotalRejected++
+		}
+		result.Items = append(result.Items, res)
+	}
+
+	result.SyncedAt = time.Now().Format(time.RFC3339)
+	return result, nil
+}
+
+func (s *buahRawService) syncItem(ctx context.Context, deviceID string, item requests.BuahRawSyncItem, existingMap map[string]domain.BuahRaw) response.BuahRawSyncItemResult {
+	res := response.BuahRawSyncItemResult{ClientID: item.ClientID}
+
+	if _, err := ksuid.Parse(item.ClientID); err != nil {
+		res.Status = constants.SyncStatusRejected
+		res.Message = "client_id bukan KSUID yang valid"
+		return res
+	}
+
+	if found, ok := existingMap[item.ClientID]; ok {
+		return s.syncDuplicateResult(res, deviceID, found)
+	}
+
+	recordedAt, err := time.Parse(time.RFC3339, item.RecordedAt)
+	if err != nil {
+		res.Status = constants.SyncStatusRejected
+		res.Message = "format recorded_at harus RFC3339"
+		return res
+	}
+
+	tglPanen := item.TglPanen
+	if tglPanen == "" {
+		tglPanen = recordedAt.Format("2006-01-02")
+	}
+
+	buah, err := s.createBuah(ctx, domain.BuahRaw{
+		ID:          item.ClientID,
+		JenisDurian: item.JenisDurianID,
+		PohonPanen:  item.PohonPanenID,
+		TglPanen:    tglPanen,
+		Berat:       item.Berat,
+		PemanenID:   emptyToNil(item.PemanenID),
+		DeviceID:    &deviceID,
+		RecordedAt:  &recordedAt,
+	}, item.BuahKualitasRequest)
+	if err != nil {
+		// Another request replayed the same item concurrently and won the insert
+		if database.IsUniqueViolation(err) {
+			found, lookupErr := s.repo.GetByIDsUnscoped(ctx, []string{item.ClientID})
+			if lookupErr == nil && len(found) == 1 {
+				return s.syncDuplicateResult(res, deviceID, found[0])
+			}
+		}
+
+		res.Status = constants.SyncStatusRejected
+		res.Message = err.Error()
+		return res
+	}
+
+	existingMap[buah.ID] = buah
+
+	res.Status = constants.SyncStatusCreated
+	res.KodeBuah = buah.KodeBuah
+	return res
+}
+
+func (s *buahRawService) syncDuplicateResult(res response.BuahRawSyncItemResult, deviceID string, found domain.BuahRaw) response.BuahRawSyncItemResult {
+	if found.DeviceID == nil || *found.DeviceID != deviceID {
+		res.Status = constants.SyncStatusRejected
+		res.Message = "client_id sudah dipakai oleh data lain"
+		return res
+	}
+
+	res.Status = constants.SyncStatusDuplicate
+	res.KodeBuah = found.KodeBuah
+	return res
 }
 
 func (s *buahRawService) BulkCreate(ctx context.Context, req requests.BuahRawBulkCreateRequest) ([]response.BuahRawResponse, error) {
@@ -104,43 +260,83 @@ func (s *buahRawService) BulkCreate(ctx context.Context, req requests.BuahRawBul
 		tglPanen = time.Now().Format("2006-01-02")
 	}
 
+	defaultPohonID := "6SRlQ8zX9vJ2mN5P6Q7R8S9T001"
+	items := make([]bulkBuahItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		pohonID := defaultPohonID
+		if item.PohonPanenID != nil && *item.PohonPanenID != "" {
+			pohonID = *item.PohonPanenID
+		}
+
+		items = append(items, bulkBuahItem{
+			JenisDurianID: item.JenisDurianID,
+			PohonID:       pohonID,
+			TglPanen:      tglPanen,
+			Jumlah:        item.Jumlah,
+			PemanenID:     emptyToNil(item.PemanenID),
+			Kualitas:      item.BuahKualitasRequest,
+		})
+	}
+
+	return s.bulkCreate(ctx, items)
+}
+
+// bulkBuahItem is one line of a bulk insert, expanded into Jumlah fruits
+type bulkBuahItem struct {
+	JenisDurianID string
+	PohonID       string
+	BlokID        *string
+	TglPanen      string
+	Berat         float64
+	Jumlah        int
+	PemanenID     *string
+	Kualitas      requests.BuahKualitasRequest
+}
+
+func (s *buahRawService) bulkCreate(ctx context.Context, items []bulkBuahItem) ([]response.BuahRawResponse, error) {
 	// Extract unique pohon IDs and fetch their hierarchies
-	pohonIDs := s.extractUniquePohonIDs(req.Items)
+	pohonIDs := s.extractUniquePohonIDs(items)
 	pohonMap, err := s.getPohonBatchWithHierarchy(ctx, pohonIDs)
 	if err != nil {
 		return nil, fmt.Errorf("gagal mengambil data pohon: %v", err)
 	}
 
-	// Build prefix map from pohon
-	prefixMap := s.buildPrefixMap(pohonMap)
-
-	// Get sequences for all prefixes
-	sequenceMap := make(map[string]int)
-	s.mu.Lock()
-	for prefix := range s.collectUniquePrefixes(prefixMap) {
-		sequence, err := s.repo.GetNextSequenceWithLock(ctx, prefix, tglPanen)
-		if err != nil {
-			s.mu.Unlock()
-			return nil, fmt.Errorf("gagal generate sequence untuk %s: %v", prefix, err)
-		}
-		sequenceMap[prefix] = sequence
-	}
-	s.mu.Unlock()
-
-	// Get jenis durian details for response mapping
+	// Get jenis durian details for code generation and response mapping
 	jenisIDs := make([]string, 0)
-	for _, item := range req.Items {
+	for _, item := range items {
 		jenisIDs = append(jenisIDs, item.JenisDurianID)
 	}
-	jenisMap, err := s.getJenisDurianBatch(ctx, jenisIDs)
+	jenisMap, err := s.getJenisDurianBatch(ctx, uniqueStrings(jenisIDs))
 	if err != nil {
 		return nil, fmt.Errorf("gagal mengambil data jenis durian: %v", err)
 	}
 
-	buahToInsert, insertedIDs := s.buildBuahRawListFromLocation(req, prefixMap, sequenceMap, tglPanen)
+	kualitasMap, err := s.kualitas.configs(ctx, jenisIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var pemanenIDs []string
+	for _, item := range items {
+		if item.PemanenID != nil {
+			pemanenIDs = append(pemanenIDs, *item.PemanenID)
+		}
+	}
+	pemanenMap := make(map[string]domain.Pemanen)
+	if len(pemanenIDs) > 0 {
+		if pemanenMap, err = s.pemanen.GetByIDs(ctx, uniqueStrings(pemanenIDs)); err != nil {
+			return nil, fmt.Errorf("gagal mengambil data pemanen: %v", err)
+		}
+	}
+
+	buahToInsert, specs, insertedIDs, err := s.buildBuahRawListFromLocation(ctx, items, pohonMap, jenisMap, kualitasMap, pemanenMap)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(buahToInsert) > 0 {
-		err := s.repo.BulkCreate(ctx, buahToInsert)
+		// Numbers for every prefix are reserved in the same transaction as the insert
+		err := s.repo.BulkCreate(ctx, buahToInsert, specs)
 		if err != nil {
 			return nil, err
 		}
@@ -158,28 +354,19 @@ func (s *buahRawService) BulkCreate(ctx context.Context, req requests.BuahRawBul
 			j := jenis
 			b.JenisDurianDetail = &j
 		}
-		// Re-assign ID since it was generated in buildBuahRawListFromLocation but not in original struct passed to repo if repo modifies it (ksuid is string so ok)
-		// Wait, buahToInsert has IDs already generated.
-		
-		// Ensure we use the ID from the inserted list if needed, but buildBuahRawListFromLocation sets it.
-		b.ID = insertedIDs[i] 
-		
+		b.ID = insertedIDs[i]
+
 		result = append(result, s.mapToResponse(b))
 	}
 
 	return result, nil
 }
 
-func (s *buahRawService) extractUniquePohonIDs(items []requests.BuahRawBulkCreateItem) []string {
+func (s *buahRawService) extractUniquePohonIDs(items []bulkBuahItem) []string {
 	uniqueMap := make(map[string]bool)
-	defaultPohonID := "6SRlQ8zX9vJ2mN5P6Q7R8S9T001"
-	
+
 	for _, item := range items {
-		pohonID := defaultPohonID
-		if item.PohonPanenID != nil && *item.PohonPanenID != "" {
-			pohonID = *item.PohonPanenID
-		}
-		uniqueMap[pohonID] = true
+		uniqueMap[item.PohonID] = true
 	}
 
 	pohonIDs := make([]string, 0, len(uniqueMap))
@@ -203,25 +390,6 @@ func (s *buahRawService) getPohonBatchWithHierarchy(ctx context.Context, ids []s
 	return result, nil
 }
 
-func (s *buahRawService) buildPrefixMap(pohonMap map[string]*domain.Pohon) map[string]string {
-	prefixMap := make(map[string]string)
-	for pohonID, pohon := range pohonMap {
-		prefix := s.buildLocationPrefix(pohon)
-		prefixMap[pohonID] = prefix
-	}
-	return prefixMap
-}
-
-func (s *buahRawService) collectUniquePrefixes(prefixMap map[string]string) map[string]bool {
-	prefixes := make(map[string]bool)
-	for _, prefix := range prefixMap {
-		if prefix != "" {
-			prefixes[prefix] = true
-		}
-	}
-	return prefixes
-}
-
 // Keep for backward compatibility (still used in Update method)
 func (s *buahRawService) getJenisDurianBatch(ctx context.Context, ids []string) (map[string]domain.JenisDurian, error) {
 	uncachedIDs := make([]string, 0)
@@ -255,53 +423,73 @@ func (s *buahRawService) getJenisDurianBatch(ctx context.Context, ids []string)
 }
 
 func (s *buahRawService) buildBuahRawListFromLocation(
-	req requests.BuahRawBulkCreateRequest,
-	prefixMap map[string]string,
-	sequenceMap map[string]int,
-	tglPanen string,
-) ([]domain.BuahRaw, []string) {
+	ctx context.Context,
+	items []bulkBuahItem,
+	pohonMap map[string]*domain.Pohon,
+	jenisMap map[string]domain.JenisDurian,
+	kualitasMap map[string]domain.JenisDurianKualitas,
+	pemanenMap map[string]domain.Pemanen,
+) ([]domain.BuahRaw, []repository.SequenceSpec, []string, error) {
 	var buahToInsert []domain.BuahRaw
+	var specs []repository.SequenceSpec
 	var insertedIDs []string
+	musimCache := make(map[string][]domain.MusimPanen)
 	now := time.Now()
-	defaultPohonID := "6SRlQ8zX9vJ2mN5P6Q7R8S9T001"
 
-	for _, item := range req.Items {
-		// Determine pohon ID for this item
-		pohonID := defaultPohonID
-		if item.PohonPanenID != nil && *item.PohonPanenID != "" {
-			pohonID = *item.PohonPanenID
+	for idx, item := range items {
+		pohonID := item.PohonID
+
+		base := domain.BuahRaw{
+			JenisDurian: item.JenisDurianID,
+			PohonPanen:  &pohonID,
+			BlokID:      item.BlokID,
+			TglPanen:    item.TglPanen,
+			Berat:       item.Berat,
+			PemanenID:   item.PemanenID,
 		}
+		if err := applyKualitas(kualitasMap[item.JenisDurianID], item.Kualitas, &base); err != nil {
+			return nil, nil, nil, errors.ValidationError(fmt.Sprintf("item ke-%d: %v", idx+1, err))
+		}
+		if item.PemanenID != nil {
+			var pemanen *domain.Pemanen
+			if p, ok := pemanenMap[*item.PemanenID]; ok {
+				pemanen = &p
+			}
+			if err := checkPemanen(pemanen, pohonMap[pohonID]); err != nil {
+				return nil, nil, nil, errors.ValidationError(fmt.Sprintf("item ke-%d: %v", idx+1, err))
+			}
+		}
+		musimID, err := s.musim.forHarvest(ctx, musimCache, pohonEstateID(pohonMap[pohonID]), item.TglPanen)
+		if err != nil {
+			return nil, nil, nil, errors.ValidationError(fmt.Sprintf("item ke-%d: %v", idx+1, err))
+		}
+		base.MusimID = musimID
 
-		prefix := prefixMap[pohonID]
-		if prefix == "" {
-			continue // Skip items with invalid prefix
+		values, companyID, ok := buahKodeValues(pohonMap[pohonID], jenisMap[item.JenisDurianID].Kode, item.TglPanen)
+		if !ok {
+			continue // Skip items with incomplete location hierarchy
 		}
 
-		currentSeq := sequenceMap[prefix]
+		spec, err := s.kode.spec(ctx, constants.KodeTipeBuah, companyID, values)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 
 		for i := 0; i < item.Jumlah; i++ {
-			newKodeBuah := fmt.Sprintf("%s-F%05d", prefix, currentSeq)
 			newID := ksuid.New().String()
 
-			buah := domain.BuahRaw{
-				ID:          newID,
-				KodeBuah:    newKodeBuah,
-				JenisDurian: item.JenisDurianID,
-				PohonPanen:  &pohonID,
-				TglPanen:    tglPanen,
-				CreatedAt:   now,
-				UpdatedAt:   now,
-			}
+			buah := base
+			buah.ID = newID
+			buah.CreatedAt = now
+			buah.UpdatedAt = now
 
 			buahToInsert = append(buahToInsert, buah)
+			specs = append(specs, spec)
 			insertedIDs = append(insertedIDs, newID)
-			currentSeq++
 		}
-
-		sequenceMap[prefix] = currentSeq
 	}
 
-	return buahToInsert, insertedIDs
+	return buahToInsert, specs, insertedIDs, nil
 }
 
 func (s *buahRawService) getJenisDurianCached(ctx context.Context, id string) (domain.JenisDurian, error) {
@@ -383,6 +571,9 @@ func (s *buahRawService) Update(ctx context.Context, id string, req requests.Bua
 	if item.ID == "" {
 		return fmt.Errorf("data not found")
 	}
+	if err := s.musim.ensureOpen(ctx, item.MusimID); err != nil {
+		return err
+	}
 
 	if req.TglPanen != "" {
 		item.TglPanen = req.TglPanen
@@ -404,12 +595,38 @@ func (s *buahRawService) Update(ctx context.Context, id string, req requests.Bua
 		item.JenisDurian = req.JenisDurianID
 	}
 
+	if err := s.kualitas.apply(ctx, req.BuahKualitasRequest, &item); err != nil {
+		return errors.ValidationError(err.Error())
+	}
+
+	// Moving the harvest date or tree can move the fruit to another season
+	if req.TglPanen != "" || req.PohonPanenID != nil {
+		estateID := ""
+		if item.PohonPanen != nil {
+			pohon, err := s.repo.GetPohonWithFullHierarchy(ctx, *item.PohonPanen)
+			if err != nil {
+				return fmt.Errorf("pohon tidak ditemukan: %v", err)
+			}
+			estateID = pohonEstateID(pohon)
+		}
+		if item.MusimID, err = s.musim.forHarvest(ctx, nil, estateID, item.TglPanen); err != nil {
+			return err
+		}
+	}
+
 	item.UpdatedAt = time.Now()
 
 	return s.repo.Update(ctx, &item)
 }
 
 func (s *buahRawService) Delete(ctx context.Context, id string) error {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err := s.musim.ensureOpen(ctx, item.MusimID); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
 
@@ -427,6 +644,9 @@ func (s *buahRawService) mapToResponse(item domain.BuahRaw) response.BuahRawResp
 		KodePohon:   s.buildKodePohon(item.PohonPanenDetail),
 		LotKode:     s.buildLotKode(item.Lot),
 		TglPanen:    item.TglPanen,
+		Berat:       item.Berat,
+		Kualitas:    toBuahKualitas(item),
+		PemanenID:   item.PemanenID,
 		CreatedAt:   item.CreatedAt.Format(time.RFC3339),
 	}
 
@@ -501,24 +721,3 @@ func (s *buahRawService) buildLotKode(lot *domain.StokLot) *string {
 	}
 	return nil
 }
-
-// buildLocationPrefix creates prefix from full hierarchy: Company+Estate+Divisi+Blok+Pohon
-// Example: IPSRES0101A010000 (IPS + RES + 01 + 01A01 + 0000)
-func (s *buahRawService) buildLocationPrefix(pohon *domain.Pohon) string {
-	if pohon == nil || pohon.Blok == nil {
-		return ""
-	}
-
-	blok := pohon.Blok
-	if blok.Divisi == nil || blok.Divisi.Estate == nil || blok.Divisi.Estate.Company == nil {
-		return ""
-	}
-
-	return fmt.Sprintf("%s%s%s%s%s",
-		blok.Divisi.Estate.Company.Kode,
-		blok.Divisi.Estate.Kode,
-		blok.Divisi.Kode,
-		blok.Kode,
-		pohon.Kode,
-	)
-}
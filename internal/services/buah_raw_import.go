@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/spreadsheet"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const buahRawImportMaxRows = 5000
+
+const (
+	importColTglPanen  = "tgl_panen"
+	importColJenisKode = "jenis_kode"
+	importColPohonKode = "pohon_kode"
+	importColBlokKode  = "blok_kode"
+	importColBerat     = "berat"
+)
+
+var importColumnAliases = map[string]string{
+	"tgl_panen":     importColTglPanen,
+	"tanggal_panen": importColTglPanen,
+	"jenis_kode":    importColJenisKode,
+	"kode_jenis":    importColJenisKode,
+	"pohon_kode":    importColPohonKode,
+	"kode_pohon":    importColPohonKode,
+	"blok_kode":     importColBlokKode,
+	"kode_blok":     importColBlokKode,
+	"berat":         importColBerat,
+
+	// Optional quality columns
+	"tingkat_kematangan": constants.AtributTingkatKematangan,
+	"kematangan":         constants.AtributTingkatKematangan,
+	"cacat":              constants.AtributCacat,
+	"skor_aroma":         constants.AtributSkorAroma,
+	"metode_panen":       constants.AtributMetodePanen,
+}
+
+var importRequiredColumns = []string{importColTglPanen, importColJenisKode, importColPohonKode, importColBlokKode, importColBerat}
+
+// importRow is one parsed spreadsheet line, Row is the 1-based line number shown to the user
+type importRow struct {
+	Row       int
+	TglPanen  string
+	JenisKode string
+	PohonKode string
+	BlokKode  string
+	Berat     float64
+	Kualitas  requests.BuahKualitasRequest
+}
+
+// Import reads a harvest tally spreadsheet, one fruit per row. Nothing is inserted unless every row
+// is valid and dryRun is false, in which case all rows go in through the bulk create path in one transaction.
+func (s *buahRawService) Import(ctx context.Context, filename string, file io.Reader, dryRun bool) (response.BuahRawImportResponse, error) {
+	result := response.BuahRawImportResponse{
+		DryRun: dryRun,
+		Errors: []response.BuahRawImportRowError{},
+	}
+
+	rows, err := spreadsheet.ReadRows(filename, file)
+	if err != nil {
+		return result, errors.ValidationError(err.Error())
+	}
+
+	parsed, rowErrors, err := parseImportRows(rows)
+	if err != nil {
+		return result, err
+	}
+
+	items, resolveErrors, err := s.resolveImportRows(ctx, parsed)
+	if err != nil {
+		return result, err
+	}
+	rowErrors = append(rowErrors, resolveErrors...)
+
+	invalid := make(map[int]bool)
+	for _, e := range rowErrors {
+		invalid[e.Row] = true
+	}
+
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+
+	result.TotalRows = len(parsed)
+	result.InvalidRows = len(invalid)
+	result.ValidRows = result.TotalRows - result.InvalidRows
+	result.Errors = append(result.Errors, rowErrors...)
+
+	if dryRun || len(rowErrors) > 0 {
+		return result, nil
+	}
+
+	inserted, err := s.bulkCreate(ctx, items)
+	if err != nil {
+		return result, err
+	}
+
+	result.TotalInserted = len(inserted)
+	result.Items = inserted
+	return result, nil
+}
+
+func parseImportRows(rows [][]string) ([]importRow, []response.BuahRawImportRowError, error) {
+	if len(rows) == 0 {
+		return nil, nil, errors.ValidationError("file kosong")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		key := strings.ToLower(strings.Join(strings.Fields(header), "_"))
+		if col, ok := importColumnAliases[key]; ok {
+			columns[col] = i
+		}
+	}
+
+	var missing []string
+	for _, col := range importRequiredColumns {
+		if _, ok := columns[col]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, errors.ValidationError(fmt.Sprintf("kolom wajib tidak ditemukan: %s", strings.Join(missing, ", ")))
+	}
+
+	if len(rows)-1 > buahRawImportMaxRows {
+		return nil, nil, errors.ValidationError(fmt.Sprintf("maksimal %d baris per file", buahRawImportMaxRows))
+	}
+
+	var parsed []importRow
+	var rowErrors []response.BuahRawImportRowError
+	today := time.Now()
+
+	for i, cells := range rows[1:] {
+		line := i + 2
+		cell := func(col string) string {
+			if idx, ok := columns[col]; ok && idx < len(cells) {
+				return strings.TrimSpace(cells[idx])
+			}
+			return ""
+		}
+
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+
+		row := importRow{
+			Row:       line,
+			JenisKode: strings.ToUpper(cell(importColJenisKode)),
+			PohonKode: strings.ToUpper(cell(importColPohonKode)),
+			BlokKode:  strings.ToUpper(cell(importColBlokKode)),
+		}
+		fail := func(col, msg string) {
+			rowErrors = append(rowErrors, response.BuahRawImportRowError{Row: line, Column: col, Message: msg})
+		}
+
+		if tgl, err := spreadsheet.ParseDate(cell(importColTglPanen)); err != nil {
+			fail(importColTglPanen, "tanggal panen tidak valid")
+		} else if tgl.After(today) {
+			fail(importColTglPanen, "tanggal panen tidak boleh di masa depan")
+		} else {
+			row.TglPanen = tgl.Format("2006-01-02")
+		}
+
+		if row.JenisKode == "" {
+			fail(importColJenisKode, "wajib diisi")
+		}
+		if row.PohonKode == "" {
+			fail(importColPohonKode, "wajib diisi")
+		}
+		if row.BlokKode == "" {
+			fail(importColBlokKode, "wajib diisi")
+		}
+
+		berat, err := strconv.ParseFloat(strings.ReplaceAll(cell(importColBerat), ",", "."), 64)
+		if err != nil || berat <= 0 {
+			fail(importColBerat, "berat harus angka lebih dari 0")
+		}
+		row.Berat = berat
+
+		if v := cell(constants.AtributTingkatKematangan); v != "" {
+			row.Kualitas.TingkatKematangan = &v
+		}
+		if v := cell(constants.AtributCacat); v != "" {
+			row.Kualitas.Cacat = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '|' })
+		}
+		if v := cell(constants.AtributSkorAroma); v != "" {
+			if score, err := strconv.Atoi(v); err != nil {
+				fail(constants.AtributSkorAroma, "skor aroma harus bilangan bulat")
+			} else {
+				row.Kualitas.SkorAroma = &score
+			}
+		}
+		if v := cell(constants.AtributMetodePanen); v != "" {
+			row.Kualitas.MetodePanen = &v
+		}
+
+		parsed = append(parsed, row)
+	}
+
+	return parsed, rowErrors, nil
+}
+
+// resolveImportRows maps codes to master data. Rows that already failed parsing are still checked
+// so the report lists every problem at once, but only fully valid rows become items.
+func (s *buahRawService) resolveImportRows(ctx context.Context, rows []importRow) ([]bulkBuahItem, []response.BuahRawImportRowError, error) {
+	var jenisKodes []string
+	var pohonKeys []repository.PohonKey
+	seenPohon := make(map[repository.PohonKey]bool)
+	for _, row := range rows {
+		if row.JenisKode != "" {
+			jenisKodes = append(jenisKodes, row.JenisKode)
+		}
+		key := repository.PohonKey{BlokKode: row.BlokKode, PohonKode: row.PohonKode}
+		if row.PohonKode != "" && row.BlokKode != "" && !seenPohon[key] {
+			seenPohon[key] = true
+			pohonKeys = append(pohonKeys, key)
+		}
+	}
+
+	jenisMap := make(map[string]domain.JenisDurian)
+	pohonMap := make(map[repository.PohonKey]*domain.Pohon)
+	var err error
+
+	if len(jenisKodes) > 0 {
+		if jenisMap, err = s.repo.GetJenisDurianByKodes(ctx, uniqueStrings(jenisKodes)); err != nil {
+			return nil, nil, fmt.Errorf("gagal mengambil data jenis durian: %v", err)
+		}
+	}
+	if len(pohonKeys) > 0 {
+		if pohonMap, err = s.repo.GetPohonByKodesWithHierarchy(ctx, pohonKeys); err != nil {
+			return nil, nil, fmt.Errorf("gagal mengambil data pohon: %v", err)
+		}
+	}
+
+	var jenisIDs []string
+	for _, jenis := range jenisMap {
+		jenisIDs = append(jenisIDs, jenis.ID)
+	}
+	kualitasMap, err := s.kualitas.configs(ctx, jenisIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []bulkBuahItem
+	var rowErrors []response.BuahRawImportRowError
+
+	for _, row := range rows {
+		fail := func(col, msg string) {
+			rowErrors = append(rowErrors, response.BuahRawImportRowError{Row: row.Row, Column: col, Message: msg})
+		}
+
+		jenis, jenisOK := jenisMap[row.JenisKode]
+		if row.JenisKode != "" && !jenisOK {
+			fail(importColJenisKode, fmt.Sprintf("jenis durian %s tidak ditemukan", row.JenisKode))
+		}
+
+		pohon, pohonOK := pohonMap[repository.PohonKey{BlokKode: row.BlokKode, PohonKode: row.PohonKode}]
+		switch {
+		case row.PohonKode == "" || row.BlokKode == "":
+			pohonOK = false
+		case !pohonOK:
+			fail(importColPohonKode, fmt.Sprintf("pohon %s tidak ditemukan di blok %s", row.PohonKode, row.BlokKode))
+		case pohon == nil:
+			fail(importColPohonKode, fmt.Sprintf("pohon %s di blok %s ditemukan lebih dari satu", row.PohonKode, row.BlokKode))
+			pohonOK = false
+		default:
+			if _, _, ok := buahKodeValues(pohon, jenis.Kode, row.TglPanen); !ok {
+				fail(importColPohonKode, "data hierarki lokasi pohon tidak lengkap")
+				pohonOK = false
+			}
+		}
+
+		if jenisOK {
+			var scratch domain.BuahRaw
+			if err := applyKualitas(kualitasMap[jenis.ID], row.Kualitas, &scratch); err != nil {
+				fail("", err.Error())
+				jenisOK = false
+			}
+		}
+
+		if !jenisOK || !pohonOK || row.TglPanen == "" || row.Berat <= 0 {
+			continue
+		}
+
+		items = append(items, bulkBuahItem{
+			JenisDurianID: jenis.ID,
+			PohonID:       pohon.ID,
+			BlokID:        pohon.BlokID,
+			TglPanen:      row.TglPanen,
+			Berat:         row.Berat,
+			Jumlah:        1,
+			Kualitas:      row.Kualitas,
+		})
+	}
+
+	return items, rowErrors, nil
+}
@@ -2,47 +2,174 @@ package services
 
 import (
 	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/dto/requests"
 	"durich-be/internal/dto/response"
 	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"sort"
 	"time"
 )
 
 type DashboardService interface {
-	GetStokDashboard(ctx context.Context, dateFrom, dateTo string) (*response.DashboardStokResponse, error)
-	GetSalesDashboard(ctx context.Context, dateFrom, dateTo string) (*response.DashboardSalesResponse, error)
+	GetStokDashboard(ctx context.Context, dateFrom, dateTo, musimID, asOf string) (*response.DashboardStokResponse, error)
+	GetSalesDashboard(ctx context.Context, dateFrom, dateTo, musimID string) (*response.DashboardSalesResponse, error)
 	GetWarehouseData(ctx context.Context, locationID string) (*response.WarehouseDataResponse, error)
 }
 
 type dashboardService struct {
-	repo repository.DashboardRepository
+	repo      repository.DashboardRepository
+	musimRepo repository.MusimRepository
+	stokRepo  repository.StokRepository
 }
 
-func NewDashboardService(repo repository.DashboardRepository) DashboardService {
-	return &dashboardService{repo: repo}
+func NewDashboardService(repo repository.DashboardRepository, musimRepo repository.MusimRepository, stokRepo repository.StokRepository) DashboardService {
+	return &dashboardService{
+		repo:      repo,
+		musimRepo: musimRepo,
+		stokRepo:  stokRepo,
+	}
 }
 
-func (s *dashboardService) GetStokDashboard(ctx context.Context, dateFrom, dateTo string) (*response.DashboardStokResponse, error) {
+// GetStokDashboard reports the stock held now, or at the end of asOf when given. The
+// throughput part always covers the date range.
+func (s *dashboardService) GetStokDashboard(ctx context.Context, dateFrom, dateTo, musimID, asOf string) (*response.DashboardStokResponse, error) {
+	dateFrom, dateTo, err := s.musimRange(ctx, dateFrom, dateTo, musimID)
+	if err != nil {
+		return nil, err
+	}
+
 	from, to, err := s.parseDateRange(dateFrom, dateTo)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetStokDashboard(ctx, from, to)
+	if asOf == "" {
+		return s.repo.GetStokDashboard(ctx, from, to, musimID)
+	}
+	return s.getStokDashboardAsOf(ctx, from, to, musimID, asOf)
 }
 
-func (s *dashboardService) GetSalesDashboard(ctx context.Context, dateFrom, dateTo string) (*response.DashboardSalesResponse, error) {
+func (s *dashboardService) getStokDashboardAsOf(ctx context.Context, from, to time.Time, musimID, asOf string) (*response.DashboardStokResponse, error) {
+	tanggal, err := parseTanggalStok(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	items, sumber, err := stokAsOf(ctx, s.stokRepo, tanggal)
+	if err != nil {
+		return nil, err
+	}
+
+	before := tanggal.AddDate(0, 0, 1)
+	if now := time.Now(); before.After(now) {
+		before = now
+	}
+	summary, err := s.repo.GetStokSummaryAsOf(ctx, before, musimID)
+	if err != nil {
+		return nil, err
+	}
+
+	throughput, trend, err := s.repo.GetThroughput(ctx, from, to, musimID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := requests.StokAsOfQuery{MusimID: musimID}
+	byJenis := make([]response.StokByJenis, 0)
+	index := make(map[string]int)
+	gradeQty := make(map[string]map[string]int)
+	for i := range items {
+		it := &items[i]
+		if !stokCocok(it, filter) {
+			continue
+		}
+
+		summary.TotalLotAktif += it.LotCount
+		if it.Status == constants.LotStatusReady {
+			summary.LotReadyToShip += it.LotCount
+		}
+
+		j, ok := index[it.JenisDurianNama]
+		if !ok {
+			j = len(byJenis)
+			index[it.JenisDurianNama] = j
+			gradeQty[it.JenisDurianNama] = map[string]int{}
+			byJenis = append(byJenis, response.StokByJenis{
+				JenisDurian:          it.JenisDurianNama,
+				AvgGradeDistribution: map[string]string{},
+			})
+		}
+		byJenis[j].TotalQty += it.Qty
+		byJenis[j].TotalBerat += it.Berat
+		byJenis[j].LotCount += it.LotCount
+		gradeQty[it.JenisDurianNama][it.KondisiBuah] += it.Qty
+	}
+
+	for i := range byJenis {
+		if byJenis[i].TotalQty == 0 {
+			continue
+		}
+		for grade, qty := range gradeQty[byJenis[i].JenisDurian] {
+			byJenis[i].AvgGradeDistribution[grade] = fmt.Sprintf("%.1f%%", float64(qty)*100/float64(byJenis[i].TotalQty))
+		}
+	}
+	sort.SliceStable(byJenis, func(i, j int) bool {
+		return byJenis[i].TotalQty > byJenis[j].TotalQty
+	})
+
+	return &response.DashboardStokResponse{
+		Summary:     summary,
+		StokByJenis: byJenis,
+		Throughput:  throughput,
+		Trend7Hari:  trend,
+		AsOf:        asOf,
+		Sumber:      sumber,
+	}, nil
+}
+
+func (s *dashboardService) GetSalesDashboard(ctx context.Context, dateFrom, dateTo, musimID string) (*response.DashboardSalesResponse, error) {
+	dateFrom, dateTo, err := s.musimRange(ctx, dateFrom, dateTo, musimID)
+	if err != nil {
+		return nil, err
+	}
+
 	from, to, err := s.parseDateRange(dateFrom, dateTo)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetSalesDashboard(ctx, from, to)
+	return s.repo.GetSalesDashboard(ctx, from, to, musimID)
 }
 
 func (s *dashboardService) GetWarehouseData(ctx context.Context, locationID string) (*response.WarehouseDataResponse, error) {
 	return s.repo.GetWarehouseData(ctx, locationID)
 }
 
+// musimRange defaults an empty date range to the season's own period
+func (s *dashboardService) musimRange(ctx context.Context, dateFrom, dateTo, musimID string) (string, string, error) {
+	if musimID == "" {
+		return dateFrom, dateTo, nil
+	}
+
+	musim, err := s.musimRepo.GetByID(ctx, musimID)
+	if err != nil {
+		return "", "", err
+	}
+	if musim == nil {
+		return "", "", errors.NotFoundError("musim panen tidak ditemukan")
+	}
+
+	if dateFrom == "" {
+		dateFrom = musim.TanggalMulai.Format("2006-01-02")
+	}
+	if dateTo == "" && musim.TanggalSelesai != nil {
+		dateTo = musim.TanggalSelesai.Format("2006-01-02")
+	}
+	return dateFrom, dateTo, nil
+}
+
 func (s *dashboardService) parseDateRange(dateFrom, dateTo string) (time.Time, time.Time, error) {
 	var from, to time.Time
 	var err error
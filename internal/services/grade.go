@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/database"
+	"durich-be/pkg/errors"
+	"fmt"
+	"strings"
+)
+
+type GradeService interface {
+	Create(ctx context.Context, req requests.GradeRequest) (*response.GradeResponse, error)
+	GetList(ctx context.Context) ([]response.GradeResponse, error)
+	GetByID(ctx context.Context, id string) (*response.GradeResponse, error)
+	Update(ctx context.Context, id string, req requests.GradeRequest) (*response.GradeResponse, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type gradeService struct {
+	repo        repository.GradeRepository
+	buahRawRepo repository.BuahRawRepository
+}
+
+func NewGradeService(repo repository.GradeRepository, buahRawRepo repository.BuahRawRepository) GradeService {
+	return &gradeService{
+		repo:        repo,
+		buahRawRepo: buahRawRepo,
+	}
+}
+
+func (s *gradeService) Create(ctx context.Context, req requests.GradeRequest) (*response.GradeResponse, error) {
+	grade := &domain.Grade{}
+	if err := s.apply(ctx, grade, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, grade); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("kode grade sudah digunakan")
+		}
+		return nil, err
+	}
+	return s.GetByID(ctx, grade.ID)
+}
+
+func (s *gradeService) GetList(ctx context.Context) ([]response.GradeResponse, error) {
+	list, err := s.repo.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.GradeResponse, 0, len(list))
+	for i := range list {
+		res = append(res, toGradeResponse(&list[i]))
+	}
+	return res, nil
+}
+
+func (s *gradeService) GetByID(ctx context.Context, id string) (*response.GradeResponse, error) {
+	grade, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grade == nil {
+		return nil, errors.NotFoundError("grade tidak ditemukan")
+	}
+
+	res := toGradeResponse(grade)
+	return &res, nil
+}
+
+// Update replaces the grade rules. Renaming the kode leaves lots already graded with the old one as they are.
+func (s *gradeService) Update(ctx context.Context, id string, req requests.GradeRequest) (*response.GradeResponse, error) {
+	grade, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grade == nil {
+		return nil, errors.NotFoundError("grade tidak ditemukan")
+	}
+	if err := s.apply(ctx, grade, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, grade); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("kode grade sudah digunakan")
+		}
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *gradeService) Delete(ctx context.Context, id string) error {
+	grade, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if grade == nil {
+		return errors.NotFoundError("grade tidak ditemukan")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *gradeService) apply(ctx context.Context, grade *domain.Grade, req requests.GradeRequest) error {
+	grade.Kode = normalizeGrade(req.Kode)
+	grade.Nama = strings.TrimSpace(req.Nama)
+	grade.Urutan = req.Urutan
+	if grade.Kode == "" {
+		return errors.ValidationError("kode grade wajib diisi")
+	}
+
+	grade.Aturan = make([]domain.GradeAturan, 0, len(req.Aturan))
+	seen := make(map[string]bool, len(req.Aturan))
+	for _, a := range req.Aturan {
+		if seen[a.JenisDurianID] {
+			return errors.ValidationError("aturan untuk satu jenis durian hanya boleh satu")
+		}
+		seen[a.JenisDurianID] = true
+
+		if _, err := s.buahRawRepo.GetJenisDurianByID(ctx, a.JenisDurianID); err != nil {
+			return errors.ValidationError("jenis durian " + a.JenisDurianID + " tidak ditemukan")
+		}
+		if a.BeratMin != nil && a.BeratMax != nil && *a.BeratMin > *a.BeratMax {
+			return errors.ValidationError("berat_min tidak boleh melebihi berat_max")
+		}
+
+		grade.Aturan = append(grade.Aturan, domain.GradeAturan{
+			JenisDurianID:  a.JenisDurianID,
+			BeratMin:       a.BeratMin,
+			BeratMax:       a.BeratMax,
+			KematanganOpsi: normalizeOpsi(a.KematanganOpsi),
+			CacatDilarang:  normalizeOpsi(a.CacatDilarang),
+			CacatMaks:      a.CacatMaks,
+			SkorAromaMin:   a.SkorAromaMin,
+		})
+	}
+	return nil
+}
+
+func toGradeResponse(grade *domain.Grade) response.GradeResponse {
+	res := response.GradeResponse{
+		ID:     grade.ID,
+		Kode:   grade.Kode,
+		Nama:   grade.Nama,
+		Urutan: grade.Urutan,
+		Aturan: make([]response.GradeAturanResponse, 0, len(grade.Aturan)),
+	}
+	for _, a := range grade.Aturan {
+		res.Aturan = append(res.Aturan, response.GradeAturanResponse{
+			JenisDurianID:  a.JenisDurianID,
+			BeratMin:       a.BeratMin,
+			BeratMax:       a.BeratMax,
+			KematanganOpsi: a.KematanganOpsi,
+			CacatDilarang:  a.CacatDilarang,
+			CacatMaks:      a.CacatMaks,
+			SkorAromaMin:   a.SkorAromaMin,
+		})
+	}
+	return res
+}
+
+func normalizeGrade(kode string) string {
+	return strings.ToUpper(strings.TrimSpace(kode))
+}
+
+// gradeChecker validates grade codes and suggests a grade for a fruit, for services that write lots
+type gradeChecker struct {
+	repo repository.GradeRepository
+}
+
+func newGradeChecker(repo repository.GradeRepository) *gradeChecker {
+	return &gradeChecker{repo: repo}
+}
+
+// resolve returns the master kode for kondisi, so "a" and " A " both become "A"
+func (c *gradeChecker) resolve(ctx context.Context, kondisi string) (string, error) {
+	kode := normalizeGrade(kondisi)
+	grade, err := c.repo.GetByKode(ctx, kode)
+	if err != nil {
+		return "", err
+	}
+	if grade == nil {
+		return "", errors.ValidationError(fmt.Sprintf("grade %s tidak terdaftar", kondisi))
+	}
+	return grade.Kode, nil
+}
+
+// saran returns the best grade whose rule for the fruit's jenis it satisfies, or nil when none does
+func (c *gradeChecker) saran(ctx context.Context, buah *domain.BuahRaw) (*string, error) {
+	rules, err := c.repo.GetAturanByJenis(ctx, buah.JenisDurian)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].Grade != nil && gradeCocok(&rules[i], buah) {
+			return &rules[i].Grade.Kode, nil
+		}
+	}
+	return nil, nil
+}
+
+// gradeCocok reports whether a fruit satisfies a rule. An attribute the rule constrains but
+// the fruit lacks does not match.
+func gradeCocok(aturan *domain.GradeAturan, buah *domain.BuahRaw) bool {
+	if aturan.BeratMin != nil && buah.Berat < *aturan.BeratMin {
+		return false
+	}
+	if aturan.BeratMax != nil && buah.Berat > *aturan.BeratMax {
+		return false
+	}
+	if len(aturan.KematanganOpsi) > 0 {
+		if buah.TingkatKematangan == nil || !containsString(aturan.KematanganOpsi, *buah.TingkatKematangan) {
+			return false
+		}
+	}
+	for _, c := range buah.Cacat {
+		if containsString(aturan.CacatDilarang, c) {
+			return false
+		}
+	}
+	if aturan.CacatMaks != nil && len(buah.Cacat) > *aturan.CacatMaks {
+		return false
+	}
+	if aturan.SkorAromaMin != nil && (buah.SkorAroma == nil || *buah.SkorAroma < *aturan.SkorAromaMin) {
+		return false
+	}
+	return true
+}
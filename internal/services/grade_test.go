@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/internal/repository"
+	"testing"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+
+func TestGradeCocok(t *testing.T) {
+	tests := []struct {
+		name   string
+		aturan domain.GradeAturan
+		buah   domain.BuahRaw
+		want   bool
+	}{
+		{
+			name: "rule without constraints matches anything",
+			buah: domain.BuahRaw{Berat: 1.2},
+			want: true,
+		},
+		{
+			name:   "weight inside range",
+			aturan: domain.GradeAturan{BeratMin: floatPtr(2), BeratMax: floatPtr(3)},
+			buah:   domain.BuahRaw{Berat: 2.5},
+			want:   true,
+		},
+		{
+			name:   "weight bounds are inclusive",
+			aturan: domain.GradeAturan{BeratMin: floatPtr(2), BeratMax: floatPtr(3)},
+			buah:   domain.BuahRaw{Berat: 3},
+			want:   true,
+		},
+		{
+			name:   "too light",
+			aturan: domain.GradeAturan{BeratMin: floatPtr(2)},
+			buah:   domain.BuahRaw{Berat: 1.99},
+		},
+		{
+			name:   "too heavy",
+			aturan: domain.GradeAturan{BeratMax: floatPtr(3)},
+			buah:   domain.BuahRaw{Berat: 3.01},
+		},
+		{
+			name:   "ripeness allowed",
+			aturan: domain.GradeAturan{KematanganOpsi: []string{"MATANG", "SETENGAH"}},
+			buah:   domain.BuahRaw{TingkatKematangan: stringPtr("MATANG")},
+			want:   true,
+		},
+		{
+			name:   "ripeness not allowed",
+			aturan: domain.GradeAturan{KematanganOpsi: []string{"MATANG"}},
+			buah:   domain.BuahRaw{TingkatKematangan: stringPtr("MENTAH")},
+		},
+		{
+			name:   "ripeness required but missing",
+			aturan: domain.GradeAturan{KematanganOpsi: []string{"MATANG"}},
+			buah:   domain.BuahRaw{},
+		},
+		{
+			name:   "forbidden defect",
+			aturan: domain.GradeAturan{CacatDilarang: []string{"RETAK"}},
+			buah:   domain.BuahRaw{Cacat: []string{"MEMAR", "RETAK"}},
+		},
+		{
+			name:   "defects within the limit",
+			aturan: domain.GradeAturan{CacatMaks: intPtr(1)},
+			buah:   domain.BuahRaw{Cacat: []string{"MEMAR"}},
+			want:   true,
+		},
+		{
+			name:   "too many defects",
+			aturan: domain.GradeAturan{CacatMaks: intPtr(1)},
+			buah:   domain.BuahRaw{Cacat: []string{"MEMAR", "ULAT"}},
+		},
+		{
+			name:   "no defects allowed",
+			aturan: domain.GradeAturan{CacatMaks: intPtr(0)},
+			buah:   domain.BuahRaw{Cacat: []string{"MEMAR"}},
+		},
+		{
+			name:   "aroma high enough",
+			aturan: domain.GradeAturan{SkorAromaMin: intPtr(4)},
+			buah:   domain.BuahRaw{SkorAroma: intPtr(4)},
+			want:   true,
+		},
+		{
+			name:   "aroma too low",
+			aturan: domain.GradeAturan{SkorAromaMin: intPtr(4)},
+			buah:   domain.BuahRaw{SkorAroma: intPtr(3)},
+		},
+		{
+			name:   "aroma required but missing",
+			aturan: domain.GradeAturan{SkorAromaMin: intPtr(4)},
+			buah:   domain.BuahRaw{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradeCocok(&tt.aturan, &tt.buah); got != tt.want {
+				t.Errorf("gradeCocok = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// aturanGradeRepo serves fixed rules, best grade first, for the grade checker
+type aturanGradeRepo struct {
+	repository.GradeRepository
+	aturan []domain.GradeAturan
+}
+
+func (r *aturanGradeRepo) GetAturanByJenis(_ context.Context, _ string) ([]domain.GradeAturan, error) {
+	return r.aturan, nil
+}
+
+func TestGradeCheckerSaran(t *testing.T) {
+	repo := &aturanGradeRepo{aturan: []domain.GradeAturan{
+		{Grade: &domain.Grade{Kode: "A"}, BeratMin: floatPtr(3), CacatMaks: intPtr(0)},
+		{Grade: &domain.Grade{Kode: "B"}, BeratMin: floatPtr(2), CacatDilarang: []string{"BUSUK"}},
+		{Grade: &domain.Grade{Kode: "C"}, CacatDilarang: []string{"BUSUK"}},
+	}}
+	checker := newGradeChecker(repo)
+
+	tests := []struct {
+		name string
+		buah domain.BuahRaw
+		want string
+	}{
+		{"best grade wins", domain.BuahRaw{Berat: 3.5}, "A"},
+		{"defect drops a grade", domain.BuahRaw{Berat: 3.5, Cacat: []string{"MEMAR"}}, "B"},
+		{"light fruit", domain.BuahRaw{Berat: 1.5}, "C"},
+		{"no rule matches", domain.BuahRaw{Berat: 3.5, Cacat: []string{"BUSUK"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.saran(context.Background(), &tt.buah)
+			if err != nil {
+				t.Fatalf("saran: %v", err)
+			}
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("saran = %s, want none", *got)
+			case tt.want != "" && (got == nil || *got != tt.want):
+				t.Errorf("saran = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type HoldService interface {
+	Create(ctx context.Context, req requests.HoldRequest, user requests.UserAuth) (*response.HoldResponse, error)
+	GetList(ctx context.Context, q requests.HoldListQuery, user requests.UserAuth) ([]response.HoldResponse, error)
+	GetByID(ctx context.Context, id string) (*response.HoldResponse, error)
+	Lepas(ctx context.Context, id string, user requests.UserAuth) (*response.HoldResponse, error)
+	RunExpiry(ctx context.Context)
+}
+
+type holdService struct {
+	repo          repository.HoldRepository
+	lotRepo       repository.LotRepository
+	interval      time.Duration
+	defaultDurasi time.Duration
+	maksDurasi    time.Duration
+}
+
+// NewHoldService takes how often RunExpiry expires holds, how long a hold lasts without an end
+// and the longest a hold may last
+func NewHoldService(repo repository.HoldRepository, lotRepo repository.LotRepository, interval, defaultDurasi, maksDurasi time.Duration) HoldService {
+	if interval <= 0 {
+		interval = constants.HoldDefaultInterval
+	}
+	if maksDurasi <= 0 {
+		maksDurasi = constants.HoldMaksDurasi
+	}
+	if defaultDurasi <= 0 {
+		defaultDurasi = constants.HoldDefaultDurasi
+	}
+	if defaultDurasi > maksDurasi {
+		defaultDurasi = maksDurasi
+	}
+	return &holdService{
+		repo:          repo,
+		lotRepo:       lotRepo,
+		interval:      interval,
+		defaultDurasi: defaultDurasi,
+		maksDurasi:    maksDurasi,
+	}
+}
+
+// Create holds a READY lot for a customer. A branch user only holds lots of its own location.
+func (s *holdService) Create(ctx context.Context, req requests.HoldRequest, user requests.UserAuth) (*response.HoldResponse, error) {
+	lot, err := s.lotRepo.GetByID(ctx, req.LotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if user.LocationID != "" && (lot.PosisiID == nil || *lot.PosisiID != user.LocationID) {
+		return nil, errors.ValidationError("akses ditolak: lot tidak berada di lokasi anda")
+	}
+
+	pelanggan := strings.TrimSpace(req.Pelanggan)
+	if pelanggan == "" {
+		return nil, errors.ValidationError("pelanggan wajib diisi")
+	}
+
+	now := time.Now()
+	berakhir := now.Add(s.defaultDurasi)
+	if req.BerakhirAt != nil {
+		berakhir = *req.BerakhirAt
+	}
+	if !berakhir.After(now) {
+		return nil, errors.ValidationError("berakhir_at harus setelah sekarang")
+	}
+	if berakhir.Sub(now) > s.maksDurasi {
+		return nil, errors.ValidationError(fmt.Sprintf("hold paling lama %s", s.maksDurasi))
+	}
+
+	hold := &domain.LotHold{
+		LotID:        lot.ID,
+		Pelanggan:    pelanggan,
+		BerakhirAt:   berakhir,
+		DipegangOleh: user.UserID,
+	}
+	if catatan := strings.TrimSpace(req.Catatan); catatan != "" {
+		hold.Catatan = &catatan
+	}
+	if err := s.repo.Create(ctx, hold); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	return s.GetByID(ctx, hold.ID)
+}
+
+// GetList lists holds, the newest first. A branch user only sees its own location.
+func (s *holdService) GetList(ctx context.Context, q requests.HoldListQuery, user requests.UserAuth) ([]response.HoldResponse, error) {
+	pemegangID := ""
+	if q.Milik {
+		pemegangID = user.UserID
+	}
+
+	list, err := s.repo.GetList(ctx, q.Status, q.LotID, user.LocationID, pemegangID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]response.HoldResponse, 0, len(list))
+	for i := range list {
+		res = append(res, toHoldResponse(&list[i], now))
+	}
+	return res, nil
+}
+
+func (s *holdService) GetByID(ctx context.Context, id string) (*response.HoldResponse, error) {
+	hold, err := s.getHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := toHoldResponse(hold, time.Now())
+	return &res, nil
+}
+
+// Lepas releases a running hold. Only its holder or an admin can release it.
+func (s *holdService) Lepas(ctx context.Context, id string, user requests.UserAuth) (*response.HoldResponse, error) {
+	hold, err := s.getHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold.DipegangOleh != user.UserID && !hasRole(user, domain.RoleAdmin) {
+		return nil, errors.ValidationError("akses ditolak: hanya pemegang hold atau admin yang dapat melepasnya")
+	}
+	if hold.Status != constants.HoldStatusAktif || !hold.BerakhirAt.After(time.Now()) {
+		return nil, errors.ValidationError("hold sudah tidak aktif")
+	}
+
+	now := time.Now()
+	hold.Status = constants.HoldStatusDilepas
+	hold.DilepasOleh = &user.UserID
+	hold.DilepasAt = &now
+	if err := s.repo.Lepas(ctx, hold); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	return s.GetByID(ctx, id)
+}
+
+// RunExpiry expires the holds whose time ran out, right away and then every interval, until
+// ctx is done. Holds are already ignored once they run out, the job only records it.
+func (s *holdService) RunExpiry(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.repo.Expire(ctx, time.Now())
+		if err != nil {
+			log.Printf("lot hold: gagal mengakhiri hold: %v", err)
+		} else if n > 0 {
+			log.Printf("lot hold: %d hold kedaluwarsa", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *holdService) getHold(ctx context.Context, id string) (*domain.LotHold, error) {
+	hold, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, errors.NotFoundError("hold tidak ditemukan")
+	}
+	return hold, nil
+}
+
+// toHoldResponse reports an AKTIF hold that ran out at now as KEDALUWARSA, before the job
+// records it
+func toHoldResponse(hold *domain.LotHold, now time.Time) response.HoldResponse {
+	res := response.HoldResponse{
+		ID:           hold.ID,
+		LotID:        hold.LotID,
+		LokasiID:     hold.LokasiID,
+		Pelanggan:    hold.Pelanggan,
+		Catatan:      hold.Catatan,
+		BerakhirAt:   hold.BerakhirAt,
+		Status:       hold.Status,
+		DipegangOleh: hold.DipegangOleh,
+		DilepasOleh:  hold.DilepasOleh,
+		DilepasAt:    hold.DilepasAt,
+		PengirimanID: hold.PengirimanID,
+		CreatedAt:    hold.CreatedAt,
+	}
+	if res.Status == constants.HoldStatusAktif && !hold.BerakhirAt.After(now) {
+		res.Status = constants.HoldStatusKedaluwarsa
+	}
+	if hold.Lot != nil {
+		res.LotKode = hold.Lot.Kode
+		res.KondisiBuah = hold.Lot.KondisiBuah
+		res.QtySisa = hold.Lot.QtySisa
+		res.BeratSisa = hold.Lot.BeratSisa
+		if hold.Lot.JenisDurianDetail != nil {
+			res.JenisDurianNama = hold.Lot.JenisDurianDetail.NamaJenis
+		}
+	}
+	if hold.Lokasi != nil {
+		res.LokasiNama = hold.Lokasi.Nama
+	}
+	if hold.Pemegang != nil {
+		res.Email = hold.Pemegang.Email
+	}
+	if hold.Pengiriman != nil {
+		res.PengirimanKode = hold.Pengiriman.Kode
+	}
+	return res
+}
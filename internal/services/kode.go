@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/repository"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Code templates are literal text mixed with {TOKEN}s, e.g. "LOT-{JENIS}-{GRADE}-{DD}{MM}{YY}-{SEQ:2}".
+// {SEQ:n} is required exactly once and is zero padded to n digits.
+const (
+	kodeTokenCompany = "COMPANY"
+	kodeTokenEstate  = "ESTATE"
+	kodeTokenDivisi  = "DIVISI"
+	kodeTokenBlok    = "BLOK"
+	kodeTokenPohon   = "POHON"
+	kodeTokenJenis   = "JENIS"
+	kodeTokenGrade   = "GRADE"
+	kodeTokenYYYY    = "YYYY"
+	kodeTokenYY      = "YY"
+	kodeTokenMM      = "MM"
+	kodeTokenDD      = "DD"
+	kodeTokenSeq     = "SEQ"
+)
+
+var kodeDateTokens = map[string]string{
+	kodeTokenYYYY: "2006",
+	kodeTokenYY:   "06",
+	kodeTokenMM:   "01",
+	kodeTokenDD:   "02",
+}
+
+var kodeAllowedTokens = map[string][]string{
+	constants.KodeTipeBuah:     {kodeTokenCompany, kodeTokenEstate, kodeTokenDivisi, kodeTokenBlok, kodeTokenPohon, kodeTokenJenis},
+	constants.KodeTipeLot:      {kodeTokenCompany, kodeTokenEstate, kodeTokenJenis, kodeTokenGrade},
+	constants.KodeTipeShipment: {kodeTokenCompany},
+}
+
+// defaultKodeTemplates reproduce the formats used before templates were configurable
+var defaultKodeTemplates = map[string]domain.KodeTemplate{
+	constants.KodeTipeBuah: {
+		Template: "{COMPANY}{ESTATE}{DIVISI}{BLOK}{POHON}-F{SEQ:5}",
+		Reset:    constants.KodeResetNone,
+	},
+	constants.KodeTipeLot: {
+		Template: "LOT-{JENIS}-{GRADE}-{DD}{MM}{YY}-{SEQ:2}",
+		Reset:    constants.KodeResetDaily,
+	},
+	constants.KodeTipeShipment: {
+		Template: "SHP-{YY}{MM}{DD}-{SEQ:3}",
+		Reset:    constants.KodeResetDaily,
+	},
+}
+
+var kodeResetPeriods = map[string]string{
+	constants.KodeResetNone:    "",
+	constants.KodeResetDaily:   "2006-01-02",
+	constants.KodeResetMonthly: "2006-01",
+	constants.KodeResetYearly:  "2006",
+}
+
+// kodeValues are the token values available when a code is generated
+type kodeValues struct {
+	Company string
+	Estate  string
+	Divisi  string
+	Blok    string
+	Pohon   string
+	Jenis   string
+	Grade   string
+	Date    time.Time
+}
+
+func (v kodeValues) get(token string) string {
+	switch token {
+	case kodeTokenCompany:
+		return v.Company
+	case kodeTokenEstate:
+		return v.Estate
+	case kodeTokenDivisi:
+		return v.Divisi
+	case kodeTokenBlok:
+		return v.Blok
+	case kodeTokenPohon:
+		return v.Pohon
+	case kodeTokenJenis:
+		return v.Jenis
+	case kodeTokenGrade:
+		return v.Grade
+	}
+	return ""
+}
+
+type kodePart struct {
+	literal string
+	token   string
+	width   int
+}
+
+type kodeTemplate struct {
+	parts []kodePart
+	reset string
+}
+
+// parseKodeTemplate checks the template against the tokens allowed for the document type. A reset period
+// must be visible in the code itself, otherwise numbers restarting each period would collide.
+func parseKodeTemplate(tipe, template, reset string) (kodeTemplate, error) {
+	allowed, ok := kodeAllowedTokens[tipe]
+	if !ok {
+		return kodeTemplate{}, fmt.Errorf("tipe kode tidak dikenal: %s", tipe)
+	}
+	if _, ok := kodeResetPeriods[reset]; !ok {
+		return kodeTemplate{}, fmt.Errorf("reset tidak valid (NONE, DAILY, MONTHLY atau YEARLY)")
+	}
+
+	tpl := kodeTemplate{reset: reset}
+	used := make(map[string]bool)
+	rest := template
+
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			tpl.parts = append(tpl.parts, kodePart{literal: rest})
+			break
+		}
+		if rest[open] == '}' {
+			return kodeTemplate{}, fmt.Errorf("kurung kurawal tidak seimbang pada template")
+		}
+		if open > 0 {
+			tpl.parts = append(tpl.parts, kodePart{literal: rest[:open]})
+		}
+
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return kodeTemplate{}, fmt.Errorf("kurung kurawal tidak seimbang pada template")
+		}
+
+		part, err := parseKodeToken(rest[open+1 : open+1+end])
+		if err != nil {
+			return kodeTemplate{}, err
+		}
+		if part.token == kodeTokenSeq && used[kodeTokenSeq] {
+			return kodeTemplate{}, fmt.Errorf("{SEQ} hanya boleh dipakai sekali")
+		}
+		if _, isDate := kodeDateTokens[part.token]; !isDate && part.token != kodeTokenSeq && !containsString(allowed, part.token) {
+			return kodeTemplate{}, fmt.Errorf("token {%s} tidak tersedia untuk kode %s", part.token, tipe)
+		}
+
+		used[part.token] = true
+		tpl.parts = append(tpl.parts, part)
+		rest = rest[open+1+end+1:]
+	}
+
+	for _, part := range tpl.parts {
+		for _, r := range part.literal {
+			if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./", r)) {
+				return kodeTemplate{}, fmt.Errorf("karakter '%c' tidak boleh dipakai pada template", r)
+			}
+		}
+	}
+
+	if !used[kodeTokenSeq] {
+		return kodeTemplate{}, fmt.Errorf("template wajib memiliki {SEQ}")
+	}
+
+	hasYear := used[kodeTokenYYYY] || used[kodeTokenYY]
+	switch reset {
+	case constants.KodeResetDaily:
+		if !hasYear || !used[kodeTokenMM] || !used[kodeTokenDD] {
+			return kodeTemplate{}, fmt.Errorf("reset harian membutuhkan {DD}, {MM} dan {YY} atau {YYYY} pada template")
+		}
+	case constants.KodeResetMonthly:
+		if !hasYear || !used[kodeTokenMM] {
+			return kodeTemplate{}, fmt.Errorf("reset bulanan membutuhkan {MM} dan {YY} atau {YYYY} pada template")
+		}
+	case constants.KodeResetYearly:
+		if !hasYear {
+			return kodeTemplate{}, fmt.Errorf("reset tahunan membutuhkan {YY} atau {YYYY} pada template")
+		}
+	}
+
+	return tpl, nil
+}
+
+func parseKodeToken(raw string) (kodePart, error) {
+	name, width, hasWidth := strings.Cut(raw, ":")
+	part := kodePart{token: name}
+
+	if name != kodeTokenSeq {
+		if hasWidth {
+			return part, fmt.Errorf("panjang digit hanya berlaku untuk {SEQ}")
+		}
+		return part, nil
+	}
+
+	part.width = 1
+	if hasWidth {
+		n, err := strconv.Atoi(width)
+		if err != nil || n < 1 || n > 9 {
+			return part, fmt.Errorf("panjang digit {SEQ} harus 1 sampai 9")
+		}
+		part.width = n
+	}
+	return part, nil
+}
+
+// spec renders everything around {SEQ}. The counter prefix keeps date tokens unrendered so the
+// counter only restarts when the reset period changes, not whenever a finer date token does.
+func (t kodeTemplate) spec(values kodeValues) (repository.SequenceSpec, error) {
+	var before, after, keyBefore, keyAfter strings.Builder
+	width := 0
+	seen := false
+
+	for _, part := range t.parts {
+		text, keyText := part.literal, part.literal
+
+		switch {
+		case part.token == kodeTokenSeq:
+			seen = true
+			width = part.width
+			continue
+		case part.token == "":
+		case kodeDateTokens[part.token] != "":
+			text = values.Date.Format(kodeDateTokens[part.token])
+			keyText = "{" + part.token + "}"
+		default:
+			text = values.get(part.token)
+			if text == "" {
+				return repository.SequenceSpec{}, fmt.Errorf("nilai {%s} tidak tersedia untuk membuat kode", part.token)
+			}
+			keyText = text
+		}
+
+		if seen {
+			after.WriteString(text)
+			keyAfter.WriteString(keyText)
+		} else {
+			before.WriteString(text)
+			keyBefore.WriteString(keyText)
+		}
+	}
+
+	prefix := keyBefore.String()
+	if keyAfter.Len() > 0 {
+		prefix += "{SEQ}" + keyAfter.String()
+	}
+
+	head, tail := before.String(), after.String()
+	return repository.SequenceSpec{
+		Prefix: prefix,
+		Period: values.Date.Format(kodeResetPeriods[t.reset]),
+		Format: func(seq int) string {
+			return fmt.Sprintf("%s%0*d%s", head, width, seq, tail)
+		},
+	}, nil
+}
+
+// kodeGenerator resolves the template configured for a company and turns it into a sequence spec
+type kodeGenerator struct {
+	repo repository.KodeTemplateRepository
+}
+
+func newKodeGenerator(repo repository.KodeTemplateRepository) *kodeGenerator {
+	return &kodeGenerator{repo: repo}
+}
+
+func (g *kodeGenerator) spec(ctx context.Context, tipe, companyID string, values kodeValues) (repository.SequenceSpec, error) {
+	tpl := defaultKodeTemplates[tipe]
+
+	configured, err := g.repo.GetEffective(ctx, tipe, companyID)
+	if err != nil {
+		return repository.SequenceSpec{}, fmt.Errorf("gagal mengambil template kode: %v", err)
+	}
+	if configured != nil {
+		tpl = *configured
+	}
+
+	parsed, err := parseKodeTemplate(tipe, tpl.Template, tpl.Reset)
+	if err != nil {
+		return repository.SequenceSpec{}, fmt.Errorf("template kode %s tidak valid: %v", tipe, err)
+	}
+
+	return parsed.spec(values)
+}
+
+// buahKodeValues collects token values from the pohon location hierarchy.
+// ok is false when the hierarchy is incomplete.
+func buahKodeValues(pohon *domain.Pohon, jenisKode, tglPanen string) (values kodeValues, companyID string, ok bool) {
+	if pohon == nil || pohon.Blok == nil {
+		return values, "", false
+	}
+
+	blok := pohon.Blok
+	if blok.Divisi == nil || blok.Divisi.Estate == nil || blok.Divisi.Estate.Company == nil {
+		return values, "", false
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", tglPanen, time.Local)
+	if err != nil {
+		date = time.Now()
+	}
+
+	return kodeValues{
+		Company: blok.Divisi.Estate.Company.Kode,
+		Estate:  blok.Divisi.Estate.Kode,
+		Divisi:  blok.Divisi.Kode,
+		Blok:    blok.Kode,
+		Pohon:   pohon.Kode,
+		Jenis:   jenisKode,
+		Date:    date,
+	}, blok.Divisi.Estate.CompanyID, true
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"time"
+)
+
+type KodeTemplateService interface {
+	GetAll(ctx context.Context, companyID string) ([]response.KodeTemplateResponse, error)
+	Save(ctx context.Context, req requests.KodeTemplateSaveRequest, locationID string) (*response.KodeTemplateResponse, error)
+	Delete(ctx context.Context, id string, locationID string) error
+	Preview(ctx context.Context, req requests.KodeTemplatePreviewRequest) (*response.KodeTemplatePreviewResponse, error)
+}
+
+type kodeTemplateService struct {
+	repo           repository.KodeTemplateRepository
+	masterDataRepo repository.MasterDataRepository
+}
+
+func NewKodeTemplateService(repo repository.KodeTemplateRepository, masterDataRepo repository.MasterDataRepository) KodeTemplateService {
+	return &kodeTemplateService{
+		repo:           repo,
+		masterDataRepo: masterDataRepo,
+	}
+}
+
+// kodePreviewValues are sample values used to show what a template produces
+var kodePreviewValues = kodeValues{
+	Company: "IPS",
+	Estate:  "RES",
+	Divisi:  "01",
+	Blok:    "01A01",
+	Pohon:   "0000",
+	Jenis:   "MK",
+	Grade:   "A",
+}
+
+func (s *kodeTemplateService) GetAll(ctx context.Context, companyID string) ([]response.KodeTemplateResponse, error) {
+	list, err := s.repo.GetAll(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.KodeTemplateResponse, 0, len(list))
+	for i := range list {
+		result = append(result, toKodeTemplateResponse(&list[i]))
+	}
+	return result, nil
+}
+
+func (s *kodeTemplateService) Save(ctx context.Context, req requests.KodeTemplateSaveRequest, locationID string) (*response.KodeTemplateResponse, error) {
+	if locationID != "" {
+		return nil, errors.ForbiddenError("akses ditolak: hanya pusat yang dapat mengatur template kode")
+	}
+
+	if _, err := parseKodeTemplate(req.Tipe, req.Template, req.Reset); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	var company *domain.Company
+	if req.CompanyID != nil && *req.CompanyID == "" {
+		req.CompanyID = nil
+	}
+	if req.CompanyID != nil {
+		var err error
+		company, err = s.masterDataRepo.GetCompanyByID(ctx, *req.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+		if company == nil {
+			return nil, errors.ValidationError("company tidak ditemukan")
+		}
+	}
+
+	companyID := ""
+	if req.CompanyID != nil {
+		companyID = *req.CompanyID
+	}
+	current, err := s.effective(ctx, req.Tipe, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if resetSaja(current, domain.KodeTemplate{Template: req.Template, Reset: req.Reset}) {
+		return nil, errors.ValidationError(errResetSaja)
+	}
+
+	tpl, err := s.repo.GetByCompanyAndTipe(ctx, req.CompanyID, req.Tipe)
+	if err != nil {
+		return nil, err
+	}
+
+	if tpl == nil {
+		tpl = &domain.KodeTemplate{
+			CompanyID: req.CompanyID,
+			Tipe:      req.Tipe,
+			Template:  req.Template,
+			Reset:     req.Reset,
+		}
+		err = s.repo.Create(ctx, tpl)
+	} else {
+		tpl.Template = req.Template
+		tpl.Reset = req.Reset
+		err = s.repo.Update(ctx, tpl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tpl.Company = company
+	resp := toKodeTemplateResponse(tpl)
+	return &resp, nil
+}
+
+func (s *kodeTemplateService) Delete(ctx context.Context, id string, locationID string) error {
+	if locationID != "" {
+		return errors.ForbiddenError("akses ditolak: hanya pusat yang dapat mengatur template kode")
+	}
+
+	tpl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tpl == nil {
+		return errors.NotFoundError("template kode tidak ditemukan")
+	}
+
+	// A company template falls back to the group default, the group default to the built-in one
+	fallback := defaultKodeTemplates[tpl.Tipe]
+	if tpl.CompanyID != nil {
+		if fallback, err = s.effective(ctx, tpl.Tipe, ""); err != nil {
+			return err
+		}
+	}
+	if resetSaja(*tpl, fallback) {
+		return errors.ValidationError(errResetSaja)
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+const errResetSaja = "reset tidak bisa diubah tanpa mengubah template, nomor urut akan mulai ulang dan kode yang sudah terpakai terbit lagi"
+
+// effective is the template a company codes with now
+func (s *kodeTemplateService) effective(ctx context.Context, tipe, companyID string) (domain.KodeTemplate, error) {
+	tpl := defaultKodeTemplates[tipe]
+	configured, err := s.repo.GetEffective(ctx, tipe, companyID)
+	if err != nil {
+		return tpl, err
+	}
+	if configured != nil {
+		tpl = *configured
+	}
+	return tpl, nil
+}
+
+// resetSaja reports whether going from lama to baru changes only the reset. Counters are keyed
+// by the template text, so the new reset would restart numbering in a period that already
+// issued codes.
+func resetSaja(lama, baru domain.KodeTemplate) bool {
+	return lama.Template == baru.Template && lama.Reset != baru.Reset
+}
+
+func (s *kodeTemplateService) Preview(ctx context.Context, req requests.KodeTemplatePreviewRequest) (*response.KodeTemplatePreviewResponse, error) {
+	tpl, err := parseKodeTemplate(req.Tipe, req.Template, req.Reset)
+	if err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	values := kodePreviewValues
+	values.Date = time.Now()
+
+	spec, err := tpl.spec(values)
+	if err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	return &response.KodeTemplatePreviewResponse{
+		Tipe:     req.Tipe,
+		Template: req.Template,
+		Reset:    req.Reset,
+		Contoh:   spec.Format(1),
+	}, nil
+}
+
+func toKodeTemplateResponse(tpl *domain.KodeTemplate) response.KodeTemplateResponse {
+	resp := response.KodeTemplateResponse{
+		ID:        tpl.ID,
+		CompanyID: tpl.CompanyID,
+		Tipe:      tpl.Tipe,
+		Template:  tpl.Template,
+		Reset:     tpl.Reset,
+		CreatedAt: tpl.CreatedAt,
+		UpdatedAt: tpl.UpdatedAt,
+	}
+	if tpl.Company != nil {
+		resp.CompanyNama = tpl.Company.Nama
+	}
+	return resp
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestParseKodeTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		tipe     string
+		template string
+		reset    string
+		wantErr  bool
+	}{
+		{"default lot", constants.KodeTipeLot, "LOT-{JENIS}-{GRADE}-{DD}{MM}{YY}-{SEQ:2}", constants.KodeResetDaily, false},
+		{"default buah", constants.KodeTipeBuah, "{COMPANY}{ESTATE}{DIVISI}{BLOK}{POHON}-F{SEQ:5}", constants.KodeResetNone, false},
+		{"seq in the middle", constants.KodeTipeShipment, "{SEQ:4}/{COMPANY}/{YYYY}", constants.KodeResetYearly, false},
+		{"monthly", constants.KodeTipeShipment, "SHP{YYYY}{MM}-{SEQ:3}", constants.KodeResetMonthly, false},
+		{"unknown tipe", "FAKTUR", "F-{SEQ}", constants.KodeResetNone, true},
+		{"unknown reset", constants.KodeTipeLot, "LOT-{SEQ}", "WEEKLY", true},
+		{"no seq", constants.KodeTipeLot, "LOT-{JENIS}", constants.KodeResetNone, true},
+		{"seq twice", constants.KodeTipeLot, "{SEQ}-{SEQ}", constants.KodeResetNone, true},
+		{"token not allowed for tipe", constants.KodeTipeLot, "{POHON}-{SEQ}", constants.KodeResetNone, true},
+		{"unknown token", constants.KodeTipeLot, "{WARNA}-{SEQ}", constants.KodeResetNone, true},
+		{"unclosed brace", constants.KodeTipeLot, "LOT-{JENIS-{SEQ}", constants.KodeResetNone, true},
+		{"stray closing brace", constants.KodeTipeLot, "LOT}-{SEQ}", constants.KodeResetNone, true},
+		{"character not allowed", constants.KodeTipeLot, "LOT {SEQ}", constants.KodeResetNone, true},
+		{"width zero", constants.KodeTipeLot, "LOT-{SEQ:0}", constants.KodeResetNone, true},
+		{"width too wide", constants.KodeTipeLot, "LOT-{SEQ:10}", constants.KodeResetNone, true},
+		{"width on another token", constants.KodeTipeLot, "{JENIS:2}-{SEQ}", constants.KodeResetNone, true},
+		{"daily without day", constants.KodeTipeLot, "LOT-{MM}{YY}-{SEQ}", constants.KodeResetDaily, true},
+		{"monthly without year", constants.KodeTipeLot, "LOT-{MM}-{SEQ}", constants.KodeResetMonthly, true},
+		{"yearly without year", constants.KodeTipeLot, "LOT-{SEQ}", constants.KodeResetYearly, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseKodeTemplate(tt.tipe, tt.template, tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKodeTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKodeTemplateSpec(t *testing.T) {
+	values := kodeValues{
+		Company: "PT1",
+		Estate:  "E1",
+		Divisi:  "D1",
+		Blok:    "B1",
+		Pohon:   "P001",
+		Jenis:   "MK",
+		Grade:   "A",
+		Date:    time.Date(2026, 3, 5, 10, 0, 0, 0, time.Local),
+	}
+
+	tests := []struct {
+		name       string
+		tipe       string
+		template   string
+		reset      string
+		seq        int
+		wantPrefix string
+		wantPeriod string
+		wantKode   string
+	}{
+		{
+			name: "lot counter keeps date tokens unrendered",
+			tipe: constants.KodeTipeLot, template: "LOT-{JENIS}-{GRADE}-{DD}{MM}{YY}-{SEQ:2}", reset: constants.KodeResetDaily, seq: 7,
+			wantPrefix: "LOT-MK-A-{DD}{MM}{YY}-", wantPeriod: "2026-03-05", wantKode: "LOT-MK-A-050326-07",
+		},
+		{
+			name: "buah never resets",
+			tipe: constants.KodeTipeBuah, template: "{COMPANY}{ESTATE}{DIVISI}{BLOK}{POHON}-F{SEQ:5}", reset: constants.KodeResetNone, seq: 42,
+			wantPrefix: "PT1E1D1B1P001-F", wantPeriod: "", wantKode: "PT1E1D1B1P001-F00042",
+		},
+		{
+			name: "text after seq joins the counter",
+			tipe: constants.KodeTipeShipment, template: "{SEQ:4}/{COMPANY}/{YYYY}", reset: constants.KodeResetYearly, seq: 3,
+			wantPrefix: "{SEQ}/PT1/{YYYY}", wantPeriod: "2026", wantKode: "0003/PT1/2026",
+		},
+		{
+			name: "monthly period",
+			tipe: constants.KodeTipeShipment, template: "SHP-{YY}{MM}{DD}-{SEQ:3}", reset: constants.KodeResetMonthly, seq: 12,
+			wantPrefix: "SHP-{YY}{MM}{DD}-", wantPeriod: "2026-03", wantKode: "SHP-260305-012",
+		},
+		{
+			name: "seq wider than its padding",
+			tipe: constants.KodeTipeLot, template: "L{SEQ:2}", reset: constants.KodeResetNone, seq: 123,
+			wantPrefix: "L", wantPeriod: "", wantKode: "L123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := parseKodeTemplate(tt.tipe, tt.template, tt.reset)
+			if err != nil {
+				t.Fatalf("parseKodeTemplate: %v", err)
+			}
+			spec, err := tpl.spec(values)
+			if err != nil {
+				t.Fatalf("spec: %v", err)
+			}
+			if spec.Prefix != tt.wantPrefix {
+				t.Errorf("Prefix = %q, want %q", spec.Prefix, tt.wantPrefix)
+			}
+			if spec.Period != tt.wantPeriod {
+				t.Errorf("Period = %q, want %q", spec.Period, tt.wantPeriod)
+			}
+			if got := spec.Format(tt.seq); got != tt.wantKode {
+				t.Errorf("Format(%d) = %q, want %q", tt.seq, got, tt.wantKode)
+			}
+		})
+	}
+}
+
+func TestKodeTemplateSpecMissingValue(t *testing.T) {
+	tpl, err := parseKodeTemplate(constants.KodeTipeLot, "LOT-{JENIS}-{GRADE}-{SEQ}", constants.KodeResetNone)
+	if err != nil {
+		t.Fatalf("parseKodeTemplate: %v", err)
+	}
+	if _, err := tpl.spec(kodeValues{Jenis: "MK"}); err == nil {
+		t.Fatal("spec without a grade should fail")
+	}
+}
+
+func TestResetSaja(t *testing.T) {
+	daily := domain.KodeTemplate{Template: "LOT-{DD}{MM}{YY}-{SEQ:2}", Reset: constants.KodeResetDaily}
+
+	tests := []struct {
+		name string
+		baru domain.KodeTemplate
+		want bool
+	}{
+		{"same template and reset", daily, false},
+		{"only the reset changes", domain.KodeTemplate{Template: daily.Template, Reset: constants.KodeResetMonthly}, true},
+		{"template changes with the reset", domain.KodeTemplate{Template: "LOT-{MM}{YY}-{SEQ:3}", Reset: constants.KodeResetMonthly}, false},
+		{"only the template changes", domain.KodeTemplate{Template: "LT-{DD}{MM}{YY}-{SEQ:2}", Reset: constants.KodeResetDaily}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resetSaja(daily, tt.baru); got != tt.want {
+				t.Errorf("resetSaja = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
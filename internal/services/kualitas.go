@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"strings"
+)
+
+type KualitasService interface {
+	GetConfig(ctx context.Context, jenisID string) (*response.JenisDurianKualitasResponse, error)
+	SaveConfig(ctx context.Context, jenisID string, req requests.JenisDurianKualitasRequest) (*response.JenisDurianKualitasResponse, error)
+	ResetConfig(ctx context.Context, jenisID string) error
+}
+
+type kualitasService struct {
+	repo        repository.KualitasRepository
+	buahRawRepo repository.BuahRawRepository
+}
+
+func NewKualitasService(repo repository.KualitasRepository, buahRawRepo repository.BuahRawRepository) KualitasService {
+	return &kualitasService{
+		repo:        repo,
+		buahRawRepo: buahRawRepo,
+	}
+}
+
+func (s *kualitasService) GetConfig(ctx context.Context, jenisID string) (*response.JenisDurianKualitasResponse, error) {
+	if _, err := s.buahRawRepo.GetJenisDurianByID(ctx, jenisID); err != nil {
+		return nil, errors.NotFoundError("jenis durian tidak ditemukan")
+	}
+
+	cfg, err := s.repo.GetByJenisID(ctx, jenisID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		def := defaultKualitasConfig(jenisID)
+		return toKualitasResponse(def, true), nil
+	}
+	return toKualitasResponse(*cfg, false), nil
+}
+
+func (s *kualitasService) SaveConfig(ctx context.Context, jenisID string, req requests.JenisDurianKualitasRequest) (*response.JenisDurianKualitasResponse, error) {
+	if _, err := s.buahRawRepo.GetJenisDurianByID(ctx, jenisID); err != nil {
+		return nil, errors.NotFoundError("jenis durian tidak ditemukan")
+	}
+
+	cfg := domain.JenisDurianKualitas{
+		JenisDurianID:   jenisID,
+		KematanganOpsi:  normalizeOpsi(req.KematanganOpsi),
+		CacatOpsi:       normalizeOpsi(req.CacatOpsi),
+		MetodePanenOpsi: normalizeOpsi(req.MetodePanenOpsi),
+		SkorAromaMin:    req.SkorAromaMin,
+		SkorAromaMax:    req.SkorAromaMax,
+		AtributWajib:    uniqueStrings(req.AtributWajib),
+	}
+
+	if err := s.repo.Save(ctx, &cfg); err != nil {
+		return nil, err
+	}
+	return toKualitasResponse(cfg, false), nil
+}
+
+func (s *kualitasService) ResetConfig(ctx context.Context, jenisID string) error {
+	return s.repo.Delete(ctx, jenisID)
+}
+
+func defaultKualitasConfig(jenisID string) domain.JenisDurianKualitas {
+	return domain.JenisDurianKualitas{
+		JenisDurianID: jenisID,
+		KematanganOpsi: []string{
+			constants.KematanganMentah,
+			constants.KematanganSetengahMatang,
+			constants.KematanganMatang,
+			constants.KematanganLewatMatang,
+		},
+		CacatOpsi:       []string{constants.CacatRetak, constants.CacatLubangUlat, constants.CacatDuriRusak},
+		MetodePanenOpsi: []string{constants.MetodePanenJatuh, constants.MetodePanenPotong},
+		SkorAromaMin:    constants.SkorAromaMinDefault,
+		SkorAromaMax:    constants.SkorAromaMaxDefault,
+		AtributWajib:    []string{},
+	}
+}
+
+func toKualitasResponse(cfg domain.JenisDurianKualitas, isDefault bool) *response.JenisDurianKualitasResponse {
+	return &response.JenisDurianKualitasResponse{
+		JenisDurianID:   cfg.JenisDurianID,
+		IsDefault:       isDefault,
+		KematanganOpsi:  cfg.KematanganOpsi,
+		CacatOpsi:       cfg.CacatOpsi,
+		MetodePanenOpsi: cfg.MetodePanenOpsi,
+		SkorAromaMin:    cfg.SkorAromaMin,
+		SkorAromaMax:    cfg.SkorAromaMax,
+		AtributWajib:    cfg.AtributWajib,
+	}
+}
+
+func normalizeOpsi(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, strings.ToUpper(strings.TrimSpace(v)))
+	}
+	return uniqueStrings(result)
+}
+
+// kualitasChecker resolves the quality configuration of a jenis for services that write fruits
+type kualitasChecker struct {
+	repo repository.KualitasRepository
+}
+
+func newKualitasChecker(repo repository.KualitasRepository) *kualitasChecker {
+	return &kualitasChecker{repo: repo}
+}
+
+func (c *kualitasChecker) configs(ctx context.Context, jenisIDs []string) (map[string]domain.JenisDurianKualitas, error) {
+	jenisIDs = uniqueStrings(jenisIDs)
+	result, err := c.repo.GetByJenisIDs(ctx, jenisIDs)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil konfigurasi kualitas: %v", err)
+	}
+
+	for _, id := range jenisIDs {
+		if _, ok := result[id]; !ok {
+			result[id] = defaultKualitasConfig(id)
+		}
+	}
+	return result, nil
+}
+
+// apply validates the attributes against cfg and writes them to buah. Nil attributes keep the current value,
+// and required attributes are checked on the result so partial updates still pass.
+func (c *kualitasChecker) apply(ctx context.Context, req requests.BuahKualitasRequest, buah *domain.BuahRaw) error {
+	cfgs, err := c.configs(ctx, []string{buah.JenisDurian})
+	if err != nil {
+		return err
+	}
+	return applyKualitas(cfgs[buah.JenisDurian], req, buah)
+}
+
+func applyKualitas(cfg domain.JenisDurianKualitas, req requests.BuahKualitasRequest, buah *domain.BuahRaw) error {
+	if req.TingkatKematangan != nil {
+		value := strings.ToUpper(strings.TrimSpace(*req.TingkatKematangan))
+		if !containsString(cfg.KematanganOpsi, value) {
+			return fmt.Errorf("tingkat_kematangan harus salah satu dari: %s", strings.Join(cfg.KematanganOpsi, ", "))
+		}
+		buah.TingkatKematangan = &value
+	}
+
+	if req.Cacat != nil {
+		cacat := normalizeOpsi(req.Cacat)
+		for _, value := range cacat {
+			if !containsString(cfg.CacatOpsi, value) {
+				return fmt.Errorf("cacat %s tidak dikenal, pilihan: %s", value, strings.Join(cfg.CacatOpsi, ", "))
+			}
+		}
+		buah.Cacat = cacat
+	}
+
+	if req.SkorAroma != nil {
+		if *req.SkorAroma < cfg.SkorAromaMin || *req.SkorAroma > cfg.SkorAromaMax {
+			return fmt.Errorf("skor_aroma harus antara %d dan %d", cfg.SkorAromaMin, cfg.SkorAromaMax)
+		}
+		score := *req.SkorAroma
+		buah.SkorAroma = &score
+	}
+
+	if req.MetodePanen != nil {
+		value := strings.ToUpper(strings.TrimSpace(*req.MetodePanen))
+		if !containsString(cfg.MetodePanenOpsi, value) {
+			return fmt.Errorf("metode_panen harus salah satu dari: %s", strings.Join(cfg.MetodePanenOpsi, ", "))
+		}
+		buah.MetodePanen = &value
+	}
+
+	for _, atribut := range cfg.AtributWajib {
+		missing := false
+		switch atribut {
+		case constants.AtributTingkatKematangan:
+			missing = buah.TingkatKematangan == nil
+		case constants.AtributCacat:
+			missing = buah.Cacat == nil
+		case constants.AtributSkorAroma:
+			missing = buah.SkorAroma == nil
+		case constants.AtributMetodePanen:
+			missing = buah.MetodePanen == nil
+		}
+		if missing {
+			return fmt.Errorf("%s wajib diisi untuk jenis durian ini", atribut)
+		}
+	}
+
+	return nil
+}
+
+func toBuahKualitas(buah domain.BuahRaw) response.BuahKualitas {
+	return response.BuahKualitas{
+		TingkatKematangan: buah.TingkatKematangan,
+		Cacat:             buah.Cacat,
+		SkorAroma:         buah.SkorAroma,
+		MetodePanen:       buah.MetodePanen,
+	}
+}
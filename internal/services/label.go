@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/label"
+	std_errors "errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LabelService interface {
+	GetBuahLabel(ctx context.Context, id string) (label.Label, error)
+	GetLotLabel(ctx context.Context, id string) (label.Label, error)
+	GetSheetLabels(ctx context.Context, req requests.LabelSheetRequest) ([]label.Label, error)
+}
+
+type labelService struct {
+	buahRawRepo repository.BuahRawRepository
+	lotRepo     repository.LotRepository
+}
+
+func NewLabelService(buahRawRepo repository.BuahRawRepository, lotRepo repository.LotRepository) LabelService {
+	return &labelService{
+		buahRawRepo: buahRawRepo,
+		lotRepo:     lotRepo,
+	}
+}
+
+func (s *labelService) GetBuahLabel(ctx context.Context, id string) (label.Label, error) {
+	list, err := s.buahRawRepo.GetByIDs(ctx, []string{id})
+	if err != nil {
+		return label.Label{}, errors.InternalError("gagal mengambil data buah", err)
+	}
+	if len(list) == 0 {
+		return label.Label{}, errors.NotFoundError("buah tidak ditemukan")
+	}
+
+	return s.buildBuahLabel(list[0], list[0].Lot), nil
+}
+
+func (s *labelService) GetLotLabel(ctx context.Context, id string) (label.Label, error) {
+	lot, items, err := s.getLotWithItems(ctx, id)
+	if err != nil {
+		return label.Label{}, err
+	}
+
+	return s.buildLotLabel(lot, items), nil
+}
+
+// GetSheetLabels returns labels for the requested fruits, or for a lot followed by every fruit inside it
+func (s *labelService) GetSheetLabels(ctx context.Context, req requests.LabelSheetRequest) ([]label.Label, error) {
+	if (req.LotID == "") == (len(req.BuahRawIDs) == 0) {
+		return nil, errors.ValidationError("isi salah satu dari buah_raw_ids atau lot_id")
+	}
+
+	if req.LotID != "" {
+		lot, items, err := s.getLotWithItems(ctx, req.LotID)
+		if err != nil {
+			return nil, err
+		}
+
+		labels := make([]label.Label, 0, len(items)+1)
+		labels = append(labels, s.buildLotLabel(lot, items))
+		for _, item := range items {
+			labels = append(labels, s.buildBuahLabel(item, lot))
+		}
+		return labels, nil
+	}
+
+	list, err := s.buahRawRepo.GetByIDs(ctx, req.BuahRawIDs)
+	if err != nil {
+		return nil, errors.InternalError("gagal mengambil data buah", err)
+	}
+	if len(list) != len(uniqueStrings(req.BuahRawIDs)) {
+		return nil, errors.NotFoundError("beberapa buah tidak ditemukan")
+	}
+
+	labels := make([]label.Label, 0, len(list))
+	for _, item := range list {
+		labels = append(labels, s.buildBuahLabel(item, item.Lot))
+	}
+	return labels, nil
+}
+
+func (s *labelService) getLotWithItems(ctx context.Context, id string) (*domain.StokLot, []domain.BuahRaw, error) {
+	lot, err := s.lotRepo.GetByID(ctx, id)
+	switch {
+	case std_errors.Is(err, sql.ErrNoRows) || (err == nil && lot == nil):
+		return nil, nil, errors.NotFoundError("lot tidak ditemukan")
+	case err != nil:
+		return nil, nil, errors.InternalError("gagal mengambil lot", err)
+	}
+
+	items, err := s.buahRawRepo.GetLotDetails(ctx, id)
+	if err != nil {
+		return nil, nil, errors.InternalError("gagal mengambil isi lot", err)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].KodeBuah < items[j].KodeBuah })
+	return lot, items, nil
+}
+
+func (s *labelService) buildBuahLabel(item domain.BuahRaw, lot *domain.StokLot) label.Label {
+	grade := "-"
+	if lot != nil && lot.KondisiBuah != "" {
+		grade = lot.KondisiBuah
+	}
+
+	return label.Label{
+		Code: item.KodeBuah,
+		Lines: []string{
+			item.KodeBuah,
+			"Jenis : " + labelJenis(item.JenisDurianDetail),
+			"Grade : " + grade,
+			"Panen : " + item.TglPanen,
+			"Blok  : " + labelBlok(item.PohonPanenDetail),
+		},
+	}
+}
+
+func (s *labelService) buildLotLabel(lot *domain.StokLot, items []domain.BuahRaw) label.Label {
+	panen := "-"
+	bloks := make([]string, 0)
+	seen := make(map[string]bool)
+	totalBerat := 0.0
+
+	if len(items) > 0 {
+		first, last := items[0].TglPanen, items[0].TglPanen
+		for _, item := range items {
+			if item.TglPanen < first {
+				first = item.TglPanen
+			}
+			if item.TglPanen > last {
+				last = item.TglPanen
+			}
+
+			blok := labelBlok(item.PohonPanenDetail)
+			if !seen[blok] {
+				seen[blok] = true
+				bloks = append(bloks, blok)
+			}
+			totalBerat += item.Berat
+		}
+
+		panen = first
+		if last != first {
+			panen = first + " s/d " + last
+		}
+	}
+
+	sort.Strings(bloks)
+	blokText := "-"
+	if len(bloks) > 3 {
+		blokText = fmt.Sprintf("%s +%d", strings.Join(bloks[:3], ", "), len(bloks)-3)
+	} else if len(bloks) > 0 {
+		blokText = strings.Join(bloks, ", ")
+	}
+
+	return label.Label{
+		Code: lot.Kode,
+		Lines: []string{
+			lot.Kode,
+			"Jenis : " + labelJenis(lot.JenisDurianDetail),
+			"Grade : " + lot.KondisiBuah,
+			"Panen : " + panen,
+			"Blok  : " + blokText,
+			fmt.Sprintf("Isi   : %d buah / %.2f kg", len(items), totalBerat),
+		},
+	}
+}
+
+func labelJenis(jenis *domain.JenisDurian) string {
+	if jenis == nil {
+		return "-"
+	}
+	if jenis.Kode == "" {
+		return jenis.NamaJenis
+	}
+	return fmt.Sprintf("%s (%s)", jenis.NamaJenis, jenis.Kode)
+}
+
+func labelBlok(pohon *domain.Pohon) string {
+	if pohon == nil || pohon.Blok == nil {
+		return "-"
+	}
+	return pohon.Blok.Kode
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -14,71 +14,90 @@ import (
 )
 
 type LotService interface {
-	Create(ctx context.Context, req requests.LotCreateRequest, locationID string) (*response.LotResponse, error)
+	Create(ctx context.Context, req requests.LotCreateRequest, userID, locationID string) (*response.LotResponse, error)
 	GetList(ctx context.Context, status, jenisDurian, kondisi, locationID, scope, createdAt string) ([]response.LotResponse, error)
 	GetDetail(ctx context.Context, id string) (*response.LotDetailResponse, error)
 	AddItems(ctx context.Context, lotID string, req requests.LotAddItemsRequest, locationID string) (*response.LotAddItemsResponse, error)
+	ScanItems(ctx context.Context, lotID string, req requests.LotScanRequest, userID, locationID string) (*response.LotScanResponse, error)
 	RemoveItem(ctx context.Context, lotID string, req requests.LotRemoveItemRequest, locationID string) error
-	Finalize(ctx context.Context, lotID string, req requests.LotFinalizeRequest, locationID string) (*response.LotFinalizeResponse, error)
+	Finalize(ctx context.Context, lotID string, req requests.LotFinalizeRequest, userID, locationID string) (*response.LotFinalizeResponse, error)
+	Split(ctx context.Context, lotID string, req requests.LotSplitRequest, userID, locationID string) (*response.LotSplitResponse, error)
+	Merge(ctx context.Context, req requests.LotMergeRequest, userID, locationID string) (*response.LotMergeResponse, error)
+	GetGenealogi(ctx context.Context, lotID string) (*response.LotGenealogiResponse, error)
+	GetHistory(ctx context.Context, lotID string) ([]response.LotStatusRiwayatResponse, error)
+	Regrade(ctx context.Context, lotID string, req requests.LotRegradeRequest, userID, locationID string) (*response.LotRegradeResponse, error)
+	GetRegrade(ctx context.Context, lotID string) ([]response.LotRegradeItem, error)
+	GetLedger(ctx context.Context, lotID string) (*response.LotLedgerResponse, error)
+	GetRekonsiliasi(ctx context.Context) ([]response.LotRekonsiliasiItem, error)
+	GetAging(ctx context.Context, lokasiID, jenisDurianID, locationID string) (*response.LotAgingResponse, error)
+	GetPeringatanUmur(ctx context.Context, lokasiID, locationID string) ([]response.LotPeringatanItem, error)
+	TimbangUlang(ctx context.Context, lotID string, req requests.LotTimbangUlangRequest, userID, locationID string) (*response.LotTimbangUlangResponse, error)
+	GetTimbangUlang(ctx context.Context, lotID string) ([]response.LotTimbangUlangResponse, error)
 }
 
 type lotService struct {
-	lotRepo     repository.LotRepository
-	buahRawRepo repository.BuahRawRepository
+	lotRepo        repository.LotRepository
+	buahRawRepo    repository.BuahRawRepository
+	masterDataRepo repository.MasterDataRepository
+	pemanenRepo    repository.PemanenRepository
+	kode           *kodeGenerator
+	kualitas       *kualitasChecker
+	musim          *musimChecker
+	grade          *gradeChecker
+	draft          *lotDrafter
 }
 
-func NewLotService(lotRepo repository.LotRepository, buahRawRepo repository.BuahRawRepository) LotService {
+func NewLotService(
+	lotRepo repository.LotRepository,
+	buahRawRepo repository.BuahRawRepository,
+	kodeTemplateRepo repository.KodeTemplateRepository,
+	masterDataRepo repository.MasterDataRepository,
+	kualitasRepo repository.KualitasRepository,
+	pemanenRepo repository.PemanenRepository,
+	musimRepo repository.MusimRepository,
+	gradeRepo repository.GradeRepository,
+) LotService {
 	return &lotService{
-		lotRepo:     lotRepo,
-		buahRawRepo: buahRawRepo,
+		lotRepo:        lotRepo,
+		buahRawRepo:    buahRawRepo,
+		masterDataRepo: masterDataRepo,
+		pemanenRepo:    pemanenRepo,
+		kode:           newKodeGenerator(kodeTemplateRepo),
+		kualitas:       newKualitasChecker(kualitasRepo),
+		musim:          newMusimChecker(musimRepo),
+		grade:          newGradeChecker(gradeRepo),
+		draft:          newLotDrafter(buahRawRepo, kodeTemplateRepo, masterDataRepo, musimRepo, gradeRepo),
 	}
 }
 
-func (s *lotService) Create(ctx context.Context, req requests.LotCreateRequest, locationID string) (*response.LotResponse, error) {
+func (s *lotService) Create(ctx context.Context, req requests.LotCreateRequest, userID, locationID string) (*response.LotResponse, error) {
 	// Validation: Only Central Users can create lots
 	if locationID != "" {
 		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat membuat lot baru (grading)")
 	}
 
-	// Get jenis durian detail first to get code
-	jenis, err := s.buahRawRepo.GetJenisDurianByID(ctx, req.JenisDurianID)
+	lot, spec, err := s.draft.draft(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("jenis durian tidak ditemukan: %v", err)
-	}
-
-	dateStr := time.Now().Format("020106") // DDMMYY
-
-	kode, err := s.lotRepo.GetNextLotSequence(ctx, dateStr, jenis.Kode, req.KondisiBuah)
-	if err != nil {
-		return nil, fmt.Errorf("gagal generate kode lot: %v", err)
-	}
-
-	lot := &domain.StokLot{
-		Kode:          kode,
-		JenisDurianID: req.JenisDurianID,
-		KondisiBuah:   req.KondisiBuah,
-		Status:        constants.LotStatusDraft,
+		return nil, err
 	}
 
-	err = s.lotRepo.Create(ctx, lot)
+	err = s.lotRepo.Create(ctx, lot, spec, userID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("gagal membuat lot: %v", err)
 	}
 
-	// Attach relation manual for response
-	lot.JenisDurianDetail = &jenis
-
 	return &response.LotResponse{
 		ID:              lot.ID,
 		Kode:            lot.Kode,
 		JenisDurianID:   lot.JenisDurianID,
-		JenisDurianNama: jenis.NamaJenis,
+		JenisDurianNama: lot.JenisDurianDetail.NamaJenis,
 		KondisiBuah:     lot.KondisiBuah,
 		BeratAwal:       lot.BeratAwal,
 		QtyAwal:         lot.QtyAwal,
 		BeratSisa:       lot.BeratSisa,
 		QtySisa:         lot.QtySisa,
 		Status:          lot.Status,
+		MusimID:         lot.MusimID,
 		CreatedAt:       lot.CreatedAt,
 	}, nil
 }
@@ -109,7 +128,11 @@ func (s *lotService) GetList(ctx context.Context, status, jenisDurianID, kondisi
 			CurrentQty:      lot.CurrentQty,
 			CurrentBerat:    lot.CurrentBerat,
 			Status:          lot.Status,
+			MusimID:         lot.MusimID,
 			CreatedAt:       lot.CreatedAt,
+			HoldOleh:        lot.HoldOleh,
+			HoldPelanggan:   lot.HoldPelanggan,
+			HoldBerakhirAt:  lot.HoldBerakhirAt,
 		}
 	}
 
@@ -183,6 +206,7 @@ func (s *lotService) GetDetail(ctx context.Context, id string) (*response.LotDet
 			CurrentQty:      currentQty,
 			CurrentBerat:    lot.CurrentBerat,
 			Status:          lot.Status,
+			MusimID:         lot.MusimID,
 			CreatedAt:       lot.CreatedAt,
 		},
 		Items: items,
@@ -203,64 +227,93 @@ func (s *lotService) AddItems(ctx context.Context, lotID string, req requests.Lo
 	if lot.Status != constants.LotStatusDraft {
 		return nil, std_errors.New("hanya lot dengan status DRAFT yang bisa ditambahkan item")
 	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
 
 	pohon, err := s.lotRepo.GetPohonByKode(ctx, req.PohonKode, req.BlokID)
 	if err != nil {
 		return nil, fmt.Errorf("pohon dengan kode %s tidak ditemukan di blok yang dipilih", req.PohonKode)
 	}
 
-	prefix := s.buildLocationPrefix(pohon)
+	jenisKode := ""
+	if lot.JenisDurianDetail != nil {
+		jenisKode = lot.JenisDurianDetail.Kode
+	}
 	tglPanen := time.Now().Format("2006-01-02")
-	
-	sequence, err := s.buahRawRepo.GetNextSequenceWithLock(ctx, prefix, tglPanen)
+
+	values, companyID, ok := buahKodeValues(pohon, jenisKode, tglPanen)
+	if !ok {
+		return nil, fmt.Errorf("gagal membuat prefix lokasi: data hierarki tidak lengkap")
+	}
+
+	spec, err := s.kode.spec(ctx, constants.KodeTipeBuah, companyID, values)
 	if err != nil {
-		return nil, fmt.Errorf("gagal generate sequence buah: %v", err)
+		return nil, err
 	}
 
-	kodeBuah := fmt.Sprintf("%s-F%05d", prefix, sequence)
-	
 	buah := &domain.BuahRaw{
-		KodeBuah:    kodeBuah,
 		JenisDurian: lot.JenisDurianID,
 		PohonPanen:  &pohon.ID,
 		TglPanen:    tglPanen,
 		LotID:       &lotID,
 		BlokID:      &req.BlokID,
 		Berat:       req.Berat,
+		PemanenID:   emptyToNil(req.PemanenID),
 	}
 
-	err = s.lotRepo.AddBuah(ctx, buah)
+	if err := s.kualitas.apply(ctx, req.BuahKualitasRequest, buah); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	if buah.PemanenID != nil {
+		pemanen, err := s.pemanenRepo.GetByID(ctx, *buah.PemanenID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPemanen(pemanen, pohon); err != nil {
+			return nil, errors.ValidationError(err.Error())
+		}
+	}
+
+	if buah.MusimID, err = s.musim.forHarvest(ctx, nil, pohonEstateID(pohon), tglPanen); err != nil {
+		return nil, err
+	}
+
+	err = s.lotRepo.AddBuah(ctx, buah, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	// A lot created without an estate joins the season of its first fruit
+	if lot.MusimID == nil && buah.MusimID != nil {
+		lot.MusimID = buah.MusimID
+		if err := s.lotRepo.Update(ctx, lot); err != nil {
+			return nil, err
+		}
+	}
+
 	count, err := s.lotRepo.GetItemCount(ctx, lotID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.LotAddItemsResponse{
+	res := &response.LotAddItemsResponse{
+		BuahRawID:  buah.ID,
+		KodeBuah:   buah.KodeBuah,
 		CurrentQty: count,
-	}, nil
-}
-
-func (s *lotService) buildLocationPrefix(pohon *domain.Pohon) string {
-	if pohon == nil || pohon.Blok == nil {
-		return ""
 	}
 
-	blok := pohon.Blok
-	if blok.Divisi == nil || blok.Divisi.Estate == nil || blok.Divisi.Estate.Company == nil {
-		return ""
+	// The suggestion only informs the operator, the fruit stays in the lot it was scanned into
+	if res.SaranGrade, err = s.grade.saran(ctx, buah); err != nil {
+		return nil, err
+	}
+	if res.SaranGrade != nil {
+		sesuai := *res.SaranGrade == normalizeGrade(lot.KondisiBuah)
+		res.GradeSesuai = &sesuai
 	}
 
-	return fmt.Sprintf("%s%s%s%s%s",
-		blok.Divisi.Estate.Company.Kode,
-		blok.Divisi.Estate.Kode,
-		blok.Divisi.Kode,
-		blok.Kode,
-		pohon.Kode,
-	)
+	return res, nil
 }
 
 func (s *lotService) RemoveItem(ctx context.Context, lotID string, req requests.LotRemoveItemRequest, locationID string) error {
@@ -277,11 +330,14 @@ func (s *lotService) RemoveItem(ctx context.Context, lotID string, req requests.
 	if lot.Status != constants.LotStatusDraft {
 		return std_errors.New("hanya lot dengan status DRAFT yang bisa dikurangi item")
 	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return err
+	}
 
 	return s.lotRepo.RemoveItem(ctx, lotID, req.BuahRawID)
 }
 
-func (s *lotService) Finalize(ctx context.Context, lotID string, req requests.LotFinalizeRequest, locationID string) (*response.LotFinalizeResponse, error) {
+func (s *lotService) Finalize(ctx context.Context, lotID string, req requests.LotFinalizeRequest, userID, locationID string) (*response.LotFinalizeResponse, error) {
 	// Validation: Only Central Users can finalize lots
 	if locationID != "" {
 		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memfinalisasi lot")
@@ -295,6 +351,9 @@ func (s *lotService) Finalize(ctx context.Context, lotID string, req requests.Lo
 	if lot.Status != constants.LotStatusDraft {
 		return nil, std_errors.New("hanya lot dengan status DRAFT yang bisa difinalisasi")
 	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
 
 	count, err := s.lotRepo.GetItemCount(ctx, lotID)
 	if err != nil {
@@ -314,11 +373,10 @@ func (s *lotService) Finalize(ctx context.Context, lotID string, req requests.Lo
 	lot.QtyAwal = count
 	lot.BeratSisa = totalWeight
 	lot.QtySisa = count
-	lot.Status = constants.LotStatusReady
 
-	err = s.lotRepo.Update(ctx, lot)
+	err = s.lotRepo.Finalize(ctx, lot, userID)
 	if err != nil {
-		return nil, err
+		return nil, repoError(err)
 	}
 
 	return &response.LotFinalizeResponse{
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"time"
+)
+
+// lotDrafter prepares new DRAFT lots and the spec of their code, for callers that insert them
+// in their own transaction
+type lotDrafter struct {
+	buahRawRepo    repository.BuahRawRepository
+	masterDataRepo repository.MasterDataRepository
+	kode           *kodeGenerator
+	musim          *musimChecker
+	grade          *gradeChecker
+}
+
+func newLotDrafter(
+	buahRawRepo repository.BuahRawRepository,
+	kodeTemplateRepo repository.KodeTemplateRepository,
+	masterDataRepo repository.MasterDataRepository,
+	musimRepo repository.MusimRepository,
+	gradeRepo repository.GradeRepository,
+) *lotDrafter {
+	return &lotDrafter{
+		buahRawRepo:    buahRawRepo,
+		masterDataRepo: masterDataRepo,
+		kode:           newKodeGenerator(kodeTemplateRepo),
+		musim:          newMusimChecker(musimRepo),
+		grade:          newGradeChecker(gradeRepo),
+	}
+}
+
+// draft returns the DRAFT lot req asks for, with its jenis attached
+func (d *lotDrafter) draft(ctx context.Context, req requests.LotCreateRequest) (*domain.StokLot, repository.SequenceSpec, error) {
+	// Get jenis durian detail first to get code
+	jenis, err := d.buahRawRepo.GetJenisDurianByID(ctx, req.JenisDurianID)
+	if err != nil {
+		return nil, repository.SequenceSpec{}, fmt.Errorf("jenis durian tidak ditemukan: %v", err)
+	}
+
+	kondisi, err := d.grade.resolve(ctx, req.KondisiBuah)
+	if err != nil {
+		return nil, repository.SequenceSpec{}, err
+	}
+
+	values := kodeValues{
+		Jenis: jenis.Kode,
+		Grade: kondisi,
+		Date:  time.Now(),
+	}
+
+	// Estate is optional, it selects the company template and fills {COMPANY}/{ESTATE}
+	companyID := ""
+	var musimID *string
+	if req.EstateID != "" {
+		estate, err := d.masterDataRepo.GetEstateByID(ctx, req.EstateID)
+		if err != nil {
+			return nil, repository.SequenceSpec{}, err
+		}
+		if estate == nil {
+			return nil, repository.SequenceSpec{}, errors.ValidationError("estate tidak ditemukan")
+		}
+		companyID = estate.CompanyID
+		values.Estate = estate.Kode
+		if estate.Company != nil {
+			values.Company = estate.Company.Kode
+		}
+		if musimID, err = d.musim.aktif(ctx, estate.ID); err != nil {
+			return nil, repository.SequenceSpec{}, err
+		}
+	}
+
+	spec, err := d.kode.spec(ctx, constants.KodeTipeLot, companyID, values)
+	if err != nil {
+		return nil, repository.SequenceSpec{}, errors.ValidationError(err.Error())
+	}
+
+	return &domain.StokLot{
+		JenisDurianID:     req.JenisDurianID,
+		KondisiBuah:       kondisi,
+		Status:            constants.LotStatusDraft,
+		MusimID:           musimID,
+		JenisDurianDetail: &jenis,
+	}, spec, nil
+}
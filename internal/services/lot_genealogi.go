@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"time"
+)
+
+// Split moves the chosen fruits of a READY lot into a new lot of the same jenis and grade
+func (s *lotService) Split(ctx context.Context, lotID string, req requests.LotSplitRequest, userID, locationID string) (*response.LotSplitResponse, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memodifikasi lot")
+	}
+
+	buahIDs := uniqueStrings(req.BuahRawIDs)
+	parent, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if parent.Status != constants.LotStatusReady {
+		return nil, errors.ValidationError("hanya lot dengan status READY yang bisa dipecah")
+	}
+	if err := s.musim.ensureOpen(ctx, parent.MusimID); err != nil {
+		return nil, err
+	}
+
+	buah, err := s.lotRepo.GetBuahRawByID(ctx, buahIDs[0])
+	if err != nil {
+		return nil, errors.ValidationError("buah tidak ditemukan")
+	}
+	spec, err := lotTurunanSpec(ctx, s.kode, parent, buah)
+	if err != nil {
+		return nil, err
+	}
+
+	child := newTurunanLot(parent)
+	if _, err := s.lotRepo.Split(ctx, parent.ID, child, spec, buahIDs, userID); err != nil {
+		return nil, repoError(err)
+	}
+
+	asal, err := s.lotRepo.GetByID(ctx, parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	baru, err := s.lotRepo.GetByID(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.LotSplitResponse{
+		Asal: toLotResponse(asal),
+		Baru: toLotResponse(baru),
+	}, nil
+}
+
+// Merge empties several READY lots of the same jenis, grade, location and season into a new lot
+func (s *lotService) Merge(ctx context.Context, req requests.LotMergeRequest, userID, locationID string) (*response.LotMergeResponse, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memodifikasi lot")
+	}
+
+	lotIDs := uniqueStrings(req.LotIDs)
+	if len(lotIDs) < 2 {
+		return nil, errors.ValidationError("minimal 2 lot berbeda untuk digabung")
+	}
+
+	first, err := s.lotRepo.GetByID(ctx, lotIDs[0])
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if err := s.musim.ensureOpen(ctx, first.MusimID); err != nil {
+		return nil, err
+	}
+
+	var buah *domain.BuahRaw
+	if items, err := s.buahRawRepo.GetLotDetails(ctx, first.ID); err == nil && len(items) > 0 {
+		buah = &items[0]
+	}
+	spec, err := lotTurunanSpec(ctx, s.kode, first, buah)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository checks every lot against child under lock
+	child := newTurunanLot(first)
+	if _, err := s.lotRepo.Merge(ctx, lotIDs, child, spec, userID); err != nil {
+		return nil, repoError(err)
+	}
+
+	res := &response.LotMergeResponse{Asal: make([]response.LotResponse, 0, len(lotIDs))}
+	for _, id := range lotIDs {
+		lot, err := s.lotRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		res.Asal = append(res.Asal, toLotResponse(lot))
+	}
+
+	baru, err := s.lotRepo.GetByID(ctx, child.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.Baru = toLotResponse(baru)
+	return res, nil
+}
+
+func (s *lotService) GetGenealogi(ctx context.Context, lotID string) (*response.LotGenealogiResponse, error) {
+	if _, err := s.lotRepo.GetByID(ctx, lotID); err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+
+	list, err := s.lotRepo.GetGenealogi(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.LotGenealogiResponse{
+		LotID:   lotID,
+		Asal:    []response.LotGenealogiItem{},
+		Turunan: []response.LotGenealogiItem{},
+	}
+	for _, g := range list {
+		item := response.LotGenealogiItem{
+			ID:        g.ID,
+			Tipe:      g.Tipe,
+			Qty:       g.Qty,
+			Berat:     g.Berat,
+			CreatedAt: g.CreatedAt,
+		}
+		if g.ChildLotID == lotID {
+			item.LotID = g.ParentLotID
+			if g.ParentLot != nil {
+				item.LotKode = g.ParentLot.Kode
+			}
+			res.Asal = append(res.Asal, item)
+		} else {
+			item.LotID = g.ChildLotID
+			if g.ChildLot != nil {
+				item.LotKode = g.ChildLot.Kode
+			}
+			res.Turunan = append(res.Turunan, item)
+		}
+	}
+	return res, nil
+}
+
+func (s *lotService) GetHistory(ctx context.Context, lotID string) ([]response.LotStatusRiwayatResponse, error) {
+	if _, err := s.lotRepo.GetByID(ctx, lotID); err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+
+	list, err := s.lotRepo.GetStatusRiwayat(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.LotStatusRiwayatResponse, 0, len(list))
+	for _, h := range list {
+		item := response.LotStatusRiwayatResponse{
+			ID:         h.ID,
+			StatusDari: h.StatusDari,
+			StatusKe:   h.StatusKe,
+			Alasan:     h.Alasan,
+			CreatedBy:  h.CreatedBy,
+			CreatedAt:  h.CreatedAt,
+		}
+		if h.Creator != nil {
+			item.Email = h.Creator.Email
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// newTurunanLot prepares a READY lot that inherits jenis, grade, location and season from lot
+func newTurunanLot(lot *domain.StokLot) *domain.StokLot {
+	return &domain.StokLot{
+		JenisDurianID: lot.JenisDurianID,
+		KondisiBuah:   lot.KondisiBuah,
+		Status:        constants.LotStatusReady,
+		PosisiID:      lot.PosisiID,
+		ArrivedAt:     lot.ArrivedAt,
+		FinalizedAt:   lot.FinalizedAt,
+		MusimID:       lot.MusimID,
+	}
+}
+
+// lotTurunanSpec builds the lot code of a lot derived from lot. The estate and company come
+// from one of its fruits, since a lot does not record where it was graded.
+func lotTurunanSpec(ctx context.Context, kode *kodeGenerator, lot *domain.StokLot, buah *domain.BuahRaw) (repository.SequenceSpec, error) {
+	values := kodeValues{
+		Grade: lot.KondisiBuah,
+		Date:  time.Now(),
+	}
+	if lot.JenisDurianDetail != nil {
+		values.Jenis = lot.JenisDurianDetail.Kode
+	}
+
+	companyID := ""
+	if buah != nil && buah.PohonPanenDetail != nil && buah.PohonPanenDetail.Blok != nil &&
+		buah.PohonPanenDetail.Blok.Divisi != nil && buah.PohonPanenDetail.Blok.Divisi.Estate != nil {
+		estate := buah.PohonPanenDetail.Blok.Divisi.Estate
+		companyID = estate.CompanyID
+		values.Estate = estate.Kode
+		if estate.Company != nil {
+			values.Company = estate.Company.Kode
+		}
+	}
+
+	spec, err := kode.spec(ctx, constants.KodeTipeLot, companyID, values)
+	if err != nil {
+		return spec, errors.ValidationError(err.Error())
+	}
+	return spec, nil
+}
+
+func toLotResponse(lot *domain.StokLot) response.LotResponse {
+	namaJenis := ""
+	if lot.JenisDurianDetail != nil {
+		namaJenis = lot.JenisDurianDetail.NamaJenis
+	}
+
+	res := response.LotResponse{
+		ID:              lot.ID,
+		Kode:            lot.Kode,
+		JenisDurianID:   lot.JenisDurianID,
+		JenisDurianNama: namaJenis,
+		KondisiBuah:     lot.KondisiBuah,
+		BeratAwal:       lot.BeratAwal,
+		QtyAwal:         lot.QtyAwal,
+		BeratSisa:       lot.BeratSisa,
+		QtySisa:         lot.QtySisa,
+		CurrentQty:      lot.CurrentQty,
+		CurrentBerat:    lot.CurrentBerat,
+		Status:          lot.Status,
+		MusimID:         lot.MusimID,
+		CreatedAt:       lot.CreatedAt,
+	}
+	if lotMenua(lot) {
+		umur := hitungUmurLot(lot, time.Now())
+		res.MulaiSimpanAt = umur.MulaiAt
+		res.KedaluwarsaAt = umur.KedaluwarsaAt
+		res.StatusUmur = umur.Status
+		if umur.MulaiAt != nil {
+			res.UmurJam = &umur.UmurJam
+		}
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/dto/response"
+	"durich-be/pkg/errors"
+	"math"
+)
+
+// GetLedger returns the stock movements of a lot and whether they add up to its balance
+func (s *lotService) GetLedger(ctx context.Context, lotID string) (*response.LotLedgerResponse, error) {
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+
+	list, err := s.lotRepo.GetMutasi(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.LotLedgerResponse{
+		LotID:     lot.ID,
+		Kode:      lot.Kode,
+		QtySisa:   lot.QtySisa,
+		BeratSisa: lot.BeratSisa,
+		Mutasi:    make([]response.LotMutasiResponse, 0, len(list)),
+	}
+	for _, m := range list {
+		item := response.LotMutasiResponse{
+			ID:            m.ID,
+			Tipe:          m.Tipe,
+			LokasiID:      m.LokasiID,
+			DeltaQty:      m.DeltaQty,
+			DeltaBerat:    m.DeltaBerat,
+			QtySaldo:      m.QtySaldo,
+			BeratSaldo:    m.BeratSaldo,
+			ReferensiTipe: m.ReferensiTipe,
+			ReferensiID:   m.ReferensiID,
+			ReferensiKode: m.ReferensiKode,
+			CreatedBy:     m.CreatedBy,
+			CreatedAt:     m.CreatedAt,
+		}
+		if m.Lokasi != nil {
+			item.LokasiNama = m.Lokasi.Nama
+		}
+		if m.Creator != nil {
+			item.Email = m.Creator.Email
+		}
+		res.Mutasi = append(res.Mutasi, item)
+		res.LedgerQty += m.DeltaQty
+		res.LedgerBerat += m.DeltaBerat
+	}
+	res.LedgerBerat = math.Round(res.LedgerBerat*100) / 100
+	res.Cocok = res.LedgerQty == lot.QtySisa && math.Abs(res.LedgerBerat-lot.BeratSisa) < 0.01
+
+	return res, nil
+}
+
+// GetRekonsiliasi lists every lot whose ledger does not add up to its balance, empty when all match
+func (s *lotService) GetRekonsiliasi(ctx context.Context) ([]response.LotRekonsiliasiItem, error) {
+	list, err := s.lotRepo.GetRekonsiliasi(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.LotRekonsiliasiItem, 0, len(list))
+	for _, l := range list {
+		res = append(res, response.LotRekonsiliasiItem{
+			LotID:       l.LotID,
+			Kode:        l.Kode,
+			Status:      l.Status,
+			QtySisa:     l.QtySisa,
+			BeratSisa:   l.BeratSisa,
+			LedgerQty:   l.LedgerQty,
+			LedgerBerat: l.LedgerBerat,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"strings"
+)
+
+// Regrade moves a whole READY lot, or some of its fruits, to another grade. The fruits go to
+// a new lot with a code for the new grade unless an existing lot of that grade is chosen.
+func (s *lotService) Regrade(ctx context.Context, lotID string, req requests.LotRegradeRequest, userID, locationID string) (*response.LotRegradeResponse, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memodifikasi lot")
+	}
+
+	alasan := strings.TrimSpace(req.Alasan)
+	if alasan == "" {
+		return nil, errors.ValidationError("alasan wajib diisi")
+	}
+	kondisi, err := s.grade.resolve(ctx, req.KondisiBuah)
+	if err != nil {
+		return nil, err
+	}
+	if req.TargetLotID != "" && len(req.BuahRawIDs) == 0 {
+		return nil, errors.ValidationError("target_lot_id hanya bisa dipakai bersama buah_raw_ids")
+	}
+
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
+
+	buahIDs := uniqueStrings(req.BuahRawIDs)
+	target := &domain.StokLot{ID: req.TargetLotID}
+	var spec repository.SequenceSpec
+	if target.ID == "" {
+		var buah *domain.BuahRaw
+		if len(buahIDs) > 0 {
+			buah, _ = s.lotRepo.GetBuahRawByID(ctx, buahIDs[0])
+		} else if items, err := s.buahRawRepo.GetLotDetails(ctx, lot.ID); err == nil && len(items) > 0 {
+			buah = &items[0]
+		}
+
+		target = newTurunanLot(lot)
+		target.KondisiBuah = kondisi
+		target.JenisDurianDetail = lot.JenisDurianDetail
+		if spec, err = lotTurunanSpec(ctx, s.kode, target, buah); err != nil {
+			return nil, err
+		}
+	}
+
+	regrade := &domain.LotRegrade{
+		KondisiKe: kondisi,
+		Alasan:    alasan,
+		CreatedBy: &userID,
+	}
+	gen, err := s.lotRepo.Regrade(ctx, lot.ID, target, spec, buahIDs, regrade)
+	if err != nil {
+		return nil, repoError(err)
+	}
+
+	asal, err := s.lotRepo.GetByID(ctx, lot.ID)
+	if err != nil {
+		return nil, err
+	}
+	tujuan, err := s.lotRepo.GetByID(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.LotRegradeResponse{
+		Asal:        toLotResponse(asal),
+		Tujuan:      toLotResponse(tujuan),
+		KondisiDari: regrade.KondisiDari,
+		KondisiKe:   regrade.KondisiKe,
+		Qty:         gen.Qty,
+		Berat:       gen.Berat,
+		Alasan:      regrade.Alasan,
+	}, nil
+}
+
+func (s *lotService) GetRegrade(ctx context.Context, lotID string) ([]response.LotRegradeItem, error) {
+	if _, err := s.lotRepo.GetByID(ctx, lotID); err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+
+	list, err := s.lotRepo.GetRegrade(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.LotRegradeItem, 0, len(list))
+	for _, rg := range list {
+		item := response.LotRegradeItem{
+			ID:          rg.ID,
+			KondisiDari: rg.KondisiDari,
+			KondisiKe:   rg.KondisiKe,
+			Alasan:      rg.Alasan,
+			CreatedBy:   rg.CreatedBy,
+			CreatedAt:   rg.CreatedAt,
+		}
+		if g := rg.Genealogi; g != nil {
+			item.LotAsalID = g.ParentLotID
+			item.LotTujuanID = g.ChildLotID
+			item.Qty = g.Qty
+			item.Berat = g.Berat
+			if g.ParentLot != nil {
+				item.LotAsalKode = g.ParentLot.Kode
+			}
+			if g.ChildLot != nil {
+				item.LotTujuanKode = g.ChildLot.Kode
+			}
+		}
+		if rg.Creator != nil {
+			item.Email = rg.Creator.Email
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
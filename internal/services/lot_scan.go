@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/pkg/errors"
+	"strings"
+)
+
+// ScanItems puts fruits already recorded at harvest into a DRAFT lot by their kode_buah.
+// Each code is checked on its own, so one bad scan does not block the rest of the batch.
+func (s *lotService) ScanItems(ctx context.Context, lotID string, req requests.LotScanRequest, userID, locationID string) (*response.LotScanResponse, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memodifikasi lot")
+	}
+
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if lot.Status != constants.LotStatusDraft {
+		return nil, errors.ValidationError("hanya lot dengan status DRAFT yang bisa ditambahkan item")
+	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
+
+	kodes := make([]string, 0, len(req.KodeBuah))
+	for _, k := range req.KodeBuah {
+		if k = strings.TrimSpace(k); k != "" {
+			kodes = append(kodes, k)
+		}
+	}
+	kodes = uniqueStrings(kodes)
+
+	found, err := s.lotRepo.GetBuahRawByKodes(ctx, kodes)
+	if err != nil {
+		return nil, err
+	}
+	byKode := make(map[string]*domain.BuahRaw, len(found))
+	for i := range found {
+		byKode[found[i].KodeBuah] = &found[i]
+	}
+
+	res := &response.LotScanResponse{
+		Diterima: []response.LotScanItem{},
+		Ditolak:  []response.LotScanDitolak{},
+	}
+	tolak := func(kode, alasan string) {
+		res.Ditolak = append(res.Ditolak, response.LotScanDitolak{KodeBuah: kode, Alasan: alasan})
+	}
+
+	musimID := lot.MusimID
+	var accepted []*domain.BuahRaw
+	var ids []string
+	for _, kode := range kodes {
+		buah, ok := byKode[kode]
+		switch {
+		case !ok:
+			tolak(kode, "kode buah tidak ditemukan")
+			continue
+		case buah.LotID != nil && *buah.LotID == lot.ID:
+			tolak(kode, "buah sudah ada di lot ini")
+			continue
+		case buah.LotID != nil:
+			lain := *buah.LotID
+			if buah.Lot != nil {
+				lain = buah.Lot.Kode
+			}
+			tolak(kode, "buah sudah masuk lot "+lain)
+			continue
+		case buah.JenisDurian != lot.JenisDurianID:
+			tolak(kode, "jenis durian buah tidak sesuai dengan lot")
+			continue
+		}
+
+		if buah.MusimID != nil {
+			if musimID == nil {
+				if err := s.musim.ensureOpen(ctx, buah.MusimID); err != nil {
+					tolak(kode, err.Error())
+					continue
+				}
+				musimID = buah.MusimID
+			} else if *musimID != *buah.MusimID {
+				tolak(kode, "buah berasal dari musim panen yang berbeda dengan lot")
+				continue
+			}
+		}
+
+		accepted = append(accepted, buah)
+		ids = append(ids, buah.ID)
+	}
+
+	if len(ids) > 0 {
+		assigned, err := s.lotRepo.AssignBuah(ctx, lot.ID, ids, musimID, userID)
+		if err != nil {
+			return nil, errors.ValidationError(err.Error())
+		}
+
+		for _, buah := range accepted {
+			// Another lot took the fruit between the check and the update
+			if !containsString(assigned, buah.ID) {
+				tolak(buah.KodeBuah, "buah sudah masuk lot lain")
+				continue
+			}
+
+			item := response.LotScanItem{
+				BuahRawID: buah.ID,
+				KodeBuah:  buah.KodeBuah,
+				Berat:     buah.Berat,
+			}
+			if item.SaranGrade, err = s.grade.saran(ctx, buah); err != nil {
+				return nil, err
+			}
+			if item.SaranGrade != nil {
+				sesuai := *item.SaranGrade == normalizeGrade(lot.KondisiBuah)
+				item.GradeSesuai = &sesuai
+			}
+			res.Diterima = append(res.Diterima, item)
+		}
+	}
+
+	if res.CurrentQty, err = s.lotRepo.GetItemCount(ctx, lot.ID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
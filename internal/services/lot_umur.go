@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/pkg/errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// kelompokUmur are the aging report buckets by hours stored, the last one has no upper bound
+var kelompokUmur = []struct {
+	Label  string
+	MaxJam float64
+}{
+	{"0-24 jam", 24},
+	{"24-48 jam", 48},
+	{"48-72 jam", 72},
+	{"> 72 jam", 0},
+}
+
+// umurLot is the age of a lot against the shelf life of its jenis. KedaluwarsaAt stays nil
+// for a jenis without shelf life.
+type umurLot struct {
+	MulaiAt       *time.Time
+	UmurJam       float64
+	KedaluwarsaAt *time.Time
+	SisaJam       float64
+	Status        string
+}
+
+// hitungUmurLot measures from the arrival at the lot's current location, or from finalize for a
+// lot that never moved. The lot needs its JenisDurianDetail.
+func hitungUmurLot(lot *domain.StokLot, now time.Time) umurLot {
+	var u umurLot
+	u.MulaiAt = lot.ArrivedAt
+	if u.MulaiAt == nil {
+		u.MulaiAt = lot.FinalizedAt
+	}
+	if u.MulaiAt == nil {
+		return u
+	}
+	u.UmurJam = roundPlaces(now.Sub(*u.MulaiAt).Hours(), 1)
+
+	jenis := lot.JenisDurianDetail
+	if jenis == nil || jenis.UmurSimpanJam == nil {
+		return u
+	}
+	kedaluwarsa := u.MulaiAt.Add(time.Duration(*jenis.UmurSimpanJam) * time.Hour)
+	u.KedaluwarsaAt = &kedaluwarsa
+	u.SisaJam = roundPlaces(kedaluwarsa.Sub(now).Hours(), 1)
+
+	batas := constants.LotPeringatanDefaultJam
+	if jenis.BatasPeringatanJam != nil {
+		batas = *jenis.BatasPeringatanJam
+	}
+	switch {
+	case !now.Before(kedaluwarsa):
+		u.Status = constants.LotUmurKedaluwarsa
+	case kedaluwarsa.Sub(now) <= time.Duration(batas)*time.Hour:
+		u.Status = constants.LotUmurMendekati
+	default:
+		u.Status = constants.LotUmurSegar
+	}
+	return u
+}
+
+// lotMenua reports whether a lot still holds fruits that age, so its age is worth showing
+func lotMenua(lot *domain.StokLot) bool {
+	switch lot.Status {
+	case constants.LotStatusReady, constants.LotStatusBooked, constants.LotStatusShipped:
+		return true
+	}
+	return false
+}
+
+// GetAging groups the stored lots of every location by how long they have been stored. A
+// branch user only sees its own location.
+func (s *lotService) GetAging(ctx context.Context, lokasiID, jenisDurianID, locationID string) (*response.LotAgingResponse, error) {
+	if locationID != "" {
+		lokasiID = locationID
+	}
+
+	lots, err := s.lotRepo.GetTersimpan(ctx, lokasiID, jenisDurianID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := &response.LotAgingResponse{Lokasi: make([]response.LotAgingLokasi, 0)}
+	index := make(map[string]int)
+	for i := range lots {
+		lot := &lots[i]
+		key := ""
+		if lot.PosisiID != nil {
+			key = *lot.PosisiID
+		}
+
+		j, ok := index[key]
+		if !ok {
+			j = len(res.Lokasi)
+			index[key] = j
+			lokasi := response.LotAgingLokasi{
+				LokasiID: lot.PosisiID,
+				Kelompok: make([]response.LotAgingKelompok, len(kelompokUmur)),
+			}
+			if lot.Posisi != nil {
+				lokasi.LokasiNama = lot.Posisi.Nama
+			}
+			for k, kel := range kelompokUmur {
+				lokasi.Kelompok[k].Label = kel.Label
+			}
+			res.Lokasi = append(res.Lokasi, lokasi)
+		}
+		lokasi := &res.Lokasi[j]
+
+		umur := hitungUmurLot(lot, now)
+		k := len(kelompokUmur) - 1
+		for n, kel := range kelompokUmur[:k] {
+			if umur.UmurJam < kel.MaxJam {
+				k = n
+				break
+			}
+		}
+		lokasi.Kelompok[k].LotCount++
+		lokasi.Kelompok[k].Qty += lot.QtySisa
+		lokasi.Kelompok[k].Berat += lot.BeratSisa
+
+		lokasi.LotCount++
+		lokasi.Qty += lot.QtySisa
+		lokasi.Berat += lot.BeratSisa
+		switch umur.Status {
+		case constants.LotUmurMendekati:
+			lokasi.Mendekati++
+		case constants.LotUmurKedaluwarsa:
+			lokasi.Kedaluwarsa++
+		}
+	}
+	return res, nil
+}
+
+// GetPeringatanUmur lists the stored lots that are about to expire or have expired, the first
+// to expire first
+func (s *lotService) GetPeringatanUmur(ctx context.Context, lokasiID, locationID string) ([]response.LotPeringatanItem, error) {
+	if locationID != "" {
+		lokasiID = locationID
+	}
+
+	lots, err := s.lotRepo.GetTersimpan(ctx, lokasiID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make([]response.LotPeringatanItem, 0)
+	for i := range lots {
+		lot := &lots[i]
+		umur := hitungUmurLot(lot, now)
+		if umur.Status != constants.LotUmurMendekati && umur.Status != constants.LotUmurKedaluwarsa {
+			continue
+		}
+
+		item := response.LotPeringatanItem{
+			LotID:         lot.ID,
+			Kode:          lot.Kode,
+			LokasiID:      lot.PosisiID,
+			KondisiBuah:   lot.KondisiBuah,
+			Status:        lot.Status,
+			QtySisa:       lot.QtySisa,
+			BeratSisa:     lot.BeratSisa,
+			MulaiSimpanAt: umur.MulaiAt,
+			KedaluwarsaAt: umur.KedaluwarsaAt,
+			SisaJam:       umur.SisaJam,
+			StatusUmur:    umur.Status,
+		}
+		if lot.Posisi != nil {
+			item.LokasiNama = lot.Posisi.Nama
+		}
+		if lot.JenisDurianDetail != nil {
+			item.JenisDurianNama = lot.JenisDurianDetail.NamaJenis
+		}
+		res = append(res, item)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].KedaluwarsaAt.Before(*res[j].KedaluwarsaAt)
+	})
+	return res, nil
+}
+
+// TimbangUlang records a new weight of a READY lot, the shrinkage is measured against the lot's
+// starting weight
+func (s *lotService) TimbangUlang(ctx context.Context, lotID string, req requests.LotTimbangUlangRequest, userID, locationID string) (*response.LotTimbangUlangResponse, error) {
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if locationID != "" && (lot.PosisiID == nil || *lot.PosisiID != locationID) {
+		return nil, errors.ValidationError("akses ditolak: lot tidak berada di lokasi anda")
+	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
+
+	tu := &domain.LotTimbangUlang{
+		LotID: lot.ID,
+		Berat: math.Round(req.Berat*100) / 100,
+	}
+	if catatan := strings.TrimSpace(req.Catatan); catatan != "" {
+		tu.Catatan = &catatan
+	}
+	if err := s.lotRepo.TimbangUlang(ctx, tu, userID); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	res := toLotTimbangUlangResponse(tu)
+	return &res, nil
+}
+
+func (s *lotService) GetTimbangUlang(ctx context.Context, lotID string) ([]response.LotTimbangUlangResponse, error) {
+	if _, err := s.lotRepo.GetByID(ctx, lotID); err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+
+	list, err := s.lotRepo.GetTimbangUlang(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.LotTimbangUlangResponse, 0, len(list))
+	for i := range list {
+		res = append(res, toLotTimbangUlangResponse(&list[i]))
+	}
+	return res, nil
+}
+
+func toLotTimbangUlangResponse(tu *domain.LotTimbangUlang) response.LotTimbangUlangResponse {
+	res := response.LotTimbangUlangResponse{
+		ID:           tu.ID,
+		LotID:        tu.LotID,
+		LokasiID:     tu.LokasiID,
+		Qty:          tu.Qty,
+		BeratAcuan:   tu.BeratAcuan,
+		BeratSebelum: tu.BeratSebelum,
+		Berat:        tu.Berat,
+		Susut:        tu.Susut,
+		Catatan:      tu.Catatan,
+		CreatedBy:    tu.CreatedBy,
+		CreatedAt:    tu.CreatedAt,
+	}
+	if tu.BeratAcuan > 0 {
+		res.SusutPersen = roundPlaces(tu.Susut*100/tu.BeratAcuan, 2)
+	}
+	if tu.Creator != nil {
+		res.Email = tu.Creator.Email
+	}
+	return res
+}
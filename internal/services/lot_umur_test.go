@@ -0,0 +1,128 @@
+package services
+
+import (
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestHitungUmurLot(t *testing.T) {
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.Local)
+	jamLalu := func(jam float64) *time.Time {
+		at := now.Add(-time.Duration(jam * float64(time.Hour)))
+		return &at
+	}
+	jenis := &domain.JenisDurian{UmurSimpanJam: intPtr(72)}
+
+	tests := []struct {
+		name        string
+		lot         domain.StokLot
+		wantMulai   *time.Time
+		wantUmur    float64
+		wantSisa    float64
+		wantStatus  string
+		wantNoBatas bool
+	}{
+		{
+			name:        "draft lot has no age",
+			lot:         domain.StokLot{JenisDurianDetail: jenis},
+			wantNoBatas: true,
+		},
+		{
+			name:       "measured from finalize when never moved",
+			lot:        domain.StokLot{FinalizedAt: jamLalu(10), JenisDurianDetail: jenis},
+			wantMulai:  jamLalu(10),
+			wantUmur:   10,
+			wantSisa:   62,
+			wantStatus: constants.LotUmurSegar,
+		},
+		{
+			name:       "arrival restarts the age",
+			lot:        domain.StokLot{FinalizedAt: jamLalu(50), ArrivedAt: jamLalu(2.25), JenisDurianDetail: jenis},
+			wantMulai:  jamLalu(2.25),
+			wantUmur:   2.3,
+			wantSisa:   69.8,
+			wantStatus: constants.LotUmurSegar,
+		},
+		{
+			name:       "inside the default warning window",
+			lot:        domain.StokLot{FinalizedAt: jamLalu(48), JenisDurianDetail: jenis},
+			wantMulai:  jamLalu(48),
+			wantUmur:   48,
+			wantSisa:   24,
+			wantStatus: constants.LotUmurMendekati,
+		},
+		{
+			name: "jenis sets its own warning window",
+			lot: domain.StokLot{FinalizedAt: jamLalu(30), JenisDurianDetail: &domain.JenisDurian{
+				UmurSimpanJam: intPtr(72), BatasPeringatanJam: intPtr(48),
+			}},
+			wantMulai:  jamLalu(30),
+			wantUmur:   30,
+			wantSisa:   42,
+			wantStatus: constants.LotUmurMendekati,
+		},
+		{
+			name:       "expires at the shelf life",
+			lot:        domain.StokLot{FinalizedAt: jamLalu(72), JenisDurianDetail: jenis},
+			wantMulai:  jamLalu(72),
+			wantUmur:   72,
+			wantSisa:   0,
+			wantStatus: constants.LotUmurKedaluwarsa,
+		},
+		{
+			name:       "past the shelf life",
+			lot:        domain.StokLot{FinalizedAt: jamLalu(80), JenisDurianDetail: jenis},
+			wantMulai:  jamLalu(80),
+			wantUmur:   80,
+			wantSisa:   -8,
+			wantStatus: constants.LotUmurKedaluwarsa,
+		},
+		{
+			name:        "jenis without shelf life only ages",
+			lot:         domain.StokLot{FinalizedAt: jamLalu(100), JenisDurianDetail: &domain.JenisDurian{}},
+			wantMulai:   jamLalu(100),
+			wantUmur:    100,
+			wantNoBatas: true,
+		},
+		{
+			name:        "jenis not loaded",
+			lot:         domain.StokLot{FinalizedAt: jamLalu(5)},
+			wantMulai:   jamLalu(5),
+			wantUmur:    5,
+			wantNoBatas: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := hitungUmurLot(&tt.lot, now)
+
+			switch {
+			case tt.wantMulai == nil && u.MulaiAt != nil:
+				t.Errorf("MulaiAt = %v, want nil", u.MulaiAt)
+			case tt.wantMulai != nil && (u.MulaiAt == nil || !u.MulaiAt.Equal(*tt.wantMulai)):
+				t.Errorf("MulaiAt = %v, want %v", u.MulaiAt, tt.wantMulai)
+			}
+			if u.UmurJam != tt.wantUmur {
+				t.Errorf("UmurJam = %v, want %v", u.UmurJam, tt.wantUmur)
+			}
+			if tt.wantNoBatas {
+				if u.KedaluwarsaAt != nil || u.Status != "" {
+					t.Errorf("KedaluwarsaAt = %v, Status = %q, want neither", u.KedaluwarsaAt, u.Status)
+				}
+				return
+			}
+			if u.KedaluwarsaAt == nil {
+				t.Fatal("KedaluwarsaAt = nil")
+			}
+			if u.SisaJam != tt.wantSisa {
+				t.Errorf("SisaJam = %v, want %v", u.SisaJam, tt.wantSisa)
+			}
+			if u.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", u.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -479,19 +479,23 @@ func (s *masterDataService) DeleteBlok(ctx context.Context, id string) error {
 
 func (s *masterDataService) CreateJenisDurian(ctx context.Context, req requests.JenisDurianCreateRequest) (*response.JenisDurianResponse, error) {
 	jenis := &domain.JenisDurian{
-		Kode:      req.Kode,
-		NamaJenis: req.NamaJenis,
+		Kode:               req.Kode,
+		NamaJenis:          req.NamaJenis,
+		UmurSimpanJam:      req.UmurSimpanJam,
+		BatasPeringatanJam: req.BatasPeringatanJam,
 	}
 	err := s.repo.CreateJenisDurian(ctx, jenis)
 	if err != nil {
 		return nil, err
 	}
 	return &response.JenisDurianResponse{
-		ID:        jenis.ID,
-		Kode:      jenis.Kode,
-		NamaJenis: jenis.NamaJenis,
-		CreatedAt: jenis.CreatedAt,
-		UpdatedAt: jenis.UpdatedAt,
+		ID:                 jenis.ID,
+		Kode:               jenis.Kode,
+		NamaJenis:          jenis.NamaJenis,
+		UmurSimpanJam:      jenis.UmurSimpanJam,
+		BatasPeringatanJam: jenis.BatasPeringatanJam,
+		CreatedAt:          jenis.CreatedAt,
+		UpdatedAt:          jenis.UpdatedAt,
 	}, nil
 }
 
@@ -503,11 +507,13 @@ func (s *masterDataService) GetJenisDurianList(ctx context.Context) ([]response.
 	result := make([]response.JenisDurianResponse, 0, len(jenisList))
 	for _, j := range jenisList {
 		result = append(result, response.JenisDurianResponse{
-			ID:        j.ID,
-			Kode:      j.Kode,
-			NamaJenis: j.NamaJenis,
-			CreatedAt: j.CreatedAt,
-			UpdatedAt: j.UpdatedAt,
+			ID:                 j.ID,
+			Kode:               j.Kode,
+			NamaJenis:          j.NamaJenis,
+			UmurSimpanJam:      j.UmurSimpanJam,
+			BatasPeringatanJam: j.BatasPeringatanJam,
+			CreatedAt:          j.CreatedAt,
+			UpdatedAt:          j.UpdatedAt,
 		})
 	}
 	return result, nil
@@ -522,11 +528,13 @@ func (s *masterDataService) GetJenisDurianByID(ctx context.Context, id string) (
 		return nil, errors.New("jenis durian not found")
 	}
 	return &response.JenisDurianResponse{
-		ID:        jenis.ID,
-		Kode:      jenis.Kode,
-		NamaJenis: jenis.NamaJenis,
-		CreatedAt: jenis.CreatedAt,
-		UpdatedAt: jenis.UpdatedAt,
+		ID:                 jenis.ID,
+		Kode:               jenis.Kode,
+		NamaJenis:          jenis.NamaJenis,
+		UmurSimpanJam:      jenis.UmurSimpanJam,
+		BatasPeringatanJam: jenis.BatasPeringatanJam,
+		CreatedAt:          jenis.CreatedAt,
+		UpdatedAt:          jenis.UpdatedAt,
 	}, nil
 }
 
@@ -539,16 +547,20 @@ func (s *masterDataService) UpdateJenisDurian(ctx context.Context, id string, re
 		return nil, errors.New("jenis durian not found")
 	}
 	existing.NamaJenis = req.NamaJenis
+	existing.UmurSimpanJam = req.UmurSimpanJam
+	existing.BatasPeringatanJam = req.BatasPeringatanJam
 	err = s.repo.UpdateJenisDurian(ctx, id, existing)
 	if err != nil {
 		return nil, err
 	}
 	return &response.JenisDurianResponse{
-		ID:        existing.ID,
-		Kode:      existing.Kode,
-		NamaJenis: existing.NamaJenis,
-		CreatedAt: existing.CreatedAt,
-		UpdatedAt: existing.UpdatedAt,
+		ID:                 existing.ID,
+		Kode:               existing.Kode,
+		NamaJenis:          existing.NamaJenis,
+		UmurSimpanJam:      existing.UmurSimpanJam,
+		BatasPeringatanJam: existing.BatasPeringatanJam,
+		CreatedAt:          existing.CreatedAt,
+		UpdatedAt:          existing.UpdatedAt,
 	}, nil
 }
 
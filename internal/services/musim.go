@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/database"
+	"durich-be/pkg/errors"
+	"fmt"
+	"time"
+)
+
+type MusimService interface {
+	Create(ctx context.Context, req requests.MusimPanenCreateRequest) (*response.MusimPanenResponse, error)
+	GetList(ctx context.Context, estateID, status string) ([]response.MusimPanenResponse, error)
+	GetByID(ctx context.Context, id string) (*response.MusimPanenResponse, error)
+	Update(ctx context.Context, id string, req requests.MusimPanenUpdateRequest) (*response.MusimPanenResponse, error)
+	Delete(ctx context.Context, id string) error
+	Close(ctx context.Context, id, userID string) (*response.MusimPanenResponse, error)
+	Reopen(ctx context.Context, id string) (*response.MusimPanenResponse, error)
+	GetRingkasan(ctx context.Context, id string) (*domain.MusimRingkasan, error)
+}
+
+type musimService struct {
+	repo           repository.MusimRepository
+	masterDataRepo repository.MasterDataRepository
+}
+
+func NewMusimService(repo repository.MusimRepository, masterDataRepo repository.MasterDataRepository) MusimService {
+	return &musimService{
+		repo:           repo,
+		masterDataRepo: masterDataRepo,
+	}
+}
+
+func (s *musimService) Create(ctx context.Context, req requests.MusimPanenCreateRequest) (*response.MusimPanenResponse, error) {
+	estate, err := s.masterDataRepo.GetEstateByID(ctx, req.EstateID)
+	if err != nil {
+		return nil, err
+	}
+	if estate == nil {
+		return nil, errors.ValidationError("estate tidak ditemukan")
+	}
+
+	aktif, err := s.repo.GetAktifByEstate(ctx, req.EstateID)
+	if err != nil {
+		return nil, err
+	}
+	if aktif != nil {
+		return nil, errors.ValidationError(fmt.Sprintf("musim %s masih aktif, tutup terlebih dahulu", aktif.Kode))
+	}
+
+	musim := &domain.MusimPanen{
+		Kode:     req.Kode,
+		Nama:     req.Nama,
+		EstateID: req.EstateID,
+		Status:   constants.MusimStatusAktif,
+	}
+	if err := s.applyTanggal(ctx, musim, req.TanggalMulai, req.TanggalSelesai); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, musim); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("kode musim sudah digunakan atau estate sudah memiliki musim aktif")
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, musim.ID)
+}
+
+func (s *musimService) GetList(ctx context.Context, estateID, status string) ([]response.MusimPanenResponse, error) {
+	list, err := s.repo.GetList(ctx, estateID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.MusimPanenResponse, 0, len(list))
+	for _, m := range list {
+		result = append(result, toMusimResponse(m))
+	}
+	return result, nil
+}
+
+func (s *musimService) GetByID(ctx context.Context, id string) (*response.MusimPanenResponse, error) {
+	musim, err := s.getMusim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toMusimResponse(*musim)
+	return &resp, nil
+}
+
+func (s *musimService) Update(ctx context.Context, id string, req requests.MusimPanenUpdateRequest) (*response.MusimPanenResponse, error) {
+	musim, err := s.getMusim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if musim.Status == constants.MusimStatusDitutup {
+		return nil, errors.ValidationError("musim yang sudah ditutup tidak bisa diubah")
+	}
+
+	musim.Nama = req.Nama
+	if err := s.applyTanggal(ctx, musim, req.TanggalMulai, req.TanggalSelesai); err != nil {
+		return nil, err
+	}
+
+	musim.Estate = nil
+	if err := s.repo.Update(ctx, musim); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *musimService) Delete(ctx context.Context, id string) error {
+	if _, err := s.getMusim(ctx, id); err != nil {
+		return err
+	}
+
+	used, err := s.repo.IsUsed(ctx, id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.ValidationError("musim sudah memiliki data panen, lot atau penjualan dan tidak bisa dihapus")
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Close freezes the season summary and locks every harvest, lot and sale tagged with it
+func (s *musimService) Close(ctx context.Context, id, userID string) (*response.MusimPanenResponse, error) {
+	musim, err := s.getMusim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if musim.Status == constants.MusimStatusDitutup {
+		return nil, errors.ValidationError("musim sudah ditutup")
+	}
+
+	ringkasan, err := s.hitungRingkasan(ctx, id)
+	if err != nil {
+		return nil, errors.InternalError("gagal menghitung ringkasan musim", err)
+	}
+
+	now := time.Now()
+	if musim.TanggalSelesai == nil {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if today.Before(musim.TanggalMulai) {
+			today = musim.TanggalMulai
+		}
+		musim.TanggalSelesai = &today
+	}
+	musim.Status = constants.MusimStatusDitutup
+	musim.Ringkasan = ringkasan
+	musim.DitutupAt = &now
+	musim.DitutupOleh = &userID
+
+	musim.Estate = nil
+	if err := s.repo.Update(ctx, musim); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Reopen unlocks a closed season for corrections, as long as the estate has not started a new one
+func (s *musimService) Reopen(ctx context.Context, id string) (*response.MusimPanenResponse, error) {
+	musim, err := s.getMusim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if musim.Status != constants.MusimStatusDitutup {
+		return nil, errors.ValidationError("musim belum ditutup")
+	}
+
+	aktif, err := s.repo.GetAktifByEstate(ctx, musim.EstateID)
+	if err != nil {
+		return nil, err
+	}
+	if aktif != nil {
+		return nil, errors.ValidationError(fmt.Sprintf("musim %s sedang aktif di estate ini", aktif.Kode))
+	}
+
+	musim.Status = constants.MusimStatusAktif
+	musim.Ringkasan = nil
+	musim.DitutupAt = nil
+	musim.DitutupOleh = nil
+
+	musim.Estate = nil
+	if err := s.repo.Update(ctx, musim); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+// GetRingkasan returns the frozen summary of a closed season, or live figures for an open one
+func (s *musimService) GetRingkasan(ctx context.Context, id string) (*domain.MusimRingkasan, error) {
+	musim, err := s.getMusim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if musim.Status == constants.MusimStatusDitutup && musim.Ringkasan != nil {
+		return musim.Ringkasan, nil
+	}
+	return s.hitungRingkasan(ctx, id)
+}
+
+func (s *musimService) hitungRingkasan(ctx context.Context, id string) (*domain.MusimRingkasan, error) {
+	ringkasan, err := s.repo.GetRingkasan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ringkasan.PanenBerat = roundPlaces(ringkasan.PanenBerat, 2)
+	ringkasan.GradingBerat = roundPlaces(ringkasan.GradingBerat, 2)
+	ringkasan.GradingYield = roundPlaces(ringkasan.GradingYield, 2)
+	ringkasan.DikirimBerat = roundPlaces(ringkasan.DikirimBerat, 2)
+	ringkasan.TerjualBerat = roundPlaces(ringkasan.TerjualBerat, 2)
+	ringkasan.Pendapatan = roundPlaces(ringkasan.Pendapatan, 2)
+	ringkasan.RataHargaPerKg = roundPlaces(ringkasan.RataHargaPerKg, 2)
+	for i := range ringkasan.Grade {
+		ringkasan.Grade[i].Berat = roundPlaces(ringkasan.Grade[i].Berat, 2)
+	}
+	return ringkasan, nil
+}
+
+func (s *musimService) getMusim(ctx context.Context, id string) (*domain.MusimPanen, error) {
+	musim, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if musim == nil {
+		return nil, errors.NotFoundError("musim panen tidak ditemukan")
+	}
+	return musim, nil
+}
+
+// applyTanggal sets the season dates, which must not overlap another season of the same estate
+func (s *musimService) applyTanggal(ctx context.Context, musim *domain.MusimPanen, mulai string, selesai *string) error {
+	start, err := time.Parse("2006-01-02", mulai)
+	if err != nil {
+		return errors.ValidationError("format tanggal_mulai harus YYYY-MM-DD")
+	}
+
+	var end *time.Time
+	if selesai != nil && *selesai != "" {
+		t, err := time.Parse("2006-01-02", *selesai)
+		if err != nil {
+			return errors.ValidationError("format tanggal_selesai harus YYYY-MM-DD")
+		}
+		if t.Before(start) {
+			return errors.ValidationError("tanggal_selesai tidak boleh sebelum tanggal_mulai")
+		}
+		end = &t
+	}
+
+	others, err := s.repo.GetByEstate(ctx, musim.EstateID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == musim.ID {
+			continue
+		}
+		if musimOverlap(start, end, other.TanggalMulai, other.TanggalSelesai) {
+			return errors.ValidationError(fmt.Sprintf("periode bertabrakan dengan musim %s", other.Kode))
+		}
+	}
+
+	musim.TanggalMulai = start
+	musim.TanggalSelesai = end
+	return nil
+}
+
+// musimOverlap compares two inclusive date ranges, a nil end is open ended
+func musimOverlap(startA time.Time, endA *time.Time, startB time.Time, endB *time.Time) bool {
+	if endA != nil && endA.Before(startB) {
+		return false
+	}
+	if endB != nil && endB.Before(startA) {
+		return false
+	}
+	return true
+}
+
+func toMusimResponse(m domain.MusimPanen) response.MusimPanenResponse {
+	resp := response.MusimPanenResponse{
+		ID:           m.ID,
+		Kode:         m.Kode,
+		Nama:         m.Nama,
+		EstateID:     m.EstateID,
+		TanggalMulai: m.TanggalMulai.Format("2006-01-02"),
+		Status:       m.Status,
+		DitutupAt:    m.DitutupAt,
+		DitutupOleh:  m.DitutupOleh,
+		Ringkasan:    m.Ringkasan,
+		CreatedAt:    m.CreatedAt,
+	}
+	if m.TanggalSelesai != nil {
+		selesai := m.TanggalSelesai.Format("2006-01-02")
+		resp.TanggalSelesai = &selesai
+	}
+	if m.Estate != nil {
+		resp.EstateNama = m.Estate.Nama
+	}
+	return resp
+}
+
+// musimChecker tags new records with their season and refuses changes inside a closed one
+type musimChecker struct {
+	repo repository.MusimRepository
+}
+
+func newMusimChecker(repo repository.MusimRepository) *musimChecker {
+	return &musimChecker{repo: repo}
+}
+
+// forHarvest returns the season of the estate covering the harvest date, nil when there is none.
+// cache may be nil, bulk callers pass a map to load each estate's seasons once.
+func (c *musimChecker) forHarvest(ctx context.Context, cache map[string][]domain.MusimPanen, estateID, tglPanen string) (*string, error) {
+	if estateID == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", tglPanen)
+	if err != nil {
+		return nil, errors.ValidationError("format tgl_panen harus YYYY-MM-DD")
+	}
+
+	list, ok := cache[estateID]
+	if !ok {
+		if list, err = c.repo.GetByEstate(ctx, estateID); err != nil {
+			return nil, err
+		}
+		if cache != nil {
+			cache[estateID] = list
+		}
+	}
+
+	for i := range list {
+		if !list[i].Covers(date) {
+			continue
+		}
+		if list[i].Status == constants.MusimStatusDitutup {
+			return nil, errors.ValidationError(fmt.Sprintf("musim panen %s sudah ditutup", list[i].Kode))
+		}
+		return &list[i].ID, nil
+	}
+	return nil, nil
+}
+
+// aktif returns the open season of the estate, nil when there is none
+func (c *musimChecker) aktif(ctx context.Context, estateID string) (*string, error) {
+	musim, err := c.repo.GetAktifByEstate(ctx, estateID)
+	if err != nil || musim == nil {
+		return nil, err
+	}
+	return &musim.ID, nil
+}
+
+// ensureOpen rejects changes to records of a closed season
+func (c *musimChecker) ensureOpen(ctx context.Context, musimID *string) error {
+	if musimID == nil {
+		return nil
+	}
+
+	musim, err := c.repo.GetByID(ctx, *musimID)
+	if err != nil {
+		return err
+	}
+	if musim != nil && musim.Status == constants.MusimStatusDitutup {
+		return errors.ValidationError(fmt.Sprintf("musim panen %s sudah ditutup, data tidak bisa diubah", musim.Kode))
+	}
+	return nil
+}
+
+// pohonEstateID walks a pohon loaded with its hierarchy up to the estate
+func pohonEstateID(pohon *domain.Pohon) string {
+	if pohon == nil || pohon.Blok == nil || pohon.Blok.Divisi == nil {
+		return ""
+	}
+	return pohon.Blok.Divisi.EstateID
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/database"
+	"durich-be/pkg/errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PemanenService interface {
+	Create(ctx context.Context, req requests.PemanenCreateRequest) (*response.PemanenResponse, error)
+	GetList(ctx context.Context, estateID, divisiID string, aktif *bool) ([]response.PemanenResponse, error)
+	GetByID(ctx context.Context, id string) (*response.PemanenResponse, error)
+	Update(ctx context.Context, id string, req requests.PemanenUpdateRequest) (*response.PemanenResponse, error)
+	Delete(ctx context.Context, id string) error
+
+	CreateTarif(ctx context.Context, req requests.TarifPanenRequest) (*response.TarifPanenResponse, error)
+	GetTarifList(ctx context.Context, jenisDurianID string) ([]response.TarifPanenResponse, error)
+	UpdateTarif(ctx context.Context, id string, req requests.TarifPanenRequest) (*response.TarifPanenResponse, error)
+	DeleteTarif(ctx context.Context, id string) error
+
+	GetUpahReport(ctx context.Context, startDate, endDate, estateID, pemanenID string) (*response.UpahPanenResponse, error)
+}
+
+type pemanenService struct {
+	repo           repository.PemanenRepository
+	masterDataRepo repository.MasterDataRepository
+	buahRawRepo    repository.BuahRawRepository
+}
+
+func NewPemanenService(repo repository.PemanenRepository, masterDataRepo repository.MasterDataRepository, buahRawRepo repository.BuahRawRepository) PemanenService {
+	return &pemanenService{
+		repo:           repo,
+		masterDataRepo: masterDataRepo,
+		buahRawRepo:    buahRawRepo,
+	}
+}
+
+func (s *pemanenService) Create(ctx context.Context, req requests.PemanenCreateRequest) (*response.PemanenResponse, error) {
+	if err := s.validateLokasi(ctx, req.EstateID, req.DivisiID); err != nil {
+		return nil, err
+	}
+
+	pemanen := &domain.Pemanen{
+		Kode:     req.Kode,
+		Nama:     req.Nama,
+		EstateID: req.EstateID,
+		DivisiID: emptyToNil(req.DivisiID),
+		Aktif:    true,
+	}
+
+	if err := s.repo.Create(ctx, pemanen); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("kode pemanen sudah digunakan")
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, pemanen.ID)
+}
+
+func (s *pemanenService) GetList(ctx context.Context, estateID, divisiID string, aktif *bool) ([]response.PemanenResponse, error) {
+	list, err := s.repo.GetList(ctx, estateID, divisiID, aktif)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.PemanenResponse, 0, len(list))
+	for _, p := range list {
+		result = append(result, toPemanenResponse(p))
+	}
+	return result, nil
+}
+
+func (s *pemanenService) GetByID(ctx context.Context, id string) (*response.PemanenResponse, error) {
+	pemanen, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pemanen == nil {
+		return nil, errors.NotFoundError("pemanen tidak ditemukan")
+	}
+
+	resp := toPemanenResponse(*pemanen)
+	return &resp, nil
+}
+
+func (s *pemanenService) Update(ctx context.Context, id string, req requests.PemanenUpdateRequest) (*response.PemanenResponse, error) {
+	pemanen, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pemanen == nil {
+		return nil, errors.NotFoundError("pemanen tidak ditemukan")
+	}
+
+	if err := s.validateLokasi(ctx, req.EstateID, req.DivisiID); err != nil {
+		return nil, err
+	}
+
+	pemanen.Nama = req.Nama
+	pemanen.EstateID = req.EstateID
+	pemanen.DivisiID = emptyToNil(req.DivisiID)
+	if req.Aktif != nil {
+		pemanen.Aktif = *req.Aktif
+	}
+
+	if err := s.repo.Update(ctx, pemanen); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *pemanenService) Delete(ctx context.Context, id string) error {
+	pemanen, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pemanen == nil {
+		return errors.NotFoundError("pemanen tidak ditemukan")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *pemanenService) validateLokasi(ctx context.Context, estateID string, divisiID *string) error {
+	estate, err := s.masterDataRepo.GetEstateByID(ctx, estateID)
+	if err != nil {
+		return err
+	}
+	if estate == nil {
+		return errors.ValidationError("estate tidak ditemukan")
+	}
+
+	if divisiID == nil || *divisiID == "" {
+		return nil
+	}
+
+	divisi, err := s.masterDataRepo.GetDivisiByID(ctx, *divisiID)
+	if err != nil {
+		return err
+	}
+	if divisi == nil {
+		return errors.ValidationError("divisi tidak ditemukan")
+	}
+	if divisi.EstateID != estateID {
+		return errors.ValidationError("divisi tidak berada di estate yang dipilih")
+	}
+	return nil
+}
+
+func (s *pemanenService) CreateTarif(ctx context.Context, req requests.TarifPanenRequest) (*response.TarifPanenResponse, error) {
+	tarif := &domain.TarifPanen{}
+	if err := s.fillTarif(ctx, tarif, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateTarif(ctx, tarif); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("tarif untuk jenis, grade dan tanggal berlaku tersebut sudah ada")
+		}
+		return nil, err
+	}
+
+	return s.getTarif(ctx, tarif.ID)
+}
+
+func (s *pemanenService) GetTarifList(ctx context.Context, jenisDurianID string) ([]response.TarifPanenResponse, error) {
+	list, err := s.repo.GetTarifList(ctx, jenisDurianID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.TarifPanenResponse, 0, len(list))
+	for _, t := range list {
+		result = append(result, toTarifPanenResponse(t))
+	}
+	return result, nil
+}
+
+func (s *pemanenService) UpdateTarif(ctx context.Context, id string, req requests.TarifPanenRequest) (*response.TarifPanenResponse, error) {
+	tarif, err := s.repo.GetTarifByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tarif == nil {
+		return nil, errors.NotFoundError("tarif panen tidak ditemukan")
+	}
+
+	if err := s.fillTarif(ctx, tarif, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTarif(ctx, tarif); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("tarif untuk jenis, grade dan tanggal berlaku tersebut sudah ada")
+		}
+		return nil, err
+	}
+
+	return s.getTarif(ctx, id)
+}
+
+func (s *pemanenService) DeleteTarif(ctx context.Context, id string) error {
+	tarif, err := s.repo.GetTarifByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tarif == nil {
+		return errors.NotFoundError("tarif panen tidak ditemukan")
+	}
+	return s.repo.DeleteTarif(ctx, id)
+}
+
+func (s *pemanenService) fillTarif(ctx context.Context, tarif *domain.TarifPanen, req requests.TarifPanenRequest) error {
+	if _, err := s.buahRawRepo.GetJenisDurianByID(ctx, req.JenisDurianID); err != nil {
+		return errors.ValidationError("jenis durian tidak ditemukan")
+	}
+	if req.TarifPerBuah == 0 && req.TarifPerKg == 0 {
+		return errors.ValidationError("isi tarif_per_buah atau tarif_per_kg")
+	}
+
+	berlaku, err := time.ParseInLocation("2006-01-02", req.BerlakuMulai, time.Local)
+	if err != nil {
+		return errors.ValidationError("format berlaku_mulai harus YYYY-MM-DD")
+	}
+
+	tarif.JenisDurianID = req.JenisDurianID
+	tarif.Grade = strings.ToUpper(strings.TrimSpace(req.Grade))
+	tarif.TarifPerBuah = req.TarifPerBuah
+	tarif.TarifPerKg = req.TarifPerKg
+	tarif.BerlakuMulai = berlaku
+	return nil
+}
+
+func (s *pemanenService) getTarif(ctx context.Context, id string) (*response.TarifPanenResponse, error) {
+	tarif, err := s.repo.GetTarifByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tarif == nil {
+		return nil, errors.NotFoundError("tarif panen tidak ditemukan")
+	}
+
+	resp := toTarifPanenResponse(*tarif)
+	return &resp, nil
+}
+
+// GetUpahReport prices every harvested fruit with the rate valid on its harvest date.
+// Fruits without a matching rate are still listed, with zero pay, so they can be chased up.
+func (s *pemanenService) GetUpahReport(ctx context.Context, startDate, endDate, estateID, pemanenID string) (*response.UpahPanenResponse, error) {
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return nil, errors.ValidationError("format tanggal_mulai harus YYYY-MM-DD")
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return nil, errors.ValidationError("format tanggal_selesai harus YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, errors.ValidationError("tanggal_selesai tidak boleh sebelum tanggal_mulai")
+	}
+
+	rows, err := s.repo.GetPanenSummary(ctx, startDate, endDate, estateID, pemanenID)
+	if err != nil {
+		return nil, err
+	}
+
+	tarifList, err := s.repo.GetTarifList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var pemanenIDs, jenisIDs []string
+	for _, row := range rows {
+		pemanenIDs = append(pemanenIDs, row.PemanenID)
+		jenisIDs = append(jenisIDs, row.JenisDurianID)
+	}
+
+	pemanenMap := make(map[string]domain.Pemanen)
+	jenisMap := make(map[string]domain.JenisDurian)
+	if len(rows) > 0 {
+		if pemanenMap, err = s.repo.GetByIDs(ctx, uniqueStrings(pemanenIDs)); err != nil {
+			return nil, err
+		}
+		if jenisMap, err = s.buahRawRepo.GetJenisDurianByIDs(ctx, uniqueStrings(jenisIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &response.UpahPanenResponse{
+		TanggalMulai:   startDate,
+		TanggalSelesai: endDate,
+		Pemanen:        []response.UpahPanenPemanen{},
+	}
+
+	type lineKey struct{ pemanen, jenis, grade, tarif string }
+	lines := make(map[lineKey]*response.UpahPanenLine)
+	var order []lineKey
+
+	for _, row := range rows {
+		tglPanen, _ := time.ParseInLocation("2006-01-02", row.TglPanen, time.Local)
+		tarif := findTarifPanen(tarifList, row.JenisDurianID, row.Grade, tglPanen)
+
+		key := lineKey{pemanen: row.PemanenID, jenis: row.JenisDurianID, grade: row.Grade}
+		if tarif != nil {
+			key.tarif = tarif.ID
+		}
+
+		line, ok := lines[key]
+		if !ok {
+			line = &response.UpahPanenLine{
+				JenisDurianID:   row.JenisDurianID,
+				JenisDurianNama: jenisMap[row.JenisDurianID].NamaJenis,
+				Grade:           row.Grade,
+			}
+			if tarif != nil {
+				tarifID := tarif.ID
+				line.TarifID = &tarifID
+				line.TarifPerBuah = tarif.TarifPerBuah
+				line.TarifPerKg = tarif.TarifPerKg
+			}
+			lines[key] = line
+			order = append(order, key)
+		}
+
+		line.Qty += row.Qty
+		line.Berat += row.Berat
+		if tarif == nil {
+			result.QtyTanpaTarif += row.Qty
+		}
+	}
+
+	byPemanen := make(map[string]*response.UpahPanenPemanen)
+	for _, key := range order {
+		line := lines[key]
+		line.Berat = roundRupiah(line.Berat)
+		line.Upah = roundRupiah(float64(line.Qty)*line.TarifPerBuah + line.Berat*line.TarifPerKg)
+
+		entry, ok := byPemanen[key.pemanen]
+		if !ok {
+			p := pemanenMap[key.pemanen]
+			entry = &response.UpahPanenPemanen{PemanenID: key.pemanen, Kode: p.Kode, Nama: p.Nama}
+			byPemanen[key.pemanen] = entry
+		}
+
+		entry.Lines = append(entry.Lines, *line)
+		entry.TotalQty += line.Qty
+		entry.TotalBerat = roundRupiah(entry.TotalBerat + line.Berat)
+		entry.TotalUpah = roundRupiah(entry.TotalUpah + line.Upah)
+	}
+
+	for _, entry := range byPemanen {
+		result.Pemanen = append(result.Pemanen, *entry)
+		result.TotalQty += entry.TotalQty
+		result.TotalBerat = roundRupiah(result.TotalBerat + entry.TotalBerat)
+		result.TotalUpah = roundRupiah(result.TotalUpah + entry.TotalUpah)
+	}
+	sort.Slice(result.Pemanen, func(i, j int) bool { return result.Pemanen[i].Nama < result.Pemanen[j].Nama })
+
+	return result, nil
+}
+
+// findTarifPanen picks the newest rate in effect on date, preferring a grade specific rate over the catch-all
+func findTarifPanen(list []domain.TarifPanen, jenisID, grade string, date time.Time) *domain.TarifPanen {
+	var exact, fallback *domain.TarifPanen
+	for i := range list {
+		t := &list[i]
+		if t.JenisDurianID != jenisID || t.BerlakuMulai.After(date) {
+			continue
+		}
+		switch t.Grade {
+		case grade:
+			if exact == nil || t.BerlakuMulai.After(exact.BerlakuMulai) {
+				exact = t
+			}
+		case "":
+			if fallback == nil || t.BerlakuMulai.After(fallback.BerlakuMulai) {
+				fallback = t
+			}
+		}
+	}
+	if exact != nil {
+		return exact
+	}
+	return fallback
+}
+
+func roundRupiah(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func emptyToNil(v *string) *string {
+	if v == nil || *v == "" {
+		return nil
+	}
+	return v
+}
+
+func toPemanenResponse(p domain.Pemanen) response.PemanenResponse {
+	resp := response.PemanenResponse{
+		ID:        p.ID,
+		Kode:      p.Kode,
+		Nama:      p.Nama,
+		EstateID:  p.EstateID,
+		DivisiID:  p.DivisiID,
+		Aktif:     p.Aktif,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+	if p.Estate != nil {
+		resp.EstateNama = p.Estate.Nama
+	}
+	if p.Divisi != nil {
+		resp.DivisiNama = p.Divisi.Nama
+	}
+	return resp
+}
+
+func toTarifPanenResponse(t domain.TarifPanen) response.TarifPanenResponse {
+	resp := response.TarifPanenResponse{
+		ID:            t.ID,
+		JenisDurianID: t.JenisDurianID,
+		Grade:         t.Grade,
+		TarifPerBuah:  t.TarifPerBuah,
+		TarifPerKg:    t.TarifPerKg,
+		BerlakuMulai:  t.BerlakuMulai.Format("2006-01-02"),
+		CreatedAt:     t.CreatedAt,
+	}
+	if t.JenisDurian != nil {
+		resp.JenisDurianNama = t.JenisDurian.NamaJenis
+	}
+	return resp
+}
+
+// checkPemanen verifies a harvester can be credited with fruit from the given pohon
+func checkPemanen(p *domain.Pemanen, pohon *domain.Pohon) error {
+	if p == nil {
+		return fmt.Errorf("pemanen tidak ditemukan")
+	}
+	if !p.Aktif {
+		return fmt.Errorf("pemanen %s tidak aktif", p.Kode)
+	}
+	if pohon != nil && pohon.Blok != nil && pohon.Blok.Divisi != nil && pohon.Blok.Divisi.EstateID != p.EstateID {
+		return fmt.Errorf("pemanen %s tidak terdaftar di estate pohon %s", p.Kode, pohon.Kode)
+	}
+	return nil
+}
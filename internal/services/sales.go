@@ -11,22 +11,26 @@ import (
 )
 
 type SalesService interface {
-	Create(ctx context.Context, req requests.SalesCreateRequest) (*response.SalesResponse, error)
+	Create(ctx context.Context, req requests.SalesCreateRequest, userID string) (*response.SalesResponse, error)
 	GetList(ctx context.Context, startDate, endDate, tipeJual, locationID string) ([]response.SalesResponse, error)
 	GetByID(ctx context.Context, id string) (*response.SalesDetailResponse, error)
 	Update(ctx context.Context, id string, req requests.SalesUpdateRequest) error
-	Delete(ctx context.Context, id string, locationID string, userRole string) error
+	Delete(ctx context.Context, id, userID, locationID, userRole string) error
 }
 
 type salesService struct {
-	repo repository.SalesRepository
+	repo  repository.SalesRepository
+	musim *musimChecker
 }
 
-func NewSalesService(repo repository.SalesRepository) SalesService {
-	return &salesService{repo: repo}
+func NewSalesService(repo repository.SalesRepository, musimRepo repository.MusimRepository) SalesService {
+	return &salesService{
+		repo:  repo,
+		musim: newMusimChecker(musimRepo),
+	}
 }
 
-func (s *salesService) Create(ctx context.Context, req requests.SalesCreateRequest) (*response.SalesResponse, error) {
+func (s *salesService) Create(ctx context.Context, req requests.SalesCreateRequest, userID string) (*response.SalesResponse, error) {
 
 	shipment, err := s.repo.GetPengirimanByID(ctx, req.PengirimanID)
 	if err != nil {
@@ -44,9 +48,17 @@ func (s *salesService) Create(ctx context.Context, req requests.SalesCreateReque
 		return nil, errors.ValidationError("invoice already exists for this shipment")
 	}
 
+	// The sale is booked to the season of the lots it ships
 	totalBerat := 0.0
+	var musimID *string
 	for _, d := range shipment.Details {
 		totalBerat += d.BeratAmbil
+		if musimID == nil && d.Lot != nil {
+			musimID = d.Lot.MusimID
+		}
+	}
+	if err := s.musim.ensureOpen(ctx, musimID); err != nil {
+		return nil, err
 	}
 
 	sales := &domain.Penjualan{
@@ -54,10 +66,11 @@ func (s *salesService) Create(ctx context.Context, req requests.SalesCreateReque
 		BeratTerjual: totalBerat,
 		HargaTotal:   req.HargaTotal,
 		TipeJual:     req.TipeJual,
+		MusimID:      musimID,
 	}
 
-	if err := s.repo.Create(ctx, sales); err != nil {
-		return nil, err
+	if err := s.repo.Create(ctx, sales, userID); err != nil {
+		return nil, repoError(err)
 	}
 
 	resp := response.NewSalesResponse(sales)
@@ -128,6 +141,9 @@ func (s *salesService) Update(ctx context.Context, id string, req requests.Sales
 	if err != nil {
 		return err
 	}
+	if err := s.musim.ensureOpen(ctx, sales.MusimID); err != nil {
+		return err
+	}
 
 	if req.HargaTotal > 0 {
 		sales.HargaTotal = req.HargaTotal
@@ -139,7 +155,7 @@ func (s *salesService) Update(ctx context.Context, id string, req requests.Sales
 	return s.repo.Update(ctx, sales)
 }
 
-func (s *salesService) Delete(ctx context.Context, id string, locationID string, userRole string) error {
+func (s *salesService) Delete(ctx context.Context, id, userID, locationID, userRole string) error {
 	// Validation:
 	// 1. Central Users (LocationID == "") -> Allow All
 	// 2. Branch Admin (LocationID != "" AND Role == "admin") -> Allow
@@ -149,5 +165,13 @@ func (s *salesService) Delete(ctx context.Context, id string, locationID string,
 		return errors.ValidationError("akses ditolak: hanya admin cabang atau pusat yang dapat menghapus data penjualan")
 	}
 
-	return s.repo.Delete(ctx, id)
+	sales, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.musim.ensureOpen(ctx, sales.MusimID); err != nil {
+		return err
+	}
+
+	return repoError(s.repo.Delete(ctx, id, userID))
 }
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SesiGradingService interface {
+	Open(ctx context.Context, req requests.SesiGradingOpenRequest, userID, locationID string) (*response.SesiGradingResponse, error)
+	GetList(ctx context.Context, status, jenisDurianID string) ([]response.SesiGradingResponse, error)
+	GetByID(ctx context.Context, id string) (*response.SesiGradingResponse, error)
+	Scan(ctx context.Context, id string, req requests.SesiGradingScanRequest, userID, locationID string) (*response.SesiGradingScanResponse, error)
+	Close(ctx context.Context, id, userID, locationID string) (*response.SesiGradingLaporan, error)
+	GetLaporan(ctx context.Context, id string) (*response.SesiGradingLaporan, error)
+}
+
+type sesiGradingService struct {
+	repo       repository.SesiGradingRepository
+	gradeRepo  repository.GradeRepository
+	lotService LotService
+	musim      *musimChecker
+	grade      *gradeChecker
+	draft      *lotDrafter
+}
+
+func NewSesiGradingService(
+	repo repository.SesiGradingRepository,
+	gradeRepo repository.GradeRepository,
+	musimRepo repository.MusimRepository,
+	buahRawRepo repository.BuahRawRepository,
+	kodeTemplateRepo repository.KodeTemplateRepository,
+	masterDataRepo repository.MasterDataRepository,
+	lotService LotService,
+) SesiGradingService {
+	return &sesiGradingService{
+		repo:       repo,
+		gradeRepo:  gradeRepo,
+		lotService: lotService,
+		musim:      newMusimChecker(musimRepo),
+		grade:      newGradeChecker(gradeRepo),
+		draft:      newLotDrafter(buahRawRepo, kodeTemplateRepo, masterDataRepo, musimRepo, gradeRepo),
+	}
+}
+
+func (s *sesiGradingService) Open(ctx context.Context, req requests.SesiGradingOpenRequest, userID, locationID string) (*response.SesiGradingResponse, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat membuka sesi grading")
+	}
+
+	grades, err := s.sesiGrades(ctx, req.JenisDurianID, req.Grade)
+	if err != nil {
+		return nil, err
+	}
+
+	sesi := &domain.SesiGrading{
+		JenisDurianID: req.JenisDurianID,
+		Status:        constants.SesiGradingStatusBuka,
+		DibukaOleh:    &userID,
+		Lots:          make([]domain.SesiGradingLot, 0, len(grades)),
+	}
+	if req.EstateID != "" {
+		sesi.EstateID = &req.EstateID
+	}
+	if catatan := strings.TrimSpace(req.Catatan); catatan != "" {
+		sesi.Catatan = &catatan
+	}
+
+	// The lots are inserted together with the session
+	specs := make([]repository.SequenceSpec, 0, len(grades))
+	for _, kode := range grades {
+		lot, spec, err := s.draft.draft(ctx, requests.LotCreateRequest{
+			JenisDurianID: req.JenisDurianID,
+			KondisiBuah:   kode,
+			EstateID:      req.EstateID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sesi.MusimID = lot.MusimID
+		sesi.Lots = append(sesi.Lots, domain.SesiGradingLot{KondisiBuah: lot.KondisiBuah, Lot: lot})
+		specs = append(specs, spec)
+	}
+
+	if err := s.repo.Create(ctx, sesi, specs, userID); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, sesi.ID)
+}
+
+// sesiGrades resolves the requested grades, or picks the grades of the jenis rules
+func (s *sesiGradingService) sesiGrades(ctx context.Context, jenisDurianID string, requested []string) ([]string, error) {
+	var grades []string
+	for _, g := range requested {
+		kode, err := s.grade.resolve(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+		grades = append(grades, kode)
+	}
+
+	if len(grades) == 0 {
+		rules, err := s.gradeRepo.GetAturanByJenis(ctx, jenisDurianID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range rules {
+			if a.Grade != nil {
+				grades = append(grades, a.Grade.Kode)
+			}
+		}
+	}
+	if len(grades) == 0 {
+		list, err := s.gradeRepo.GetList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range list {
+			grades = append(grades, g.Kode)
+		}
+	}
+
+	grades = uniqueStrings(grades)
+	if len(grades) == 0 {
+		return nil, errors.ValidationError("belum ada grade yang terdaftar")
+	}
+	return grades, nil
+}
+
+func (s *sesiGradingService) GetList(ctx context.Context, status, jenisDurianID string) ([]response.SesiGradingResponse, error) {
+	list, err := s.repo.GetList(ctx, status, jenisDurianID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.SesiGradingResponse, 0, len(list))
+	for i := range list {
+		item, err := s.toResponse(ctx, &list[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *item)
+	}
+	return res, nil
+}
+
+func (s *sesiGradingService) GetByID(ctx context.Context, id string) (*response.SesiGradingResponse, error) {
+	sesi, err := s.getSesi(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(ctx, sesi)
+}
+
+// Scan puts the fruits into the session lot of the chosen grade, the scanning user is the grader
+func (s *sesiGradingService) Scan(ctx context.Context, id string, req requests.SesiGradingScanRequest, userID, locationID string) (*response.SesiGradingScanResponse, error) {
+	sesi, err := s.getSesi(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sesi.Status != constants.SesiGradingStatusBuka {
+		return nil, errors.ValidationError("sesi grading sudah ditutup")
+	}
+
+	kondisi := normalizeGrade(req.KondisiBuah)
+	var target *domain.SesiGradingLot
+	for i := range sesi.Lots {
+		if sesi.Lots[i].KondisiBuah == kondisi {
+			target = &sesi.Lots[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, errors.ValidationError(fmt.Sprintf("grade %s tidak ada di sesi ini", req.KondisiBuah))
+	}
+
+	hasil, err := s.lotService.ScanItems(ctx, target.LotID, requests.LotScanRequest{KodeBuah: req.KodeBuah}, userID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.SesiGradingScanResponse{
+		LotID:           target.LotID,
+		KondisiBuah:     target.KondisiBuah,
+		LotScanResponse: *hasil,
+	}
+	if target.Lot != nil {
+		res.LotKode = target.Lot.Kode
+	}
+	return res, nil
+}
+
+// Close finalizes all session lots together and returns the session report
+func (s *sesiGradingService) Close(ctx context.Context, id, userID, locationID string) (*response.SesiGradingLaporan, error) {
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat menutup sesi grading")
+	}
+
+	sesi, err := s.getSesi(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sesi.Status != constants.SesiGradingStatusBuka {
+		return nil, errors.ValidationError("sesi grading sudah ditutup")
+	}
+	if err := s.musim.ensureOpen(ctx, sesi.MusimID); err != nil {
+		return nil, err
+	}
+
+	sesi.JenisDurian, sesi.Lots = nil, nil
+	if err := s.repo.Close(ctx, sesi, userID); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	return s.GetLaporan(ctx, id)
+}
+
+func (s *sesiGradingService) GetLaporan(ctx context.Context, id string) (*response.SesiGradingLaporan, error) {
+	sesi, err := s.getSesi(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := s.lotResponses(ctx, sesi)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.SesiGradingLaporan{
+		SesiID:         sesi.ID,
+		Status:         sesi.Status,
+		Distribusi:     make([]response.SesiGradingDistribusi, 0, len(lots)),
+		Grader:         []response.SesiGradingGraderItem{},
+		TerdaftarQty:   sesi.TerdaftarQty,
+		TerdaftarBerat: sesi.TerdaftarBerat,
+		DihitungAt:     time.Now(),
+	}
+	for _, l := range lots {
+		res.TergradingQty += l.Qty
+		res.TergradingBerat += l.Berat
+	}
+	for _, l := range lots {
+		d := response.SesiGradingDistribusi{
+			KondisiBuah: l.KondisiBuah,
+			LotID:       l.LotID,
+			LotKode:     l.Kode,
+			Qty:         l.Qty,
+			Berat:       l.Berat,
+		}
+		if res.TergradingQty > 0 {
+			d.PersenQty = float64(l.Qty) * 100 / float64(res.TergradingQty)
+		}
+		if res.TergradingBerat > 0 {
+			d.PersenBerat = l.Berat * 100 / res.TergradingBerat
+		}
+		res.Distribusi = append(res.Distribusi, d)
+	}
+
+	// An open session compares with the fruit that is still unsorted now
+	if sesi.Status == constants.SesiGradingStatusBuka {
+		qty, berat, err := s.repo.GetBelumGrading(ctx, sesi.JenisDurianID, sesi.MusimID)
+		if err != nil {
+			return nil, err
+		}
+		res.TerdaftarQty = res.TergradingQty + qty
+		res.TerdaftarBerat = res.TergradingBerat + berat
+	}
+	if res.TerdaftarQty > 0 {
+		res.YieldPersen = float64(res.TergradingQty) * 100 / float64(res.TerdaftarQty)
+	}
+	if res.TerdaftarBerat > 0 {
+		res.YieldBeratPersen = res.TergradingBerat * 100 / res.TerdaftarBerat
+	}
+
+	graders, err := s.repo.GetGrader(ctx, sesi.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range graders {
+		item := response.SesiGradingGraderItem{
+			GraderID:  g.GraderID,
+			Email:     g.Email,
+			Qty:       g.Qty,
+			Berat:     g.Berat,
+			MulaiAt:   g.MulaiAt,
+			SelesaiAt: g.SelesaiAt,
+		}
+		if jam := g.SelesaiAt.Sub(g.MulaiAt).Hours(); jam > 0 {
+			item.PerJam = float64(g.Qty) / jam
+		}
+		res.Grader = append(res.Grader, item)
+	}
+
+	return res, nil
+}
+
+func (s *sesiGradingService) getSesi(ctx context.Context, id string) (*domain.SesiGrading, error) {
+	sesi, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sesi == nil {
+		return nil, errors.NotFoundError("sesi grading tidak ditemukan")
+	}
+	return sesi, nil
+}
+
+func (s *sesiGradingService) toResponse(ctx context.Context, sesi *domain.SesiGrading) (*response.SesiGradingResponse, error) {
+	lots, err := s.lotResponses(ctx, sesi)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.SesiGradingResponse{
+		ID:            sesi.ID,
+		JenisDurianID: sesi.JenisDurianID,
+		EstateID:      sesi.EstateID,
+		MusimID:       sesi.MusimID,
+		Status:        sesi.Status,
+		Catatan:       sesi.Catatan,
+		Lots:          lots,
+		DibukaOleh:    sesi.DibukaOleh,
+		DitutupOleh:   sesi.DitutupOleh,
+		DitutupAt:     sesi.DitutupAt,
+		CreatedAt:     sesi.CreatedAt,
+	}
+	if sesi.JenisDurian != nil {
+		res.JenisDurianNama = sesi.JenisDurian.NamaJenis
+	}
+	return res, nil
+}
+
+// lotResponses lists the session lots with what they hold. An open session counts the fruit
+// in each lot now, a closed one the finalized amounts, and lots discarded empty are left out.
+func (s *sesiGradingService) lotResponses(ctx context.Context, sesi *domain.SesiGrading) ([]response.SesiGradingLotResponse, error) {
+	isi := make(map[string]domain.SesiGradingIsi)
+	if sesi.Status == constants.SesiGradingStatusBuka {
+		list, err := s.repo.GetIsi(ctx, sesi.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range list {
+			isi[i.LotID] = i
+		}
+	}
+
+	res := make([]response.SesiGradingLotResponse, 0, len(sesi.Lots))
+	for _, l := range sesi.Lots {
+		if l.Lot == nil || l.Lot.DeletedAt != nil {
+			continue
+		}
+		item := response.SesiGradingLotResponse{
+			LotID:       l.LotID,
+			Kode:        l.Lot.Kode,
+			KondisiBuah: l.KondisiBuah,
+			Status:      l.Lot.Status,
+			Qty:         l.Lot.QtyAwal,
+			Berat:       l.Lot.BeratAwal,
+		}
+		if sesi.Status == constants.SesiGradingStatusBuka {
+			item.Qty = isi[l.LotID].Qty
+			item.Berat = isi[l.LotID].Berat
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
@@ -8,6 +8,7 @@ import (
 	"durich-be/internal/dto/response"
 	"durich-be/internal/repository"
 	"durich-be/pkg/errors"
+	std_errors "errors"
 	"time"
 )
 
@@ -15,22 +16,35 @@ type ShipmentService interface {
 	Create(ctx context.Context, req requests.ShipmentCreateRequest, userID string) (*response.ShipmentResponse, error)
 	GetList(ctx context.Context, tujuan, status, locationID, listType, tujuanType string, page, limit int) ([]response.ShipmentResponse, int64, error)
 	GetByID(ctx context.Context, id string) (*response.ShipmentDetailResponse, error)
-	AddItem(ctx context.Context, shipmentID string, req requests.ShipmentAddItemRequest, locationID string) error
-	RemoveItem(ctx context.Context, shipmentID string, detailID string) error
+	AddItem(ctx context.Context, shipmentID string, req requests.ShipmentAddItemRequest, userID, locationID string) error
+	RemoveItem(ctx context.Context, shipmentID, detailID, userID string) error
 	UpdateStatus(ctx context.Context, shipmentID string, req requests.ShipmentUpdateStatusRequest, userID string) error
-	Finalize(ctx context.Context, id string) error
-	Receive(ctx context.Context, id string, req requests.ShipmentReceiveRequest) error
+	Finalize(ctx context.Context, id, userID string) error
+	Receive(ctx context.Context, id string, req requests.ShipmentReceiveRequest, userID string) error
+	Saran(ctx context.Context, req requests.ShipmentSaranRequest, userID, locationID string) (*response.ShipmentSaranResponse, error)
 }
 
 type shipmentService struct {
-	repo       repository.ShipmentRepository
-	tujuanRepo repository.TujuanPengirimanRepository
+	repo           repository.ShipmentRepository
+	tujuanRepo     repository.TujuanPengirimanRepository
+	masterDataRepo repository.MasterDataRepository
+	lotRepo        repository.LotRepository
+	kode           *kodeGenerator
 }
 
-func NewShipmentService(repo repository.ShipmentRepository, tujuanRepo repository.TujuanPengirimanRepository) ShipmentService {
+func NewShipmentService(
+	repo repository.ShipmentRepository,
+	tujuanRepo repository.TujuanPengirimanRepository,
+	kodeTemplateRepo repository.KodeTemplateRepository,
+	masterDataRepo repository.MasterDataRepository,
+	lotRepo repository.LotRepository,
+) ShipmentService {
 	return &shipmentService{
-		repo:       repo,
-		tujuanRepo: tujuanRepo,
+		repo:           repo,
+		tujuanRepo:     tujuanRepo,
+		masterDataRepo: masterDataRepo,
+		lotRepo:        lotRepo,
+		kode:           newKodeGenerator(kodeTemplateRepo),
 	}
 }
 
@@ -38,40 +52,59 @@ func (s *shipmentService) Create(ctx context.Context, req requests.ShipmentCreat
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	kode, err := s.repo.GetNextShipmentKode(ctx)
+	shipment, spec, err := s.newShipment(ctx, req.TujuanID, req.TglKirim, req.CompanyID, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	tujuanDetail, err := s.tujuanRepo.GetByID(ctx, req.TujuanID)
+	err = s.repo.Create(ctx, shipment, spec)
 	if err != nil {
-		return nil, errors.ValidationError("invalid tujuan_id")
+		return nil, err
+	}
+
+	resp := response.NewShipmentResponse(shipment)
+	return &resp, nil
+}
+
+// newShipment prepares a DRAFT shipment to tujuanID and the spec of its code
+func (s *shipmentService) newShipment(ctx context.Context, tujuanID string, tglKirim time.Time, companyID, userID string) (*domain.Pengiriman, repository.SequenceSpec, error) {
+	var spec repository.SequenceSpec
+	tujuanDetail, err := s.tujuanRepo.GetByID(ctx, tujuanID)
+	if err != nil {
+		return nil, spec, errors.ValidationError("invalid tujuan_id")
 	}
 	if tujuanDetail == nil {
-		return nil, errors.ValidationError("tujuan pengiriman not found")
+		return nil, spec, errors.ValidationError("tujuan pengiriman not found")
 	}
 
-	tglKirim := req.TglKirim
 	if tglKirim.IsZero() {
 		tglKirim = time.Now()
 	}
 
-	shipment := &domain.Pengiriman{
-		Kode:      kode,
-		Tujuan:    tujuanDetail.Nama,
-		TujuanID:  req.TujuanID,
-		TglKirim:  tglKirim,
-		Status:    constants.ShipmentStatusDraft,
-		CreatedBy: userID,
+	values := kodeValues{Date: tglKirim}
+	if companyID != "" {
+		company, err := s.masterDataRepo.GetCompanyByID(ctx, companyID)
+		if err != nil {
+			return nil, spec, err
+		}
+		if company == nil {
+			return nil, spec, errors.ValidationError("company tidak ditemukan")
+		}
+		values.Company = company.Kode
 	}
 
-	err = s.repo.Create(ctx, shipment)
+	spec, err = s.kode.spec(ctx, constants.KodeTipeShipment, companyID, values)
 	if err != nil {
-		return nil, err
+		return nil, spec, errors.ValidationError(err.Error())
 	}
 
-	resp := response.NewShipmentResponse(shipment)
-	return &resp, nil
+	return &domain.Pengiriman{
+		Tujuan:    tujuanDetail.Nama,
+		TujuanID:  tujuanID,
+		TglKirim:  tglKirim,
+		Status:    constants.ShipmentStatusDraft,
+		CreatedBy: userID,
+	}, spec, nil
 }
 
 func (s *shipmentService) GetList(ctx context.Context, tujuan, status, locationID, listType, tujuanType string, page, limit int) ([]response.ShipmentResponse, int64, error) {
@@ -115,6 +148,7 @@ func (s *shipmentService) GetByID(ctx context.Context, id string) (*response.Shi
 			LotID:      d.LotSumberID,
 			QtyAmbil:   d.QtyAmbil,
 			BeratAmbil: d.BeratAmbil,
+			Parsial:    d.Parsial,
 		}
 		if d.Lot != nil {
 			item.KodeLot = d.Lot.Kode
@@ -132,7 +166,7 @@ func (s *shipmentService) GetByID(ctx context.Context, id string) (*response.Shi
 	}, nil
 }
 
-func (s *shipmentService) AddItem(ctx context.Context, shipmentID string, req requests.ShipmentAddItemRequest, locationID string) error {
+func (s *shipmentService) AddItem(ctx context.Context, shipmentID string, req requests.ShipmentAddItemRequest, userID, locationID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
@@ -141,14 +175,85 @@ func (s *shipmentService) AddItem(ctx context.Context, shipmentID string, req re
 		LotSumberID:  req.LotID,
 	}
 
-	return s.repo.AddItem(ctx, detail, locationID)
+	var split *repository.ShipmentSplit
+	switch {
+	case len(req.KodeBuah) > 0:
+		if req.QtyAmbil != nil || req.BeratAmbil != nil {
+			return errors.ValidationError("kode_buah tidak bisa digabung dengan qty_ambil atau berat_ambil")
+		}
+		var err error
+		if split, err = s.splitByKodeBuah(ctx, req.LotID, req.KodeBuah, locationID); err != nil {
+			return err
+		}
+	case req.QtyAmbil != nil:
+		detail.QtyAmbil = *req.QtyAmbil
+		if req.BeratAmbil != nil {
+			detail.BeratAmbil = *req.BeratAmbil
+		}
+	case req.BeratAmbil != nil:
+		return errors.ValidationError("berat_ambil membutuhkan qty_ambil")
+	}
+
+	return repoError(s.repo.AddItem(ctx, detail, split, userID, locationID))
 }
 
-func (s *shipmentService) RemoveItem(ctx context.Context, shipmentID string, detailID string) error {
+// splitByKodeBuah plans moving the chosen fruits into their own lot so the shipment can take it
+// whole. It returns nil when they are everything left in the lot, the lot is taken itself.
+func (s *shipmentService) splitByKodeBuah(ctx context.Context, lotID string, kodeBuah []string, locationID string) (*repository.ShipmentSplit, error) {
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if lot.Status != constants.LotStatusReady {
+		return nil, errors.ValidationError("lot tidak berstatus READY")
+	}
+	if (locationID == "" && lot.PosisiID != nil) || (locationID != "" && (lot.PosisiID == nil || *lot.PosisiID != locationID)) {
+		return nil, errors.ValidationError("akses ditolak: lot tidak berada di lokasi anda")
+	}
+
+	kodes := uniqueStrings(kodeBuah)
+	buah, err := s.lotRepo.GetBuahByKodes(ctx, lot.ID, kodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(buah) != len(kodes) {
+		found := make([]string, 0, len(buah))
+		for _, b := range buah {
+			found = append(found, b.KodeBuah)
+		}
+		for _, k := range kodes {
+			if !containsString(found, k) {
+				return nil, errors.ValidationError("buah " + k + " bukan anggota lot " + lot.Kode)
+			}
+		}
+	}
+	if len(buah) == lot.QtySisa {
+		return nil, nil
+	}
+
+	spec, err := lotTurunanSpec(ctx, s.kode, lot, &buah[0])
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(buah))
+	for _, b := range buah {
+		ids = append(ids, b.ID)
+	}
+
+	return &repository.ShipmentSplit{
+		ParentID:   lot.ID,
+		Child:      newTurunanLot(lot),
+		Spec:       spec,
+		BuahRawIDs: ids,
+	}, nil
+}
+
+func (s *shipmentService) RemoveItem(ctx context.Context, shipmentID, detailID, userID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	return s.repo.RemoveItem(ctx, shipmentID, detailID)
+	return repoError(s.repo.RemoveItem(ctx, shipmentID, detailID, userID))
 }
 
 func (s *shipmentService) UpdateStatus(ctx context.Context, shipmentID string, req requests.ShipmentUpdateStatusRequest, userID string) error {
@@ -186,7 +291,7 @@ func (s *shipmentService) UpdateStatus(ctx context.Context, shipmentID string, r
 	return s.repo.UpdateStatus(ctx, shipmentID, newStatus, req.Notes, userID)
 }
 
-func (s *shipmentService) Finalize(ctx context.Context, id string) error {
+func (s *shipmentService) Finalize(ctx context.Context, id, userID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
@@ -201,10 +306,10 @@ func (s *shipmentService) Finalize(ctx context.Context, id string) error {
 		return errors.ValidationError("shipment cannot be empty")
 	}
 
-	return s.repo.Finalize(ctx, id)
+	return repoError(s.repo.Finalize(ctx, id, userID))
 }
 
-func (s *shipmentService) Receive(ctx context.Context, id string, req requests.ShipmentReceiveRequest) error {
+func (s *shipmentService) Receive(ctx context.Context, id string, req requests.ShipmentReceiveRequest, userID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
@@ -218,12 +323,8 @@ func (s *shipmentService) Receive(ctx context.Context, id string, req requests.S
 	}
 
 	// 2. Validate Status
-	if shipment.Status != constants.ShipmentStatusSending && shipment.Status != constants.ShipmentStatusSending {
-		// Note: Constants for sending might be SENDING or SHIPPED, checking for sending phase
-		// Assuming 'SENDING' is the status after finalize
-		if shipment.Status != constants.ShipmentStatusSending {
-			return errors.ValidationError("shipment must be in SENDING status to receive")
-		}
+	if shipment.Status != constants.ShipmentStatusSending {
+		return errors.ValidationError("shipment must be in SENDING status to receive")
 	}
 
 	// 3. Validate Tujuan Type (Must be INTERNAL)
@@ -254,10 +355,20 @@ func (s *shipmentService) Receive(ctx context.Context, id string, req requests.S
 			finalQty = *item.QtyDiterima
 		}
 
-		updates[item.LotID] = repository.ShipmentReceiveItem{
-			Berat: item.BeratDiterima,
-			Qty:   finalQty,
+		receiveItem := repository.ShipmentReceiveItem{
+			Berat:    item.BeratDiterima,
+			Qty:      finalQty,
+			QtyAmbil: detail.QtyAmbil,
+			Parsial:  detail.Parsial,
+		}
+		if detail.Parsial && detail.Lot != nil {
+			spec, err := lotTurunanSpec(ctx, s.kode, detail.Lot, nil)
+			if err != nil {
+				return err
+			}
+			receiveItem.Spec = spec
 		}
+		updates[item.LotID] = receiveItem
 	}
 
 	if len(updates) != len(shipment.Details) {
@@ -265,5 +376,14 @@ func (s *shipmentService) Receive(ctx context.Context, id string, req requests.S
 	}
 
 	// 5. Execute Updates
-	return s.repo.Receive(ctx, id, updates, shipment.TujuanID, req.ReceivedDate)
+	return repoError(s.repo.Receive(ctx, id, updates, shipment.TujuanID, req.ReceivedDate, userID))
+}
+
+// repoError reports a rule a repository rejected as a bad request and passes anything else on
+func repoError(err error) error {
+	var ve *repository.ValidationError
+	if std_errors.As(err, &ve) {
+		return errors.ValidationError(ve.Message)
+	}
+	return err
 }
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/pkg/errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// lotSaran is a READY lot that can be proposed, with its age
+type lotSaran struct {
+	lot  *domain.StokLot
+	umur umurLot
+}
+
+// Saran proposes the READY lots of the user's location that fill each requested jenis and
+// grade, the first to expire or the oldest first. Expired lots and lots held by someone else
+// are never proposed. With req.Buat the DRAFT shipment is created with those lots in one
+// transaction.
+func (s *shipmentService) Saran(ctx context.Context, req requests.ShipmentSaranRequest, userID, locationID string) (*response.ShipmentSaranResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	urutan := req.Urutan
+	if urutan == "" {
+		urutan = constants.ShipmentSaranFEFO
+	}
+
+	seen := make(map[string]bool)
+	for i := range req.Items {
+		it := &req.Items[i]
+		it.KondisiBuah = normalizeGrade(it.KondisiBuah)
+		if it.Qty == 0 && it.Berat <= 0 {
+			return nil, errors.ValidationError("qty atau berat wajib diisi untuk setiap item")
+		}
+		key := it.JenisDurianID + "|" + it.KondisiBuah
+		if seen[key] {
+			return nil, errors.ValidationError(fmt.Sprintf("jenis durian dan grade %s diminta lebih dari sekali", it.KondisiBuah))
+		}
+		seen[key] = true
+	}
+
+	now := time.Now()
+	res := &response.ShipmentSaranResponse{
+		Urutan:  urutan,
+		Lengkap: true,
+		Items:   make([]response.ShipmentSaranItem, 0, len(req.Items)),
+	}
+	var details []domain.PengirimanDetail
+	for _, it := range req.Items {
+		lots, err := s.lotRepo.GetSiapKirim(ctx, locationID, it.JenisDurianID, it.KondisiBuah, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		kandidat := make([]lotSaran, 0, len(lots))
+		for i := range lots {
+			umur := hitungUmurLot(&lots[i], now)
+			if umur.Status == constants.LotUmurKedaluwarsa {
+				continue
+			}
+			kandidat = append(kandidat, lotSaran{lot: &lots[i], umur: umur})
+		}
+		urutkanLotSaran(kandidat, urutan)
+
+		item := response.ShipmentSaranItem{
+			JenisDurianID: it.JenisDurianID,
+			KondisiBuah:   it.KondisiBuah,
+			QtyDiminta:    it.Qty,
+			BeratDiminta:  it.Berat,
+			Lots:          make([]response.ShipmentSaranLot, 0),
+		}
+		if len(lots) > 0 && lots[0].JenisDurianDetail != nil {
+			item.JenisDurianNama = lots[0].JenisDurianDetail.NamaJenis
+		}
+
+		for _, k := range kandidat {
+			qty := ambilSaran(k.lot, it, item.Qty, item.Berat)
+			if qty == 0 {
+				break
+			}
+
+			detail := domain.PengirimanDetail{LotSumberID: k.lot.ID}
+			berat := k.lot.BeratSisa
+			if qty < k.lot.QtySisa {
+				// The shipment repository prorates the weight the same way
+				detail.QtyAmbil = qty
+				berat = math.Round(k.lot.BeratSisa*float64(qty)/float64(k.lot.QtySisa)*100) / 100
+			}
+			details = append(details, detail)
+
+			item.Qty += qty
+			item.Berat = roundPlaces(item.Berat+berat, 2)
+			item.Lots = append(item.Lots, response.ShipmentSaranLot{
+				LotID:         k.lot.ID,
+				Kode:          k.lot.Kode,
+				QtyAmbil:      qty,
+				BeratAmbil:    berat,
+				Parsial:       qty < k.lot.QtySisa,
+				MulaiSimpanAt: k.umur.MulaiAt,
+				KedaluwarsaAt: k.umur.KedaluwarsaAt,
+				StatusUmur:    k.umur.Status,
+			})
+		}
+
+		if it.Qty > 0 {
+			item.Lengkap = item.Qty >= it.Qty
+		} else {
+			item.Lengkap = item.Berat >= roundPlaces(it.Berat, 2)
+		}
+		if !item.Lengkap {
+			res.Lengkap = false
+		}
+		res.Items = append(res.Items, item)
+	}
+
+	if !req.Buat {
+		return res, nil
+	}
+	if len(details) == 0 {
+		return nil, errors.ValidationError("tidak ada lot READY yang bisa dikirim untuk permintaan ini")
+	}
+
+	shipment, spec, err := s.newShipment(ctx, req.TujuanID, req.TglKirim, req.CompanyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateWithItems(ctx, shipment, spec, details, userID, locationID); err != nil {
+		return nil, repoError(err)
+	}
+
+	if res.Shipment, err = s.GetByID(ctx, shipment.ID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ambilSaran returns how many fruits to take from lot for a need that has got qty fruits and
+// berat kg so far, 0 once it is met. A need by weight rounds up to whole fruits.
+func ambilSaran(lot *domain.StokLot, need requests.ShipmentSaranItemRequest, qty int, berat float64) int {
+	var perlu int
+	if need.Qty > 0 {
+		perlu = need.Qty - qty
+	} else {
+		sisa := need.Berat - berat
+		if sisa <= 0.005 {
+			return 0
+		}
+		if lot.BeratSisa <= sisa {
+			return lot.QtySisa
+		}
+		perlu = int(math.Ceil(sisa / (lot.BeratSisa / float64(lot.QtySisa))))
+	}
+
+	if perlu <= 0 {
+		return 0
+	}
+	if perlu > lot.QtySisa {
+		return lot.QtySisa
+	}
+	return perlu
+}
+
+// urutkanLotSaran sorts by expiry for FEFO and by finalize for FIFO. Lots without a shelf life
+// or date go last, oldest first, and the lot code breaks ties.
+func urutkanLotSaran(list []lotSaran, urutan string) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if urutan == constants.ShipmentSaranFEFO {
+			if lebih, ok := waktuLebihAwal(a.umur.KedaluwarsaAt, b.umur.KedaluwarsaAt); ok {
+				return lebih
+			}
+			if lebih, ok := waktuLebihAwal(a.umur.MulaiAt, b.umur.MulaiAt); ok {
+				return lebih
+			}
+		}
+		if lebih, ok := waktuLebihAwal(a.lot.FinalizedAt, b.lot.FinalizedAt); ok {
+			return lebih
+		}
+		return a.lot.Kode < b.lot.Kode
+	})
+}
+
+// waktuLebihAwal compares two optional times, a missing one counts as the latest. ok is false
+// when they are equal.
+func waktuLebihAwal(a, b *time.Time) (lebih bool, ok bool) {
+	switch {
+	case a == nil && b == nil:
+		return false, false
+	case a == nil:
+		return false, true
+	case b == nil:
+		return true, true
+	case a.Equal(*b):
+		return false, false
+	}
+	return a.Before(*b), true
+}
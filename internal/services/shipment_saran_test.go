@@ -0,0 +1,118 @@
+package services
+
+import (
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAmbilSaran(t *testing.T) {
+	lot := &domain.StokLot{QtySisa: 10, BeratSisa: 25}
+
+	tests := []struct {
+		name  string
+		need  requests.ShipmentSaranItemRequest
+		qty   int
+		berat float64
+		want  int
+	}{
+		{name: "qty need inside the lot", need: requests.ShipmentSaranItemRequest{Qty: 4}, want: 4},
+		{name: "qty need larger than the lot", need: requests.ShipmentSaranItemRequest{Qty: 15}, want: 10},
+		{name: "qty need partly met", need: requests.ShipmentSaranItemRequest{Qty: 15}, qty: 8, want: 7},
+		{name: "qty need met", need: requests.ShipmentSaranItemRequest{Qty: 5}, qty: 5, want: 0},
+		{name: "qty need exceeded", need: requests.ShipmentSaranItemRequest{Qty: 5}, qty: 6, want: 0},
+		{name: "weight rounds up to whole fruits", need: requests.ShipmentSaranItemRequest{Berat: 6}, want: 3},
+		{name: "weight on a fruit boundary", need: requests.ShipmentSaranItemRequest{Berat: 5}, want: 2},
+		{name: "weight larger than the lot", need: requests.ShipmentSaranItemRequest{Berat: 30}, want: 10},
+		{name: "weight equal to the lot", need: requests.ShipmentSaranItemRequest{Berat: 25}, want: 10},
+		{name: "weight partly met", need: requests.ShipmentSaranItemRequest{Berat: 30}, berat: 26, want: 2},
+		{name: "weight met within rounding", need: requests.ShipmentSaranItemRequest{Berat: 10}, berat: 9.996, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ambilSaran(lot, tt.need, tt.qty, tt.berat); got != tt.want {
+				t.Errorf("ambilSaran = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUrutkanLotSaran(t *testing.T) {
+	base := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+	jam := func(h int) *time.Time {
+		at := base.Add(time.Duration(h) * time.Hour)
+		return &at
+	}
+	saran := func(kode string, finalized, mulai, kedaluwarsa *time.Time) lotSaran {
+		return lotSaran{
+			lot:  &domain.StokLot{Kode: kode, FinalizedAt: finalized},
+			umur: umurLot{MulaiAt: mulai, KedaluwarsaAt: kedaluwarsa},
+		}
+	}
+
+	// A arrived late but expires first, B was finalized first, C has no shelf life, D and E tie
+	lots := []lotSaran{
+		saran("C", jam(2), jam(2), nil),
+		saran("E", jam(3), jam(5), jam(50)),
+		saran("B", jam(0), jam(6), jam(60)),
+		saran("A", jam(1), jam(10), jam(40)),
+		saran("D", jam(3), jam(5), jam(50)),
+		saran("F", nil, nil, nil),
+	}
+
+	tests := []struct {
+		urutan string
+		want   []string
+	}{
+		{constants.ShipmentSaranFEFO, []string{"A", "D", "E", "B", "C", "F"}},
+		{constants.ShipmentSaranFIFO, []string{"B", "A", "C", "D", "E", "F"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.urutan, func(t *testing.T) {
+			list := append([]lotSaran(nil), lots...)
+			urutkanLotSaran(list, tt.urutan)
+
+			got := make([]string, 0, len(list))
+			for _, l := range list {
+				got = append(got, l.lot.Kode)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaktuLebihAwal(t *testing.T) {
+	pagi := time.Date(2026, 3, 5, 6, 0, 0, 0, time.Local)
+	siang := pagi.Add(6 * time.Hour)
+	pagiUTC := pagi.UTC()
+
+	tests := []struct {
+		name      string
+		a, b      *time.Time
+		wantLebih bool
+		wantOK    bool
+	}{
+		{"earlier", &pagi, &siang, true, true},
+		{"later", &siang, &pagi, false, true},
+		{"same instant in another zone", &pagi, &pagiUTC, false, false},
+		{"missing counts as latest", nil, &pagi, false, true},
+		{"present before missing", &pagi, nil, true, true},
+		{"both missing", nil, nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lebih, ok := waktuLebihAwal(tt.a, tt.b)
+			if lebih != tt.wantLebih || ok != tt.wantOK {
+				t.Errorf("waktuLebihAwal = (%v, %v), want (%v, %v)", lebih, ok, tt.wantLebih, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"log"
+	"time"
+)
+
+type StokService interface {
+	GetAsOf(ctx context.Context, q requests.StokAsOfQuery, locationID string) (*response.StokAsOfResponse, error)
+	TakeSnapshot(ctx context.Context, req requests.StokSnapshotRequest) (*response.StokSnapshotResponse, error)
+	RunSnapshot(ctx context.Context)
+}
+
+type stokService struct {
+	repo     repository.StokRepository
+	interval time.Duration
+}
+
+// NewStokService takes how often RunSnapshot checks whether yesterday's snapshot was taken
+func NewStokService(repo repository.StokRepository, interval time.Duration) StokService {
+	if interval <= 0 {
+		interval = constants.StokSnapshotDefaultInterval
+	}
+	return &stokService{
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// GetAsOf returns the stock held at the end of a day. A branch user only sees its own location.
+func (s *stokService) GetAsOf(ctx context.Context, q requests.StokAsOfQuery, locationID string) (*response.StokAsOfResponse, error) {
+	tanggal, err := parseTanggalStok(q.Tanggal)
+	if err != nil {
+		return nil, err
+	}
+	if locationID != "" {
+		q.LokasiID = locationID
+	}
+
+	items, sumber, err := stokAsOf(ctx, s.repo, tanggal)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.StokAsOfResponse{
+		Tanggal: q.Tanggal,
+		Sumber:  sumber,
+		Items:   make([]response.StokPosisiItem, 0, len(items)),
+	}
+	for _, it := range items {
+		if !stokCocok(&it, q) {
+			continue
+		}
+		res.Items = append(res.Items, response.StokPosisiItem{
+			LokasiID:        it.LokasiID,
+			LokasiNama:      it.LokasiNama,
+			MusimID:         it.MusimID,
+			JenisDurianID:   it.JenisDurianID,
+			JenisDurianNama: it.JenisDurianNama,
+			KondisiBuah:     it.KondisiBuah,
+			Status:          it.Status,
+			LotCount:        it.LotCount,
+			Qty:             it.Qty,
+			Berat:           it.Berat,
+		})
+		res.TotalLot += it.LotCount
+		res.TotalQty += it.Qty
+		res.TotalBerat += it.Berat
+	}
+	return res, nil
+}
+
+// TakeSnapshot takes, or takes again, the snapshot of a day that has ended
+func (s *stokService) TakeSnapshot(ctx context.Context, req requests.StokSnapshotRequest) (*response.StokSnapshotResponse, error) {
+	tanggal, err := parseTanggalStok(req.Tanggal)
+	if err != nil {
+		return nil, err
+	}
+	if !tanggal.AddDate(0, 0, 1).Before(time.Now()) {
+		return nil, errors.ValidationError("snapshot hanya bisa diambil untuk hari yang sudah lewat")
+	}
+	if err := cekAwalLedger(ctx, s.repo, tanggal); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.takeSnapshot(ctx, tanggal)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.StokSnapshotResponse{
+		Tanggal:    req.Tanggal,
+		JumlahItem: len(snapshot.Items),
+		CreatedAt:  snapshot.CreatedAt,
+	}
+	for _, it := range snapshot.Items {
+		res.TotalQty += it.Qty
+		res.TotalBerat += it.Berat
+	}
+	return res, nil
+}
+
+// RunSnapshot takes yesterday's snapshot when it is missing, right away and then every interval,
+// until ctx is done. A server that was down at midnight catches up when it starts.
+func (s *stokService) RunSnapshot(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.snapshotKemarin(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *stokService) snapshotKemarin(ctx context.Context) {
+	now := time.Now()
+	kemarin := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+
+	existing, err := s.repo.GetSnapshot(ctx, kemarin)
+	if err != nil {
+		log.Printf("stok snapshot: gagal memeriksa %s: %v", kemarin.Format("2006-01-02"), err)
+		return
+	}
+	if existing != nil {
+		return
+	}
+	// Nothing to take before the ledger starts
+	if err := cekAwalLedger(ctx, s.repo, kemarin); err != nil {
+		return
+	}
+
+	if _, err := s.takeSnapshot(ctx, kemarin); err != nil {
+		log.Printf("stok snapshot: gagal mengambil %s: %v", kemarin.Format("2006-01-02"), err)
+	}
+}
+
+// takeSnapshot replays the ledger to the end of tanggal. The ledger is append-only, so a day
+// that has ended always replays to the same stock.
+func (s *stokService) takeSnapshot(ctx context.Context, tanggal time.Time) (*domain.StokSnapshot, error) {
+	items, err := s.repo.GetPosisi(ctx, tanggal.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &domain.StokSnapshot{Tanggal: tanggal, Items: items}
+	if err := s.repo.SaveSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// stokAsOf returns the stock at the end of tanggal and where it came from. A day with a
+// snapshot is read from it, any other day replays the ledger, today up to now.
+func stokAsOf(ctx context.Context, repo repository.StokRepository, tanggal time.Time) ([]domain.StokSnapshotItem, string, error) {
+	if err := cekAwalLedger(ctx, repo, tanggal); err != nil {
+		return nil, "", err
+	}
+
+	snapshot, err := repo.GetSnapshot(ctx, tanggal)
+	if err != nil {
+		return nil, "", err
+	}
+	if snapshot != nil {
+		return snapshot.Items, constants.StokSumberSnapshot, nil
+	}
+
+	before := tanggal.AddDate(0, 0, 1)
+	if now := time.Now(); before.After(now) {
+		before = now
+	}
+	items, err := repo.GetPosisi(ctx, before)
+	if err != nil {
+		return nil, "", err
+	}
+	return items, constants.StokSumberReplay, nil
+}
+
+// cekAwalLedger refuses a day that ended before the ledger started, its stock would replay
+// as empty
+func cekAwalLedger(ctx context.Context, repo repository.StokRepository, tanggal time.Time) error {
+	awal, err := repo.GetAwalLedger(ctx)
+	if err != nil {
+		return err
+	}
+	if awal != nil && !tanggal.AddDate(0, 0, 1).After(*awal) {
+		return errors.ValidationError("stok hanya tersedia mulai " + awal.In(time.Local).Format("2006-01-02") + ", saat pencatatan mutasi stok dimulai")
+	}
+	return nil
+}
+
+// parseTanggalStok reads a day in the server's timezone, the one the snapshot job uses
+func parseTanggalStok(value string) (time.Time, error) {
+	tanggal, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return tanggal, errors.ValidationError("format tanggal harus YYYY-MM-DD")
+	}
+	if tanggal.After(time.Now()) {
+		return tanggal, errors.ValidationError("tanggal tidak boleh melewati hari ini")
+	}
+	return tanggal, nil
+}
+
+func stokCocok(it *domain.StokSnapshotItem, q requests.StokAsOfQuery) bool {
+	if q.LokasiID != "" && (it.LokasiID == nil || *it.LokasiID != q.LokasiID) {
+		return false
+	}
+	if q.MusimID != "" && (it.MusimID == nil || *it.MusimID != q.MusimID) {
+		return false
+	}
+	if q.JenisDurianID != "" && it.JenisDurianID != q.JenisDurianID {
+		return false
+	}
+	if q.KondisiBuah != "" && it.KondisiBuah != normalizeGrade(q.KondisiBuah) {
+		return false
+	}
+	if q.Status != "" && it.Status != q.Status {
+		return false
+	}
+	return true
+}
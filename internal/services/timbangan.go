@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/database"
+	"durich-be/pkg/errors"
+	"durich-be/pkg/scale"
+	"log"
+	"sync"
+	"time"
+)
+
+type TimbanganService interface {
+	Create(ctx context.Context, req requests.StasiunTimbangCreateRequest) (*response.StasiunTimbangResponse, error)
+	GetList(ctx context.Context) ([]response.StasiunTimbangResponse, error)
+	GetByID(ctx context.Context, id string) (*response.StasiunTimbangResponse, error)
+	Update(ctx context.Context, id string, req requests.StasiunTimbangRequest) (*response.StasiunTimbangResponse, error)
+	Delete(ctx context.Context, id string) error
+
+	OpenSesi(ctx context.Context, id string, req requests.StasiunTimbangSesiRequest, userID, locationID string) (*response.StasiunTimbangResponse, error)
+	CloseSesi(ctx context.Context, id string) (*response.StasiunTimbangResponse, error)
+	GetPembacaan(ctx context.Context, id, lotID string, limit int) ([]response.PembacaanTimbangResponse, error)
+
+	// Run connects to every active station and feeds stable weights into the open lot until ctx ends
+	Run(ctx context.Context)
+}
+
+type timbanganService struct {
+	repo          repository.TimbanganRepository
+	lotRepo       repository.LotRepository
+	pemanenRepo   repository.PemanenRepository
+	lotService    LotService
+	readTimeout   time.Duration
+	retryInterval time.Duration
+
+	mu      sync.Mutex
+	runCtx  context.Context
+	readers map[string]*scaleReader
+}
+
+// scaleReader is the running connection of one station
+type scaleReader struct {
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	status response.StasiunTimbangStatus
+}
+
+func NewTimbanganService(
+	repo repository.TimbanganRepository,
+	lotRepo repository.LotRepository,
+	pemanenRepo repository.PemanenRepository,
+	lotService LotService,
+	readTimeout, retryInterval time.Duration,
+) TimbanganService {
+	return &timbanganService{
+		repo:          repo,
+		lotRepo:       lotRepo,
+		pemanenRepo:   pemanenRepo,
+		lotService:    lotService,
+		readTimeout:   readTimeout,
+		retryInterval: retryInterval,
+		readers:       make(map[string]*scaleReader),
+	}
+}
+
+func (s *timbanganService) Create(ctx context.Context, req requests.StasiunTimbangCreateRequest) (*response.StasiunTimbangResponse, error) {
+	stasiun := &domain.StasiunTimbang{
+		Kode:      req.Kode,
+		MinBerat:  constants.TimbanganDefaultMinBerat,
+		Toleransi: constants.TimbanganDefaultToleransi,
+		Sampel:    constants.TimbanganDefaultSampel,
+		Aktif:     true,
+	}
+	applyStasiunRequest(stasiun, req.StasiunTimbangRequest)
+
+	if err := s.repo.Create(ctx, stasiun); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.ValidationError("kode stasiun timbang sudah digunakan")
+		}
+		return nil, err
+	}
+
+	s.restartReader(stasiun)
+	return s.GetByID(ctx, stasiun.ID)
+}
+
+func (s *timbanganService) GetList(ctx context.Context) ([]response.StasiunTimbangResponse, error) {
+	list, err := s.repo.GetList(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.StasiunTimbangResponse, 0, len(list))
+	for _, stasiun := range list {
+		result = append(result, s.toResponse(stasiun))
+	}
+	return result, nil
+}
+
+func (s *timbanganService) GetByID(ctx context.Context, id string) (*response.StasiunTimbangResponse, error) {
+	stasiun, err := s.getStasiun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := s.toResponse(*stasiun)
+	return &resp, nil
+}
+
+func (s *timbanganService) Update(ctx context.Context, id string, req requests.StasiunTimbangRequest) (*response.StasiunTimbangResponse, error) {
+	stasiun, err := s.getStasiun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyStasiunRequest(stasiun, req)
+	if err := s.repo.Update(ctx, stasiun); err != nil {
+		return nil, err
+	}
+
+	s.restartReader(stasiun)
+	return s.GetByID(ctx, id)
+}
+
+func (s *timbanganService) Delete(ctx context.Context, id string) error {
+	stasiun, err := s.getStasiun(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stasiun.LotID != nil {
+		return errors.ValidationError("tutup sesi stasiun terlebih dahulu")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.stopReader(id)
+	return nil
+}
+
+func (s *timbanganService) OpenSesi(ctx context.Context, id string, req requests.StasiunTimbangSesiRequest, userID, locationID string) (*response.StasiunTimbangResponse, error) {
+	// Weighed fruits go through AddItems, which only central users may do
+	if locationID != "" {
+		return nil, errors.ValidationError("akses ditolak: hanya pusat yang dapat memodifikasi lot")
+	}
+
+	stasiun, err := s.getStasiun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lot, err := s.lotRepo.GetByID(ctx, req.LotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if lot.Status != constants.LotStatusDraft {
+		return nil, errors.ValidationError("hanya lot dengan status DRAFT yang bisa ditimbang")
+	}
+
+	pohon, err := s.lotRepo.GetPohonByKode(ctx, req.PohonKode, req.BlokID)
+	if err != nil {
+		return nil, errors.ValidationError("pohon dengan kode " + req.PohonKode + " tidak ditemukan di blok yang dipilih")
+	}
+
+	pemanenID := emptyToNil(req.PemanenID)
+	if pemanenID != nil {
+		pemanen, err := s.pemanenRepo.GetByID(ctx, *pemanenID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPemanen(pemanen, pohon); err != nil {
+			return nil, errors.ValidationError(err.Error())
+		}
+	}
+
+	now := time.Now()
+	stasiun.LotID = &req.LotID
+	stasiun.BlokID = &req.BlokID
+	stasiun.PohonKode = &req.PohonKode
+	stasiun.PemanenID = pemanenID
+	stasiun.SesiOleh = &userID
+	stasiun.SesiMulaiAt = &now
+
+	if err := s.repo.UpdateSesi(ctx, stasiun); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *timbanganService) CloseSesi(ctx context.Context, id string) (*response.StasiunTimbangResponse, error) {
+	stasiun, err := s.getStasiun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stasiun.LotID = nil
+	stasiun.BlokID = nil
+	stasiun.PohonKode = nil
+	stasiun.PemanenID = nil
+	stasiun.SesiOleh = nil
+	stasiun.SesiMulaiAt = nil
+
+	if err := s.repo.UpdateSesi(ctx, stasiun); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *timbanganService) GetPembacaan(ctx context.Context, id, lotID string, limit int) ([]response.PembacaanTimbangResponse, error) {
+	if _, err := s.getStasiun(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	list, err := s.repo.GetPembacaanList(ctx, id, lotID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.PembacaanTimbangResponse, 0, len(list))
+	for _, p := range list {
+		result = append(result, response.PembacaanTimbangResponse{
+			ID:        p.ID,
+			LotID:     p.LotID,
+			Berat:     p.Berat,
+			Raw:       p.Raw,
+			Status:    p.Status,
+			BuahRawID: p.BuahRawID,
+			KodeBuah:  p.KodeBuah,
+			Pesan:     p.Pesan,
+			CreatedAt: p.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+func (s *timbanganService) getStasiun(ctx context.Context, id string) (*domain.StasiunTimbang, error) {
+	stasiun, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stasiun == nil {
+		return nil, errors.NotFoundError("stasiun timbang tidak ditemukan")
+	}
+	return stasiun, nil
+}
+
+func applyStasiunRequest(stasiun *domain.StasiunTimbang, req requests.StasiunTimbangRequest) {
+	stasiun.Nama = req.Nama
+	stasiun.Alamat = req.Alamat
+	stasiun.Protokol = req.Protokol
+	stasiun.Transport = req.Transport
+	if stasiun.Transport == "" {
+		stasiun.Transport = scale.TransportTCP
+	}
+	if req.MinBerat != nil {
+		stasiun.MinBerat = *req.MinBerat
+	}
+	if req.Toleransi != nil {
+		stasiun.Toleransi = *req.Toleransi
+	}
+	if req.Sampel != nil {
+		stasiun.Sampel = *req.Sampel
+	}
+	if req.Aktif != nil {
+		stasiun.Aktif = *req.Aktif
+	}
+}
+
+func (s *timbanganService) toResponse(stasiun domain.StasiunTimbang) response.StasiunTimbangResponse {
+	resp := response.StasiunTimbangResponse{
+		ID:        stasiun.ID,
+		Kode:      stasiun.Kode,
+		Nama:      stasiun.Nama,
+		Alamat:    stasiun.Alamat,
+		Protokol:  stasiun.Protokol,
+		Transport: stasiun.Transport,
+		MinBerat:  stasiun.MinBerat,
+		Toleransi: stasiun.Toleransi,
+		Sampel:    stasiun.Sampel,
+		Aktif:     stasiun.Aktif,
+		CreatedAt: stasiun.CreatedAt,
+	}
+
+	if stasiun.LotID != nil {
+		resp.Sesi = &response.StasiunTimbangSesi{
+			LotID:     *stasiun.LotID,
+			BlokID:    stasiun.BlokID,
+			PohonKode: stasiun.PohonKode,
+			PemanenID: stasiun.PemanenID,
+			MulaiAt:   stasiun.SesiMulaiAt,
+		}
+		if stasiun.Lot != nil {
+			resp.Sesi.LotKode = stasiun.Lot.Kode
+		}
+	}
+
+	s.mu.Lock()
+	reader := s.readers[stasiun.ID]
+	s.mu.Unlock()
+	if reader != nil {
+		reader.mu.Lock()
+		resp.Status = reader.status
+		reader.mu.Unlock()
+	}
+
+	return resp
+}
+
+func (s *timbanganService) Run(ctx context.Context) {
+	list, err := s.repo.GetList(ctx, true)
+	if err != nil {
+		log.Printf("timbangan: gagal memuat stasiun: %v", err)
+	}
+
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.mu.Unlock()
+
+	for i := range list {
+		s.restartReader(&list[i])
+	}
+
+	<-ctx.Done()
+}
+
+// restartReader applies changed station settings. It is a no-op until Run has been called.
+func (s *timbanganService) restartReader(stasiun *domain.StasiunTimbang) {
+	s.stopReader(stasiun.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runCtx == nil || !stasiun.Aktif {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.runCtx)
+	reader := &scaleReader{cancel: cancel}
+	s.readers[stasiun.ID] = reader
+
+	go s.read(ctx, reader, *stasiun)
+}
+
+func (s *timbanganService) stopReader(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reader, ok := s.readers[id]; ok {
+		reader.cancel()
+		delete(s.readers, id)
+	}
+}
+
+func (s *timbanganService) read(ctx context.Context, reader *scaleReader, stasiun domain.StasiunTimbang) {
+	detector := &scale.Detector{
+		MinWeight: stasiun.MinBerat,
+		Tolerance: stasiun.Toleransi,
+		Samples:   stasiun.Sampel,
+	}
+
+	client := &scale.Client{
+		Address:       stasiun.Alamat,
+		Protocol:      stasiun.Protokol,
+		Transport:     stasiun.Transport,
+		ReadTimeout:   s.readTimeout,
+		RetryInterval: s.retryInterval,
+		OnConnect: func(connected bool) {
+			reader.mu.Lock()
+			reader.status.Terhubung = connected
+			if connected {
+				reader.status.Error = ""
+			}
+			reader.mu.Unlock()
+			detector.Reset()
+		},
+		OnError: func(err error) {
+			reader.mu.Lock()
+			reader.status.Error = err.Error()
+			reader.mu.Unlock()
+		},
+		OnReading: func(r scale.Reading) {
+			now := time.Now()
+			weight := r.Weight
+			reader.mu.Lock()
+			reader.status.BeratTerakhir = &weight
+			reader.status.Bergerak = r.Motion
+			reader.status.DibacaAt = &now
+			reader.mu.Unlock()
+
+			if berat, ok := detector.Feed(r); ok {
+				s.capture(ctx, stasiun.ID, berat, r.Raw)
+			}
+		},
+	}
+
+	client.Run(ctx)
+}
+
+// capture adds one weighed fruit to the station's open lot and logs the outcome.
+// The session is re-read on every capture so opening or closing it needs no reconnect.
+func (s *timbanganService) capture(ctx context.Context, stasiunID string, berat float64, raw string) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	pembacaan := &domain.PembacaanTimbang{
+		StasiunID: stasiunID,
+		Berat:     berat,
+		Raw:       raw,
+		Status:    constants.PembacaanStatusDitolak,
+	}
+
+	stasiun, err := s.repo.GetByID(ctx, stasiunID)
+	switch {
+	case err != nil:
+		pembacaan.Pesan = stringPtr(err.Error())
+	case stasiun == nil || stasiun.LotID == nil:
+		pembacaan.Pesan = stringPtr("tidak ada sesi timbang yang aktif")
+	default:
+		pembacaan.LotID = stasiun.LotID
+		res, err := s.lotService.AddItems(ctx, *stasiun.LotID, requests.LotAddItemsRequest{
+			PohonKode: *stasiun.PohonKode,
+			BlokID:    *stasiun.BlokID,
+			Berat:     berat,
+			PemanenID: stasiun.PemanenID,
+		}, "")
+		if err != nil {
+			pembacaan.Pesan = stringPtr(err.Error())
+			break
+		}
+		pembacaan.Status = constants.PembacaanStatusDiterima
+		pembacaan.BuahRawID = &res.BuahRawID
+		pembacaan.KodeBuah = &res.KodeBuah
+	}
+
+	if err := s.repo.CreatePembacaan(ctx, pembacaan); err != nil {
+		log.Printf("timbangan: gagal mencatat pembacaan stasiun %s: %v", stasiunID, err)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
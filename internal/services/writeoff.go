@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"durich-be/internal/constants"
+	"durich-be/internal/domain"
+	"durich-be/internal/dto/requests"
+	"durich-be/internal/dto/response"
+	"durich-be/internal/repository"
+	"durich-be/pkg/errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type WriteOffService interface {
+	Create(ctx context.Context, req requests.WriteOffRequest, user requests.UserAuth) (*response.WriteOffResponse, error)
+	GetList(ctx context.Context, status, lotID string, user requests.UserAuth) ([]response.WriteOffResponse, error)
+	GetByID(ctx context.Context, id, locationID string) (*response.WriteOffResponse, error)
+	Approve(ctx context.Context, id string, req requests.WriteOffKeputusanRequest, userID string) (*response.WriteOffResponse, error)
+	Reject(ctx context.Context, id string, req requests.WriteOffKeputusanRequest, userID string) (*response.WriteOffResponse, error)
+	GetLaporan(ctx context.Context, q requests.WriteOffLaporanQuery, locationID string) (*response.WriteOffLaporanResponse, error)
+}
+
+type writeOffService struct {
+	repo        repository.WriteOffRepository
+	lotRepo     repository.LotRepository
+	musim       *musimChecker
+	ambangBerat float64
+}
+
+// NewWriteOffService takes the weight in kg above which a non-admin write-off needs approval
+func NewWriteOffService(repo repository.WriteOffRepository, lotRepo repository.LotRepository, musimRepo repository.MusimRepository, ambangBerat float64) WriteOffService {
+	if ambangBerat <= 0 {
+		ambangBerat = constants.WriteOffDefaultAmbangBerat
+	}
+	return &writeOffService{
+		repo:        repo,
+		lotRepo:     lotRepo,
+		musim:       newMusimChecker(musimRepo),
+		ambangBerat: ambangBerat,
+	}
+}
+
+// Create writes stock off right away, unless a non-admin writes off more than the threshold.
+// That write-off waits for an admin and the lot keeps its stock until then.
+func (s *writeOffService) Create(ctx context.Context, req requests.WriteOffRequest, user requests.UserAuth) (*response.WriteOffResponse, error) {
+	lot, err := s.lotRepo.GetByID(ctx, req.LotID)
+	if err != nil {
+		return nil, errors.NotFoundError("lot tidak ditemukan")
+	}
+	if user.LocationID != "" && (lot.PosisiID == nil || *lot.PosisiID != user.LocationID) {
+		return nil, errors.ValidationError("akses ditolak: lot tidak berada di lokasi anda")
+	}
+	if lot.Status != constants.LotStatusReady {
+		return nil, errors.ValidationError("hanya lot dengan status READY yang bisa di-write-off")
+	}
+	if err := s.musim.ensureOpen(ctx, lot.MusimID); err != nil {
+		return nil, err
+	}
+
+	if req.AlasanKode == constants.WriteOffAlasanSusut {
+		if req.Qty != 0 || req.Berat <= 0 {
+			return nil, errors.ValidationError("susut hanya mengurangi berat, isi berat tanpa qty")
+		}
+	} else if req.Qty < 1 {
+		return nil, errors.ValidationError("qty wajib diisi")
+	}
+	if req.Qty > lot.QtySisa {
+		return nil, errors.ValidationError(fmt.Sprintf("qty melebihi sisa lot (%d)", lot.QtySisa))
+	}
+
+	wo := &domain.LotWriteOff{
+		LotID:         lot.ID,
+		LokasiID:      lot.PosisiID,
+		JenisDurianID: lot.JenisDurianID,
+		AlasanKode:    req.AlasanKode,
+		Qty:           req.Qty,
+		Berat:         req.Berat,
+		DiajukanOleh:  &user.UserID,
+	}
+	if catatan := strings.TrimSpace(req.Catatan); catatan != "" {
+		wo.Catatan = &catatan
+	}
+
+	berat := wo.Berat
+	if wo.Qty == lot.QtySisa {
+		berat = lot.BeratSisa
+	} else if berat == 0 {
+		berat = math.Round(lot.BeratSisa*float64(wo.Qty)/float64(lot.QtySisa)*100) / 100
+	}
+
+	if berat > s.ambangBerat && !hasRole(user, domain.RoleAdmin) {
+		wo.Berat = berat
+		wo.Status = constants.WriteOffStatusMenunggu
+		if err := s.repo.Create(ctx, wo); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.Apply(ctx, wo, user.UserID); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	return s.GetByID(ctx, wo.ID, "")
+}
+
+func (s *writeOffService) GetList(ctx context.Context, status, lotID string, user requests.UserAuth) ([]response.WriteOffResponse, error) {
+	list, err := s.repo.GetList(ctx, status, lotID, user.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]response.WriteOffResponse, 0, len(list))
+	for i := range list {
+		res = append(res, toWriteOffResponse(&list[i]))
+	}
+	return res, nil
+}
+
+// GetByID returns a write-off. A branch user only sees its own location.
+func (s *writeOffService) GetByID(ctx context.Context, id, locationID string) (*response.WriteOffResponse, error) {
+	wo, err := s.getWriteOff(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if locationID != "" && (wo.LokasiID == nil || *wo.LokasiID != locationID) {
+		return nil, errors.ValidationError("akses ditolak: write-off tidak berada di lokasi anda")
+	}
+	res := toWriteOffResponse(wo)
+	return &res, nil
+}
+
+// Approve applies a waiting write-off against the lot as it is now
+func (s *writeOffService) Approve(ctx context.Context, id string, req requests.WriteOffKeputusanRequest, userID string) (*response.WriteOffResponse, error) {
+	wo, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wo.Lot != nil {
+		if err := s.musim.ensureOpen(ctx, wo.Lot.MusimID); err != nil {
+			return nil, err
+		}
+	}
+
+	wo.CatatanKeputusan = keputusanCatatan(req)
+	if err := s.repo.Apply(ctx, wo, userID); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	return s.GetByID(ctx, id, "")
+}
+
+func (s *writeOffService) Reject(ctx context.Context, id string, req requests.WriteOffKeputusanRequest, userID string) (*response.WriteOffResponse, error) {
+	wo, err := s.getPending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	wo.Status = constants.WriteOffStatusDitolak
+	wo.DiputuskanOleh = &userID
+	wo.DiputuskanAt = &now
+	wo.CatatanKeputusan = keputusanCatatan(req)
+	if err := s.repo.Reject(ctx, wo); err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+	return s.GetByID(ctx, id, "")
+}
+
+// GetLaporan sums approved write-offs. A branch user only sees its own location.
+func (s *writeOffService) GetLaporan(ctx context.Context, q requests.WriteOffLaporanQuery, locationID string) (*response.WriteOffLaporanResponse, error) {
+	if locationID != "" {
+		q.LokasiID = locationID
+	}
+
+	var from, to *time.Time
+	if q.DateFrom != "" {
+		t, _ := time.Parse("2006-01-02", q.DateFrom)
+		from = &t
+	}
+	if q.DateTo != "" {
+		t, _ := time.Parse("2006-01-02", q.DateTo)
+		to = &t
+	}
+
+	list, err := s.repo.GetLaporan(ctx, from, to, q.LokasiID, q.JenisDurianID, q.AlasanKode)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.WriteOffLaporanResponse{Items: make([]response.WriteOffLaporanItem, 0, len(list))}
+	for _, l := range list {
+		res.Items = append(res.Items, response.WriteOffLaporanItem{
+			LokasiID:        l.LokasiID,
+			LokasiNama:      l.LokasiNama,
+			JenisDurianID:   l.JenisDurianID,
+			JenisDurianNama: l.JenisDurianNama,
+			AlasanKode:      l.AlasanKode,
+			Jumlah:          l.Jumlah,
+			Qty:             l.Qty,
+			Berat:           l.Berat,
+		})
+		res.TotalQty += l.Qty
+		res.TotalBerat += l.Berat
+	}
+	return res, nil
+}
+
+func (s *writeOffService) getWriteOff(ctx context.Context, id string) (*domain.LotWriteOff, error) {
+	wo, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wo == nil {
+		return nil, errors.NotFoundError("write-off tidak ditemukan")
+	}
+	return wo, nil
+}
+
+func (s *writeOffService) getPending(ctx context.Context, id string) (*domain.LotWriteOff, error) {
+	wo, err := s.getWriteOff(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wo.Status != constants.WriteOffStatusMenunggu {
+		return nil, errors.ValidationError("write-off sudah diputuskan")
+	}
+	return wo, nil
+}
+
+func keputusanCatatan(req requests.WriteOffKeputusanRequest) *string {
+	catatan := strings.TrimSpace(req.Catatan)
+	if catatan == "" {
+		return nil
+	}
+	return &catatan
+}
+
+func hasRole(user requests.UserAuth, role domain.UserRole) bool {
+	for _, r := range user.Role {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func toWriteOffResponse(wo *domain.LotWriteOff) response.WriteOffResponse {
+	res := response.WriteOffResponse{
+		ID:               wo.ID,
+		LotID:            wo.LotID,
+		LokasiID:         wo.LokasiID,
+		JenisDurianID:    wo.JenisDurianID,
+		AlasanKode:       wo.AlasanKode,
+		Qty:              wo.Qty,
+		Berat:            wo.Berat,
+		Catatan:          wo.Catatan,
+		Status:           wo.Status,
+		DiajukanOleh:     wo.DiajukanOleh,
+		DiputuskanOleh:   wo.DiputuskanOleh,
+		DiputuskanAt:     wo.DiputuskanAt,
+		CatatanKeputusan: wo.CatatanKeputusan,
+		CreatedAt:        wo.CreatedAt,
+	}
+	if wo.Lot != nil {
+		res.LotKode = wo.Lot.Kode
+	}
+	if wo.Lokasi != nil {
+		res.LokasiNama = wo.Lokasi.Nama
+	}
+	if wo.JenisDurian != nil {
+		res.JenisDurianNama = wo.JenisDurian.NamaJenis
+	}
+	if wo.Pengaju != nil {
+		res.Email = wo.Pengaju.Email
+	}
+	return res
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"strings"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"durich-be/pkg/authentication"
 	"durich-be/pkg/config"
 	"durich-be/pkg/database"
+	"durich-be/pkg/label"
+	"durich-be/pkg/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -34,26 +37,71 @@ func main() {
 
 	userRepo := repository.NewUserRepository(db)
 	authRepo := repository.NewAuthenticationRepository(db)
-	buahRawRepo := repository.NewBuahRawRepository(db)
+	sequenceRepo := repository.NewSequenceRepository(db)
+	buahRawRepo := repository.NewBuahRawRepository(db, sequenceRepo)
 	masterDataRepo := repository.NewMasterDataRepository(db)
-	lotRepo := repository.NewLotRepository(db)
-	shipmentRepo := repository.NewShipmentRepository(db)
+	lotRepo := repository.NewLotRepository(db, sequenceRepo)
+	shipmentRepo := repository.NewShipmentRepository(db, sequenceRepo)
 	tujuanPengirimanRepo := repository.NewTujuanPengirimanRepository(db)
 	salesRepo := repository.NewSalesRepository(db)
 	dashboardRepo := repository.NewDashboardRepository(db)
 	traceabilityRepo := repository.NewTraceabilityRepository(db)
+	kodeTemplateRepo := repository.NewKodeTemplateRepository(db)
+	kualitasRepo := repository.NewKualitasRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	pemanenRepo := repository.NewPemanenRepository(db)
+	timbanganRepo := repository.NewTimbanganRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	musimRepo := repository.NewMusimRepository(db)
+	gradeRepo := repository.NewGradeRepository(db)
+	sesiGradingRepo := repository.NewSesiGradingRepository(db, sequenceRepo)
+	writeOffRepo := repository.NewWriteOffRepository(db)
+	stokRepo := repository.NewStokRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+
+	fileStorage, err := storage.New(cfg.Storage.Driver, cfg.Storage.LocalPath)
+	if err != nil {
+		log.Fatalf("failed to init storage: %v", err)
+	}
+	maxUploadSize := cfg.Storage.MaxUploadSize
+	if maxUploadSize <= 0 {
+		maxUploadSize = 10 << 20
+	}
 
 	authService := services.NewAuthService(userRepo, authRepo)
 	profileService := services.NewProfileService(userRepo, authRepo)
 	memberService := services.NewMemberService(userRepo, authRepo)
-	buahRawService := services.NewBuahRawService(buahRawRepo)
+	buahRawService := services.NewBuahRawService(buahRawRepo, kodeTemplateRepo, kualitasRepo, pemanenRepo, musimRepo)
 	masterDataService := services.NewMasterDataService(masterDataRepo)
-	lotService := services.NewLotService(lotRepo, buahRawRepo)
-	shipmentService := services.NewShipmentService(shipmentRepo, tujuanPengirimanRepo)
+	lotService := services.NewLotService(lotRepo, buahRawRepo, kodeTemplateRepo, masterDataRepo, kualitasRepo, pemanenRepo, musimRepo, gradeRepo)
+	shipmentService := services.NewShipmentService(shipmentRepo, tujuanPengirimanRepo, kodeTemplateRepo, masterDataRepo, lotRepo)
 	tujuanPengirimanService := services.NewTujuanPengirimanService(tujuanPengirimanRepo)
-	salesService := services.NewSalesService(salesRepo)
-	dashboardService := services.NewDashboardService(dashboardRepo)
+	salesService := services.NewSalesService(salesRepo, musimRepo)
+	dashboardService := services.NewDashboardService(dashboardRepo, musimRepo, stokRepo)
 	traceabilityService := services.NewTraceabilityService(traceabilityRepo)
+	labelService := services.NewLabelService(buahRawRepo, lotRepo)
+	kodeTemplateService := services.NewKodeTemplateService(kodeTemplateRepo, masterDataRepo)
+	kualitasService := services.NewKualitasService(kualitasRepo, buahRawRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, fileStorage, maxUploadSize)
+	pemanenService := services.NewPemanenService(pemanenRepo, masterDataRepo, buahRawRepo)
+	timbanganService := services.NewTimbanganService(timbanganRepo, lotRepo, pemanenRepo, lotService, cfg.Scale.ReadTimeout, cfg.Scale.RetryInterval)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, musimRepo)
+	musimService := services.NewMusimService(musimRepo, masterDataRepo)
+	gradeService := services.NewGradeService(gradeRepo, buahRawRepo)
+	sesiGradingService := services.NewSesiGradingService(sesiGradingRepo, gradeRepo, musimRepo, buahRawRepo, kodeTemplateRepo, masterDataRepo, lotService)
+	writeOffService := services.NewWriteOffService(writeOffRepo, lotRepo, musimRepo, cfg.WriteOff.AmbangBerat)
+	stokService := services.NewStokService(stokRepo, cfg.StokSnapshot.Interval)
+	holdService := services.NewHoldService(holdRepo, lotRepo, cfg.LotHold.Interval, cfg.LotHold.DefaultDurasi, cfg.LotHold.MaksDurasi)
+
+	if cfg.Scale.Enabled {
+		go timbanganService.Run(context.Background())
+	}
+	if cfg.StokSnapshot.Enabled {
+		go stokService.RunSnapshot(context.Background())
+	}
+	if cfg.LotHold.Enabled {
+		go holdService.RunExpiry(context.Background())
+	}
 
 	authController := controllers.NewAuthController(authService)
 	profileController := controllers.NewProfileController(profileService)
@@ -66,6 +114,28 @@ func main() {
 	salesController := controllers.NewSalesController(salesService)
 	dashboardController := controllers.NewDashboardController(dashboardService)
 	traceabilityController := controllers.NewTraceabilityController(traceabilityService)
+	kodeTemplateController := controllers.NewKodeTemplateController(kodeTemplateService)
+	kualitasController := controllers.NewKualitasController(kualitasService)
+	attachmentController := controllers.NewAttachmentController(attachmentService)
+	pemanenController := controllers.NewPemanenController(pemanenService)
+	timbanganController := controllers.NewTimbanganController(timbanganService)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	musimController := controllers.NewMusimController(musimService)
+	gradeController := controllers.NewGradeController(gradeService)
+	sesiGradingController := controllers.NewSesiGradingController(sesiGradingService)
+	writeOffController := controllers.NewWriteOffController(writeOffService)
+	stokController := controllers.NewStokController(stokService)
+	holdController := controllers.NewHoldController(holdService)
+	printerProfiles := make([]label.PrinterProfile, 0, len(cfg.Label.Printers))
+	for _, p := range cfg.Label.Printers {
+		printerProfiles = append(printerProfiles, label.PrinterProfile{
+			Name:     p.Name,
+			WidthMM:  p.WidthMM,
+			HeightMM: p.HeightMM,
+			DPI:      p.DPI,
+		})
+	}
+	labelController := controllers.NewLabelController(labelService, label.NewPrinterProfiles(cfg.Label.DefaultPrinter, printerProfiles))
 
 	router := gin.Default()
 
@@ -95,6 +165,19 @@ func main() {
 	routes.RegisterSales(v1, salesController)
 	routes.RegisterDashboard(v1, dashboardController)
 	routes.RegisterTraceability(v1, traceabilityController)
+	routes.RegisterLabel(v1, labelController)
+	routes.RegisterKodeTemplate(v1, kodeTemplateController)
+	routes.RegisterKualitas(v1, kualitasController)
+	routes.RegisterAttachment(v1, attachmentController)
+	routes.RegisterPemanen(v1, pemanenController)
+	routes.RegisterTimbangan(v1, timbanganController)
+	routes.RegisterAnalytics(v1, analyticsController)
+	routes.RegisterMusim(v1, musimController)
+	routes.RegisterGrade(v1, gradeController)
+	routes.RegisterSesiGrading(v1, sesiGradingController)
+	routes.RegisterWriteOff(v1, writeOffController)
+	routes.RegisterStok(v1, stokController)
+	routes.RegisterHold(v1, holdController)
 
 	log.Printf("Server running on port %s", cfg.Server.Port)
 	log.Fatal(router.Run(":" + cfg.Server.Port))
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_buah_raw_device_id;
+
+ALTER TABLE tb_buah_raw DROP COLUMN recorded_at;
+ALTER TABLE tb_buah_raw DROP COLUMN device_id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE tb_buah_raw ADD COLUMN device_id TEXT;
+ALTER TABLE tb_buah_raw ADD COLUMN recorded_at TIMESTAMPTZ;
+
+CREATE INDEX idx_buah_raw_device_id ON tb_buah_raw(device_id);
\ No newline at end of file
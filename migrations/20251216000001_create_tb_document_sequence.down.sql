@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tb_document_sequence;
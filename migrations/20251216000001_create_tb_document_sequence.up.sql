@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS tb_document_sequence (
+    prefix VARCHAR(100) NOT NULL,
+    period VARCHAR(20) NOT NULL DEFAULT '',
+    last_value INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (prefix, period)
+);
+
+-- Continue numbering after the codes that were generated before counters existed
+
+-- Buah: <location>-F00001, never resets
+INSERT INTO tb_document_sequence (prefix, period, last_value)
+SELECT substring(kode_buah from '^(.*-F)[0-9]+$'),
+       '',
+       MAX(CAST(substring(kode_buah from '-F([0-9]+)$') AS INTEGER))
+FROM tb_buah_raw
+WHERE kode_buah ~ '-F[0-9]+$'
+GROUP BY 1
+ON CONFLICT (prefix, period) DO NOTHING;
+
+-- Lot: LOT-<jenis>-<grade>-DDMMYY-01, resets daily
+INSERT INTO tb_document_sequence (prefix, period, last_value)
+SELECT substring(kode from '^(LOT-.*-[0-9]{6}-)[0-9]+$'),
+       to_char(to_date(substring(kode from '-([0-9]{6})-[0-9]+$'), 'DDMMYY'), 'YYYY-MM-DD'),
+       MAX(CAST(substring(kode from '-([0-9]+)$') AS INTEGER))
+FROM tb_stok_lot
+WHERE kode ~ '^LOT-.*-[0-9]{6}-[0-9]+$'
+GROUP BY 1, 2
+ON CONFLICT (prefix, period) DO NOTHING;
+
+-- Shipment: SHP-YYMMDD-001, resets daily
+INSERT INTO tb_document_sequence (prefix, period, last_value)
+SELECT substring(kode from '^(SHP-[0-9]{6}-)[0-9]+$'),
+       to_char(to_date(substring(kode from '^SHP-([0-9]{6})-'), 'YYMMDD'), 'YYYY-MM-DD'),
+       MAX(CAST(substring(kode from '-([0-9]+)$') AS INTEGER))
+FROM tb_pengiriman
+WHERE kode ~ '^SHP-[0-9]{6}-[0-9]+$'
+GROUP BY 1, 2
+ON CONFLICT (prefix, period) DO NOTHING;
@@ -0,0 +1,9 @@
+UPDATE tb_document_sequence
+SET prefix = 'SHP-' || to_char(to_date(period, 'YYYY-MM-DD'), 'YYMMDD') || '-'
+WHERE prefix = 'SHP-{YY}{MM}{DD}-';
+
+UPDATE tb_document_sequence
+SET prefix = replace(prefix, '{DD}{MM}{YY}', to_char(to_date(period, 'YYYY-MM-DD'), 'DDMMYY'))
+WHERE prefix ~ '^LOT-.*-\{DD\}\{MM\}\{YY\}-$';
+
+DROP TABLE IF EXISTS tb_kode_template;
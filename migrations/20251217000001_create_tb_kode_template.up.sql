@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS tb_kode_template (
+    id VARCHAR(27) PRIMARY KEY,
+    company_id VARCHAR(27) REFERENCES company(id),
+    tipe VARCHAR(20) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    reset VARCHAR(20) NOT NULL DEFAULT 'NONE',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+-- One active template per company and document type, company_id NULL is the group default
+CREATE UNIQUE INDEX idx_kode_template_company_tipe
+    ON tb_kode_template (COALESCE(company_id, ''), tipe)
+    WHERE deleted_at IS NULL;
+
+-- Counters are now keyed by the template with date tokens left unrendered, the period carries the date
+UPDATE tb_document_sequence
+SET prefix = regexp_replace(prefix, '-[0-9]{6}-$', '-{DD}{MM}{YY}-')
+WHERE prefix ~ '^LOT-.*-[0-9]{6}-$';
+
+UPDATE tb_document_sequence
+SET prefix = 'SHP-{YY}{MM}{DD}-'
+WHERE prefix ~ '^SHP-[0-9]{6}-$';
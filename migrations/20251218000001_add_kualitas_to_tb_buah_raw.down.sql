@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS tb_jenis_durian_kualitas;
+
+DROP INDEX IF EXISTS idx_buah_raw_cacat;
+DROP INDEX IF EXISTS idx_buah_raw_metode_panen;
+DROP INDEX IF EXISTS idx_buah_raw_tingkat_kematangan;
+
+ALTER TABLE tb_buah_raw DROP COLUMN metode_panen;
+ALTER TABLE tb_buah_raw DROP COLUMN skor_aroma;
+ALTER TABLE tb_buah_raw DROP COLUMN cacat;
+ALTER TABLE tb_buah_raw DROP COLUMN tingkat_kematangan;
@@ -0,0 +1,22 @@
+ALTER TABLE tb_buah_raw ADD COLUMN tingkat_kematangan TEXT;
+ALTER TABLE tb_buah_raw ADD COLUMN cacat TEXT[];
+ALTER TABLE tb_buah_raw ADD COLUMN skor_aroma SMALLINT;
+ALTER TABLE tb_buah_raw ADD COLUMN metode_panen TEXT;
+
+CREATE INDEX idx_buah_raw_tingkat_kematangan ON tb_buah_raw(tingkat_kematangan);
+CREATE INDEX idx_buah_raw_metode_panen ON tb_buah_raw(metode_panen);
+CREATE INDEX idx_buah_raw_cacat ON tb_buah_raw USING GIN (cacat);
+
+CREATE TABLE tb_jenis_durian_kualitas (
+    jenis_durian_id VARCHAR(27) PRIMARY KEY,
+    kematangan_opsi TEXT[] NOT NULL,
+    cacat_opsi TEXT[] NOT NULL,
+    metode_panen_opsi TEXT[] NOT NULL,
+    skor_aroma_min INT NOT NULL,
+    skor_aroma_max INT NOT NULL,
+    atribut_wajib TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_jenis_durian_kualitas_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id),
+    CONSTRAINT chk_jenis_durian_kualitas_skor CHECK (skor_aroma_min <= skor_aroma_max)
+);
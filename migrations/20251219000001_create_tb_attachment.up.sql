@@ -0,0 +1,18 @@
+CREATE TABLE tb_attachment (
+    id VARCHAR(27) PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id VARCHAR(27) NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT,
+    keterangan TEXT,
+    uploaded_by VARCHAR(27) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_attachment_uploader FOREIGN KEY (uploaded_by) REFERENCES users(id),
+    CONSTRAINT chk_attachment_entity_type CHECK (entity_type IN ('buah', 'lot', 'shipment', 'sales'))
+);
+
+CREATE INDEX idx_attachment_entity ON tb_attachment(entity_type, entity_id) WHERE deleted_at IS NULL;
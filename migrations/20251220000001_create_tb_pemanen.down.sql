@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_buah_raw_pemanen;
+ALTER TABLE tb_buah_raw DROP CONSTRAINT IF EXISTS fk_buah_raw_pemanen;
+ALTER TABLE tb_buah_raw DROP COLUMN IF EXISTS pemanen_id;
+
+DROP TABLE IF EXISTS tb_tarif_panen;
+DROP TABLE IF EXISTS tb_pemanen;
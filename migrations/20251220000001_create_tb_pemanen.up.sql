@@ -0,0 +1,34 @@
+CREATE TABLE tb_pemanen (
+    id VARCHAR(27) PRIMARY KEY,
+    kode TEXT UNIQUE NOT NULL,
+    nama TEXT NOT NULL,
+    estate_id VARCHAR(27) NOT NULL,
+    divisi_id VARCHAR(27),
+    aktif BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_pemanen_estate FOREIGN KEY (estate_id) REFERENCES estate(id),
+    CONSTRAINT fk_pemanen_divisi FOREIGN KEY (divisi_id) REFERENCES divisi(id)
+);
+
+CREATE INDEX idx_pemanen_estate ON tb_pemanen(estate_id);
+
+CREATE TABLE tb_tarif_panen (
+    id VARCHAR(27) PRIMARY KEY,
+    jenis_durian_id VARCHAR(27) NOT NULL,
+    grade TEXT NOT NULL DEFAULT '',
+    tarif_per_buah DECIMAL(12,2) NOT NULL DEFAULT 0,
+    tarif_per_kg DECIMAL(12,2) NOT NULL DEFAULT 0,
+    berlaku_mulai DATE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_tarif_panen_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id)
+);
+
+CREATE UNIQUE INDEX idx_tarif_panen_unique ON tb_tarif_panen(jenis_durian_id, grade, berlaku_mulai) WHERE deleted_at IS NULL;
+
+ALTER TABLE tb_buah_raw ADD COLUMN pemanen_id VARCHAR(27);
+ALTER TABLE tb_buah_raw ADD CONSTRAINT fk_buah_raw_pemanen FOREIGN KEY (pemanen_id) REFERENCES tb_pemanen(id);
+CREATE INDEX idx_buah_raw_pemanen ON tb_buah_raw(pemanen_id, tgl_panen);
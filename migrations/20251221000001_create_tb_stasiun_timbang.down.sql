@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tb_pembacaan_timbang;
+DROP TABLE IF EXISTS tb_stasiun_timbang;
@@ -0,0 +1,41 @@
+CREATE TABLE tb_stasiun_timbang (
+    id VARCHAR(27) PRIMARY KEY,
+    kode TEXT UNIQUE NOT NULL,
+    nama TEXT NOT NULL,
+    alamat TEXT NOT NULL,
+    protokol TEXT NOT NULL,
+    transport TEXT NOT NULL DEFAULT 'tcp',
+    min_berat DECIMAL(10,3) NOT NULL DEFAULT 0.5,
+    toleransi DECIMAL(10,3) NOT NULL DEFAULT 0.02,
+    sampel INTEGER NOT NULL DEFAULT 5,
+    aktif BOOLEAN NOT NULL DEFAULT TRUE,
+    lot_id VARCHAR(27),
+    blok_id VARCHAR(27),
+    pohon_kode TEXT,
+    pemanen_id VARCHAR(27),
+    sesi_oleh VARCHAR(27),
+    sesi_mulai_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_stasiun_timbang_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_stasiun_timbang_blok FOREIGN KEY (blok_id) REFERENCES blok(id),
+    CONSTRAINT fk_stasiun_timbang_pemanen FOREIGN KEY (pemanen_id) REFERENCES tb_pemanen(id)
+);
+
+CREATE TABLE tb_pembacaan_timbang (
+    id VARCHAR(27) PRIMARY KEY,
+    stasiun_id VARCHAR(27) NOT NULL,
+    lot_id VARCHAR(27),
+    berat DECIMAL(10,3) NOT NULL,
+    raw TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    buah_raw_id VARCHAR(27),
+    kode_buah TEXT,
+    pesan TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_pembacaan_timbang_stasiun FOREIGN KEY (stasiun_id) REFERENCES tb_stasiun_timbang(id)
+);
+
+CREATE INDEX idx_pembacaan_timbang_stasiun ON tb_pembacaan_timbang(stasiun_id, created_at DESC);
+CREATE INDEX idx_pembacaan_timbang_lot ON tb_pembacaan_timbang(lot_id);
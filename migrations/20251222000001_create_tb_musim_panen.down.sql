@@ -0,0 +1,5 @@
+ALTER TABLE tb_penjualan DROP COLUMN IF EXISTS musim_id;
+ALTER TABLE tb_stok_lot DROP COLUMN IF EXISTS musim_id;
+ALTER TABLE tb_buah_raw DROP COLUMN IF EXISTS musim_id;
+
+DROP TABLE IF EXISTS tb_musim_panen;
@@ -0,0 +1,32 @@
+CREATE TABLE tb_musim_panen (
+    id VARCHAR(27) PRIMARY KEY,
+    kode TEXT UNIQUE NOT NULL,
+    nama TEXT NOT NULL,
+    estate_id VARCHAR(27) NOT NULL,
+    tanggal_mulai DATE NOT NULL,
+    tanggal_selesai DATE,
+    status TEXT NOT NULL DEFAULT 'AKTIF',
+    ringkasan JSONB,
+    ditutup_at TIMESTAMPTZ,
+    ditutup_oleh VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_musim_panen_estate FOREIGN KEY (estate_id) REFERENCES estate(id),
+    CONSTRAINT chk_musim_panen_tanggal CHECK (tanggal_selesai IS NULL OR tanggal_selesai >= tanggal_mulai)
+);
+
+-- One open season per estate
+CREATE UNIQUE INDEX idx_musim_panen_aktif ON tb_musim_panen(estate_id) WHERE status = 'AKTIF' AND deleted_at IS NULL;
+
+ALTER TABLE tb_buah_raw ADD COLUMN musim_id VARCHAR(27);
+ALTER TABLE tb_buah_raw ADD CONSTRAINT fk_buah_raw_musim FOREIGN KEY (musim_id) REFERENCES tb_musim_panen(id);
+CREATE INDEX idx_buah_raw_musim ON tb_buah_raw(musim_id);
+
+ALTER TABLE tb_stok_lot ADD COLUMN musim_id VARCHAR(27);
+ALTER TABLE tb_stok_lot ADD CONSTRAINT fk_stok_lot_musim FOREIGN KEY (musim_id) REFERENCES tb_musim_panen(id);
+CREATE INDEX idx_stok_lot_musim ON tb_stok_lot(musim_id);
+
+ALTER TABLE tb_penjualan ADD COLUMN musim_id VARCHAR(27);
+ALTER TABLE tb_penjualan ADD CONSTRAINT fk_penjualan_musim FOREIGN KEY (musim_id) REFERENCES tb_musim_panen(id);
+CREATE INDEX idx_penjualan_musim ON tb_penjualan(musim_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tb_lot_genealogi_buah;
+DROP TABLE IF EXISTS tb_lot_genealogi;
@@ -0,0 +1,26 @@
+CREATE TABLE tb_lot_genealogi (
+    id VARCHAR(27) PRIMARY KEY,
+    tipe TEXT NOT NULL,
+    parent_lot_id VARCHAR(27) NOT NULL,
+    child_lot_id VARCHAR(27) NOT NULL,
+    qty INTEGER NOT NULL DEFAULT 0,
+    berat NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_genealogi_parent FOREIGN KEY (parent_lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_genealogi_child FOREIGN KEY (child_lot_id) REFERENCES tb_stok_lot(id)
+);
+
+CREATE INDEX idx_lot_genealogi_parent ON tb_lot_genealogi(parent_lot_id);
+CREATE INDEX idx_lot_genealogi_child ON tb_lot_genealogi(child_lot_id);
+
+-- Fruits moved by each split or merge, so a fruit's path through lots can be traced
+CREATE TABLE tb_lot_genealogi_buah (
+    genealogi_id VARCHAR(27) NOT NULL,
+    buah_raw_id VARCHAR(27) NOT NULL,
+    PRIMARY KEY (genealogi_id, buah_raw_id),
+    CONSTRAINT fk_lot_genealogi_buah_genealogi FOREIGN KEY (genealogi_id) REFERENCES tb_lot_genealogi(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_genealogi_buah_buah FOREIGN KEY (buah_raw_id) REFERENCES tb_buah_raw(id)
+);
+
+CREATE INDEX idx_lot_genealogi_buah_buah ON tb_lot_genealogi_buah(buah_raw_id);
@@ -0,0 +1 @@
+ALTER TABLE tb_pengiriman_detail DROP COLUMN IF EXISTS parsial;
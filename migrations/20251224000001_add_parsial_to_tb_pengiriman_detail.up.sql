@@ -0,0 +1,2 @@
+-- A partial detail took only part of its lot, the rest stays READY at the origin
+ALTER TABLE tb_pengiriman_detail ADD COLUMN parsial BOOLEAN NOT NULL DEFAULT false;
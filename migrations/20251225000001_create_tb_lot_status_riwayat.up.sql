@@ -0,0 +1,14 @@
+-- Every lot status change, status_dari is empty when the lot was created
+CREATE TABLE tb_lot_status_riwayat (
+    id VARCHAR(27) PRIMARY KEY,
+    lot_id VARCHAR(27) NOT NULL,
+    status_dari TEXT,
+    status_ke TEXT NOT NULL,
+    alasan TEXT,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_status_riwayat_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_status_riwayat_user FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_lot_status_riwayat_lot ON tb_lot_status_riwayat(lot_id, created_at);
@@ -0,0 +1,15 @@
+-- Grade changes of lots and fruits. The fruits move along the linked genealogy record,
+-- from the original lot into a lot of the new grade.
+CREATE TABLE tb_lot_regrade (
+    id VARCHAR(27) PRIMARY KEY,
+    genealogi_id VARCHAR(27) NOT NULL,
+    kondisi_dari TEXT NOT NULL,
+    kondisi_ke TEXT NOT NULL,
+    alasan TEXT NOT NULL,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_regrade_genealogi FOREIGN KEY (genealogi_id) REFERENCES tb_lot_genealogi(id),
+    CONSTRAINT fk_lot_regrade_user FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_lot_regrade_genealogi ON tb_lot_regrade(genealogi_id);
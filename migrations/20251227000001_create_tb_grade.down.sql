@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tb_grade_aturan;
+DROP TABLE IF EXISTS tb_grade;
@@ -0,0 +1,35 @@
+CREATE TABLE tb_grade (
+    id VARCHAR(27) PRIMARY KEY,
+    kode TEXT NOT NULL,
+    nama TEXT NOT NULL,
+    urutan INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_grade_kode ON tb_grade(kode) WHERE deleted_at IS NULL;
+
+-- Per-jenis rules used to suggest a grade for a fruit. Empty columns do not constrain.
+CREATE TABLE tb_grade_aturan (
+    id VARCHAR(27) PRIMARY KEY,
+    grade_id VARCHAR(27) NOT NULL,
+    jenis_durian_id VARCHAR(27) NOT NULL,
+    berat_min NUMERIC(10, 2),
+    berat_max NUMERIC(10, 2),
+    kematangan_opsi TEXT[] NOT NULL DEFAULT '{}',
+    cacat_dilarang TEXT[] NOT NULL DEFAULT '{}',
+    cacat_maks INT,
+    skor_aroma_min INT,
+    CONSTRAINT fk_grade_aturan_grade FOREIGN KEY (grade_id) REFERENCES tb_grade(id) ON DELETE CASCADE,
+    CONSTRAINT fk_grade_aturan_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id),
+    CONSTRAINT uq_grade_aturan_jenis UNIQUE (grade_id, jenis_durian_id),
+    CONSTRAINT chk_grade_aturan_berat CHECK (berat_min IS NULL OR berat_max IS NULL OR berat_min <= berat_max)
+);
+
+CREATE INDEX idx_grade_aturan_jenis ON tb_grade_aturan(jenis_durian_id);
+
+INSERT INTO tb_grade (id, kode, nama, urutan) VALUES
+('2GRDQ8zX9vJ2mN5P6Q7R8S9T001', 'A', 'Grade A', 1),
+('2GRDQ8zX9vJ2mN5P6Q7R8S9T002', 'B', 'Grade B', 2),
+('2GRDQ8zX9vJ2mN5P6Q7R8S9T003', 'C', 'Grade C', 3);
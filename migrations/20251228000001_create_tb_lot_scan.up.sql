@@ -0,0 +1,14 @@
+-- Fruits that joined a lot by scanning their kode_buah. Removing such a fruit from the lot
+-- releases it back to unsorted instead of deleting the harvest record.
+CREATE TABLE tb_lot_scan (
+    id VARCHAR(27) PRIMARY KEY,
+    lot_id VARCHAR(27) NOT NULL,
+    buah_raw_id VARCHAR(27) NOT NULL,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_scan_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_scan_buah FOREIGN KEY (buah_raw_id) REFERENCES tb_buah_raw(id),
+    CONSTRAINT fk_lot_scan_user FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_lot_scan_lot ON tb_lot_scan(lot_id, buah_raw_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tb_sesi_grading_lot;
+DROP TABLE IF EXISTS tb_sesi_grading;
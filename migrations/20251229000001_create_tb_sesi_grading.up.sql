@@ -0,0 +1,35 @@
+-- A grading session sorts unsorted fruit of one jenis into one DRAFT lot per grade.
+-- terdaftar_* is the registered fruit the session drew from, frozen when it is closed.
+CREATE TABLE tb_sesi_grading (
+    id VARCHAR(27) PRIMARY KEY,
+    jenis_durian_id VARCHAR(27) NOT NULL,
+    estate_id VARCHAR(27),
+    musim_id VARCHAR(27),
+    status TEXT NOT NULL DEFAULT 'BUKA',
+    catatan TEXT,
+    terdaftar_qty INT NOT NULL DEFAULT 0,
+    terdaftar_berat NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    dibuka_oleh VARCHAR(27),
+    ditutup_oleh VARCHAR(27),
+    ditutup_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_sesi_grading_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id),
+    CONSTRAINT fk_sesi_grading_estate FOREIGN KEY (estate_id) REFERENCES estate(id),
+    CONSTRAINT fk_sesi_grading_musim FOREIGN KEY (musim_id) REFERENCES tb_musim_panen(id),
+    CONSTRAINT fk_sesi_grading_dibuka FOREIGN KEY (dibuka_oleh) REFERENCES users(id),
+    CONSTRAINT fk_sesi_grading_ditutup FOREIGN KEY (ditutup_oleh) REFERENCES users(id)
+);
+
+CREATE INDEX idx_sesi_grading_status ON tb_sesi_grading(status, created_at DESC);
+
+CREATE TABLE tb_sesi_grading_lot (
+    sesi_id VARCHAR(27) NOT NULL,
+    lot_id VARCHAR(27) NOT NULL,
+    kondisi_buah TEXT NOT NULL,
+    PRIMARY KEY (sesi_id, lot_id),
+    CONSTRAINT uq_sesi_grading_lot_grade UNIQUE (sesi_id, kondisi_buah),
+    CONSTRAINT uq_sesi_grading_lot_lot UNIQUE (lot_id),
+    CONSTRAINT fk_sesi_grading_lot_sesi FOREIGN KEY (sesi_id) REFERENCES tb_sesi_grading(id),
+    CONSTRAINT fk_sesi_grading_lot_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id)
+);
@@ -0,0 +1,28 @@
+-- Stock written off a READY lot. lokasi_id and jenis_durian_id are copied from the lot so the
+-- loss report keeps the place the loss happened after the lot moves on.
+CREATE TABLE tb_lot_writeoff (
+    id VARCHAR(27) PRIMARY KEY,
+    lot_id VARCHAR(27) NOT NULL,
+    lokasi_id VARCHAR(27),
+    jenis_durian_id VARCHAR(27) NOT NULL,
+    alasan_kode TEXT NOT NULL,
+    qty INT NOT NULL DEFAULT 0,
+    berat NUMERIC(10, 2) NOT NULL,
+    catatan TEXT,
+    status TEXT NOT NULL,
+    diajukan_oleh VARCHAR(27),
+    diputuskan_oleh VARCHAR(27),
+    diputuskan_at TIMESTAMPTZ,
+    catatan_keputusan TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_writeoff_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_writeoff_lokasi FOREIGN KEY (lokasi_id) REFERENCES tb_tujuan_pengiriman(id),
+    CONSTRAINT fk_lot_writeoff_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id),
+    CONSTRAINT fk_lot_writeoff_diajukan FOREIGN KEY (diajukan_oleh) REFERENCES users(id),
+    CONSTRAINT fk_lot_writeoff_diputuskan FOREIGN KEY (diputuskan_oleh) REFERENCES users(id),
+    CONSTRAINT chk_lot_writeoff_jumlah CHECK (qty >= 0 AND berat > 0)
+);
+
+CREATE INDEX idx_lot_writeoff_lot ON tb_lot_writeoff(lot_id);
+CREATE INDEX idx_lot_writeoff_status ON tb_lot_writeoff(status, created_at DESC);
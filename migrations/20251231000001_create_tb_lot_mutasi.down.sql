@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_lot_mutasi_append_only ON tb_lot_mutasi;
+DROP FUNCTION IF EXISTS tb_lot_mutasi_append_only();
+DROP TABLE IF EXISTS tb_lot_mutasi;
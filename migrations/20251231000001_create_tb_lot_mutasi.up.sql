@@ -0,0 +1,38 @@
+-- Append-only stock ledger. Every change of a lot's qty_sisa/berat_sisa is one row, so the
+-- deltas of a lot add up to its current balance. urutan orders rows written in one transaction.
+CREATE TABLE tb_lot_mutasi (
+    id VARCHAR(27) PRIMARY KEY,
+    urutan BIGSERIAL NOT NULL,
+    lot_id VARCHAR(27) NOT NULL,
+    lokasi_id VARCHAR(27),
+    tipe TEXT NOT NULL,
+    delta_qty INT NOT NULL,
+    delta_berat NUMERIC(12, 2) NOT NULL,
+    referensi_tipe TEXT,
+    referensi_id VARCHAR(27),
+    referensi_kode TEXT,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_mutasi_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_mutasi_lokasi FOREIGN KEY (lokasi_id) REFERENCES tb_tujuan_pengiriman(id),
+    CONSTRAINT fk_lot_mutasi_user FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_lot_mutasi_lot ON tb_lot_mutasi(lot_id, urutan);
+
+CREATE FUNCTION tb_lot_mutasi_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'tb_lot_mutasi hanya bisa ditambah';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_lot_mutasi_append_only
+BEFORE UPDATE OR DELETE ON tb_lot_mutasi
+FOR EACH ROW EXECUTE FUNCTION tb_lot_mutasi_append_only();
+
+-- Opening balance of the lots that already hold stock
+INSERT INTO tb_lot_mutasi (id, lot_id, lokasi_id, tipe, delta_qty, delta_berat)
+SELECT LEFT('SA' || md5(id), 27), id, current_location_id, 'SALDO_AWAL', qty_sisa, berat_sisa
+FROM tb_stok_lot
+WHERE deleted_at IS NULL AND (qty_sisa <> 0 OR berat_sisa <> 0)
+ORDER BY created_at;
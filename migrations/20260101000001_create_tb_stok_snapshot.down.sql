@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_lot_mutasi_created;
+DROP TABLE IF EXISTS tb_stok_snapshot_item;
+DROP TABLE IF EXISTS tb_stok_snapshot;
@@ -0,0 +1,27 @@
+-- Stock held at the end of a day, per location, season, jenis, grade and lot status. A day
+-- has a tb_stok_snapshot row once it is taken, even when no stock was held.
+CREATE TABLE tb_stok_snapshot (
+    tanggal DATE PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE tb_stok_snapshot_item (
+    id VARCHAR(27) PRIMARY KEY,
+    tanggal DATE NOT NULL,
+    lokasi_id VARCHAR(27),
+    musim_id VARCHAR(27),
+    jenis_durian_id VARCHAR(27) NOT NULL,
+    kondisi_buah TEXT NOT NULL,
+    status TEXT NOT NULL,
+    lot_count INT NOT NULL,
+    qty INT NOT NULL,
+    berat NUMERIC(12, 2) NOT NULL,
+    CONSTRAINT fk_stok_snapshot_item_tanggal FOREIGN KEY (tanggal) REFERENCES tb_stok_snapshot(tanggal) ON DELETE CASCADE,
+    CONSTRAINT fk_stok_snapshot_item_lokasi FOREIGN KEY (lokasi_id) REFERENCES tb_tujuan_pengiriman(id),
+    CONSTRAINT fk_stok_snapshot_item_jenis FOREIGN KEY (jenis_durian_id) REFERENCES jenis_durian(id)
+);
+
+CREATE INDEX idx_stok_snapshot_item_tanggal ON tb_stok_snapshot_item(tanggal);
+
+-- Replaying the ledger up to a moment
+CREATE INDEX idx_lot_mutasi_created ON tb_lot_mutasi(created_at);
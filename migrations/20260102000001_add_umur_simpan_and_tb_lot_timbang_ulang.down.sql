@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tb_lot_timbang_ulang;
+ALTER TABLE tb_stok_lot DROP COLUMN IF EXISTS finalized_at;
+ALTER TABLE jenis_durian DROP COLUMN IF EXISTS batas_peringatan_jam;
+ALTER TABLE jenis_durian DROP COLUMN IF EXISTS umur_simpan_jam;
@@ -0,0 +1,34 @@
+-- Shelf life per jenis, in hours from when a lot is stored. A jenis without it never expires.
+ALTER TABLE jenis_durian ADD COLUMN umur_simpan_jam INT;
+ALTER TABLE jenis_durian ADD COLUMN batas_peringatan_jam INT;
+
+-- When a lot became READY, copied to the lots split, merged or received from it
+ALTER TABLE tb_stok_lot ADD COLUMN finalized_at TIMESTAMPTZ;
+
+UPDATE tb_stok_lot AS sl
+SET finalized_at = COALESCE(
+    (SELECT MIN(rw.created_at) FROM tb_lot_status_riwayat AS rw WHERE rw.lot_id = sl.id AND rw.status_ke = 'READY'),
+    sl.updated_at)
+WHERE sl.status <> 'DRAFT';
+
+-- A lot weighed again while stored. berat_acuan is the share of berat_awal of the fruits still
+-- in the lot, susut the weight lost against it.
+CREATE TABLE tb_lot_timbang_ulang (
+    id VARCHAR(27) PRIMARY KEY,
+    lot_id VARCHAR(27) NOT NULL,
+    lokasi_id VARCHAR(27),
+    qty INT NOT NULL,
+    berat_acuan NUMERIC(12, 2) NOT NULL,
+    berat_sebelum NUMERIC(12, 2) NOT NULL,
+    berat NUMERIC(12, 2) NOT NULL,
+    susut NUMERIC(12, 2) NOT NULL,
+    catatan TEXT,
+    created_by VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_timbang_ulang_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_timbang_ulang_lokasi FOREIGN KEY (lokasi_id) REFERENCES tb_tujuan_pengiriman(id),
+    CONSTRAINT fk_lot_timbang_ulang_user FOREIGN KEY (created_by) REFERENCES users(id),
+    CONSTRAINT chk_lot_timbang_ulang_berat CHECK (berat > 0)
+);
+
+CREATE INDEX idx_lot_timbang_ulang_lot ON tb_lot_timbang_ulang(lot_id, created_at);
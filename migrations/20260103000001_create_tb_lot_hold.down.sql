@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tb_lot_hold;
@@ -0,0 +1,28 @@
+-- A READY lot held for a customer until berakhir_at. Only its holder can add it to a shipment
+-- while it is AKTIF, which turns the hold DIKIRIM.
+CREATE TABLE tb_lot_hold (
+    id VARCHAR(27) PRIMARY KEY,
+    lot_id VARCHAR(27) NOT NULL,
+    lokasi_id VARCHAR(27),
+    pelanggan VARCHAR(255) NOT NULL,
+    catatan TEXT,
+    berakhir_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'AKTIF',
+    dipegang_oleh VARCHAR(27) NOT NULL,
+    dilepas_oleh VARCHAR(27),
+    dilepas_at TIMESTAMPTZ,
+    pengiriman_id VARCHAR(27),
+    pengiriman_detail_id VARCHAR(27),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_lot_hold_lot FOREIGN KEY (lot_id) REFERENCES tb_stok_lot(id),
+    CONSTRAINT fk_lot_hold_lokasi FOREIGN KEY (lokasi_id) REFERENCES tb_tujuan_pengiriman(id),
+    CONSTRAINT fk_lot_hold_pemegang FOREIGN KEY (dipegang_oleh) REFERENCES users(id),
+    CONSTRAINT fk_lot_hold_pelepas FOREIGN KEY (dilepas_oleh) REFERENCES users(id),
+    CONSTRAINT fk_lot_hold_pengiriman FOREIGN KEY (pengiriman_id) REFERENCES tb_pengiriman(id),
+    CONSTRAINT chk_lot_hold_status CHECK (status IN ('AKTIF', 'DILEPAS', 'KEDALUWARSA', 'DIKIRIM'))
+);
+
+CREATE UNIQUE INDEX uq_lot_hold_aktif ON tb_lot_hold(lot_id) WHERE status = 'AKTIF';
+CREATE INDEX idx_lot_hold_berakhir ON tb_lot_hold(berakhir_at) WHERE status = 'AKTIF';
+CREATE INDEX idx_lot_hold_detail ON tb_lot_hold(pengiriman_detail_id);
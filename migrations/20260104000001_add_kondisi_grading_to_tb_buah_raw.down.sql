@@ -0,0 +1 @@
+ALTER TABLE tb_buah_raw DROP COLUMN IF EXISTS kondisi_grading;
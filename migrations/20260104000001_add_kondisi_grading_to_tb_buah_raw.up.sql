@@ -0,0 +1,17 @@
+-- Grade a fruit got when its lot was finalized, later regrades leave it alone. Harvester pay
+-- is counted on it so a closed period does not change.
+ALTER TABLE tb_buah_raw ADD COLUMN kondisi_grading TEXT;
+
+-- Fruits already graded take the grade they had before their first regrade, else their lot's
+UPDATE tb_buah_raw AS br
+SET kondisi_grading = COALESCE(
+    (SELECT rg.kondisi_dari
+     FROM tb_lot_genealogi_buah AS gb
+     JOIN tb_lot_regrade AS rg ON rg.genealogi_id = gb.genealogi_id
+     WHERE gb.buah_raw_id = br.id
+     ORDER BY rg.created_at
+     LIMIT 1),
+    sl.kondisi_buah)
+FROM tb_stok_lot AS sl
+WHERE sl.id = br.lot_id
+  AND sl.status <> 'DRAFT';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_buah_raw_writeoff;
+ALTER TABLE tb_buah_raw DROP CONSTRAINT IF EXISTS fk_buah_raw_writeoff;
+ALTER TABLE tb_buah_raw DROP COLUMN IF EXISTS writeoff_id;
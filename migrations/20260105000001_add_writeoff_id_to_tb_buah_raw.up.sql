@@ -0,0 +1,6 @@
+-- Write-off that took a fruit out of stock. The fruit keeps its lot so it can still be traced,
+-- but it no longer counts as a fruit the lot holds.
+ALTER TABLE tb_buah_raw ADD COLUMN writeoff_id VARCHAR(27);
+ALTER TABLE tb_buah_raw ADD CONSTRAINT fk_buah_raw_writeoff FOREIGN KEY (writeoff_id) REFERENCES tb_lot_writeoff(id);
+
+CREATE INDEX idx_buah_raw_writeoff ON tb_buah_raw(writeoff_id);
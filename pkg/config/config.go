@@ -13,6 +13,12 @@ type Config struct {
 	JWT            JWTConfig            `mapstructure:"jwt"`
 	App            AppConfig            `mapstructure:"app"`
 	Authentication AuthenticationConfig `mapstructure:"authentication"`
+	Label          LabelConfig          `mapstructure:"label"`
+	Storage        StorageConfig        `mapstructure:"storage"`
+	Scale          ScaleConfig          `mapstructure:"scale"`
+	WriteOff       WriteOffConfig       `mapstructure:"write_off"`
+	StokSnapshot   StokSnapshotConfig   `mapstructure:"stok_snapshot"`
+	LotHold        LotHoldConfig        `mapstructure:"lot_hold"`
 }
 
 type DatabaseConfig struct {
@@ -50,6 +56,49 @@ type AuthenticationConfig struct {
 	RefreshTokenExpiry   time.Duration `mapstructure:"refresh_token_expiry"`
 }
 
+type LabelConfig struct {
+	DefaultPrinter string                 `mapstructure:"default_printer"`
+	Printers       []PrinterProfileConfig `mapstructure:"printers"`
+}
+
+type PrinterProfileConfig struct {
+	Name     string  `mapstructure:"name"`
+	WidthMM  float64 `mapstructure:"width_mm"`
+	HeightMM float64 `mapstructure:"height_mm"`
+	DPI      int     `mapstructure:"dpi"`
+}
+
+type StorageConfig struct {
+	Driver        string `mapstructure:"driver"`
+	LocalPath     string `mapstructure:"local_path"`
+	MaxUploadSize int64  `mapstructure:"max_upload_size"`
+}
+
+type ScaleConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	ReadTimeout   time.Duration `mapstructure:"read_timeout"`
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+// WriteOffConfig sets the weight in kg above which a non-admin write-off waits for admin approval
+type WriteOffConfig struct {
+	AmbangBerat float64 `mapstructure:"ambang_berat"`
+}
+
+// StokSnapshotConfig runs the daily stock snapshot job, checking every Interval for a missing day
+type StokSnapshotConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// LotHoldConfig runs the job that expires lot holds every Interval and bounds how long a hold lasts
+type LotHoldConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Interval      time.Duration `mapstructure:"interval"`
+	DefaultDurasi time.Duration `mapstructure:"default_durasi"`
+	MaksDurasi    time.Duration `mapstructure:"maks_durasi"`
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("env")
 	viper.SetConfigType("yaml")
@@ -3,7 +3,9 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 
+	"github.com/lib/pq"
 	"github.com/uptrace/bun"
 )
 
@@ -32,3 +34,12 @@ func RunInTx(
 	done = true
 	return tx.Commit()
 }
+
+// IsUniqueViolation reports whether err is a postgres unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
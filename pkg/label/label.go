@@ -0,0 +1,96 @@
+package label
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+)
+
+type Symbology string
+
+const (
+	SymbologyQR      Symbology = "qr"
+	SymbologyCode128 Symbology = "code128"
+)
+
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+	FormatPDF Format = "pdf"
+	FormatZPL Format = "zpl"
+)
+
+// Label is a single printable tag: Code is encoded into the barcode, Lines are printed as text next to it
+type Label struct {
+	Code  string
+	Lines []string
+}
+
+func ParseSymbology(value string) (Symbology, error) {
+	switch Symbology(strings.ToLower(value)) {
+	case "", SymbologyQR:
+		return SymbologyQR, nil
+	case SymbologyCode128:
+		return SymbologyCode128, nil
+	}
+	return "", fmt.Errorf("symbology tidak didukung: %s", value)
+}
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case "", FormatPNG:
+		return FormatPNG, nil
+	case FormatSVG:
+		return FormatSVG, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	case FormatZPL:
+		return FormatZPL, nil
+	}
+	return "", fmt.Errorf("format tidak didukung: %s", value)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatZPL:
+		return "text/plain; charset=utf-8"
+	}
+	return "image/png"
+}
+
+// encode returns the unscaled barcode, one pixel per module
+func encode(code string, symbology Symbology) (barcode.Barcode, error) {
+	if code == "" {
+		return nil, fmt.Errorf("kode label kosong")
+	}
+
+	switch symbology {
+	case SymbologyCode128:
+		return code128.Encode(code)
+	default:
+		return qr.Encode(code, qr.M, qr.Auto)
+	}
+}
+
+// modules converts a barcode into a matrix of dark modules, which every renderer draws from
+func modules(bc barcode.Barcode) [][]bool {
+	bounds := bc.Bounds()
+	matrix := make([][]bool, bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		matrix[y] = make([]bool, bounds.Dx())
+		for x := 0; x < bounds.Dx(); x++ {
+			r, _, _, _ := bc.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			matrix[y][x] = r < 0x8000
+		}
+	}
+	return matrix
+}
@@ -0,0 +1,51 @@
+package label
+
+const (
+	margin       = 20
+	qrSize       = 200
+	barcodeWidth = 440
+	barcodeHigh  = 100
+	textWidth    = 260
+	lineHeight   = 18
+	fontAscent   = 13
+)
+
+// layout holds pixel positions shared by the PNG and SVG renderers
+type layout struct {
+	width, height    int
+	barX, barY       int
+	moduleW, moduleH int
+	textX, textY     int
+}
+
+func newLayout(matrix [][]bool, symbology Symbology, lineCount int) layout {
+	rows := len(matrix)
+	cols := 0
+	if rows > 0 {
+		cols = len(matrix[0])
+	}
+	if cols == 0 {
+		cols = 1
+	}
+
+	l := layout{barX: margin, barY: margin}
+
+	if symbology == SymbologyCode128 {
+		l.moduleW = max(1, barcodeWidth/cols)
+		l.moduleH = barcodeHigh / max(1, rows)
+		l.textX = margin
+		l.textY = margin + barcodeHigh + margin + fontAscent
+		l.width = max(cols*l.moduleW+2*margin, barcodeWidth+2*margin)
+		l.height = l.textY + lineCount*lineHeight
+		return l
+	}
+
+	l.moduleW = max(2, qrSize/cols)
+	l.moduleH = l.moduleW
+	size := cols * l.moduleW
+	l.textX = margin + size + margin
+	l.textY = margin + fontAscent
+	l.width = l.textX + textWidth
+	l.height = max(size, lineCount*lineHeight) + 2*margin
+	return l
+}
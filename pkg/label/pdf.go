@@ -0,0 +1,101 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// A4 sheet with 3 x 8 labels of 70 x 37 mm, the common self-adhesive layout used at grading
+const (
+	sheetColumns = 3
+	sheetRows    = 8
+	cellWidth    = 70.0
+	cellHeight   = 37.125
+	cellPadding  = 3.0
+	textSize     = 7.0
+	textLeading  = 3.4
+)
+
+// RenderSheetPDF lays out labels on as many A4 pages as needed
+func RenderSheetPDF(labels []Label, symbology Symbology) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("tidak ada label untuk dicetak")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", textSize)
+
+	perPage := sheetColumns * sheetRows
+	for i, lbl := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		slot := i % perPage
+		x := float64(slot%sheetColumns) * cellWidth
+		y := float64(slot/sheetColumns) * cellHeight
+
+		if err := drawCell(pdf, fmt.Sprintf("label-%d", i), lbl, symbology, x, y); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawCell(pdf *gofpdf.Fpdf, name string, lbl Label, symbology Symbology, x, y float64) error {
+	img, err := barcodePNG(lbl.Code, symbology)
+	if err != nil {
+		return err
+	}
+
+	opts := gofpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(img))
+
+	var textX, textY, textW float64
+	if symbology == SymbologyCode128 {
+		barH := 12.0
+		pdf.ImageOptions(name, x+cellPadding, y+cellPadding, cellWidth-2*cellPadding, barH, false, opts, 0, "")
+		textX = x + cellPadding
+		textY = y + cellPadding + barH + 1
+		textW = cellWidth - 2*cellPadding
+	} else {
+		size := cellHeight - 2*cellPadding
+		pdf.ImageOptions(name, x+cellPadding, y+cellPadding, size, size, false, opts, 0, "")
+		textX = x + cellPadding + size + 2
+		textY = y + cellPadding + 1
+		textW = cellWidth - size - 2*cellPadding - 2
+	}
+
+	maxLines := int((y + cellHeight - cellPadding - textY) / textLeading)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	for i, line := range lbl.Lines {
+		if i >= maxLines {
+			break
+		}
+		pdf.SetXY(textX, textY+float64(i)*textLeading)
+		pdf.CellFormat(textW, textLeading, fitText(pdf, tr(line), textW), "", 0, "L", false, 0, "")
+	}
+
+	return pdf.Error()
+}
+
+// fitText trims a line so it never spills into the neighbouring label
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"..") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
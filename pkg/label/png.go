@@ -0,0 +1,88 @@
+package label
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPNG draws the barcode with its text lines on a white canvas
+func RenderPNG(lbl Label, symbology Symbology) ([]byte, error) {
+	bc, err := encode(lbl.Code, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := modules(bc)
+	l := newLayout(matrix, symbology, len(lbl.Lines))
+
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for y, row := range matrix {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			rect := image.Rect(
+				l.barX+x*l.moduleW, l.barY+y*l.moduleH,
+				l.barX+(x+1)*l.moduleW, l.barY+(y+1)*l.moduleH,
+			)
+			draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+		}
+	}
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.Black),
+		Face: basicfont.Face7x13,
+	}
+	for i, line := range lbl.Lines {
+		drawer.Dot = fixed.P(l.textX, l.textY+i*lineHeight)
+		drawer.DrawString(line)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// barcodePNG renders only the barcode, used as the image source for PDF sheets
+func barcodePNG(code string, symbology Symbology) ([]byte, error) {
+	bc, err := encode(code, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := modules(bc)
+	moduleW, moduleH := 8, 8
+	if symbology == SymbologyCode128 {
+		moduleW, moduleH = 4, 160
+	}
+
+	rows, cols := len(matrix), len(matrix[0])
+	img := image.NewGray(image.Rect(0, 0, cols*moduleW, rows*moduleH))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for y, row := range matrix {
+		for x, dark := range row {
+			if dark {
+				rect := image.Rect(x*moduleW, y*moduleH, (x+1)*moduleW, (y+1)*moduleH)
+				draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
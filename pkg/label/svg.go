@@ -0,0 +1,51 @@
+package label
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// RenderSVG produces a vector version of RenderPNG so labels stay sharp at any print size
+func RenderSVG(lbl Label, symbology Symbology) ([]byte, error) {
+	bc, err := encode(lbl.Code, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := modules(bc)
+	l := newLayout(matrix, symbology, len(lbl.Lines))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, l.width, l.height)
+
+	buf.WriteString(`<g fill="#000">`)
+	for y, row := range matrix {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// merge horizontal runs to keep the document small
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d"/>`,
+				l.barX+start*l.moduleW, l.barY+y*l.moduleH, (x-start+1)*l.moduleW, l.moduleH)
+		}
+	}
+	buf.WriteString(`</g>`)
+
+	buf.WriteString(`<g font-family="monospace" font-size="13" fill="#000">`)
+	for i, line := range lbl.Lines {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d">`, l.textX, l.textY+i*lineHeight)
+		if err := xml.EscapeText(&buf, []byte(line)); err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</text>`)
+	}
+	buf.WriteString(`</g></svg>`)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrinterProfile describes the label stock loaded in a thermal printer
+type PrinterProfile struct {
+	Name     string  `json:"name"`
+	WidthMM  float64 `json:"width_mm"`
+	HeightMM float64 `json:"height_mm"`
+	DPI      int     `json:"dpi"`
+}
+
+// DefaultPrinterProfile is a 50 x 30 mm label on a 203 dpi printer
+var DefaultPrinterProfile = PrinterProfile{Name: "default", WidthMM: 50, HeightMM: 30, DPI: 203}
+
+type PrinterProfiles struct {
+	defaultName string
+	profiles    map[string]PrinterProfile
+}
+
+func NewPrinterProfiles(defaultName string, profiles []PrinterProfile) *PrinterProfiles {
+	p := &PrinterProfiles{
+		defaultName: defaultName,
+		profiles:    make(map[string]PrinterProfile, len(profiles)+1),
+	}
+	for _, profile := range profiles {
+		if profile.DPI <= 0 {
+			profile.DPI = DefaultPrinterProfile.DPI
+		}
+		p.profiles[profile.Name] = profile
+	}
+	if _, ok := p.profiles[DefaultPrinterProfile.Name]; !ok {
+		p.profiles[DefaultPrinterProfile.Name] = DefaultPrinterProfile
+	}
+	if _, ok := p.profiles[p.defaultName]; !ok {
+		p.defaultName = DefaultPrinterProfile.Name
+	}
+	return p
+}
+
+// Get resolves a profile by name, an empty name returns the configured default
+func (p *PrinterProfiles) Get(name string) (PrinterProfile, error) {
+	if name == "" {
+		name = p.defaultName
+	}
+	profile, ok := p.profiles[name]
+	if !ok {
+		return PrinterProfile{}, fmt.Errorf("profil printer tidak ditemukan: %s", name)
+	}
+	return profile, nil
+}
+
+func (p *PrinterProfiles) List() []PrinterProfile {
+	list := make([]PrinterProfile, 0, len(p.profiles))
+	for _, profile := range p.profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func (p PrinterProfile) dots(mm float64) int {
+	return int(mm * float64(p.DPI) / 25.4)
+}
+
+// RenderZPL returns one ^XA..^XZ block per label, ready to be sent to a Zebra compatible printer as is
+func RenderZPL(labels []Label, symbology Symbology, profile PrinterProfile) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("tidak ada label untuk dicetak")
+	}
+
+	var buf bytes.Buffer
+	for _, lbl := range labels {
+		if err := writeZPL(&buf, lbl, symbology, profile); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZPL(buf *bytes.Buffer, lbl Label, symbology Symbology, profile PrinterProfile) error {
+	bc, err := encode(lbl.Code, symbology)
+	if err != nil {
+		return err
+	}
+	cols := bc.Bounds().Dx()
+
+	width := profile.dots(profile.WidthMM)
+	height := profile.dots(profile.HeightMM)
+	margin := profile.dots(2)
+
+	buf.WriteString("^XA^CI28\n")
+	fmt.Fprintf(buf, "^PW%d^LL%d^LH0,0\n", width, height)
+
+	var textX, textY, textW int
+	if symbology == SymbologyCode128 {
+		moduleW := clamp((width-2*margin)/max(1, cols), 1, 10)
+		barH := height * 35 / 100
+		fmt.Fprintf(buf, "^FO%d,%d^BY%d^BCN,%d,N,N,N^FD%s^FS\n", margin, margin, moduleW, barH, lbl.Code)
+		textX = margin
+		textY = margin + barH + margin
+		textW = width - 2*margin
+	} else {
+		// QR must fit the label height and leave at least half of the width for text
+		mag := clamp(min((height-2*margin)/max(1, cols), (width/2-margin)/max(1, cols)), 1, 10)
+		fmt.Fprintf(buf, "^FO%d,%d^BQN,2,%d^FDMA,%s^FS\n", margin, margin, mag, lbl.Code)
+		textX = margin + cols*mag + margin
+		textY = margin
+		textW = width - textX - margin
+	}
+
+	lines := lbl.Lines
+	fontH := zplFontHeight(lines, textW, height-textY-margin, profile.DPI)
+	lineH := fontH + fontH/4
+	for i, line := range lines {
+		y := textY + i*lineH
+		if y+fontH > height-margin {
+			break
+		}
+		fmt.Fprintf(buf, "^FO%d,%d^A0N,%d,%d^FB%d,1,0,L,0^FH_^FD%s^FS\n", textX, y, fontH, fontH, textW, zplEscape(line))
+	}
+
+	buf.WriteString("^XZ\n")
+	return nil
+}
+
+// zplFontHeight picks the largest font that fits every line in the text area, the printer's A0 font is about 0.55em wide
+func zplFontHeight(lines []string, width, height, dpi int) int {
+	longest := 1
+	for _, line := range lines {
+		longest = max(longest, len([]rune(line)))
+	}
+
+	byHeight := height * 4 / (max(1, len(lines)) * 5)
+	byWidth := int(float64(width) / (float64(longest) * 0.55))
+	return clamp(min(byHeight, byWidth), 12, dpi/6)
+}
+
+// zplEscape hex encodes the characters that ZPL treats as commands, ^FH_ turns them back into text
+func zplEscape(value string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(value)
+}
+
+func clamp(v, lo, hi int) int {
+	if hi < lo {
+		hi = lo
+	}
+	return min(max(v, lo), hi)
+}
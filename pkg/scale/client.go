@@ -0,0 +1,217 @@
+package scale
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"time"
+)
+
+// Transport of the connection to the indicator
+const (
+	// TransportTCP is an indicator with an ethernet port or a bridge in raw mode
+	TransportTCP = "tcp"
+	// TransportTelnet is a serial device server speaking RFC 2217, telnet commands are stripped from the stream
+	TransportTelnet = "telnet"
+)
+
+const maxFrameSize = 256
+
+// Client keeps a connection to one indicator open and reports every decoded frame
+type Client struct {
+	Address       string
+	Protocol      string
+	Transport     string
+	DialTimeout   time.Duration
+	ReadTimeout   time.Duration
+	RetryInterval time.Duration
+
+	OnReading func(Reading)
+	OnError   func(error)
+	OnConnect func(connected bool)
+}
+
+// Run reads until ctx is cancelled, reconnecting after every failure
+func (c *Client) Run(ctx context.Context) error {
+	retry := c.RetryInterval
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+
+	for {
+		err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && c.OnError != nil {
+			c.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+func (c *Client) session(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: c.DialTimeout}
+	if dialer.Timeout <= 0 {
+		dialer.Timeout = 5 * time.Second
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the reader when the context ends
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if c.OnConnect != nil {
+		c.OnConnect(true)
+		defer c.OnConnect(false)
+	}
+
+	readTimeout := c.ReadTimeout
+	if readTimeout <= 0 {
+		readTimeout = 10 * time.Second
+	}
+
+	var r io.Reader = conn
+	if c.Transport == TransportTelnet {
+		r = &telnetReader{r: conn}
+	}
+
+	scanner := bufio.NewScanner(&deadlineReader{conn: conn, r: r, timeout: readTimeout})
+	scanner.Buffer(make([]byte, maxFrameSize), maxFrameSize)
+	scanner.Split(scanFrames)
+
+	for scanner.Scan() {
+		frame := scanner.Bytes()
+		if len(bytes.TrimSpace(frame)) == 0 {
+			continue
+		}
+
+		reading, err := Parse(c.Protocol, frame)
+		if err != nil {
+			if c.OnError != nil {
+				c.OnError(err)
+			}
+			continue
+		}
+		if c.OnReading != nil {
+			c.OnReading(reading)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// scanFrames splits on CR or LF, so CRLF, bare CR and bare LF terminated indicators all work
+func scanFrames(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// deadlineReader fails the read when the indicator stops sending, which surfaces half-open connections
+type deadlineReader struct {
+	conn    net.Conn
+	r       io.Reader
+	timeout time.Duration
+}
+
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	if err := d.conn.SetReadDeadline(time.Now().Add(d.timeout)); err != nil {
+		return 0, err
+	}
+	return d.r.Read(p)
+}
+
+// Telnet command bytes
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetDONT = 254
+	telnetIAC  = 255
+)
+
+// telnetReader drops telnet negotiation from an RFC 2217 stream and unescapes doubled IAC bytes
+type telnetReader struct {
+	r     io.Reader
+	state int
+	buf   [512]byte
+}
+
+const (
+	telnetData = iota
+	telnetCommand
+	telnetOption
+	telnetSub
+	telnetSubIAC
+)
+
+func (t *telnetReader) Read(p []byte) (int, error) {
+	for {
+		max := len(p)
+		if max > len(t.buf) {
+			max = len(t.buf)
+		}
+		n, err := t.r.Read(t.buf[:max])
+
+		out := 0
+		for _, b := range t.buf[:n] {
+			switch t.state {
+			case telnetData:
+				if b == telnetIAC {
+					t.state = telnetCommand
+					continue
+				}
+				p[out] = b
+				out++
+			case telnetCommand:
+				switch {
+				case b == telnetIAC:
+					p[out] = b
+					out++
+					t.state = telnetData
+				case b == telnetSB:
+					t.state = telnetSub
+				case b >= telnetWILL && b <= telnetDONT:
+					t.state = telnetOption
+				default:
+					t.state = telnetData
+				}
+			case telnetOption:
+				t.state = telnetData
+			case telnetSub:
+				if b == telnetIAC {
+					t.state = telnetSubIAC
+				}
+			case telnetSubIAC:
+				if b == telnetSE {
+					t.state = telnetData
+				} else {
+					t.state = telnetSub
+				}
+			}
+		}
+
+		if out > 0 || err != nil {
+			return out, err
+		}
+	}
+}
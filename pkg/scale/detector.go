@@ -0,0 +1,67 @@
+package scale
+
+import "math"
+
+// Detector turns a stream of readings into one captured weight per item placed on the pan.
+// A capture needs Samples consecutive motion-free readings within Tolerance of each other,
+// and the pan must drop below MinWeight before the next capture. The detector starts disarmed,
+// so whatever is already on the pan when the connection comes up is never recorded.
+type Detector struct {
+	MinWeight float64
+	Tolerance float64
+	Samples   int
+
+	armed  bool
+	window []float64
+}
+
+// Feed returns the captured weight when r completes a stable window
+func (d *Detector) Feed(r Reading) (float64, bool) {
+	if r.Weight < d.MinWeight {
+		d.armed = true
+		d.window = d.window[:0]
+		return 0, false
+	}
+	if !d.armed {
+		return 0, false
+	}
+	if r.Motion {
+		d.window = d.window[:0]
+		return 0, false
+	}
+
+	d.window = append(d.window, r.Weight)
+	lo, hi := d.window[0], d.window[0]
+	for _, w := range d.window {
+		lo = math.Min(lo, w)
+		hi = math.Max(hi, w)
+	}
+	if hi-lo > d.Tolerance {
+		d.window = append(d.window[:0], r.Weight)
+		return 0, false
+	}
+
+	samples := d.Samples
+	if samples < 1 {
+		samples = 1
+	}
+	if len(d.window) < samples {
+		return 0, false
+	}
+
+	var sum float64
+	for _, w := range d.window {
+		sum += w
+	}
+	weight := roundGram(sum / float64(len(d.window)))
+
+	d.armed = false
+	d.window = d.window[:0]
+	return weight, true
+}
+
+// Reset disarms the detector, used after a reconnect
+func (d *Detector) Reset() {
+	d.armed = false
+	d.window = d.window[:0]
+}
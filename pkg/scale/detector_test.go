@@ -0,0 +1,102 @@
+package scale
+
+import (
+	"math"
+	"testing"
+)
+
+func weights(values ...float64) []Reading {
+	list := make([]Reading, 0, len(values))
+	for _, v := range values {
+		list = append(list, Reading{Weight: v})
+	}
+	return list
+}
+
+func TestDetectorFeed(t *testing.T) {
+	moving := Reading{Weight: 5, Motion: true}
+
+	tests := []struct {
+		name     string
+		readings []Reading
+		want     []float64
+	}{
+		{
+			name:     "whatever is on the pan at start is skipped",
+			readings: weights(5, 5, 5, 5),
+		},
+		{
+			name:     "captures once the pan was empty",
+			readings: weights(0, 5, 5, 5),
+			want:     []float64{5},
+		},
+		{
+			name:     "one capture per item",
+			readings: weights(0, 5, 5, 5, 5, 5, 5),
+			want:     []float64{5},
+		},
+		{
+			name:     "next item after the pan empties",
+			readings: weights(0, 5, 5, 5, 0.1, 3, 3, 3),
+			want:     []float64{5, 3},
+		},
+		{
+			name:     "motion restarts the window",
+			readings: append(append(weights(0, 5, 5), moving), weights(5, 5)...),
+		},
+		{
+			name:     "stable again after motion",
+			readings: append(append(weights(0, 5, 5), moving), weights(5, 5, 5)...),
+			want:     []float64{5},
+		},
+		{
+			name:     "drift beyond tolerance restarts the window",
+			readings: weights(0, 5, 5.05, 5.05, 5.05),
+			want:     []float64{5.05},
+		},
+		{
+			name:     "readings within tolerance are averaged",
+			readings: weights(0, 5, 5.006, 5.003),
+			want:     []float64{5.003},
+		},
+		{
+			name:     "below the minimum never captures",
+			readings: weights(0, 0.3, 0.3, 0.3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Detector{MinWeight: 0.5, Tolerance: 0.01, Samples: 3}
+
+			var got []float64
+			for _, r := range tt.readings {
+				if w, ok := d.Feed(r); ok {
+					got = append(got, w)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("captured %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("capture %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDetectorReset(t *testing.T) {
+	d := &Detector{MinWeight: 0.5, Tolerance: 0.01, Samples: 2}
+	d.Feed(Reading{Weight: 0})
+	d.Feed(Reading{Weight: 4})
+	d.Reset()
+
+	for _, r := range weights(4, 4, 4) {
+		if w, ok := d.Feed(r); ok {
+			t.Fatalf("captured %v after a reset without the pan emptying", w)
+		}
+	}
+}
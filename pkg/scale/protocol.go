@@ -0,0 +1,191 @@
+package scale
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Indicator output formats. Every format is a stream of frames terminated by CR and/or LF.
+const (
+	// ProtocolAND is the "ST,GS,+0012.345kg" header format used by A&D and most Chinese indicators
+	ProtocolAND = "and"
+	// ProtocolToledo is Mettler Toledo continuous output: STX, three status bytes, 6 digit weight, 6 digit tare
+	ProtocolToledo = "toledo"
+	// ProtocolSimple is a bare number with an optional unit, for indicators without a status field
+	ProtocolSimple = "simple"
+)
+
+var (
+	ErrUnknownProtocol = errors.New("protokol timbangan tidak dikenal")
+	ErrOverload        = errors.New("timbangan overload")
+)
+
+// Reading is one decoded frame, always in kilograms
+type Reading struct {
+	Weight float64 `json:"weight"`
+	Motion bool    `json:"motion"`
+	Raw    string  `json:"raw"`
+}
+
+func ValidProtocol(protocol string) bool {
+	switch protocol {
+	case ProtocolAND, ProtocolToledo, ProtocolSimple:
+		return true
+	}
+	return false
+}
+
+// Parse decodes a single frame without its line terminator
+func Parse(protocol string, frame []byte) (Reading, error) {
+	frame = bytes.Trim(frame, "\r\n\x00")
+	switch protocol {
+	case ProtocolAND:
+		return parseAND(frame)
+	case ProtocolToledo:
+		return parseToledo(frame)
+	case ProtocolSimple:
+		return parseSimple(frame)
+	}
+	return Reading{}, ErrUnknownProtocol
+}
+
+func parseAND(frame []byte) (Reading, error) {
+	line := strings.TrimSpace(string(frame))
+	parts := strings.SplitN(line, ",", 3)
+	if len(parts) != 3 {
+		return Reading{}, fmt.Errorf("frame tidak valid: %q", line)
+	}
+
+	var motion bool
+	switch strings.TrimSpace(parts[0]) {
+	case "ST":
+	case "US":
+		motion = true
+	case "OL":
+		return Reading{}, ErrOverload
+	default:
+		return Reading{}, fmt.Errorf("status tidak dikenal: %q", parts[0])
+	}
+
+	weight, err := parseWeight(parts[2])
+	if err != nil {
+		return Reading{}, err
+	}
+	return Reading{Weight: weight, Motion: motion, Raw: line}, nil
+}
+
+func parseSimple(frame []byte) (Reading, error) {
+	line := strings.TrimSpace(string(frame))
+	weight, err := parseWeight(line)
+	if err != nil {
+		return Reading{}, err
+	}
+	return Reading{Weight: weight, Raw: line}, nil
+}
+
+// parseWeight reads a signed number followed by an optional kg, g or lb unit
+func parseWeight(s string) (float64, error) {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+
+	factor := 1.0
+	switch {
+	case strings.HasSuffix(s, "kg"):
+		s = strings.TrimSuffix(s, "kg")
+	case strings.HasSuffix(s, "lb"):
+		s = strings.TrimSuffix(s, "lb")
+		factor = 0.45359237
+	case strings.HasSuffix(s, "g"):
+		s = strings.TrimSuffix(s, "g")
+		factor = 0.001
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("berat tidak valid: %q", s)
+	}
+	return roundGram(v * factor), nil
+}
+
+// Toledo status word bits
+const (
+	toledoSTX       = 0x02
+	toledoSignBit   = 1 << 1
+	toledoRangeBit  = 1 << 2
+	toledoMotionBit = 1 << 3
+	toledoKgBit     = 1 << 4
+)
+
+func parseToledo(frame []byte) (Reading, error) {
+	if i := bytes.IndexByte(frame, toledoSTX); i >= 0 {
+		frame = frame[i+1:]
+	}
+	if len(frame) < 9 {
+		return Reading{}, fmt.Errorf("frame toledo terlalu pendek: %q", frame)
+	}
+
+	swa, swb := frame[0], frame[1]
+	if swb&toledoRangeBit != 0 {
+		return Reading{}, ErrOverload
+	}
+
+	raw, err := strconv.Atoi(strings.TrimSpace(string(frame[3:9])))
+	if err != nil {
+		return Reading{}, fmt.Errorf("berat toledo tidak valid: %q", frame[3:9])
+	}
+
+	// SWA bits 0-2 place the decimal point: 0 = x100, 1 = x10, 2 = x1, 3..7 = 1..5 decimals
+	weight := float64(raw) * math.Pow10(2-int(swa&0x07))
+	if swb&toledoSignBit != 0 {
+		weight = -weight
+	}
+	if swb&toledoKgBit == 0 {
+		weight *= 0.45359237
+	}
+
+	return Reading{
+		Weight: roundGram(weight),
+		Motion: swb&toledoMotionBit != 0,
+		Raw:    string(frame),
+	}, nil
+}
+
+// Format encodes a reading the way an indicator of the given protocol would send it, CRLF included.
+// It backs the simulator and uses 3 decimals (1 gram) everywhere.
+func Format(protocol string, r Reading) ([]byte, error) {
+	switch protocol {
+	case ProtocolAND:
+		status := "ST"
+		if r.Motion {
+			status = "US"
+		}
+		return []byte(fmt.Sprintf("%s,GS,%+09.3fkg\r\n", status, r.Weight)), nil
+
+	case ProtocolToledo:
+		// SWA: bit5 always set, decimal code 5 = three decimals
+		swa := byte(0x20 | 0x05)
+		swb := byte(0x20 | toledoKgBit)
+		if r.Weight < 0 {
+			swb |= toledoSignBit
+		}
+		if r.Motion {
+			swb |= toledoMotionBit
+		}
+		grams := int(math.Round(math.Abs(r.Weight) * 1000))
+		if grams > 999999 {
+			return nil, ErrOverload
+		}
+		return []byte(fmt.Sprintf("\x02%c%c%c%06d%06d\r\n", swa, swb, 0x20, grams, 0)), nil
+
+	case ProtocolSimple:
+		return []byte(fmt.Sprintf("%.3f kg\r\n", r.Weight)), nil
+	}
+	return nil, ErrUnknownProtocol
+}
+
+func roundGram(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
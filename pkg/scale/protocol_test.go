@@ -0,0 +1,96 @@
+package scale
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		protocol   string
+		frame      string
+		wantWeight float64
+		wantMotion bool
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{name: "and stable", protocol: ProtocolAND, frame: "ST,GS,+0012.345kg\r\n", wantWeight: 12.345},
+		{name: "and motion", protocol: ProtocolAND, frame: "US,GS,+0012.345kg", wantWeight: 12.345, wantMotion: true},
+		{name: "and negative", protocol: ProtocolAND, frame: "ST,GS,-0000.500kg", wantWeight: -0.5},
+		{name: "and grams", protocol: ProtocolAND, frame: "ST,NT,+1234g", wantWeight: 1.234},
+		{name: "and pounds", protocol: ProtocolAND, frame: "ST,GS,10lb", wantWeight: 4.536},
+		{name: "and overload", protocol: ProtocolAND, frame: "OL,GS,+9999.999kg", wantErr: ErrOverload},
+		{name: "and unknown status", protocol: ProtocolAND, frame: "XX,GS,+0012.345kg", wantAnyErr: true},
+		{name: "and missing field", protocol: ProtocolAND, frame: "ST,GS", wantAnyErr: true},
+		{name: "and bad number", protocol: ProtocolAND, frame: "ST,GS,12.3.4kg", wantAnyErr: true},
+		{name: "toledo kg three decimals", protocol: ProtocolToledo, frame: "\x02%0 012345000000\r\n", wantWeight: 12.345},
+		{name: "toledo negative", protocol: ProtocolToledo, frame: "\x02%2 012345000000", wantWeight: -12.345},
+		{name: "toledo motion", protocol: ProtocolToledo, frame: "\x02%8 012345000000", wantWeight: 12.345, wantMotion: true},
+		{name: "toledo whole units", protocol: ProtocolToledo, frame: "\x02\"0 000042000000", wantWeight: 42},
+		{name: "toledo pounds", protocol: ProtocolToledo, frame: "\x02%  012345000000", wantWeight: 5.6},
+		{name: "toledo overload", protocol: ProtocolToledo, frame: "\x02%4 012345000000", wantErr: ErrOverload},
+		{name: "toledo too short", protocol: ProtocolToledo, frame: "\x02%0 0123", wantAnyErr: true},
+		{name: "simple kg", protocol: ProtocolSimple, frame: "12.5 kg\r\n", wantWeight: 12.5},
+		{name: "simple bare number", protocol: ProtocolSimple, frame: " 3.25 ", wantWeight: 3.25},
+		{name: "simple grams", protocol: ProtocolSimple, frame: "800 g", wantWeight: 0.8},
+		{name: "simple garbage", protocol: ProtocolSimple, frame: "abc", wantAnyErr: true},
+		{name: "unknown protocol", protocol: "ohaus", frame: "12.5", wantErr: ErrUnknownProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.protocol, []byte(tt.frame))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			case tt.wantAnyErr:
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", r)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if math.Abs(r.Weight-tt.wantWeight) > 1e-9 {
+				t.Errorf("Weight = %v, want %v", r.Weight, tt.wantWeight)
+			}
+			if r.Motion != tt.wantMotion {
+				t.Errorf("Motion = %v, want %v", r.Motion, tt.wantMotion)
+			}
+		})
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	readings := []Reading{
+		{Weight: 2.5},
+		{Weight: 12.345, Motion: true},
+		{Weight: -0.75},
+	}
+
+	for _, protocol := range []string{ProtocolAND, ProtocolToledo, ProtocolSimple} {
+		for _, want := range readings {
+			frame, err := Format(protocol, want)
+			if err != nil {
+				t.Fatalf("%s: Format(%+v): %v", protocol, want, err)
+			}
+			got, err := Parse(protocol, frame)
+			if err != nil {
+				t.Fatalf("%s: Parse(%q): %v", protocol, frame, err)
+			}
+			if math.Abs(got.Weight-want.Weight) > 1e-9 {
+				t.Errorf("%s: Weight = %v, want %v", protocol, got.Weight, want.Weight)
+			}
+			// The simple format carries no status
+			if protocol != ProtocolSimple && got.Motion != want.Motion {
+				t.Errorf("%s: Motion = %v, want %v", protocol, got.Motion, want.Motion)
+			}
+		}
+	}
+}
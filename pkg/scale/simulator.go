@@ -0,0 +1,111 @@
+package scale
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Simulator is a software indicator. It streams the current pan weight to every connected client,
+// in motion with some jitter for a few frames after each change, then stable.
+type Simulator struct {
+	protocol     string
+	interval     time.Duration
+	settleFrames int
+
+	mu     sync.Mutex
+	weight float64
+	settle int
+}
+
+func NewSimulator(protocol string, interval time.Duration) (*Simulator, error) {
+	if !ValidProtocol(protocol) {
+		return nil, ErrUnknownProtocol
+	}
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+	return &Simulator{protocol: protocol, interval: interval, settleFrames: 5}, nil
+}
+
+// Place puts an item of the given weight on the pan, replacing whatever was there
+func (s *Simulator) Place(kg float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.weight = kg
+	s.settle = s.settleFrames
+}
+
+// Clear empties the pan
+func (s *Simulator) Clear() {
+	s.Place(0)
+}
+
+func (s *Simulator) next() Reading {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.settle > 0 {
+		s.settle--
+		jitter := (rand.Float64() - 0.5) * 0.1 * s.weight
+		return Reading{Weight: roundGram(s.weight + jitter), Motion: true}
+	}
+	return Reading{Weight: s.weight}
+}
+
+// Serve accepts connections on ln until ctx is cancelled
+func (s *Simulator) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	var (
+		mu      sync.Mutex
+		clients = make(map[net.Conn]struct{})
+	)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				mu.Lock()
+				for conn := range clients {
+					conn.Close()
+				}
+				mu.Unlock()
+				return
+			case <-ticker.C:
+				frame, err := Format(s.protocol, s.next())
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				for conn := range clients {
+					conn.SetWriteDeadline(time.Now().Add(s.interval * 5))
+					if _, err := conn.Write(frame); err != nil {
+						conn.Close()
+						delete(clients, conn)
+					}
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		clients[conn] = struct{}{}
+		mu.Unlock()
+	}
+}
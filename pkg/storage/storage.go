@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNotFound = errors.New("file tidak ditemukan")
+
+// Storage keeps blobs by key. Keys are slash separated and generated by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+const DriverLocal = "local"
+
+// New builds the storage selected by driver
+func New(driver, localPath string) (Storage, error) {
+	switch driver {
+	case "", DriverLocal:
+		return NewLocal(localPath)
+	}
+	return nil, fmt.Errorf("storage driver tidak didukung: %s", driver)
+}